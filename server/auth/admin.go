@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type IssueRequest struct {
+	Name   string        `json:"name" binding:"required"`
+	Scopes []string      `json:"scopes" binding:"required"`
+	Limits *utils.Limits `json:"limits"`
+}
+
+func RegisterAdminRoutes(r gin.IRouter, store *Store, adminToken string) {
+	admin := r.Group("/admin", RequireAdmin(adminToken))
+
+	admin.GET("/keys", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"keys": store.List()})
+	})
+
+	admin.POST("/keys", func(c *gin.Context) {
+		var req IssueRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		key, secret, err := store.Issue(req.Name, req.Scopes)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Limits != nil {
+			store.SetLimits(key.ID, req.Limits)
+			key.Limits = req.Limits
+		}
+		c.JSON(http.StatusCreated, gin.H{"key": key, "secret": secret})
+	})
+
+	admin.GET("/keys/:id/usage", func(c *gin.Context) {
+		key, ok := store.Get(c.Param("id"))
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "key not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"id": key.ID, "usage": key.Usage})
+	})
+
+	admin.PUT("/keys/:id/limits", func(c *gin.Context) {
+		var limits utils.Limits
+		if err := c.ShouldBindJSON(&limits); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !store.SetLimits(c.Param("id"), &limits) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "key not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "limits": limits})
+	})
+
+	admin.DELETE("/keys/:id/limits", func(c *gin.Context) {
+		if !store.SetLimits(c.Param("id"), nil) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "key not found"})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	admin.DELETE("/keys/:id", func(c *gin.Context) {
+		if !store.Revoke(c.Param("id")) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "key not found"})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
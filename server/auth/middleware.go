@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/middleware"
+	"toolkit-backend/utils"
+)
+
+const contextKey = "apiKey"
+
+func Operation(c *gin.Context) string {
+	return strings.TrimPrefix(c.FullPath(), "/")
+}
+
+func Authenticate(store *Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret := c.GetHeader(middleware.APIKeyHeader)
+		if secret == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing API key"})
+			return
+		}
+
+		key, ok := store.Lookup(secret)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+			return
+		}
+
+		if op := Operation(c); !key.Allows(op) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key not allowed to call " + op})
+			return
+		}
+
+		c.Set(contextKey, key)
+		// ContentLength is unknown for chunked or decompressed bodies, so
+		// usage counts the bytes actually read
+		body := &countingReader{ReadCloser: c.Request.Body}
+		if c.Request.Body != nil {
+			c.Request.Body = body
+		}
+		c.Next()
+
+		store.Record(key.ID, body.n)
+	}
+}
+
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func CurrentKey(c *gin.Context) (Key, bool) {
+	v, ok := c.Get(contextKey)
+	if !ok {
+		return Key{}, false
+	}
+	key, ok := v.(Key)
+	return key, ok
+}
+
+// KeyLimits is meant as the per-request hook of middleware.EnforceLimits
+func KeyLimits(c *gin.Context) (utils.Limits, bool) {
+	key, ok := CurrentKey(c)
+	if !ok || key.Limits == nil {
+		return utils.Limits{}, false
+	}
+	return *key.Limits, true
+}
+
+// KeyID is meant as the keyID hook of middleware.RateLimit. It identifies
+// the caller whether or not Authenticate has run yet.
+func (s *Store) KeyID(c *gin.Context) (string, bool) {
+	if key, ok := CurrentKey(c); ok {
+		return key.ID, true
+	}
+	secret := c.GetHeader(middleware.APIKeyHeader)
+	if secret == "" {
+		return "", false
+	}
+	key, ok := s.Lookup(secret)
+	return key.ID, ok
+}
+
+func RequireAdmin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "admin token required"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+type Config struct {
+	Backend    string        `json:"backend"`
+	MaxEntries int           `json:"maxEntries"`
+	MaxBytes   int64         `json:"maxBytes"`
+	MaxValue   int           `json:"maxValue"`
+	TTL        time.Duration `json:"ttl"`
+	RedisAddr  string        `json:"redisAddr"`
+	RedisPass  string        `json:"redisPassword"`
+	RedisDB    int           `json:"redisDb"`
+	// RedisConns bounds the open Redis connections, 16 by default, and
+	// RedisTimeout each Redis operation, 500ms by default
+	RedisConns   int           `json:"redisConns"`
+	RedisTimeout time.Duration `json:"redisTimeout"`
+	// MaxBody is the largest request body the middleware caches by, 1 MiB by
+	// default; larger requests go through uncached
+	MaxBody int64 `json:"maxBody"`
+}
+
+type Cache struct {
+	backend  Backend
+	ttl      time.Duration
+	maxValue int
+	maxBody  int64
+}
+
+const defaultMaxBody = 1 << 20
+
+func New(cfg Config) (*Cache, error) {
+	var backend Backend
+	switch cfg.Backend {
+	case "", "memory":
+		backend = NewMemory(cfg.MaxEntries, cfg.MaxBytes)
+	case "redis":
+		if cfg.RedisAddr == "" {
+			return nil, fmt.Errorf("redis cache requires an address")
+		}
+		backend = NewRedis(cfg.RedisAddr, cfg.RedisPass, cfg.RedisDB, cfg.RedisConns, cfg.RedisTimeout)
+	default:
+		return nil, fmt.Errorf("unknown cache backend: %s", cfg.Backend)
+	}
+	maxBody := cfg.MaxBody
+	if maxBody <= 0 {
+		maxBody = defaultMaxBody
+	}
+	return &Cache{backend: backend, ttl: cfg.TTL, maxValue: cfg.MaxValue, maxBody: maxBody}, nil
+}
+
+func Key(operation string, params interface{}, input string) string {
+	h := sha256.New()
+	h.Write([]byte(operation))
+	h.Write([]byte{0})
+	if params != nil {
+		encoded, _ := json.Marshal(params)
+		h.Write(encoded)
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(input))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool) {
+	value, ok, err := c.backend.Get(ctx, key)
+	if err != nil {
+		return nil, false
+	}
+	return value, ok
+}
+
+func (c *Cache) Set(ctx context.Context, key string, value []byte) {
+	if c.maxValue > 0 && len(value) > c.maxValue {
+		return
+	}
+	c.backend.Set(ctx, key, value, c.ttl)
+}
+
+func (c *Cache) Do(ctx context.Context, operation string, params interface{}, input string, compute func() (string, error)) (string, error) {
+	key := Key(operation, params, input)
+	if value, ok := c.Get(ctx, key); ok {
+		return string(value), nil
+	}
+
+	result, err := compute()
+	if err != nil {
+		return "", err
+	}
+	c.Set(ctx, key, []byte(result))
+	return result, nil
+}
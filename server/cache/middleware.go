@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/auth"
+)
+
+// DefaultRoutes are the pure transforms: their response depends on nothing
+// but the request. Routes that change state, read stored per-key resources
+// (presets, snippets, sessions, documents), draw random numbers or reach the
+// network (link checks) must never be cached, since a hit would skip the
+// handler.
+var DefaultRoutes = []string{
+	"/regex/test", "/replace/preview",
+	"/invisible/detect", "/whitespace/visualize", "/paste/clean", "/charmap",
+	"/markup/convert", "/emoji/to-shortcode", "/emoji/to-emoji",
+	"/mime/quoted-printable", "/mime/header",
+	"/palindrome", "/anagrams", "/phonetic", "/nearest",
+	"/near-duplicates", "/dedupe/paragraphs",
+	"/analyze/entropy", "/analyze/tokens", "/analyze/vocabulary", "/analyze/repeats",
+	"/analyze/style", "/analyze/progress", "/analyze/frequency", "/analyze/binary", "/analyze/outline",
+	"/checksum/verify", "/checksum/verify-list", "/validate/lines",
+	"/chunk", "/split/limit", "/csv/validate", "/csv/fix",
+	"/sentences", "/stopwords/remove", "/languages/tag", "/languages/filter",
+	"/frontmatter/extract", "/frontmatter/set", "/frontmatter/validate",
+	"/common", "/merge", "/fixed-width", "/lists/compare", "/lint/line-length",
+	"/bidi/analyze", "/bidi/isolate",
+	"/logs/normalize", "/logs/parse", "/logs/filter", "/logs/sort", "/logs/epoch/expand", "/logs/epoch/collapse",
+}
+
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Middleware caches the JSON responses of the given routes, DefaultRoutes if
+// none are given. Entries are keyed by the caller's API key as well as the
+// request, so it must run after auth.Authenticate where keys are in use.
+func Middleware(c *Cache, routes ...string) gin.HandlerFunc {
+	if len(routes) == 0 {
+		routes = DefaultRoutes
+	}
+	cacheable := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		cacheable[r] = true
+	}
+
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method != http.MethodPost || !cacheable[ctx.FullPath()] || ctx.Request.Body == nil {
+			ctx.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, c.maxBody+1))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		if int64(len(body)) > c.maxBody {
+			// too large to key by; the handler reads what is left as usual
+			ctx.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), ctx.Request.Body))
+			ctx.Next()
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		caller := ""
+		if key, ok := auth.CurrentKey(ctx); ok {
+			caller = key.ID
+		}
+		key := Key(ctx.FullPath(), []string{caller, ctx.Request.URL.RawQuery}, string(body))
+		if cached, ok := c.Get(ctx.Request.Context(), key); ok {
+			ctx.Header("X-Cache", "HIT")
+			ctx.Data(http.StatusOK, "application/json; charset=utf-8", cached)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Header("X-Cache", "MISS")
+		w := &recordingWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = w
+		ctx.Next()
+
+		if w.Status() == http.StatusOK && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			c.Set(ctx.Request.Context(), key, w.body.Bytes())
+		}
+	}
+}
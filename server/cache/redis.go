@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRedisConns   = 16
+	defaultRedisTimeout = 500 * time.Millisecond
+)
+
+// Redis keeps a small pool of connections. Every operation runs under a
+// deadline, the caller's or Timeout if sooner, so a hung server turns into
+// cache misses rather than stalled requests.
+type Redis struct {
+	addr     string
+	password string
+	db       int
+	timeout  time.Duration
+
+	// slots bounds the number of open connections
+	slots chan struct{}
+
+	mu     sync.Mutex
+	idle   []*redisConn
+	closed bool
+}
+
+type redisConn struct {
+	net.Conn
+	rw *bufio.ReadWriter
+}
+
+func NewRedis(addr, password string, db, maxConns int, timeout time.Duration) *Redis {
+	if maxConns <= 0 {
+		maxConns = defaultRedisConns
+	}
+	if timeout <= 0 {
+		timeout = defaultRedisTimeout
+	}
+	return &Redis{
+		addr:     addr,
+		password: password,
+		db:       db,
+		timeout:  timeout,
+		slots:    make(chan struct{}, maxConns),
+	}
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.do(ctx, "GET", key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+	return reply, true, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	_, err := r.do(ctx, args...)
+	return err
+}
+
+func (r *Redis) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	var err error
+	for _, conn := range r.idle {
+		if cerr := conn.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	r.idle = nil
+	return err
+}
+
+func (r *Redis) do(ctx context.Context, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	select {
+	case r.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("redis pool exhausted: %w", ctx.Err())
+	}
+	defer func() { <-r.slots }()
+
+	conn, err := r.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	reply, err := conn.roundTrip(args)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	r.put(conn)
+	return reply, nil
+}
+
+// get returns an idle connection or dials a new one; the caller holds a slot
+func (r *Redis) get(ctx context.Context) (*redisConn, error) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("redis client closed")
+	}
+	if n := len(r.idle); n > 0 {
+		conn := r.idle[n-1]
+		r.idle = r.idle[:n-1]
+		r.mu.Unlock()
+		return conn, nil
+	}
+	r.mu.Unlock()
+	return r.connect(ctx)
+}
+
+func (r *Redis) put(conn *redisConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		conn.Close()
+		return
+	}
+	r.idle = append(r.idle, conn)
+}
+
+func (r *Redis) connect(ctx context.Context) (*redisConn, error) {
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", r.addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis connect failed: %v", err)
+	}
+	conn := &redisConn{Conn: nc, rw: bufio.NewReadWriter(bufio.NewReader(nc), bufio.NewWriter(nc))}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	if r.password != "" {
+		if _, err := conn.roundTrip([]string{"AUTH", r.password}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if r.db != 0 {
+		if _, err := conn.roundTrip([]string{"SELECT", strconv.Itoa(r.db)}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (c *redisConn) roundTrip(args []string) ([]byte, error) {
+	fmt.Fprintf(c.rw, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(c.rw, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := c.rw.Flush(); err != nil {
+		return nil, fmt.Errorf("redis write failed: %v", err)
+	}
+	return readReply(c.rw.Reader)
+}
+
+func readReply(br *bufio.Reader) ([]byte, error) {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %v", err)
+	}
+	if len(line) < 3 {
+		return nil, fmt.Errorf("redis protocol error: short reply")
+	}
+	body := line[1 : len(line)-2]
+
+	switch line[0] {
+	case '+', ':':
+		return []byte(body), nil
+	case '-':
+		return nil, fmt.Errorf("redis error: %s", body)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("redis protocol error: %v", err)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, fmt.Errorf("redis read failed: %v", err)
+		}
+		return buf[:n], nil
+	default:
+		return nil, fmt.Errorf("redis protocol error: unexpected reply %q", line[0])
+	}
+}
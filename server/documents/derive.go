@@ -0,0 +1,155 @@
+package documents
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"toolkit-backend/languages"
+	"toolkit-backend/utils"
+)
+
+const (
+	HighlightInvisible          = "invisible"
+	HighlightTrailingWhitespace = "trailingWhitespace"
+	HighlightDoubledWord        = "doubledWord"
+)
+
+type Highlight struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Length int    `json:"length"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type Counts struct {
+	Words              int `json:"words"`
+	Characters         int `json:"characters"`
+	CharactersNoSpaces int `json:"charactersNoSpaces"`
+	Lines              int `json:"lines"`
+	Paragraphs         int `json:"paragraphs"`
+	Sentences          int `json:"sentences"`
+	Bytes              int `json:"bytes"`
+}
+
+type Readability struct {
+	Syllables          int     `json:"syllables"`
+	FleschReadingEase  float64 `json:"fleschReadingEase"`
+	FleschKincaidGrade float64 `json:"fleschKincaidGrade"`
+}
+
+type Derived struct {
+	Counts      Counts      `json:"counts"`
+	Readability Readability `json:"readability"`
+	Highlights  int         `json:"highlights"`
+}
+
+// line caches everything derived from one line, so an edit only has to
+// re-analyze the lines it touches
+type line struct {
+	text string
+
+	words, chars, nonSpace, sentences, syllables int
+	blank, endsSentence                          bool
+	highlights                                   []Highlight
+	// dirty marks lines re-analyzed by the edits being applied
+	dirty bool
+}
+
+// totals are kept as running sums over the lines
+type totals struct {
+	words, chars, nonSpace, bytes, sentences, syllables, highlights int
+}
+
+func (t *totals) add(l *line, sign int) {
+	t.words += sign * l.words
+	t.chars += sign * l.chars
+	t.nonSpace += sign * l.nonSpace
+	t.bytes += sign * (len(l.text) + 1)
+	t.sentences += sign * l.sentences
+	t.syllables += sign * l.syllables
+	t.highlights += sign * len(l.highlights)
+}
+
+func analyzeLine(text string) line {
+	l := line{text: text}
+	body := strings.TrimSuffix(text, "\r")
+	l.blank = strings.TrimSpace(body) == ""
+
+	for _, g := range utils.Graphemes(body) {
+		l.chars++
+		if r, _ := utf8.DecodeRuneInString(g); !unicode.IsSpace(r) {
+			l.nonSpace++
+		}
+	}
+
+	runes := []rune(body)
+	prev := ""
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && !unicode.IsSpace(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		l.words++
+		l.syllables += syllables(word)
+		if closed := strings.TrimRight(word, "\"')]"); closed != "" && strings.ContainsRune(".!?", rune(closed[len(closed)-1])) {
+			l.sentences++
+		}
+
+		folded := strings.ToLower(strings.TrimFunc(word, isPunct))
+		if folded != "" && folded == prev {
+			l.highlights = append(l.highlights, Highlight{Column: i + 1, Length: j - i, Kind: HighlightDoubledWord, Detail: folded})
+		}
+		prev = folded
+		// "it ended. It began" is not a doubled word
+		if strings.ContainsRune(".!?,;:", runes[j-1]) {
+			prev = ""
+		}
+		i = j
+	}
+
+	for _, c := range utils.DetectInvisible(body).Characters {
+		l.highlights = append(l.highlights, Highlight{Column: c.Column, Length: 1, Kind: HighlightInvisible, Detail: c.Name})
+	}
+	if trimmed := strings.TrimRightFunc(body, unicode.IsSpace); !l.blank && len(trimmed) < len(body) {
+		start := utf8.RuneCountInString(trimmed)
+		l.highlights = append(l.highlights, Highlight{Column: start + 1, Length: len(runes) - start, Kind: HighlightTrailingWhitespace})
+	}
+
+	trimmed := strings.TrimRight(body, " \t\"')]")
+	l.endsSentence = trimmed != "" && strings.ContainsRune(".!?", rune(trimmed[len(trimmed)-1]))
+	return l
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// syllables uses the English rules whatever the text, since the
+// readability formulas are English ones
+func syllables(word string) int {
+	english, err := languages.Default.Get("en")
+	if err != nil {
+		return 1
+	}
+	return english.CountSyllables(word)
+}
+
+func readability(words, sentences, syllableCount int) Readability {
+	r := Readability{Syllables: syllableCount}
+	if words == 0 || sentences == 0 {
+		return r
+	}
+	wps := float64(words) / float64(sentences)
+	spw := float64(syllableCount) / float64(words)
+	r.FleschReadingEase = math.Round((206.835-1.015*wps-84.6*spw)*10) / 10
+	r.FleschKincaidGrade = math.Round((0.39*wps+11.8*spw-15.59)*10) / 10
+	return r
+}
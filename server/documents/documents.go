@@ -0,0 +1,326 @@
+package documents
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"toolkit-backend/utils"
+)
+
+var (
+	ErrNotFound        = errors.New("document not found")
+	ErrVersionConflict = errors.New("document version conflict")
+	ErrInvalidEdit     = errors.New("invalid edit")
+)
+
+type Config struct {
+	MaxBytes     int           `json:"maxBytes"`
+	MaxDocuments int           `json:"maxDocuments"`
+	TTL          time.Duration `json:"ttl"`
+	// MaxPerOwner is how many documents one API key may keep open, 20 by
+	// default, so a single client can't fill the store
+	MaxPerOwner int `json:"maxPerOwner"`
+}
+
+// Edit replaces the bytes in [Start, End) with Text. Offsets are byte offsets
+// into the document as left by the previous edit of the same request.
+type Edit struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+// Change says which lines one edit replaced: Removed lines starting at
+// FromLine became FromLine..ToLine, numbered as right after that edit
+type Change struct {
+	FromLine int `json:"fromLine"`
+	ToLine   int `json:"toLine"`
+	Removed  int `json:"removed"`
+}
+
+// Update is what an editor needs to patch its view after a set of edits:
+// the line changes in order, and the fresh highlights of every line that was
+// re-analyzed, numbered as in the final document
+type Update struct {
+	Changes    []Change    `json:"changes"`
+	Lines      []int       `json:"lines"`
+	Highlights []Highlight `json:"highlights"`
+}
+
+type Document struct {
+	ID       string    `json:"id"`
+	Version  int       `json:"version"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"lastUsed"`
+
+	owner  string
+	lines  []line
+	totals totals
+	// paragraph starts and paragraph ends missing closing punctuation
+	paragraphs, unterminated int
+}
+
+type Store struct {
+	mu   sync.Mutex
+	cfg  Config
+	docs map[string]*Document
+}
+
+func NewStore(cfg Config) *Store {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = 4 << 20
+	}
+	if cfg.MaxDocuments <= 0 {
+		cfg.MaxDocuments = 1000
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Hour
+	}
+	if cfg.MaxPerOwner <= 0 {
+		cfg.MaxPerOwner = 20
+	}
+	return &Store{cfg: cfg, docs: make(map[string]*Document)}
+}
+
+func (s *Store) Create(owner, text string) (Document, Derived, error) {
+	if len(text) > s.cfg.MaxBytes {
+		return Document{}, Derived{}, fmt.Errorf("%w: documents are limited to %d bytes", utils.ErrInputTooLarge, s.cfg.MaxBytes)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evict(time.Now())
+	if len(s.docs) >= s.cfg.MaxDocuments {
+		return Document{}, Derived{}, fmt.Errorf("%w: too many open documents", utils.ErrInputTooLarge)
+	}
+	owned := 0
+	for _, doc := range s.docs {
+		if doc.owner == owner {
+			owned++
+		}
+	}
+	if owned >= s.cfg.MaxPerOwner {
+		return Document{}, Derived{}, fmt.Errorf("%w: at most %d open documents per key", utils.ErrInputTooLarge, s.cfg.MaxPerOwner)
+	}
+
+	now := time.Now()
+	doc := &Document{ID: uuid.New().String(), Version: 1, Created: now, LastUsed: now, owner: owner}
+	for _, text := range strings.Split(text, "\n") {
+		doc.lines = append(doc.lines, analyzeLine(text))
+		doc.totals.add(&doc.lines[len(doc.lines)-1], 1)
+	}
+	doc.paragraphs, doc.unterminated = doc.paragraphStats(0, len(doc.lines)-1)
+	s.docs[doc.ID] = doc
+	return *doc, doc.derived(), nil
+}
+
+// document looks up one of owner's documents; another key's reads as missing
+func (s *Store) document(owner, id string) (*Document, error) {
+	doc, ok := s.docs[id]
+	if !ok || doc.owner != owner {
+		return nil, ErrNotFound
+	}
+	if time.Since(doc.LastUsed) > s.cfg.TTL {
+		delete(s.docs, id)
+		return nil, ErrNotFound
+	}
+	doc.LastUsed = time.Now()
+	return doc, nil
+}
+
+func (s *Store) Get(owner, id string) (Document, string, Derived, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.document(owner, id)
+	if err != nil {
+		return Document{}, "", Derived{}, err
+	}
+	return *doc, doc.text(), doc.derived(), nil
+}
+
+// Highlights returns the highlights of lines from..to (1-based, inclusive);
+// a to of 0 means the last line
+func (s *Store) Highlights(owner, id string, from, to int) ([]Highlight, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.document(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	return doc.highlights(from, to), nil
+}
+
+// Apply applies edits made against version and returns the new version's
+// derived data. The edits are all or nothing.
+func (s *Store) Apply(owner, id string, version int, edits []Edit) (Document, Derived, Update, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.document(owner, id)
+	if err != nil {
+		return Document{}, Derived{}, Update{}, err
+	}
+	if version != doc.Version {
+		return Document{}, Derived{}, Update{}, fmt.Errorf("%w: edits are against version %d, document is at %d", ErrVersionConflict, version, doc.Version)
+	}
+
+	// work on a copy so a bad edit leaves the document untouched
+	work := *doc
+	work.lines = append([]line(nil), doc.lines...)
+	update := Update{Changes: make([]Change, 0, len(edits)), Lines: []int{}, Highlights: []Highlight{}}
+	for i, e := range edits {
+		change, err := work.apply(e, s.cfg.MaxBytes)
+		if err != nil {
+			return Document{}, Derived{}, Update{}, fmt.Errorf("edit %d: %w", i+1, err)
+		}
+		update.Changes = append(update.Changes, change)
+	}
+	for i := range work.lines {
+		if l := &work.lines[i]; l.dirty {
+			l.dirty = false
+			update.Lines = append(update.Lines, i+1)
+			update.Highlights = append(update.Highlights, work.highlights(i+1, i+1)...)
+		}
+	}
+	work.Version++
+	*doc = work
+	return *doc, doc.derived(), update, nil
+}
+
+func (s *Store) Delete(owner, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if doc, ok := s.docs[id]; ok && doc.owner == owner {
+		delete(s.docs, id)
+	}
+}
+
+func (s *Store) evict(now time.Time) {
+	for id, doc := range s.docs {
+		if now.Sub(doc.LastUsed) > s.cfg.TTL {
+			delete(s.docs, id)
+		}
+	}
+}
+
+// locate maps a byte offset to a line index and the offset within that line
+func (d *Document) locate(offset int) (int, int, bool) {
+	pos := 0
+	for i, l := range d.lines {
+		if offset <= pos+len(l.text) {
+			return i, offset - pos, true
+		}
+		pos += len(l.text) + 1
+	}
+	return 0, 0, false
+}
+
+func (d *Document) apply(e Edit, maxBytes int) (Change, error) {
+	if e.Start < 0 || e.End < e.Start {
+		return Change{}, fmt.Errorf("%w: range %d-%d", ErrInvalidEdit, e.Start, e.End)
+	}
+	first, startCol, ok := d.locate(e.Start)
+	last, endCol, ok2 := d.locate(e.End)
+	if !ok || !ok2 {
+		return Change{}, fmt.Errorf("%w: range %d-%d is outside the document", ErrInvalidEdit, e.Start, e.End)
+	}
+	prefix, suffix := d.lines[first].text[:startCol], d.lines[last].text[endCol:]
+	if !utf8.ValidString(prefix) || !utf8.ValidString(suffix) {
+		return Change{}, fmt.Errorf("%w: offsets must fall on character boundaries", ErrInvalidEdit)
+	}
+	if size := d.totals.bytes - 1 - (e.End - e.Start) + len(e.Text); size > maxBytes {
+		return Change{}, fmt.Errorf("%w: documents are limited to %d bytes", utils.ErrInputTooLarge, maxBytes)
+	}
+
+	// paragraph boundaries depend on the neighbouring lines, so they are recounted around the edit
+	starts, open := d.paragraphStats(first-1, last+1)
+	d.paragraphs, d.unterminated = d.paragraphs-starts, d.unterminated-open
+	for i := first; i <= last; i++ {
+		d.totals.add(&d.lines[i], -1)
+	}
+
+	replaced := strings.Split(prefix+e.Text+suffix, "\n")
+	fresh := make([]line, len(replaced))
+	for i, text := range replaced {
+		fresh[i] = analyzeLine(text)
+		fresh[i].dirty = true
+		d.totals.add(&fresh[i], 1)
+	}
+	tail := append([]line(nil), d.lines[last+1:]...)
+	d.lines = append(append(d.lines[:first], fresh...), tail...)
+	starts, open = d.paragraphStats(first-1, first+len(fresh))
+	d.paragraphs, d.unterminated = d.paragraphs+starts, d.unterminated+open
+
+	return Change{FromLine: first + 1, ToLine: first + len(fresh), Removed: last - first + 1}, nil
+}
+
+// paragraphStats counts, over lines from..to, the non-blank lines that open a
+// paragraph and those that close one without ending a sentence
+func (d *Document) paragraphStats(from, to int) (starts, unterminated int) {
+	for i := max(from, 0); i <= to && i < len(d.lines); i++ {
+		l := d.lines[i]
+		if l.blank {
+			continue
+		}
+		if i == 0 || d.lines[i-1].blank {
+			starts++
+		}
+		if !l.endsSentence && (i == len(d.lines)-1 || d.lines[i+1].blank) {
+			unterminated++
+		}
+	}
+	return starts, unterminated
+}
+
+func (d *Document) text() string {
+	var b strings.Builder
+	b.Grow(d.totals.bytes)
+	for i, l := range d.lines {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(l.text)
+	}
+	return b.String()
+}
+
+func (d *Document) derived() Derived {
+	t := d.totals
+	sentences := t.sentences + d.unterminated
+	return Derived{
+		Counts: Counts{
+			Words:              t.words,
+			Characters:         t.chars + len(d.lines) - 1,
+			CharactersNoSpaces: t.nonSpace,
+			Lines:              len(d.lines),
+			Paragraphs:         d.paragraphs,
+			Sentences:          sentences,
+			Bytes:              t.bytes - 1,
+		},
+		Readability: readability(t.words, sentences, t.syllables),
+		Highlights:  t.highlights,
+	}
+}
+
+func (d *Document) highlights(from, to int) []Highlight {
+	if to <= 0 || to > len(d.lines) {
+		to = len(d.lines)
+	}
+	out := []Highlight{}
+	for i := max(from, 1) - 1; i < to; i++ {
+		for _, h := range d.lines[i].highlights {
+			h.Line = i + 1
+			out = append(out, h)
+		}
+	}
+	return out
+}
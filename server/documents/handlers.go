@@ -0,0 +1,114 @@
+package documents
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/auth"
+	"toolkit-backend/utils"
+)
+
+type CreateRequest struct {
+	Text string `json:"text"`
+}
+
+type EditRequest struct {
+	Version int    `json:"version" binding:"required"`
+	Edits   []Edit `json:"edits" binding:"required"`
+}
+
+func owner(c *gin.Context) (string, bool) {
+	key, ok := auth.CurrentKey(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "documents require an API key"})
+		return "", false
+	}
+	return key.ID, true
+}
+
+var errorStatuses = []utils.ErrorStatus{
+	{Err: ErrNotFound, Status: http.StatusNotFound},
+	{Err: ErrVersionConflict, Status: http.StatusConflict},
+}
+
+func statusFor(err error) int {
+	return utils.StatusFor(err, http.StatusBadRequest, errorStatuses...)
+}
+
+func RegisterRoutes(r gin.IRouter, s *Store) {
+	r.POST("/documents", func(c *gin.Context) {
+		key, ok := owner(c)
+		if !ok {
+			return
+		}
+		var req CreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		doc, derived, err := s.Create(key, req.Text)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		highlights, _ := s.Highlights(key, doc.ID, 1, 0)
+		c.JSON(http.StatusCreated, gin.H{"id": doc.ID, "version": doc.Version, "derived": derived, "highlights": highlights})
+	})
+
+	r.GET("/documents/:id", func(c *gin.Context) {
+		key, ok := owner(c)
+		if !ok {
+			return
+		}
+		doc, text, derived, err := s.Get(key, c.Param("id"))
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"id": doc.ID, "version": doc.Version, "text": text, "derived": derived})
+	})
+
+	r.GET("/documents/:id/highlights", func(c *gin.Context) {
+		key, ok := owner(c)
+		if !ok {
+			return
+		}
+		from, _ := strconv.Atoi(c.DefaultQuery("from", "1"))
+		to, _ := strconv.Atoi(c.DefaultQuery("to", "0"))
+		highlights, err := s.Highlights(key, c.Param("id"), from, to)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"highlights": highlights})
+	})
+
+	r.POST("/documents/:id/edits", func(c *gin.Context) {
+		key, ok := owner(c)
+		if !ok {
+			return
+		}
+		var req EditRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		doc, derived, update, err := s.Apply(key, c.Param("id"), req.Version, req.Edits)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"version": doc.Version, "derived": derived, "update": update})
+	})
+
+	r.DELETE("/documents/:id", func(c *gin.Context) {
+		key, ok := owner(c)
+		if !ok {
+			return
+		}
+		s.Delete(key, c.Param("id"))
+		c.Status(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,657 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"toolkit-backend/pipeline"
+	"toolkit-backend/utils"
+)
+
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+type Response struct {
+	Data   interface{} `json:"data"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+type Executor struct {
+	registry *pipeline.Registry
+}
+
+func NewExecutor(registry *pipeline.Registry) *Executor {
+	return &Executor{registry: registry}
+}
+
+type object []objectField
+
+type objectField struct {
+	key   string
+	value interface{}
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.key)
+		b.Write(key)
+		b.WriteByte(':')
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+type execution struct {
+	ctx     context.Context
+	doc     *Document
+	vars    map[string]interface{}
+	catalog map[string]pipeline.OperationSpec
+	errors  []Error
+	exec    *Executor
+
+	collected  map[collectKey][]*Field
+	selections int
+	stopped    bool
+}
+
+type transformValue struct {
+	text string
+}
+
+func (e *Executor) Execute(ctx context.Context, req Request) Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	vars, err := coerceVariables(op, req.Variables)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	ex := &execution{ctx: ctx, doc: doc, vars: vars, exec: e, catalog: make(map[string]pipeline.OperationSpec), collected: make(map[collectKey][]*Field)}
+	for _, spec := range e.registry.Catalog() {
+		ex.catalog[spec.Name] = spec
+	}
+
+	root := "Query"
+	if op.Type == "mutation" {
+		root = "Mutation"
+	}
+	data := ex.selectionSet(root, nil, op.SelectionSet, nil)
+	return Response{Data: data, Errors: ex.errors}
+}
+
+func selectOperation(doc *Document, name string) (*OperationDef, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, fmt.Errorf("operationName is required when the document has several operations")
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+func coerceVariables(op *OperationDef, given map[string]interface{}) (map[string]interface{}, error) {
+	vars := make(map[string]interface{})
+	for _, def := range op.Variables {
+		value, ok := given[def.Name]
+		if !ok && def.HasDef {
+			value, ok = def.Default, true
+		}
+		if def.Type.NonNull && (!ok || value == nil) {
+			return nil, fmt.Errorf("variable $%s of type %s is required", def.Name, def.Type)
+		}
+		if ok {
+			vars[def.Name] = value
+		}
+	}
+	return vars, nil
+}
+
+func resolveValue(v Value, vars map[string]interface{}) (interface{}, error) {
+	switch v.Kind {
+	case "Variable":
+		value, ok := vars[v.Variable]
+		if !ok {
+			return nil, nil
+		}
+		return value, nil
+	case "List":
+		out := make([]interface{}, len(v.List))
+		for i, item := range v.List {
+			value, err := resolveValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = value
+		}
+		return out, nil
+	case "Object":
+		out := make(map[string]interface{}, len(v.Fields))
+		for _, f := range v.Fields {
+			value, err := resolveValue(f.Value, vars)
+			if err != nil {
+				return nil, err
+			}
+			out[f.Name] = value
+		}
+		return out, nil
+	}
+	return v.Raw, nil
+}
+
+// MaxSelections bounds how many selections one execution may collect, so
+// fragments spread over and over cannot multiply the work
+const MaxSelections = 10000
+
+type collectKey struct {
+	typeName string
+	set      *Selection
+	n        int
+}
+
+// collectFields returns the fields set selects on typeName. Directives only
+// depend on the variables, so the result is the same for every object the
+// set is applied to and is worked out once per execution.
+func (ex *execution) collectFields(typeName string, set []Selection) []*Field {
+	if len(set) == 0 {
+		return nil
+	}
+	key := collectKey{typeName: typeName, set: &set[0], n: len(set)}
+	if fields, ok := ex.collected[key]; ok {
+		return fields
+	}
+	fields := ex.collect(typeName, set, map[string]bool{}, 0)
+	ex.collected[key] = fields
+	return fields
+}
+
+// collect follows the spec's CollectFields: visited is shared by the whole
+// walk, so each fragment is expanded at most once however often it is spread
+func (ex *execution) collect(typeName string, set []Selection, visited map[string]bool, depth int) []*Field {
+	if ex.halted() {
+		return nil
+	}
+	ex.selections += len(set)
+	if ex.selections > MaxSelections {
+		ex.halt(fmt.Errorf("query selects more than %d fields", MaxSelections))
+		return nil
+	}
+
+	var fields []*Field
+	index := make(map[string]int)
+	add := func(f *Field) {
+		if i, ok := index[f.Key()]; ok {
+			merged := *fields[i]
+			merged.SelectionSet = append(append([]Selection(nil), merged.SelectionSet...), f.SelectionSet...)
+			fields[i] = &merged
+			return
+		}
+		index[f.Key()] = len(fields)
+		fields = append(fields, f)
+	}
+
+	for _, sel := range set {
+		if !ex.included(sel.Directives) {
+			continue
+		}
+		switch {
+		case sel.Field != nil:
+			add(sel.Field)
+		case sel.Inline != nil:
+			if sel.Inline.On == "" || sel.Inline.On == typeName {
+				for _, f := range ex.collect(typeName, sel.Inline.SelectionSet, visited, depth) {
+					add(f)
+				}
+			}
+		default:
+			frag, ok := ex.doc.Fragments[sel.Fragment]
+			if !ok {
+				ex.errors = append(ex.errors, Error{Message: fmt.Sprintf("unknown fragment %q", sel.Fragment)})
+				continue
+			}
+			if visited[frag.Name] || frag.On != typeName {
+				continue
+			}
+			if depth >= MaxDepth {
+				ex.errors = append(ex.errors, Error{Message: fmt.Sprintf("fragment spreads nest deeper than %d levels", MaxDepth)})
+				continue
+			}
+			visited[frag.Name] = true
+			for _, f := range ex.collect(typeName, frag.SelectionSet, visited, depth+1) {
+				add(f)
+			}
+		}
+	}
+	return fields
+}
+
+// halted reports whether execution has stopped, recording why the first
+// time it does
+func (ex *execution) halted() bool {
+	if ex.stopped {
+		return true
+	}
+	if err := ex.ctx.Err(); err != nil {
+		ex.halt(err)
+	}
+	return ex.stopped
+}
+
+func (ex *execution) halt(err error) {
+	ex.stopped = true
+	ex.errors = append(ex.errors, Error{Message: err.Error()})
+}
+
+func (ex *execution) included(dirs []Directive) bool {
+	for _, dir := range dirs {
+		if dir.Name != "skip" && dir.Name != "include" {
+			continue
+		}
+		cond := false
+		for _, arg := range dir.Arguments {
+			if arg.Name == "if" {
+				value, _ := resolveValue(arg.Value, ex.vars)
+				cond, _ = value.(bool)
+			}
+		}
+		if dir.Name == "skip" && cond || dir.Name == "include" && !cond {
+			return false
+		}
+	}
+	return true
+}
+
+func (ex *execution) selectionSet(typeName string, parent interface{}, set []Selection, path []interface{}) object {
+	result := object{}
+	for _, field := range ex.collectFields(typeName, set) {
+		fieldPath := append(append([]interface{}(nil), path...), field.Key())
+		value, err := ex.resolve(typeName, parent, field, fieldPath)
+		if err != nil {
+			ex.errors = append(ex.errors, Error{Message: err.Error(), Path: fieldPath})
+			value = nil
+		}
+		result = append(result, objectField{key: field.Key(), value: value})
+	}
+	return result
+}
+
+func (ex *execution) resolve(typeName string, parent interface{}, field *Field, path []interface{}) (interface{}, error) {
+	if err := ex.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if field.Name == "__typename" {
+		return typeName, nil
+	}
+
+	args := make(map[string]interface{}, len(field.Arguments))
+	for _, arg := range field.Arguments {
+		value, err := resolveValue(arg.Value, ex.vars)
+		if err != nil {
+			return nil, err
+		}
+		args[arg.Name] = value
+	}
+
+	switch typeName {
+	case "Query":
+		switch field.Name {
+		case "operations":
+			if err := checkArgs(field, args, nil); err != nil {
+				return nil, err
+			}
+			return ex.complete("[Operation!]!", ex.exec.registry.Catalog(), field, path)
+		case "transform":
+			if err := checkArgs(field, args, []argDef{{"text", "String!"}}); err != nil {
+				return nil, err
+			}
+			return ex.complete("Transform!", transformValue{text: args["text"].(string)}, field, path)
+		}
+	case "Mutation":
+		if field.Name == "run" {
+			if err := checkArgs(field, args, []argDef{{"text", "String!"}, {"steps", "[StepInput!]!"}}); err != nil {
+				return nil, err
+			}
+			return ex.runSteps(args["text"].(string), args["steps"])
+		}
+		if spec, ok := ex.catalog[field.Name]; ok {
+			defs := append([]argDef{{"text", "String!"}}, paramArgs(spec)...)
+			if err := checkArgs(field, args, defs); err != nil {
+				return nil, err
+			}
+			text := args["text"].(string)
+			delete(args, "text")
+			return ex.runOperation(spec.Name, text, args)
+		}
+	case "Transform":
+		return ex.resolveTransform(parent.(transformValue), field, args, path)
+	default:
+		if fields, ok := objectTypes[typeName]; ok {
+			for _, def := range fields {
+				if def.name == field.Name {
+					if err := checkArgs(field, args, nil); err != nil {
+						return nil, err
+					}
+					value, _ := parent.(map[string]interface{})
+					return ex.complete(def.typ, value[field.Name], field, path)
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("cannot query field %q on type %q", field.Name, typeName)
+}
+
+func (ex *execution) resolveTransform(t transformValue, field *Field, args map[string]interface{}, path []interface{}) (interface{}, error) {
+	switch field.Name {
+	case "text":
+		if err := checkArgs(field, args, nil); err != nil {
+			return nil, err
+		}
+		return ex.complete("String!", t.text, field, path)
+	case "pipeline":
+		if err := checkArgs(field, args, []argDef{{"steps", "[StepInput!]!"}}); err != nil {
+			return nil, err
+		}
+		return ex.runSteps(t.text, args["steps"])
+	case "wordCount":
+		if err := checkArgs(field, args, []argDef{{"model", "String"}, {"keepWhitespace", "Boolean"}, {"countRunes", "Boolean"}, {"perLine", "Boolean"},
+			{"splitHyphenated", "Boolean"}, {"splitContractions", "Boolean"}, {"splitUrls", "Boolean"},
+			{"excludeNumbers", "Boolean"}, {"excludeCode", "Boolean"}, {"stripMarkdown", "Boolean"}}); err != nil {
+			return nil, err
+		}
+		opts := utils.CountOptions{}
+		opts.Model, _ = args["model"].(string)
+		opts.KeepWhitespace, _ = args["keepWhitespace"].(bool)
+		opts.CountRunes, _ = args["countRunes"].(bool)
+		opts.PerLine, _ = args["perLine"].(bool)
+		opts.SplitHyphenated, _ = args["splitHyphenated"].(bool)
+		opts.SplitContractions, _ = args["splitContractions"].(bool)
+		opts.SplitURLs, _ = args["splitUrls"].(bool)
+		opts.ExcludeNumbers, _ = args["excludeNumbers"].(bool)
+		opts.ExcludeCode, _ = args["excludeCode"].(bool)
+		opts.StripMarkdown, _ = args["stripMarkdown"].(bool)
+		if err := opts.Validate(); err != nil {
+			return nil, err
+		}
+		return ex.complete("WordCount!", utils.WordCountWithOptions(t.text, opts), field, path)
+	case "tokens":
+		if err := checkArgs(field, args, []argDef{{"model", "String"}}); err != nil {
+			return nil, err
+		}
+		model, _ := args["model"].(string)
+		n, err := utils.EstimateTokens(t.text, model)
+		if err != nil {
+			return nil, err
+		}
+		return ex.complete("Int!", n, field, path)
+	case "entropy":
+		if err := checkArgs(field, args, nil); err != nil {
+			return nil, err
+		}
+		return ex.complete("Float!", utils.ShannonEntropy(t.text), field, path)
+	case "sentences":
+		if err := checkArgs(field, args, []argDef{{"lang", "String"}}); err != nil {
+			return nil, err
+		}
+		lang, _ := args["lang"].(string)
+		sentences, err := utils.SplitSentences(t.text, lang)
+		if err != nil {
+			return nil, err
+		}
+		return ex.complete("[Sentence!]!", sentences, field, path)
+	}
+
+	spec, ok := ex.catalog[field.Name]
+	if !ok {
+		return nil, fmt.Errorf("cannot query field %q on type %q", field.Name, "Transform")
+	}
+	if err := checkArgs(field, args, paramArgs(spec)); err != nil {
+		return nil, err
+	}
+	return ex.runOperation(spec.Name, t.text, args)
+}
+
+func (ex *execution) runOperation(name, text string, params map[string]interface{}) (interface{}, error) {
+	out, err := ex.exec.registry.RunContext(ex.ctx, text, []pipeline.Step{{Operation: name, Params: params}})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (ex *execution) runSteps(text string, raw interface{}) (interface{}, error) {
+	items, _ := raw.([]interface{})
+	steps := make([]pipeline.Step, 0, len(items))
+	for _, item := range items {
+		m, _ := item.(map[string]interface{})
+		params, _ := m["params"].(map[string]interface{})
+		when, _ := m["when"].(string)
+		lines, _ := m["lines"].(string)
+		steps = append(steps, pipeline.Step{Operation: m["operation"].(string), Params: params, When: when, Lines: lines})
+	}
+	out, err := ex.exec.registry.RunContext(ex.ctx, text, steps)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (ex *execution) complete(typ string, value interface{}, field *Field, path []interface{}) (interface{}, error) {
+	nonNull := strings.HasSuffix(typ, "!")
+	typ = strings.TrimSuffix(typ, "!")
+	if value == nil {
+		if nonNull {
+			return nil, fmt.Errorf("non-null field %q resolved to null", field.Name)
+		}
+		return nil, nil
+	}
+
+	if strings.HasPrefix(typ, "[") {
+		elem := typ[1 : len(typ)-1]
+		items := plainList(value)
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			v, err := ex.complete(elem, item, field, append(append([]interface{}(nil), path...), i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		return out, nil
+	}
+
+	if _, ok := objectTypes[typ]; ok || typ == "Transform" {
+		if len(field.SelectionSet) == 0 {
+			return nil, fmt.Errorf("field %q of type %q must have a selection of subfields", field.Name, typ)
+		}
+		if typ != "Transform" {
+			value = plainValue(value)
+		}
+		return ex.selectionSet(typ, value, field.SelectionSet, path), nil
+	}
+	if len(field.SelectionSet) > 0 {
+		return nil, fmt.Errorf("field %q of type %q has no subfields", field.Name, typ)
+	}
+	return value, nil
+}
+
+func plainValue(value interface{}) interface{} {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}, string, bool, float64, int:
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var out interface{}
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func plainList(value interface{}) []interface{} {
+	list, _ := plainValue(value).([]interface{})
+	return list
+}
+
+type argDef struct {
+	name string
+	typ  string
+}
+
+func paramArgs(spec pipeline.OperationSpec) []argDef {
+	defs := make([]argDef, len(spec.Params))
+	for i, p := range spec.Params {
+		defs[i] = argDef{name: p.Name, typ: graphqlType(p)}
+	}
+	return defs
+}
+
+func graphqlType(p pipeline.ParamSpec) string {
+	typ := "String"
+	switch p.Type {
+	case "integer":
+		typ = "Int"
+	case "boolean":
+		typ = "Boolean"
+	case "array":
+		typ = "[String!]"
+	case "object":
+		typ = "JSON"
+	}
+	if p.Required {
+		typ += "!"
+	}
+	return typ
+}
+
+func checkArgs(field *Field, args map[string]interface{}, defs []argDef) error {
+	known := make(map[string]string, len(defs))
+	for _, def := range defs {
+		known[def.name] = def.typ
+	}
+	for name := range args {
+		if _, ok := known[name]; !ok {
+			return fmt.Errorf("unknown argument %q on field %q", name, field.Name)
+		}
+	}
+	for _, def := range defs {
+		value, err := coerceInput(def.typ, args[def.name])
+		if err != nil {
+			return fmt.Errorf("argument %q on field %q: %v", def.name, field.Name, err)
+		}
+		if value == nil {
+			delete(args, def.name)
+		} else {
+			args[def.name] = value
+		}
+	}
+	return nil
+}
+
+func coerceInput(typ string, value interface{}) (interface{}, error) {
+	nonNull := strings.HasSuffix(typ, "!")
+	typ = strings.TrimSuffix(typ, "!")
+	if value == nil {
+		if nonNull {
+			return nil, fmt.Errorf("expected %s!, found null", typ)
+		}
+		return nil, nil
+	}
+
+	if strings.HasPrefix(typ, "[") {
+		elem := typ[1 : len(typ)-1]
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			v, err := coerceInput(elem, item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		return out, nil
+	}
+
+	switch typ {
+	case "String":
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case "Int":
+		switch n := value.(type) {
+		case int:
+			return n, nil
+		case float64:
+			if n == math.Trunc(n) {
+				return int(n), nil
+			}
+		}
+	case "Boolean":
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case "StepInput":
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			break
+		}
+		op, ok := m["operation"].(string)
+		if !ok {
+			return nil, fmt.Errorf("StepInput.operation must be a string")
+		}
+		if params, ok := m["params"]; ok && params != nil {
+			if _, ok := params.(map[string]interface{}); !ok {
+				return nil, fmt.Errorf("StepInput.params must be an object")
+			}
+		}
+		out := map[string]interface{}{"operation": op, "params": m["params"]}
+		for _, key := range []string{"when", "lines"} {
+			if v, ok := m[key]; ok && v != nil {
+				s, ok := v.(string)
+				if !ok {
+					return nil, fmt.Errorf("StepInput.%s must be a string", key)
+				}
+				out[key] = s
+			}
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("expected %s, found %v", typ, value)
+}
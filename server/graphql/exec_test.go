@@ -0,0 +1,170 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"toolkit-backend/pipeline"
+)
+
+func testExecutor() *Executor {
+	r := pipeline.NewRegistry()
+	r.Register("upper", func(text string, params pipeline.Params) (string, error) {
+		suffix, _ := params["suffix"].(string)
+		return strings.ToUpper(text) + suffix, nil
+	})
+	r.Describe("upper", []pipeline.ParamSpec{{Name: "suffix", Type: "string"}})
+	return NewExecutor(r)
+}
+
+func execute(t *testing.T, ctx context.Context, req Request) (string, []Error) {
+	t.Helper()
+	resp := testExecutor().Execute(ctx, req)
+	data, err := json.Marshal(resp.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), resp.Errors
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{
+			name: "transform fields keep selection order",
+			req:  Request{Query: `{ transform(text: "a b") { upper(suffix: "!") text } }`},
+			want: `{"transform":{"upper":"A B!","text":"a b"}}`,
+		},
+		{
+			name: "aliases and variables",
+			req:  Request{Query: `query($t: String!) { x: transform(text: $t) { t: text } }`, Variables: map[string]interface{}{"t": "hi"}},
+			want: `{"x":{"t":"hi"}}`,
+		},
+		{
+			name: "fragments merge fields",
+			req:  Request{Query: `{ transform(text: "a") { ...A ... on Transform { upper } } } fragment A on Transform { text upper }`},
+			want: `{"transform":{"text":"a","upper":"A"}}`,
+		},
+		{
+			name: "skip and include",
+			req: Request{
+				Query:     `query($on: Boolean!) { transform(text: "a") { text @skip(if: $on) upper @include(if: $on) } }`,
+				Variables: map[string]interface{}{"on": true},
+			},
+			want: `{"transform":{"upper":"A"}}`,
+		},
+		{
+			name: "nested object types",
+			req:  Request{Query: `{ operations { name params { name type } } }`},
+			want: `{"operations":[{"name":"upper","params":[{"name":"suffix","type":"string"}]}]}`,
+		},
+		{
+			name: "mutation runs steps",
+			req:  Request{Query: `mutation { run(text: "a", steps: [{operation: "upper"}]) upper(text: "b", suffix: "?") }`},
+			want: `{"run":"A","upper":"B?"}`,
+		},
+		{
+			name: "typename",
+			req:  Request{Query: `{ __typename transform(text: "") { __typename } }`},
+			want: `{"__typename":"Query","transform":{"__typename":"Transform"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := execute(t, context.Background(), tt.req)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors %+v", errs)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{"unknown field", Request{Query: `{ nope }`}, `cannot query field "nope"`},
+		{"unknown argument", Request{Query: `{ transform(text: "a", x: 1) { text } }`}, `unknown argument "x"`},
+		{"wrong argument type", Request{Query: `{ transform(text: 1) { text } }`}, `expected String`},
+		{"missing variable", Request{Query: `query($t: String!) { transform(text: $t) { text } }`}, `variable $t`},
+		{"missing subselection", Request{Query: `{ transform(text: "a") }`}, `must have a selection`},
+		{"unknown fragment", Request{Query: `{ transform(text: "a") { ...F } }`}, `unknown fragment "F"`},
+		{"ambiguous operation", Request{Query: `query A { __typename } query B { __typename }`}, `operationName is required`},
+		{"unknown operation", Request{Query: `query A { __typename }`, OperationName: "B"}, `unknown operation "B"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := execute(t, context.Background(), tt.req)
+			if len(errs) == 0 || !strings.Contains(errs[0].Message, tt.want) {
+				t.Errorf("errors = %+v, want %q", errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteFragmentCycle(t *testing.T) {
+	got, _ := execute(t, context.Background(), Request{Query: `{ transform(text: "a") { ...A } }
+		fragment A on Transform { text ...B }
+		fragment B on Transform { upper ...A }`})
+	if want := `{"transform":{"text":"a","upper":"A"}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// each fragment spreads the next one twice; expanding every spread would
+// take 2^n steps
+func TestExecuteFragmentFanOut(t *testing.T) {
+	const n = 40
+	var q strings.Builder
+	q.WriteString(`{ transform(text: "a") { ...F0 } }`)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&q, " fragment F%d on Transform { text ...F%d ...F%d }", i, i+1, i+1)
+	}
+	fmt.Fprintf(&q, " fragment F%d on Transform { upper }", n)
+
+	start := time.Now()
+	got, errs := execute(t, context.Background(), Request{Query: q.String()})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors %+v", errs)
+	}
+	if want := `{"transform":{"text":"a","upper":"A"}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v", elapsed)
+	}
+}
+
+func TestExecuteSelectionLimit(t *testing.T) {
+	var q strings.Builder
+	q.WriteString("{ transform(text: \"a\") {")
+	for i := 0; i <= MaxSelections; i++ {
+		fmt.Fprintf(&q, " f%d: text", i)
+	}
+	q.WriteString(" } }")
+	_, errs := execute(t, context.Background(), Request{Query: q.String()})
+	if len(errs) == 0 || !strings.Contains(errs[0].Message, "more than") {
+		t.Errorf("errors = %+v, want selection limit", errs)
+	}
+}
+
+func TestExecuteCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, errs := execute(t, ctx, Request{Query: `{ transform(text: "a") { text } }`})
+	if len(errs) == 0 || !strings.Contains(errs[0].Message, context.Canceled.Error()) {
+		t.Errorf("errors = %+v, want %v", errs, context.Canceled)
+	}
+}
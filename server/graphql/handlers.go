@@ -0,0 +1,27 @@
+package graphql
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r gin.IRouter, e *Executor) {
+	r.POST("/graphql", func(c *gin.Context) {
+		var req Request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		resp := e.Execute(c.Request.Context(), req)
+		if resp.Data == nil && len(resp.Errors) > 0 {
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	r.GET("/graphql/schema", func(c *gin.Context) {
+		c.String(http.StatusOK, e.Schema())
+	})
+}
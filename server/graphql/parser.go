@@ -0,0 +1,724 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type Document struct {
+	Operations []*OperationDef
+	Fragments  map[string]*FragmentDef
+}
+
+type OperationDef struct {
+	Type         string
+	Name         string
+	Variables    []VariableDef
+	SelectionSet []Selection
+}
+
+type VariableDef struct {
+	Name    string
+	Type    TypeRef
+	Default interface{}
+	HasDef  bool
+}
+
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+func (t TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+type FragmentDef struct {
+	Name         string
+	On           string
+	SelectionSet []Selection
+}
+
+type Selection struct {
+	Field      *Field
+	Fragment   string
+	Inline     *InlineFragment
+	Directives []Directive
+}
+
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    []Argument
+	SelectionSet []Selection
+}
+
+func (f *Field) Key() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+type InlineFragment struct {
+	On           string
+	SelectionSet []Selection
+}
+
+type Argument struct {
+	Name  string
+	Value Value
+}
+
+type Directive struct {
+	Name      string
+	Arguments []Argument
+}
+
+type Value struct {
+	Kind     string
+	Raw      interface{}
+	List     []Value
+	Fields   []Argument
+	Variable string
+}
+
+const (
+	tokEOF = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type token struct {
+	kind  int
+	value string
+	pos   int
+}
+
+// MaxDepth bounds how deeply selection sets, list and object values, list
+// types and fragment spreads may nest, so that a hostile query cannot recurse
+// the server off its stack
+const MaxDepth = 64
+
+type parser struct {
+	src   string
+	pos   int
+	tok   token
+	depth int
+}
+
+// enter counts one level of nesting; callers defer p.leave()
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > MaxDepth {
+		return p.errorf("nesting deeper than %d levels", MaxDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func Parse(src string) (*Document, error) {
+	p := &parser{src: src}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	doc := &Document{Fragments: make(map[string]*FragmentDef)}
+	for p.tok.kind != tokEOF {
+		switch {
+		case p.is(tokPunct, "{"):
+			set, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, &OperationDef{Type: "query", SelectionSet: set})
+		case p.is(tokName, "query"), p.is(tokName, "mutation"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case p.is(tokName, "fragment"):
+			frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			doc.Fragments[frag.Name] = frag
+		default:
+			return nil, p.errorf("unexpected %q", p.tok.value)
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, fmt.Errorf("document contains no operations")
+	}
+	return doc, nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	line := 1 + strings.Count(p.src[:p.tok.pos], "\n")
+	col := p.tok.pos - strings.LastIndex(p.src[:p.tok.pos], "\n")
+	return fmt.Errorf("syntax error at %d:%d: %s", line, col, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) is(kind int, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+func (p *parser) expect(kind int, value string) error {
+	if !p.is(kind, value) {
+		return p.errorf("expected %q, found %q", value, p.tok.value)
+	}
+	return p.next()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokName {
+		return "", p.errorf("expected name, found %q", p.tok.value)
+	}
+	name := p.tok.value
+	return name, p.next()
+}
+
+func (p *parser) operation() (*OperationDef, error) {
+	op := &OperationDef{Type: p.tok.value}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokName {
+		op.Name = p.tok.value
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if p.is(tokPunct, "(") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		for !p.is(tokPunct, ")") {
+			def, err := p.variableDef()
+			if err != nil {
+				return nil, err
+			}
+			op.Variables = append(op.Variables, def)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	set, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.SelectionSet = set
+	return op, nil
+}
+
+func (p *parser) variableDef() (VariableDef, error) {
+	var def VariableDef
+	if err := p.expect(tokPunct, "$"); err != nil {
+		return def, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return def, err
+	}
+	def.Name = name
+	if err := p.expect(tokPunct, ":"); err != nil {
+		return def, err
+	}
+	if def.Type, err = p.typeRef(); err != nil {
+		return def, err
+	}
+	if p.is(tokPunct, "=") {
+		if err := p.next(); err != nil {
+			return def, err
+		}
+		v, err := p.value(true)
+		if err != nil {
+			return def, err
+		}
+		def.HasDef = true
+		if def.Default, err = resolveValue(v, nil); err != nil {
+			return def, err
+		}
+	}
+	return def, nil
+}
+
+func (p *parser) typeRef() (TypeRef, error) {
+	var t TypeRef
+	if err := p.enter(); err != nil {
+		return t, err
+	}
+	defer p.leave()
+	if p.is(tokPunct, "[") {
+		if err := p.next(); err != nil {
+			return t, err
+		}
+		elem, err := p.typeRef()
+		if err != nil {
+			return t, err
+		}
+		t.Elem = &elem
+		if err := p.expect(tokPunct, "]"); err != nil {
+			return t, err
+		}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return t, err
+		}
+		t.Name = name
+	}
+	if p.is(tokPunct, "!") {
+		t.NonNull = true
+		if err := p.next(); err != nil {
+			return t, err
+		}
+	}
+	return t, nil
+}
+
+func (p *parser) fragment() (*FragmentDef, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokName, "on"); err != nil {
+		return nil, err
+	}
+	on, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	set, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &FragmentDef{Name: name, On: on, SelectionSet: set}, nil
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	if err := p.expect(tokPunct, "{"); err != nil {
+		return nil, err
+	}
+	var set []Selection
+	for !p.is(tokPunct, "}") {
+		if p.tok.kind == tokEOF {
+			return nil, p.errorf("unterminated selection set")
+		}
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, sel)
+	}
+	return set, p.next()
+}
+
+func (p *parser) selection() (Selection, error) {
+	var sel Selection
+	if p.is(tokPunct, "...") {
+		if err := p.next(); err != nil {
+			return sel, err
+		}
+		if p.tok.kind == tokName && p.tok.value != "on" {
+			sel.Fragment = p.tok.value
+			if err := p.next(); err != nil {
+				return sel, err
+			}
+			dirs, err := p.directives()
+			sel.Directives = dirs
+			return sel, err
+		}
+		inline := &InlineFragment{}
+		if p.is(tokName, "on") {
+			if err := p.next(); err != nil {
+				return sel, err
+			}
+			on, err := p.name()
+			if err != nil {
+				return sel, err
+			}
+			inline.On = on
+		}
+		dirs, err := p.directives()
+		if err != nil {
+			return sel, err
+		}
+		sel.Directives = dirs
+		if inline.SelectionSet, err = p.selectionSet(); err != nil {
+			return sel, err
+		}
+		sel.Inline = inline
+		return sel, nil
+	}
+
+	field := &Field{}
+	name, err := p.name()
+	if err != nil {
+		return sel, err
+	}
+	field.Name = name
+	if p.is(tokPunct, ":") {
+		if err := p.next(); err != nil {
+			return sel, err
+		}
+		field.Alias = name
+		if field.Name, err = p.name(); err != nil {
+			return sel, err
+		}
+	}
+	if p.is(tokPunct, "(") {
+		if field.Arguments, err = p.arguments(false); err != nil {
+			return sel, err
+		}
+	}
+	if sel.Directives, err = p.directives(); err != nil {
+		return sel, err
+	}
+	if p.is(tokPunct, "{") {
+		if field.SelectionSet, err = p.selectionSet(); err != nil {
+			return sel, err
+		}
+	}
+	sel.Field = field
+	return sel, nil
+}
+
+func (p *parser) arguments(constant bool) ([]Argument, error) {
+	if err := p.expect(tokPunct, "("); err != nil {
+		return nil, err
+	}
+	var args []Argument
+	for !p.is(tokPunct, ")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokPunct, ":"); err != nil {
+			return nil, err
+		}
+		v, err := p.value(constant)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, Argument{Name: name, Value: v})
+	}
+	return args, p.next()
+}
+
+func (p *parser) directives() ([]Directive, error) {
+	var dirs []Directive
+	for p.is(tokPunct, "@") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		dir := Directive{Name: name}
+		if p.is(tokPunct, "(") {
+			if dir.Arguments, err = p.arguments(false); err != nil {
+				return nil, err
+			}
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs, nil
+}
+
+func (p *parser) value(constant bool) (Value, error) {
+	if err := p.enter(); err != nil {
+		return Value{}, err
+	}
+	defer p.leave()
+	tok := p.tok
+	switch {
+	case p.is(tokPunct, "$") && !constant:
+		if err := p.next(); err != nil {
+			return Value{}, err
+		}
+		name, err := p.name()
+		return Value{Kind: "Variable", Variable: name}, err
+	case p.is(tokPunct, "["):
+		if err := p.next(); err != nil {
+			return Value{}, err
+		}
+		v := Value{Kind: "List", List: []Value{}}
+		for !p.is(tokPunct, "]") {
+			item, err := p.value(constant)
+			if err != nil {
+				return Value{}, err
+			}
+			v.List = append(v.List, item)
+		}
+		return v, p.next()
+	case p.is(tokPunct, "{"):
+		if err := p.next(); err != nil {
+			return Value{}, err
+		}
+		v := Value{Kind: "Object"}
+		for !p.is(tokPunct, "}") {
+			name, err := p.name()
+			if err != nil {
+				return Value{}, err
+			}
+			if err := p.expect(tokPunct, ":"); err != nil {
+				return Value{}, err
+			}
+			item, err := p.value(constant)
+			if err != nil {
+				return Value{}, err
+			}
+			v.Fields = append(v.Fields, Argument{Name: name, Value: item})
+		}
+		return v, p.next()
+	case tok.kind == tokInt:
+		n, err := strconv.Atoi(tok.value)
+		if err != nil {
+			return Value{}, p.errorf("invalid integer %s", tok.value)
+		}
+		return Value{Kind: "Int", Raw: n}, p.next()
+	case tok.kind == tokFloat:
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return Value{}, p.errorf("invalid float %s", tok.value)
+		}
+		return Value{Kind: "Float", Raw: f}, p.next()
+	case tok.kind == tokString:
+		return Value{Kind: "String", Raw: tok.value}, p.next()
+	case tok.kind == tokName:
+		switch tok.value {
+		case "true", "false":
+			return Value{Kind: "Boolean", Raw: tok.value == "true"}, p.next()
+		case "null":
+			return Value{Kind: "Null"}, p.next()
+		}
+		return Value{Kind: "Enum", Raw: tok.value}, p.next()
+	}
+	return Value{}, p.errorf("unexpected %q in value", tok.value)
+}
+
+func (p *parser) next() error {
+	src := p.src
+	for p.pos < len(src) {
+		c := src[p.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			p.pos++
+			continue
+		}
+		if strings.HasPrefix(src[p.pos:], "\ufeff") {
+			p.pos += len("\ufeff")
+			continue
+		}
+		if c == '#' {
+			for p.pos < len(src) && src[p.pos] != '\n' {
+				p.pos++
+			}
+			continue
+		}
+		break
+	}
+
+	start := p.pos
+	if p.pos >= len(src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return nil
+	}
+
+	c := src[p.pos]
+	switch {
+	case strings.HasPrefix(src[p.pos:], "..."):
+		p.pos += 3
+		p.tok = token{kind: tokPunct, value: "...", pos: start}
+	case strings.IndexByte("!$()[]{}:=@|&", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokPunct, value: string(c), pos: start}
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		for p.pos < len(src) && isNameByte(src[p.pos]) {
+			p.pos++
+		}
+		p.tok = token{kind: tokName, value: src[start:p.pos], pos: start}
+	case c == '-' || c >= '0' && c <= '9':
+		return p.number(start)
+	case c == '"':
+		return p.string(start)
+	default:
+		r, _ := utf8.DecodeRuneInString(src[p.pos:])
+		p.tok = token{pos: start}
+		return p.errorf("unexpected character %q", r)
+	}
+	return nil
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func (p *parser) number(start int) error {
+	src := p.src
+	kind := tokInt
+	if src[p.pos] == '-' {
+		p.pos++
+	}
+	digits := func() {
+		for p.pos < len(src) && src[p.pos] >= '0' && src[p.pos] <= '9' {
+			p.pos++
+		}
+	}
+	digits()
+	if p.pos < len(src) && src[p.pos] == '.' {
+		kind = tokFloat
+		p.pos++
+		digits()
+	}
+	if p.pos < len(src) && (src[p.pos] == 'e' || src[p.pos] == 'E') {
+		kind = tokFloat
+		p.pos++
+		if p.pos < len(src) && (src[p.pos] == '+' || src[p.pos] == '-') {
+			p.pos++
+		}
+		digits()
+	}
+	p.tok = token{kind: kind, value: src[start:p.pos], pos: start}
+	return nil
+}
+
+func (p *parser) string(start int) error {
+	src := p.src
+	if strings.HasPrefix(src[p.pos:], `"""`) {
+		end := strings.Index(src[p.pos+3:], `"""`)
+		for end >= 0 && src[p.pos+3+end-1] == '\\' {
+			next := strings.Index(src[p.pos+3+end+3:], `"""`)
+			if next < 0 {
+				end = -1
+				break
+			}
+			end += 3 + next
+		}
+		if end < 0 {
+			p.tok = token{pos: start}
+			return p.errorf("unterminated block string")
+		}
+		raw := strings.ReplaceAll(src[p.pos+3:p.pos+3+end], `\"""`, `"""`)
+		p.pos += 3 + end + 3
+		p.tok = token{kind: tokString, value: blockString(raw), pos: start}
+		return nil
+	}
+
+	var b strings.Builder
+	p.pos++
+	for {
+		if p.pos >= len(src) || src[p.pos] == '\n' {
+			p.tok = token{pos: start}
+			return p.errorf("unterminated string")
+		}
+		c := src[p.pos]
+		if c == '"' {
+			p.pos++
+			break
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+		if p.pos+1 >= len(src) {
+			p.tok = token{pos: start}
+			return p.errorf("unterminated string")
+		}
+		esc := src[p.pos+1]
+		p.pos += 2
+		switch esc {
+		case '"', '\\', '/':
+			b.WriteByte(esc)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if p.pos+4 > len(src) {
+				p.tok = token{pos: start}
+				return p.errorf("invalid unicode escape")
+			}
+			n, err := strconv.ParseUint(src[p.pos:p.pos+4], 16, 32)
+			if err != nil {
+				p.tok = token{pos: start}
+				return p.errorf("invalid unicode escape")
+			}
+			b.WriteRune(rune(n))
+			p.pos += 4
+		default:
+			p.tok = token{pos: start}
+			return p.errorf("invalid escape \\%c", esc)
+		}
+	}
+	p.tok = token{kind: tokString, value: b.String(), pos: start}
+	return nil
+}
+
+func blockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,107 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	doc, err := Parse(`
+		query Count($text: String! = "a b", $skip: Boolean) {
+			t: transform(text: $text) {
+				...Counts @skip(if: $skip)
+				... on Transform { entropy }
+			}
+		}
+		fragment Counts on Transform { wordCount(model: "words") { words } }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Operations) != 1 {
+		t.Fatalf("got %d operations, want 1", len(doc.Operations))
+	}
+	op := doc.Operations[0]
+	if op.Type != "query" || op.Name != "Count" {
+		t.Errorf("got %s %s, want query Count", op.Type, op.Name)
+	}
+	if len(op.Variables) != 2 || op.Variables[0].Type.String() != "String!" || op.Variables[0].Default != "a b" {
+		t.Errorf("unexpected variables %+v", op.Variables)
+	}
+
+	field := op.SelectionSet[0].Field
+	if field.Key() != "t" || field.Name != "transform" || field.Arguments[0].Value.Variable != "text" {
+		t.Errorf("unexpected field %+v", field)
+	}
+	spread, inline := field.SelectionSet[0], field.SelectionSet[1]
+	if spread.Fragment != "Counts" || spread.Directives[0].Name != "skip" {
+		t.Errorf("unexpected spread %+v", spread)
+	}
+	if inline.Inline == nil || inline.Inline.On != "Transform" {
+		t.Errorf("unexpected inline fragment %+v", inline)
+	}
+	if frag := doc.Fragments["Counts"]; frag == nil || frag.On != "Transform" {
+		t.Errorf("fragment Counts not parsed: %+v", frag)
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	doc, err := Parse(`{ f(i: -3, x: 1.5e2, s: "tab\tqé", b: """ block "quoted" """, n: null, on: true, l: [1, [2]], o: {k: "v"}) }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]interface{}{}
+	for _, arg := range doc.Operations[0].SelectionSet[0].Field.Arguments {
+		v, err := resolveValue(arg.Value, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[arg.Name] = v
+	}
+	if got["i"] != -3 {
+		t.Errorf("i = %#v, want -3", got["i"])
+	}
+	if got["x"] != 150.0 {
+		t.Errorf("x = %#v, want 150", got["x"])
+	}
+	if got["s"] != "tab\tqé" {
+		t.Errorf("s = %q", got["s"])
+	}
+	if got["b"] != ` block "quoted" ` {
+		t.Errorf("b = %q", got["b"])
+	}
+	if got["n"] != nil || got["on"] != true {
+		t.Errorf("n = %#v, on = %#v", got["n"], got["on"])
+	}
+	if l, ok := got["l"].([]interface{}); !ok || len(l) != 2 {
+		t.Errorf("l = %#v", got["l"])
+	}
+	if o, ok := got["o"].(map[string]interface{}); !ok || o["k"] != "v" {
+		t.Errorf("o = %#v", got["o"])
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{``, "no operations"},
+		{`fragment F on T { a }`, "no operations"},
+		{`{ a`, "syntax error"},
+		{`{ a(x: "open) }`, "unterminated string"},
+		{`{ a(x: """open) }`, "unterminated block string"},
+		{`{ a(x: "\q") }`, "invalid escape"},
+		{`{ a(x: 99999999999999999999) }`, "invalid integer"},
+		{`{ a } }`, "unexpected"},
+		{`{ a % }`, "unexpected character"},
+		{strings.Repeat("{ a ", MaxDepth+1) + strings.Repeat("}", MaxDepth+1), "nesting deeper"},
+		{`{ a(x: ` + strings.Repeat("[", MaxDepth+1) + strings.Repeat("]", MaxDepth+1) + `) }`, "nesting deeper"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.query)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%.40q) error = %v, want %q", tt.query, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+type fieldDef struct {
+	name string
+	typ  string
+}
+
+var objectTypes = map[string][]fieldDef{
+	"Operation": {{"name", "String!"}, {"params", "[Param!]!"}},
+	"Param": {
+		{"name", "String!"}, {"type", "String!"}, {"default", "JSON"},
+		{"enum", "[String!]"}, {"minimum", "Int"}, {"maximum", "Int"}, {"required", "Boolean"},
+	},
+	"WordCount": {
+		{"words", "Int!"}, {"characters", "Int!"}, {"charactersNoSpaces", "Int!"},
+		{"lines", "Int!"}, {"paragraphs", "Int!"}, {"tokens", "Int!"}, {"lineStats", "LineStats"},
+	},
+	"LineStats": {
+		{"lines", "[LineStat!]!"}, {"longestLine", "Int!"}, {"longestLength", "Int!"},
+		{"averageLength", "Float!"}, {"standardDeviation", "Float!"},
+	},
+	"LineStat": {{"line", "Int!"}, {"length", "Int!"}, {"words", "Int!"}, {"bytes", "Int!"}},
+	"Sentence": {{"text", "String!"}, {"start", "Int!"}, {"end", "Int!"}},
+}
+
+var objectOrder = []string{"Operation", "Param", "WordCount", "LineStats", "LineStat", "Sentence"}
+
+func (e *Executor) Schema() string {
+	var b strings.Builder
+	b.WriteString("scalar JSON\n\n")
+	b.WriteString("input StepInput {\n  operation: String!\n  params: JSON\n  when: String\n  lines: String\n}\n\n")
+	b.WriteString("type Query {\n  operations: [Operation!]!\n  transform(text: String!): Transform!\n}\n\n")
+
+	catalog := e.registry.Catalog()
+	b.WriteString("type Mutation {\n  run(text: String!, steps: [StepInput!]!): String\n")
+	for _, spec := range catalog {
+		args := append([]argDef{{"text", "String!"}}, paramArgs(spec)...)
+		fmt.Fprintf(&b, "  %s%s: String\n", spec.Name, formatArgs(args))
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString("type Transform {\n  text: String!\n  pipeline(steps: [StepInput!]!): String\n")
+	b.WriteString("  wordCount(model: String, keepWhitespace: Boolean, countRunes: Boolean, perLine: Boolean, splitHyphenated: Boolean, splitContractions: Boolean, splitUrls: Boolean, excludeNumbers: Boolean, excludeCode: Boolean, stripMarkdown: Boolean): WordCount!\n")
+	b.WriteString("  tokens(model: String): Int!\n  entropy: Float!\n  sentences(lang: String): [Sentence!]!\n")
+	for _, spec := range catalog {
+		if builtinTransformField(spec.Name) {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s%s: String\n", spec.Name, formatArgs(paramArgs(spec)))
+	}
+	b.WriteString("}\n")
+
+	for _, name := range objectOrder {
+		fmt.Fprintf(&b, "\ntype %s {\n", name)
+		for _, f := range objectTypes[name] {
+			fmt.Fprintf(&b, "  %s: %s\n", f.name, f.typ)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func builtinTransformField(name string) bool {
+	switch name {
+	case "text", "pipeline", "wordCount", "tokens", "entropy", "sentences":
+		return true
+	}
+	return false
+}
+
+func formatArgs(args []argDef) string {
+	if len(args) == 0 {
+		return ""
+	}
+	parts := make([]string, len(args))
+	for i, a := range args {
+		parts[i] = a.name + ": " + a.typ
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type AnalysisRequest struct {
+	Text string `json:"text"`
+}
+
+type TokenRequest struct {
+	Text  string `json:"text"`
+	Model string `json:"model"`
+}
+
+func AnalyzeEntropy(c *gin.Context) {
+	var req AnalysisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.AnalyzeEntropy(req.Text))
+}
+
+func EstimateTokens(c *gin.Context) {
+	var req TokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := utils.EstimateTokens(req.Text, req.Model)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	model := req.Model
+	if model == "" {
+		model = utils.DefaultTokenModel
+	}
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens, "model": model})
+}
+
+func AnalyzeVocabulary(c *gin.Context) {
+	var req AnalysisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.VocabularyProfile(req.Text))
+}
+
+type RepeatRequest struct {
+	Text    string              `json:"text"`
+	Options utils.RepeatOptions `json:"options"`
+}
+
+func FindRepeatedWords(c *gin.Context) {
+	var req RepeatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := utils.FindRepeatedWordsWithOptions(req.Text, req.Options)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func AnalyzeStyle(c *gin.Context) {
+	var req AnalysisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.AnalyzeStyle(req.Text))
+}
+
+type ProgressRequest struct {
+	Text    string                `json:"text"`
+	Options utils.ProgressOptions `json:"options"`
+}
+
+func CountProgress(c *gin.Context) {
+	var req ProgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.CountProgressWithOptions(req.Text, req.Options)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+type FrequencyRequest struct {
+	Text    string                 `json:"text"`
+	Options utils.FrequencyOptions `json:"options"`
+}
+
+func CharFrequency(c *gin.Context) {
+	var req FrequencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CharFrequencyWithOptions(req.Text, req.Options))
+}
+
+// DetectBinary inspects a raw upload, of any content type, without treating it as text
+func DetectBinary(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 64<<10))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.DetectBinary(body))
+}
+
+type OutlineRequest struct {
+	Text   string              `json:"text"`
+	Format utils.OutlineFormat `json:"format"`
+}
+
+func ExtractOutline(c *gin.Context) {
+	var req OutlineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	outline, err := utils.ExtractOutline(req.Text, req.Format)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, outline)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/pipeline"
+)
+
+const MaxArchiveBytes = 50 << 20
+
+func readArchiveUpload(c *gin.Context) (*zip.Reader, string, bool) {
+	file, header, err := c.Request.FormFile("archive")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "archive file is required"})
+		return nil, "", false
+	}
+	defer file.Close()
+
+	if header.Size > MaxArchiveBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "archive too large"})
+		return nil, "", false
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxArchiveBytes+1))
+	if err != nil || len(data) > MaxArchiveBytes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read archive"})
+		return nil, "", false
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid zip archive: " + err.Error()})
+		return nil, "", false
+	}
+	return zr, header.Filename, true
+}
+
+func archiveIncludes(c *gin.Context) []string {
+	var include []string
+	for _, g := range c.PostFormArray("include") {
+		for _, p := range strings.Split(g, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				include = append(include, p)
+			}
+		}
+	}
+	return include
+}
+
+func ProcessArchive(c *gin.Context) {
+	zr, filename, ok := readArchiveUpload(c)
+	if !ok {
+		return
+	}
+
+	var steps []pipeline.Step
+	if raw := c.PostForm("steps"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &steps); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid steps: " + err.Error()})
+			return
+		}
+	} else if op := c.PostForm("operation"); op != "" {
+		step := pipeline.Step{Operation: op}
+		if raw := c.PostForm("params"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &step.Params); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid params: " + err.Error()})
+				return
+			}
+		}
+		steps = []pipeline.Step{step}
+	}
+	if err := pipeline.Default.Validate(steps); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts := pipeline.ArchiveOptions{Include: archiveIncludes(c)}
+	if err := opts.Validate(zr); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// the archive is built in memory so that a file which failed to read or
+	// transform can still turn into an error response instead of being
+	// passed through unchanged
+	var buf bytes.Buffer
+	results, err := pipeline.ProcessArchive(zr, &buf, opts, func(_, text string) (string, error) {
+		return pipeline.Default.RunContext(c.Request.Context(), text, steps)
+	})
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+	var failed []pipeline.ArchiveFileResult
+	for _, r := range results {
+		if r.Error != "" {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": fmt.Sprintf("%d of %d files failed", len(failed), len(results)),
+			"files": failed,
+		})
+		return
+	}
+
+	name := strings.TrimSuffix(path.Base(filename), ".zip") + "-processed.zip"
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type BidiIsolateRequest struct {
+	Text      string              `json:"text"`
+	Direction utils.BidiDirection `json:"direction"`
+}
+
+func AnalyzeBidi(c *gin.Context) {
+	var req AnalysisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.AnalyzeBidi(req.Text))
+}
+
+func IsolateBidi(c *gin.Context) {
+	var req BidiIsolateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.IsolateBidi(req.Text, req.Direction)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
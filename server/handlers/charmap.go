@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type CharMapRequest struct {
+	Text string `json:"text"`
+	utils.CharMapOptions
+}
+
+func MapCharacters(c *gin.Context) {
+	var req CharMapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.MapCharactersWithOptions(req.Text, req.CharMapOptions)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
+
+func ListCharMaps(c *gin.Context) {
+	tables := gin.H{}
+	for _, m := range utils.CharMaps {
+		tables[string(m)] = m.Table()
+	}
+	c.JSON(http.StatusOK, gin.H{"tables": tables})
+}
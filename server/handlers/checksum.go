@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type ChecksumRequest struct {
+	Text      string `json:"text"`
+	Algorithm string `json:"algorithm"`
+	Expected  string `json:"expected" binding:"required"`
+}
+
+type ChecksumListRequest struct {
+	List      string            `json:"list" binding:"required"`
+	Files     map[string]string `json:"files"`
+	Algorithm string            `json:"algorithm"`
+}
+
+func VerifyChecksum(c *gin.Context) {
+	var req ChecksumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.VerifyChecksum(req.Text, req.Algorithm, req.Expected)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func VerifyChecksumList(c *gin.Context) {
+	var req ChecksumListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := utils.VerifyChecksumList(req.List, req.Files, req.Algorithm)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ok := true
+	for _, r := range results {
+		ok = ok && r.Match
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": ok, "results": results})
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type ChunkRequest struct {
+	Text    string             `json:"text"`
+	Options utils.ChunkOptions `json:"options"`
+}
+
+func ChunkText(c *gin.Context) {
+	var req ChunkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	chunks, err := utils.ChunkText(req.Text, req.Options)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"chunks": chunks, "count": len(chunks)})
+}
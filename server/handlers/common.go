@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type CommonRequest struct {
+	Text  string `json:"text"`
+	Other string `json:"other"`
+}
+
+func CommonAffixes(c *gin.Context) {
+	var req CommonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lines := strings.Split(req.Text, "\n")
+	resp := gin.H{
+		"prefix": utils.CommonPrefix(lines),
+		"suffix": utils.CommonSuffix(lines),
+	}
+	if req.Other != "" {
+		resp["longestCommonSubstring"] = utils.LongestCommonSubstring(req.Text, req.Other)
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type CompareListsRequest struct {
+	A       string               `json:"a"`
+	B       string               `json:"b"`
+	Options utils.CompareOptions `json:"options"`
+}
+
+func CompareLists(c *gin.Context) {
+	var req CompareListsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.Options.Equivalence.Validate(); err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CompareLists(req.A, req.B, req.Options))
+}
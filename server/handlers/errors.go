@@ -0,0 +1,11 @@
+package handlers
+
+import (
+	"net/http"
+
+	"toolkit-backend/utils"
+)
+
+func statusFor(err error) int {
+	return utils.StatusFor(err, http.StatusUnprocessableEntity)
+}
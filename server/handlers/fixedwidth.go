@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type FixedWidthRequest struct {
+	Text    string                  `json:"text"`
+	Options utils.FixedWidthOptions `json:"options"`
+}
+
+func ConvertFixedWidth(c *gin.Context) {
+	var req FixedWidthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	table, err := utils.ParseFixedWidth(req.Text, req.Options)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+	result, err := utils.ConvertFixedWidth(req.Text, req.Options)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"widths": table.Widths, "columns": table.Columns, "rows": table.Rows, "result": result})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type FrontMatterRequest struct {
+	Text     string                 `json:"text"`
+	Key      string                 `json:"key"`
+	Set      map[string]interface{} `json:"set"`
+	Unset    []string               `json:"unset"`
+	Required []string               `json:"required"`
+}
+
+func ExtractFrontMatter(c *gin.Context) {
+	var req FrontMatterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Key != "" {
+		value, found, err := utils.FrontMatterField(req.Text, req.Key)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"key": req.Key, "value": value, "found": found})
+		return
+	}
+
+	fm, err := utils.ExtractFrontMatter(req.Text)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fm)
+}
+
+func SetFrontMatter(c *gin.Context) {
+	var req FrontMatterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.SetFrontMatter(req.Text, req.Set, req.Unset)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
+
+func ValidateFrontMatter(c *gin.Context) {
+	var req FrontMatterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	problems, err := utils.ValidateFrontMatter(req.Text, req.Required)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"valid": false, "problems": []string{err.Error()}})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"valid": len(problems) == 0, "problems": problems})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type InvisibleRequest struct {
+	Text string `json:"text"`
+}
+
+type HideMessageRequest struct {
+	Cover  string `json:"cover"`
+	Secret string `json:"secret" binding:"required"`
+}
+
+func DetectInvisible(c *gin.Context) {
+	var req InvisibleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.DetectInvisible(req.Text))
+}
+
+func VisualizeWhitespace(c *gin.Context) {
+	var req InvisibleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.VisualizeWhitespace(req.Text))
+}
+
+func HideMessage(c *gin.Context) {
+	var req HideMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.HideMessage(req.Cover, req.Secret)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
+
+func RevealMessage(c *gin.Context) {
+	var req InvisibleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message, err := utils.RevealMessage(req.Text)
+	if errors.Is(err, utils.ErrNoHiddenMessage) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
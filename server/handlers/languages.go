@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/languages"
+	"toolkit-backend/utils"
+)
+
+func ListLanguages(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"languages": languages.Default.List()})
+}
+
+type StopWordsRequest struct {
+	Text string `json:"text"`
+	Lang string `json:"lang"`
+	// StopWords are removed along with the language's own
+	StopWords []string `json:"stopWords"`
+}
+
+func RemoveStopWords(c *gin.Context) {
+	var req StopWordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.RemoveStopWords(req.Text, req.Lang, req.StopWords)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
+
+type TagLanguagesRequest struct {
+	Text string `json:"text"`
+	utils.LanguageTagOptions
+}
+
+func TagLanguages(c *gin.Context) {
+	var req TagLanguagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	spans, err := utils.TagLanguages(req.Text, req.LanguageTagOptions)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": spans})
+}
+
+type FilterLanguageRequest struct {
+	Text string `json:"text"`
+	utils.LanguageFilterOptions
+}
+
+func FilterLanguage(c *gin.Context) {
+	var req FilterLanguageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.FilterByLanguage(req.Text, req.LanguageFilterOptions)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type LineLengthRequest struct {
+	Text  string `json:"text"`
+	Limit int    `json:"limit" binding:"required"`
+	Fix   bool   `json:"fix"`
+}
+
+func CheckLineLengths(c *gin.Context) {
+	var req LineLengthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	violations, err := utils.CheckLineLengths(req.Text, req.Limit)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := gin.H{"ok": len(violations) == 0, "violations": violations}
+	if req.Fix {
+		fixed, err := utils.FixLineLengths(req.Text, req.Limit)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		resp["fixed"] = fixed
+	}
+	c.JSON(http.StatusOK, resp)
+}
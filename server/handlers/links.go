@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/sources"
+	"toolkit-backend/utils"
+)
+
+type LinksRequest struct {
+	Text    string                   `json:"text"`
+	Format  string                   `json:"format"`
+	Check   bool                     `json:"check"`
+	Options sources.LinkCheckOptions `json:"options"`
+}
+
+func ExtractLinks(c *gin.Context) {
+	var req LinksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	links, err := utils.ExtractLinks(req.Text, req.Format)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+	if !req.Check {
+		c.JSON(http.StatusOK, gin.H{"links": links})
+		return
+	}
+
+	statuses, err := sources.CheckLinks(c.Request.Context(), links, req.Options)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"links": links, "statuses": statuses})
+}
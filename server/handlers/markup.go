@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type MarkupRequest struct {
+	Text string             `json:"text"`
+	From utils.MarkupFormat `json:"from" binding:"required"`
+	To   utils.MarkupFormat `json:"to" binding:"required"`
+}
+
+func ConvertMarkup(c *gin.Context) {
+	var req MarkupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.ConvertMarkup(req.Text, req.From, req.To)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
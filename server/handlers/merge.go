@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type MergeRequest struct {
+	Base    string             `json:"base"`
+	Ours    string             `json:"ours"`
+	Theirs  string             `json:"theirs"`
+	Options utils.MergeOptions `json:"options"`
+}
+
+func Merge3(c *gin.Context) {
+	var req MergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.Merge3WithOptions(c.Request.Context(), req.Base, req.Ours, req.Theirs, req.Options)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type MIMERequest struct {
+	Text     string `json:"text"`
+	Decode   bool   `json:"decode"`
+	Encoding string `json:"encoding"`
+}
+
+func QuotedPrintable(c *gin.Context) {
+	var req MIMERequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	convert := utils.EncodeQuotedPrintable
+	if req.Decode {
+		convert = utils.DecodeQuotedPrintable
+	}
+	result, err := convert(req.Text)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
+
+func MIMEHeader(c *gin.Context) {
+	var req MIMERequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result string
+	var err error
+	if req.Decode {
+		result, err = utils.DecodeMIMEHeader(req.Text)
+	} else {
+		result, err = utils.EncodeMIMEHeader(req.Text, req.Encoding)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
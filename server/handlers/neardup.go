@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type NearDuplicatesRequest struct {
+	Text      string   `json:"text"`
+	Documents []string `json:"documents"`
+	Threshold float64  `json:"threshold"`
+}
+
+func NearDuplicates(c *gin.Context) {
+	var req NearDuplicatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Threshold == 0 {
+		req.Threshold = 0.8
+	}
+
+	var clusters []utils.NearDuplicateCluster
+	var err error
+	if len(req.Documents) > 0 {
+		clusters, err = utils.NearDuplicateDocuments(req.Documents, req.Threshold)
+	} else {
+		clusters, err = utils.NearDuplicates(req.Text, req.Threshold)
+	}
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"clusters": clusters})
+}
+
+type DedupeParagraphsRequest struct {
+	Text string `json:"text"`
+	utils.ParagraphDedupeOptions
+}
+
+func DedupeParagraphs(c *gin.Context) {
+	var req DedupeParagraphsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, removed, err := utils.RemoveDuplicateParagraphs(req.Text, req.ParagraphDedupeOptions)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result, "removed": removed})
+}
@@ -0,0 +1,13 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/pipeline"
+)
+
+func ListOperations(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"operations": pipeline.Default.Catalog()})
+}
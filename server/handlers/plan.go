@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/pipeline"
+)
+
+// PlanRequest sizes the input either from Input or, when given, from Text
+type PlanRequest struct {
+	Steps []pipeline.Step `json:"steps" binding:"required"`
+	Input pipeline.Size   `json:"input"`
+	Text  *string         `json:"text"`
+}
+
+func PlanPipeline(c *gin.Context) {
+	var req PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input := req.Input
+	if req.Text != nil {
+		input = pipeline.SizeOf(*req.Text)
+	}
+	plan, err := pipeline.Default.Plan(req.Steps, input)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type RegexRequest struct {
+	Pattern string           `json:"pattern" binding:"required"`
+	Text    string           `json:"text"`
+	Flags   utils.RegexFlags `json:"flags"`
+}
+
+func TestRegex(c *gin.Context) {
+	var req RegexRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result *utils.RegexResult
+	ctx := c.Request.Context()
+	err := utils.LimitsFrom(ctx).CheckRegex(req.Pattern, req.Flags)
+	if err == nil {
+		result, err = utils.TestRegexContext(ctx, req.Pattern, req.Text, req.Flags)
+	}
+	if err != nil {
+		var rerr *utils.RegexError
+		if errors.As(err, &rerr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": rerr.Error(), "details": rerr})
+			return
+		}
+		var lerr *utils.LimitError
+		if errors.As(err, &lerr) {
+			c.JSON(lerr.Status(), gin.H{"error": lerr.Error(), "details": lerr})
+			return
+		}
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/pipeline"
+	"toolkit-backend/utils"
+)
+
+type ReplacePreviewRequest struct {
+	Text    string              `json:"text"`
+	Pairs   []utils.ReplacePair `json:"pairs" binding:"required"`
+	Context *int                `json:"context"`
+}
+
+func PreviewReplace(c *gin.Context) {
+	var req ReplacePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	context := utils.DefaultPreviewContext
+	if req.Context != nil {
+		context = *req.Context
+	}
+
+	preview, err := utils.PreviewReplaceMany(req.Text, req.Pairs, context)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+type ReplaceFilesRequest struct {
+	Files []utils.NamedText   `json:"files" binding:"required,dive"`
+	Pairs []utils.ReplacePair `json:"pairs" binding:"required,dive"`
+}
+
+func ReplaceFiles(c *gin.Context) {
+	var req ReplaceFilesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.ReplaceAcrossFiles(req.Files, req.Pairs)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func ReplaceArchive(c *gin.Context) {
+	zr, _, ok := readArchiveUpload(c)
+	if !ok {
+		return
+	}
+
+	var pairs []utils.ReplacePair
+	if err := json.Unmarshal([]byte(c.PostForm("pairs")), &pairs); err != nil || len(pairs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pairs must be a non-empty JSON array"})
+		return
+	}
+
+	files, skipped, err := pipeline.ReadArchiveTexts(zr, pipeline.ArchiveOptions{Include: archiveIncludes(c)})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.ReplaceAcrossFiles(files, pairs)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	skippedFiles := []pipeline.ArchiveFileResult{}
+	c.JSON(http.StatusOK, gin.H{
+		"files":        result.Files,
+		"replacements": result.Replacements,
+		"changed":      result.Changed,
+		"diff":         result.Diff,
+		"skipped":      append(skippedFiles, skipped...),
+	})
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r gin.IRouter) {
+	r.POST("/regex/test", TestRegex)
+	r.POST("/replace/preview", PreviewReplace)
+	r.POST("/replace/files", ReplaceFiles)
+	r.POST("/replace/archive", ReplaceArchive)
+	r.POST("/archive/process", ProcessArchive)
+	r.GET("/operations", ListOperations)
+	r.POST("/operations/plan", PlanPipeline)
+	r.POST("/pseudonymize", Pseudonymize)
+	r.POST("/secrets/scan", ScanSecrets)
+	r.POST("/invisible/detect", DetectInvisible)
+	r.POST("/whitespace/visualize", VisualizeWhitespace)
+	r.POST("/paste/clean", CleanPaste)
+	r.POST("/charmap", MapCharacters)
+	r.GET("/charmap/tables", ListCharMaps)
+	r.POST("/markup/convert", ConvertMarkup)
+	r.POST("/emoji/to-shortcode", EmojiToShortcode)
+	r.POST("/emoji/to-emoji", ShortcodeToEmoji)
+	r.POST("/stego/hide", HideMessage)
+	r.POST("/stego/reveal", RevealMessage)
+	r.POST("/mime/quoted-printable", QuotedPrintable)
+	r.POST("/mime/header", MIMEHeader)
+	r.POST("/palindrome", CheckPalindrome)
+	r.POST("/anagrams", Anagrams)
+	r.POST("/phonetic", MatchPhonetic)
+	r.POST("/nearest", NearestLines)
+	r.POST("/near-duplicates", NearDuplicates)
+	r.POST("/dedupe/paragraphs", DedupeParagraphs)
+	r.POST("/analyze/entropy", AnalyzeEntropy)
+	r.POST("/analyze/tokens", EstimateTokens)
+	r.POST("/analyze/vocabulary", AnalyzeVocabulary)
+	r.POST("/analyze/repeats", FindRepeatedWords)
+	r.POST("/analyze/style", AnalyzeStyle)
+	r.POST("/analyze/progress", CountProgress)
+	r.POST("/analyze/frequency", CharFrequency)
+	r.POST("/analyze/binary", DetectBinary)
+	r.POST("/analyze/outline", ExtractOutline)
+	r.POST("/checksum/verify", VerifyChecksum)
+	r.POST("/checksum/verify-list", VerifyChecksumList)
+	r.POST("/validate/lines", ValidateLines)
+	r.POST("/sample", SampleLines)
+	r.POST("/pick", PickLines)
+	r.POST("/chunk", ChunkText)
+	r.POST("/split/limit", SplitForLimit)
+	r.POST("/generate/fixture", GenerateFixture)
+	r.POST("/links/extract", ExtractLinks)
+	r.POST("/csv/validate", ValidateCSV)
+	r.POST("/csv/fix", FixCSV)
+	r.POST("/sentences", SplitSentences)
+	r.POST("/stopwords/remove", RemoveStopWords)
+	r.GET("/languages", ListLanguages)
+	r.POST("/languages/tag", TagLanguages)
+	r.POST("/languages/filter", FilterLanguage)
+	r.POST("/frontmatter/extract", ExtractFrontMatter)
+	r.POST("/frontmatter/set", SetFrontMatter)
+	r.POST("/frontmatter/validate", ValidateFrontMatter)
+	r.POST("/common", CommonAffixes)
+	r.POST("/merge", Merge3)
+	r.POST("/fixed-width", ConvertFixedWidth)
+	r.POST("/lists/compare", CompareLists)
+	r.POST("/lint/line-length", CheckLineLengths)
+	r.POST("/bidi/analyze", AnalyzeBidi)
+	r.POST("/bidi/isolate", IsolateBidi)
+}
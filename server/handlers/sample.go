@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type SampleRequest struct {
+	Text  string `json:"text"`
+	Count int    `json:"count" binding:"required"`
+	Seed  int64  `json:"seed"`
+}
+
+func SampleLines(c *gin.Context) {
+	var req SampleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.SampleLines(req.Text, req.Count, req.Seed)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
+
+type PickRequest struct {
+	Text  string `json:"text"`
+	Count int    `json:"count" binding:"required"`
+	utils.PickOptions
+}
+
+func PickLines(c *gin.Context) {
+	var req PickRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := utils.PickLines(req.Text, req.Count, req.PickOptions)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
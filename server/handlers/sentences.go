@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type SentenceRequest struct {
+	Text string `json:"text"`
+	Lang string `json:"lang"`
+}
+
+func SplitSentences(c *gin.Context) {
+	var req SentenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sentences, err := utils.SplitSentences(req.Text, req.Lang)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sentences": sentences, "count": len(sentences)})
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type SplitLimitRequest struct {
+	Text    string                  `json:"text"`
+	Limit   int                     `json:"limit"`
+	Options utils.SplitLimitOptions `json:"options"`
+}
+
+func SplitForLimit(c *gin.Context) {
+	var req SplitLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	parts, err := utils.SplitForLimit(req.Text, req.Limit, req.Options)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"parts": parts, "count": len(parts)})
+}
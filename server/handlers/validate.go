@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type ValidateLinesRequest struct {
+	Text string `json:"text"`
+	utils.ValidateOptions
+}
+
+func ValidateLines(c *gin.Context) {
+	var req ValidateLinesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, summary, err := utils.ValidateLines(req.Text, req.ValidateOptions)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result, "valid": summary.Valid, "invalid": summary.Invalid})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type PalindromeRequest struct {
+	Text    string              `json:"text"`
+	Options utils.LetterOptions `json:"options"`
+}
+
+type AnagramRequest struct {
+	Text  string `json:"text"`
+	Other string `json:"other"`
+}
+
+func CheckPalindrome(c *gin.Context) {
+	var req PalindromeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"palindrome": utils.IsPalindrome(req.Text, req.Options)})
+}
+
+func Anagrams(c *gin.Context) {
+	var req AnagramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Other != "" {
+		c.JSON(http.StatusOK, gin.H{"anagrams": utils.AreAnagrams(req.Text, req.Other)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"groups": utils.GroupAnagrams(req.Text)})
+}
+
+type PhoneticRequest struct {
+	Text  string `json:"text"`
+	Query string `json:"query" binding:"required"`
+}
+
+func MatchPhonetic(c *gin.Context) {
+	var req PhoneticRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	primary, secondary := utils.DoubleMetaphone(req.Query)
+	c.JSON(http.StatusOK, gin.H{
+		"result":    utils.MatchPhonetic(req.Text, req.Query),
+		"soundex":   utils.Soundex(req.Query),
+		"metaphone": []string{primary, secondary},
+	})
+}
+
+type NearestRequest struct {
+	Text  string `json:"text"`
+	Query string `json:"query" binding:"required"`
+	utils.NearestOptions
+}
+
+func NearestLines(c *gin.Context) {
+	var req NearestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	matches, err := utils.NearestLinesWithOptions(req.Text, req.Query, req.NearestOptions)
+	if err != nil {
+		c.JSON(statusFor(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": matches})
+}
@@ -0,0 +1,102 @@
+package history
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/pipeline"
+	"toolkit-backend/utils"
+)
+
+type CreateRequest struct {
+	Text string `json:"text"`
+}
+
+type RevertRequest struct {
+	Step int `json:"step"`
+}
+
+var errorStatuses = []utils.ErrorStatus{
+	{Err: ErrSessionNotFound, Status: http.StatusNotFound},
+	{Err: ErrStepNotFound, Status: http.StatusNotFound},
+	{Err: ErrTooManySessions, Status: http.StatusServiceUnavailable},
+	{Err: ErrSessionFull, Status: http.StatusRequestEntityTooLarge},
+}
+
+func statusFor(err error) int {
+	return utils.StatusFor(err, http.StatusBadRequest, errorStatuses...)
+}
+
+func RegisterRoutes(r gin.IRouter, s *Store) {
+	r.POST("/sessions", func(c *gin.Context) {
+		var req CreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		session, err := s.Create(req.Text)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": session.ID, "step": 0})
+	})
+
+	r.POST("/sessions/:id/apply", func(c *gin.Context) {
+		var step pipeline.Step
+		if err := c.ShouldBindJSON(&step); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		entry, result, err := s.Apply(c.Request.Context(), c.Param("id"), step)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"step": entry.Index, "result": result})
+	})
+
+	r.GET("/sessions/:id/history", func(c *gin.Context) {
+		entries, err := s.History(c.Param("id"))
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"entries": entries})
+	})
+
+	r.GET("/sessions/:id/steps/:step", func(c *gin.Context) {
+		index, err := strconv.Atoi(c.Param("step"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid step"})
+			return
+		}
+		text, err := s.Text(c.Param("id"), index)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"step": index, "result": text})
+	})
+
+	r.POST("/sessions/:id/revert", func(c *gin.Context) {
+		var req RevertRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		text, err := s.Revert(c.Param("id"), req.Step)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"step": req.Step, "result": text})
+	})
+
+	r.DELETE("/sessions/:id", func(c *gin.Context) {
+		s.Delete(c.Param("id"))
+		c.Status(http.StatusNoContent)
+	})
+}
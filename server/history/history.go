@@ -0,0 +1,231 @@
+package history
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"toolkit-backend/pipeline"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrStepNotFound    = errors.New("history step not found")
+	ErrTooManySessions = errors.New("too many open sessions")
+	ErrSessionFull     = errors.New("session history is full")
+)
+
+// Every entry keeps its output. Replaying steps cannot reproduce them: date
+// variables, wordlists and snippet sets change between runs.
+type Entry struct {
+	Index      int            `json:"index"`
+	Step       *pipeline.Step `json:"step,omitempty"`
+	InputHash  string         `json:"inputHash"`
+	OutputHash string         `json:"outputHash"`
+	OutputSize int            `json:"outputSize"`
+	AppliedAt  time.Time      `json:"appliedAt"`
+
+	output string
+}
+
+// Session is locked by its own mu while a step runs, so sessions do not
+// wait on each other; LastUsed belongs to the store's lock
+type Session struct {
+	ID       string    `json:"id"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"lastUsed"`
+
+	mu      sync.Mutex
+	entries []*Entry
+	size    int64
+}
+
+type Config struct {
+	MaxSteps    int `json:"maxSteps"`
+	MaxSessions int `json:"maxSessions"`
+	// MaxSessionBytes bounds the outputs one session keeps
+	MaxSessionBytes int64         `json:"maxSessionBytes"`
+	SessionTTL      time.Duration `json:"sessionTtl"`
+}
+
+type Store struct {
+	mu       sync.Mutex
+	registry *pipeline.Registry
+	cfg      Config
+	sessions map[string]*Session
+}
+
+func NewStore(registry *pipeline.Registry, cfg Config) *Store {
+	if cfg.MaxSteps <= 0 {
+		cfg.MaxSteps = 200
+	}
+	if cfg.MaxSessions <= 0 {
+		cfg.MaxSessions = 1000
+	}
+	if cfg.MaxSessionBytes <= 0 {
+		cfg.MaxSessionBytes = 64 << 20
+	}
+	if cfg.SessionTTL <= 0 {
+		cfg.SessionTTL = 2 * time.Hour
+	}
+	return &Store{registry: registry, cfg: cfg, sessions: make(map[string]*Session)}
+}
+
+func hashText(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Store) Create(text string) (*Session, error) {
+	if int64(len(text)) > s.cfg.MaxSessionBytes {
+		return nil, fmt.Errorf("%w: text exceeds %d bytes", ErrSessionFull, s.cfg.MaxSessionBytes)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.evict(now)
+	if len(s.sessions) >= s.cfg.MaxSessions {
+		return nil, ErrTooManySessions
+	}
+
+	hash := hashText(text)
+	session := &Session{
+		ID:       uuid.New().String(),
+		Created:  now,
+		LastUsed: now,
+		entries: []*Entry{{
+			Index:      0,
+			InputHash:  hash,
+			OutputHash: hash,
+			OutputSize: len(text),
+			AppliedAt:  now,
+			output:     text,
+		}},
+		size: int64(len(text)),
+	}
+	s.sessions[session.ID] = session
+	return session, nil
+}
+
+func (s *Store) session(id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	if time.Since(session.LastUsed) > s.cfg.SessionTTL {
+		delete(s.sessions, id)
+		return nil, ErrSessionNotFound
+	}
+	session.LastUsed = time.Now()
+	return session, nil
+}
+
+// Apply runs step under ctx, normally the request's, so its limits,
+// deadline and cancellation apply
+func (s *Store) Apply(ctx context.Context, id string, step pipeline.Step) (*Entry, string, error) {
+	session, err := s.session(id)
+	if err != nil {
+		return nil, "", err
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if len(session.entries) > s.cfg.MaxSteps {
+		return nil, "", fmt.Errorf("%w: limit of %d steps reached", ErrSessionFull, s.cfg.MaxSteps)
+	}
+
+	last := session.entries[len(session.entries)-1]
+	out, err := s.registry.RunContext(ctx, last.output, []pipeline.Step{step})
+	if err != nil {
+		return nil, "", err
+	}
+	if session.size+int64(len(out)) > s.cfg.MaxSessionBytes {
+		return nil, "", fmt.Errorf("%w: limit of %d bytes reached", ErrSessionFull, s.cfg.MaxSessionBytes)
+	}
+
+	entry := &Entry{
+		Index:      len(session.entries),
+		Step:       &step,
+		InputHash:  last.OutputHash,
+		OutputHash: hashText(out),
+		OutputSize: len(out),
+		AppliedAt:  time.Now(),
+		output:     out,
+	}
+	session.entries = append(session.entries, entry)
+	session.size += int64(len(out))
+	return entry, out, nil
+}
+
+func (s *Store) History(id string) ([]Entry, error) {
+	session, err := s.session(id)
+	if err != nil {
+		return nil, err
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	entries := make([]Entry, len(session.entries))
+	for i, e := range session.entries {
+		entries[i] = *e
+	}
+	return entries, nil
+}
+
+func (s *Store) Text(id string, index int) (string, error) {
+	session, err := s.session(id)
+	if err != nil {
+		return "", err
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if index < 0 || index >= len(session.entries) {
+		return "", ErrStepNotFound
+	}
+	return session.entries[index].output, nil
+}
+
+func (s *Store) Revert(id string, index int) (string, error) {
+	session, err := s.session(id)
+	if err != nil {
+		return "", err
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if index < 0 || index >= len(session.entries) {
+		return "", ErrStepNotFound
+	}
+	for _, e := range session.entries[index+1:] {
+		session.size -= int64(len(e.output))
+	}
+	clear(session.entries[index+1:])
+	session.entries = session.entries[:index+1]
+	return session.entries[index].output, nil
+}
+
+func (s *Store) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+func (s *Store) evict(now time.Time) {
+	for id, session := range s.sessions {
+		if now.Sub(session.LastUsed) > s.cfg.SessionTTL {
+			delete(s.sessions, id)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"toolkit-backend/metrics"
+	"toolkit-backend/pipeline"
+	"toolkit-backend/sources"
+	"toolkit-backend/utils"
+)
+
+var (
+	ErrNotFound  = errors.New("job not found")
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrWebhooksDisabled is returned for a webhook when no secret is
+	// configured, since receivers could not tell a delivery from a forgery
+	ErrWebhooksDisabled = errors.New("webhooks are not enabled: no webhook secret is configured")
+)
+
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+type Job struct {
+	ID         string          `json:"id"`
+	Status     string          `json:"status"`
+	Steps      []pipeline.Step `json:"steps"`
+	Result     string          `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	WebhookURL string          `json:"webhookUrl,omitempty"`
+	InputURI   string          `json:"inputUri,omitempty"`
+	OutputURI  string          `json:"outputUri,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	StartedAt  *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+
+	input       string
+	credentials sources.Credentials
+	// values is the submitting request's context, kept for what it carries:
+	// the caller's wordlists and snippet sets, limits and request id
+	values context.Context
+}
+
+type Request struct {
+	Text        string               `json:"text"`
+	Steps       []pipeline.Step      `json:"steps" binding:"required"`
+	WebhookURL  string               `json:"webhookUrl"`
+	InputURI    string               `json:"inputUri"`
+	OutputURI   string               `json:"outputUri"`
+	Credentials *sources.Credentials `json:"credentials"`
+}
+
+type Config struct {
+	Workers        int           `json:"workers"`
+	QueueSize      int           `json:"queueSize"`
+	Retention      time.Duration `json:"retention"`
+	WebhookSecret  string        `json:"-"`
+	WebhookRetries int           `json:"webhookRetries"`
+	WebhookBackoff time.Duration `json:"webhookBackoff"`
+	WebhookTimeout time.Duration `json:"webhookTimeout"`
+	MaxObjectBytes int64         `json:"maxObjectBytes"`
+	// Objects configures the stores behind inputUri and outputUri
+	Objects sources.ObjectConfig `json:"objects"`
+	// AllowPrivateWebhooks lets webhooks reach loopback and private
+	// addresses, for local development only
+	AllowPrivateWebhooks bool `json:"allowPrivateWebhooks"`
+}
+
+type Queue struct {
+	registry *pipeline.Registry
+	cfg      Config
+	client   *http.Client
+	pending  chan *Job
+
+	mu   sync.RWMutex
+	jobs map[string]*Job
+}
+
+func NewQueue(registry *pipeline.Registry, cfg Config) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = time.Hour
+	}
+	if cfg.WebhookRetries <= 0 {
+		cfg.WebhookRetries = 5
+	}
+	if cfg.WebhookBackoff <= 0 {
+		cfg.WebhookBackoff = time.Second
+	}
+	if cfg.WebhookTimeout <= 0 {
+		cfg.WebhookTimeout = 10 * time.Second
+	}
+	// job output must not be posted to internal services, so every
+	// connection, redirects included, is checked where it is dialed
+	dialer := &net.Dialer{Timeout: cfg.WebhookTimeout}
+	if !cfg.AllowPrivateWebhooks {
+		dialer.Control = sources.PublicOnly
+	}
+	return &Queue{
+		registry: registry,
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.WebhookTimeout, Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: cfg.WebhookTimeout}},
+		pending:  make(chan *Job, cfg.QueueSize),
+		jobs:     make(map[string]*Job),
+	}
+}
+
+func (q *Queue) Start(ctx context.Context) {
+	for i := 0; i < q.cfg.Workers; i++ {
+		go q.work(ctx)
+	}
+	go q.prune(ctx)
+}
+
+func (q *Queue) Depth() int {
+	return len(q.pending)
+}
+
+func (q *Queue) RegisterMetrics(r *metrics.Registry) {
+	r.RegisterGauge(metrics.QueueDepthGauge, func() float64 {
+		return float64(q.Depth())
+	})
+}
+
+// Submit queues the job; its steps later run with the values of ctx, the
+// submitting request's context, though not with its deadline
+func (q *Queue) Submit(ctx context.Context, req Request) (Job, error) {
+	if err := q.registry.Validate(req.Steps); err != nil {
+		return Job{}, err
+	}
+	if req.WebhookURL != "" {
+		if q.cfg.WebhookSecret == "" {
+			return Job{}, ErrWebhooksDisabled
+		}
+		if err := validateWebhookURL(req.WebhookURL, q.cfg.AllowPrivateWebhooks); err != nil {
+			return Job{}, err
+		}
+	}
+	for _, uri := range []string{req.InputURI, req.OutputURI} {
+		if uri == "" {
+			continue
+		}
+		if _, _, _, err := sources.ParseObjectURI(uri); err != nil {
+			return Job{}, err
+		}
+		if req.Credentials == nil {
+			return Job{}, fmt.Errorf("credentials are required for %s", uri)
+		}
+	}
+
+	job := &Job{
+		ID:         uuid.New().String(),
+		Status:     StatusQueued,
+		Steps:      req.Steps,
+		WebhookURL: req.WebhookURL,
+		InputURI:   req.InputURI,
+		OutputURI:  req.OutputURI,
+		CreatedAt:  time.Now(),
+		input:      req.Text,
+		values:     context.WithoutCancel(ctx),
+	}
+	if req.Credentials != nil {
+		job.credentials = *req.Credentials
+	}
+
+	q.mu.Lock()
+	q.jobs[job.ID] = job
+	q.mu.Unlock()
+
+	select {
+	case q.pending <- job:
+		return *job, nil
+	default:
+		q.mu.Lock()
+		delete(q.jobs, job.ID)
+		q.mu.Unlock()
+		return Job{}, ErrQueueFull
+	}
+}
+
+func (q *Queue) Get(id string) (Job, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return *job, nil
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-q.pending:
+			q.run(ctx, job)
+		}
+	}
+}
+
+func (q *Queue) run(ctx context.Context, job *Job) {
+	started := time.Now()
+	q.mu.Lock()
+	job.Status = StatusRunning
+	job.StartedAt = &started
+	input, creds, values := job.input, job.credentials, job.values
+	q.mu.Unlock()
+
+	// cancelled with the worker rather than the long finished request, and
+	// held to the submitting key's time budget from the moment it starts
+	limits := utils.LimitsFrom(values)
+	var runCtx context.Context
+	var cancel context.CancelFunc
+	if limits.TimeBudget > 0 {
+		runCtx, cancel = context.WithTimeout(values, limits.TimeBudget)
+	} else {
+		runCtx, cancel = context.WithCancel(values)
+	}
+	stop := context.AfterFunc(ctx, cancel)
+	result, err := q.execute(runCtx, job, input, creds)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		err = &utils.LimitError{Limit: utils.LimitTimeBudget, Max: limits.TimeBudget.Seconds(), Actual: time.Since(started).Round(time.Millisecond).Seconds()}
+	}
+	stop()
+	cancel()
+
+	finished := time.Now()
+	q.mu.Lock()
+	job.FinishedAt = &finished
+	job.input = ""
+	job.credentials = sources.Credentials{}
+	job.values = nil
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = StatusCompleted
+		job.Result = result
+	}
+	snapshot := *job
+	q.mu.Unlock()
+
+	slog.InfoContext(ctx, "job finished", "job_id", job.ID, "status", snapshot.Status, "duration", finished.Sub(started))
+
+	if snapshot.WebhookURL != "" {
+		go q.notify(ctx, snapshot)
+	}
+}
+
+func (q *Queue) execute(ctx context.Context, job *Job, input string, creds sources.Credentials) (string, error) {
+	if job.InputURI != "" {
+		text, err := sources.ReadObject(ctx, job.InputURI, creds, q.cfg.Objects, q.cfg.MaxObjectBytes)
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %v", err)
+		}
+		if err := utils.LimitsFrom(ctx).CheckInput(int64(len(text))); err != nil {
+			return "", err
+		}
+		input = text
+	}
+
+	result, err := q.registry.RunContext(ctx, input, job.Steps)
+	if err != nil {
+		return "", err
+	}
+
+	if job.OutputURI != "" {
+		if err := sources.WriteObject(ctx, job.OutputURI, creds, q.cfg.Objects, result); err != nil {
+			return "", fmt.Errorf("failed to write output: %v", err)
+		}
+		return "", nil
+	}
+	return result, nil
+}
+
+func (q *Queue) prune(ctx context.Context) {
+	ticker := time.NewTicker(q.cfg.Retention / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			q.mu.Lock()
+			for id, job := range q.jobs {
+				if job.FinishedAt != nil && now.Sub(*job.FinishedAt) > q.cfg.Retention {
+					delete(q.jobs, id)
+				}
+			}
+			q.mu.Unlock()
+		}
+	}
+}
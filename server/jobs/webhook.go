@@ -0,0 +1,125 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"toolkit-backend/sources"
+)
+
+const (
+	SignatureHeader = "X-TextForge-Signature"
+	EventHeader     = "X-TextForge-Event"
+	DeliveryHeader  = "X-TextForge-Delivery"
+)
+
+type webhookPayload struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Result     string     `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+}
+
+// validateWebhookURL rejects literal internal addresses up front; names that
+// resolve to one are refused when the client dials them
+func validateWebhookURL(raw string, allowPrivate bool) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("webhook URL must use http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("webhook URL must include a host")
+	}
+	if allowPrivate {
+		return nil
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if ip := net.ParseIP(host); host == "localhost" || strings.HasSuffix(host, ".localhost") || ip != nil && !sources.IsPublicIP(ip) {
+		return fmt.Errorf("webhook URL must not point to a private address")
+	}
+	return nil
+}
+
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (q *Queue) notify(ctx context.Context, job Job) {
+	body, err := json.Marshal(webhookPayload{
+		ID:         job.ID,
+		Status:     job.Status,
+		Result:     job.Result,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to encode webhook payload", "job_id", job.ID, "error", err)
+		return
+	}
+
+	event := "job." + job.Status
+	signature := Sign(q.cfg.WebhookSecret, body)
+	backoff := q.cfg.WebhookBackoff
+
+	for attempt := 1; attempt <= q.cfg.WebhookRetries; attempt++ {
+		err := q.deliver(ctx, job.WebhookURL, body, event, signature, attempt)
+		if err == nil {
+			return
+		}
+		slog.WarnContext(ctx, "webhook delivery failed", "job_id", job.ID, "attempt", attempt, "error", err)
+
+		if attempt == q.cfg.WebhookRetries {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	slog.ErrorContext(ctx, "webhook delivery abandoned", "job_id", job.ID, "attempts", q.cfg.WebhookRetries)
+}
+
+func (q *Queue) deliver(ctx context.Context, target string, body []byte, event, signature string, attempt int) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(SignatureHeader, signature)
+	req.Header.Set(DeliveryHeader, strconv.Itoa(attempt))
+
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
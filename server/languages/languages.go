@@ -0,0 +1,308 @@
+package languages
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var (
+	ErrUnknownLanguage = errors.New("unknown language")
+	ErrInvalidData     = errors.New("invalid language data")
+)
+
+// language files are named after the code, "en.txt" or "pt-br.txt"; codes
+// are matched without regard to case
+var validCode = regexp.MustCompile(`^[a-z]{2,3}(?:-[a-z0-9]{2,8})*$`)
+
+type SyllableRules struct {
+	Vowels string `json:"vowels"`
+	// Silent endings drop a syllable, "make" has one, unless the word also
+	// ends with one of Keep, as "table" does
+	Silent []string `json:"silent,omitempty"`
+	Keep   []string `json:"keep,omitempty"`
+}
+
+type Language struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// Script is the Unicode script the language is written in, as named by
+	// the unicode package ("Latin", "Cyrillic")
+	Script string `json:"script,omitempty"`
+	// Letters are characters that point to this language among others in
+	// the same script, "ñ" for Spanish
+	Letters       string          `json:"letters,omitempty"`
+	StopWords     map[string]bool `json:"-"`
+	Abbreviations map[string]bool `json:"-"`
+	Syllables     *SyllableRules  `json:"syllables,omitempty"`
+}
+
+// Summary is what listing shows of a language instead of every word
+type Summary struct {
+	Code          string         `json:"code"`
+	Name          string         `json:"name"`
+	Script        string         `json:"script,omitempty"`
+	Letters       string         `json:"letters,omitempty"`
+	StopWords     int            `json:"stopWords"`
+	Abbreviations int            `json:"abbreviations"`
+	Syllables     *SyllableRules `json:"syllables,omitempty"`
+}
+
+func (l *Language) Summary() Summary {
+	return Summary{Code: l.Code, Name: l.Name, Script: l.Script, Letters: l.Letters, StopWords: len(l.StopWords), Abbreviations: len(l.Abbreviations), Syllables: l.Syllables}
+}
+
+func (l *Language) IsStopWord(word string) bool {
+	return l.StopWords[strings.ToLower(word)]
+}
+
+// CountSyllables approximates the syllables of a word from its vowel groups.
+// Languages without rules count every word as one.
+func (l *Language) CountSyllables(word string) int {
+	word = strings.ToLower(strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) }))
+	if word == "" {
+		return 0
+	}
+	rules := l.Syllables
+	if rules == nil || rules.Vowels == "" {
+		return 1
+	}
+	count, inVowel := 0, false
+	for _, r := range word {
+		vowel := strings.ContainsRune(rules.Vowels, r)
+		if vowel && !inVowel {
+			count++
+		}
+		inVowel = vowel
+	}
+	if count > 1 && hasAnySuffix(word, rules.Silent) && !hasAnySuffix(word, rules.Keep) {
+		count--
+	}
+	return max(count, 1)
+}
+
+func hasAnySuffix(word string, suffixes []string) bool {
+	for _, s := range suffixes {
+		if strings.HasSuffix(word, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry keeps the per-language resources that analysis features share:
+// stop words, the abbreviations sentence splitting must not break after, and
+// syllable rules. The built-in languages are embedded; more can be loaded
+// from files at startup, and a later file for the same language replaces
+// the sections it has.
+type Registry struct {
+	mu        sync.RWMutex
+	languages map[string]*Language
+}
+
+func NewRegistry() *Registry {
+	return &Registry{languages: make(map[string]*Language)}
+}
+
+//go:embed data/*.txt
+var builtin embed.FS
+
+// Default holds the built-in languages and whatever is loaded at startup
+var Default = func() *Registry {
+	r := NewRegistry()
+	if err := r.LoadFS(builtin, "data"); err != nil {
+		panic(err)
+	}
+	return r
+}()
+
+func (r *Registry) Get(code string) (*Language, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	l, ok := r.languages[strings.ToLower(code)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownLanguage, code)
+	}
+	return l, nil
+}
+
+// Codes lists the languages that have a resource, such as
+// func(l *Language) bool { return len(l.StopWords) > 0 }, or all of them
+// for a nil filter
+func (r *Registry) Codes(has func(*Language) bool) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	codes := make([]string, 0, len(r.languages))
+	for code, l := range r.languages {
+		if has == nil || has(l) {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func (r *Registry) List() []Summary {
+	codes := r.Codes(nil)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	summaries := make([]Summary, len(codes))
+	for i, code := range codes {
+		summaries[i] = r.languages[code].Summary()
+	}
+	return summaries
+}
+
+// Load reads one language file. Languages are never changed in place, so a
+// feature holding one keeps a consistent view while another file loads.
+func (r *Registry) Load(code string, rd io.Reader) error {
+	code = strings.ToLower(code)
+	if !validCode.MatchString(code) {
+		return fmt.Errorf("%w: %q is not a language code", ErrInvalidData, code)
+	}
+	parsed, err := parse(rd)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidData, code, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l := &Language{Code: code, Name: code}
+	if existing, ok := r.languages[code]; ok {
+		*l = *existing
+	}
+	if parsed.Name != "" {
+		l.Name = parsed.Name
+	}
+	if parsed.Script != "" {
+		l.Script = parsed.Script
+	}
+	if parsed.Letters != "" {
+		l.Letters = parsed.Letters
+	}
+	if parsed.StopWords != nil {
+		l.StopWords = parsed.StopWords
+	}
+	if parsed.Abbreviations != nil {
+		l.Abbreviations = parsed.Abbreviations
+	}
+	if parsed.Syllables != nil {
+		l.Syllables = parsed.Syllables
+	}
+	r.languages[code] = l
+	return nil
+}
+
+func (r *Registry) LoadFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.Load(strings.TrimSuffix(filepath.Base(file), ".txt"), f)
+}
+
+// LoadDir loads every .txt file in dir, for deployments that ship their own
+// languages
+func (r *Registry) LoadDir(dir string) error {
+	return r.LoadFS(os.DirFS(dir), ".")
+}
+
+func (r *Registry) LoadFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.txt"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		f, err := fsys.Open(file)
+		if err != nil {
+			return err
+		}
+		err = r.Load(strings.TrimSuffix(path.Base(file), ".txt"), f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parse reads the sectioned format of the data files: "name:" and
+// "script:" first, then [stopwords] and [abbreviations] as
+// whitespace-separated words, [letters] as characters and [syllables] as
+// "vowels:", "silent:" and "keep:" lines. Lines starting with # are
+// comments.
+func parse(rd io.Reader) (*Language, error) {
+	l := &Language{}
+	section := ""
+	scanner := bufio.NewScanner(rd)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			switch section {
+			case "stopwords":
+				l.StopWords = map[string]bool{}
+			case "abbreviations":
+				l.Abbreviations = map[string]bool{}
+			case "syllables":
+				l.Syllables = &SyllableRules{}
+			case "letters":
+			default:
+				return nil, fmt.Errorf("line %d: unknown section %q", n, section)
+			}
+			continue
+		}
+
+		switch section {
+		case "letters":
+			l.Letters += strings.Join(strings.Fields(strings.ToLower(line)), "")
+		case "stopwords", "abbreviations":
+			set := l.StopWords
+			if section == "abbreviations" {
+				set = l.Abbreviations
+			}
+			for _, w := range strings.Fields(line) {
+				set[strings.ToLower(w)] = true
+			}
+		case "", "syllables":
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected \"key: value\"", n)
+			}
+			key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+			switch {
+			case section == "" && key == "name":
+				l.Name = value
+			case section == "" && key == "script":
+				if _, ok := unicode.Scripts[value]; !ok {
+					return nil, fmt.Errorf("line %d: unknown script %q", n, value)
+				}
+				l.Script = value
+			case section == "syllables" && key == "vowels":
+				l.Syllables.Vowels = strings.ToLower(value)
+			case section == "syllables" && key == "silent":
+				l.Syllables.Silent = strings.Fields(strings.ToLower(value))
+			case section == "syllables" && key == "keep":
+				l.Syllables.Keep = strings.Fields(strings.ToLower(value))
+			default:
+				return nil, fmt.Errorf("line %d: unknown key %q", n, key)
+			}
+		}
+	}
+	return l, scanner.Err()
+}
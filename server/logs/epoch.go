@@ -0,0 +1,112 @@
+package logs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"toolkit-backend/utils"
+)
+
+type EpochUnit string
+
+const (
+	EpochAuto    EpochUnit = "auto"
+	EpochSeconds EpochUnit = "seconds"
+	EpochMillis  EpochUnit = "millis"
+)
+
+var EpochUnits = []EpochUnit{EpochAuto, EpochSeconds, EpochMillis}
+
+func (EpochUnit) Values() []string {
+	return utils.EnumValues(EpochUnits)
+}
+
+type EpochOptions struct {
+	Zone   string    `json:"zone"`
+	Format string    `json:"format"`
+	Unit   EpochUnit `json:"unit"`
+}
+
+// 10 digits covers 2001-09-09 to 2286, which keeps ids and counters from matching
+var (
+	epochPattern       = regexp.MustCompile(`\b\d{10}(?:\d{3})?\b`)
+	epochSecondPattern = regexp.MustCompile(`\b\d{10}\b`)
+	epochMilliPattern  = regexp.MustCompile(`\b\d{13}\b`)
+)
+
+const millisLayout = "2006-01-02T15:04:05.000Z07:00"
+
+func (o EpochOptions) pattern() (*regexp.Regexp, error) {
+	switch o.Unit {
+	case "", EpochAuto:
+		return epochPattern, nil
+	case EpochSeconds:
+		return epochSecondPattern, nil
+	case EpochMillis:
+		return epochMilliPattern, nil
+	}
+	return nil, fmt.Errorf("unknown epoch unit %q", o.Unit)
+}
+
+func EpochsToTimestamps(text string, opts EpochOptions) (string, int, error) {
+	pattern, err := opts.pattern()
+	if err != nil {
+		return "", 0, err
+	}
+	loc, err := loadLocation(opts.Zone)
+	if err != nil {
+		return "", 0, err
+	}
+
+	count := 0
+	out := pattern.ReplaceAllStringFunc(text, func(m string) string {
+		n, err := strconv.ParseInt(m, 10, 64)
+		if err != nil {
+			return m
+		}
+		count++
+		if len(m) == 13 {
+			t := time.UnixMilli(n).In(loc)
+			if opts.Format == "" {
+				return t.Format(millisLayout)
+			}
+			return FormatTimestamp(t, opts.Format)
+		}
+		format := opts.Format
+		if format == "" {
+			format = "RFC3339"
+		}
+		return FormatTimestamp(time.Unix(n, 0).In(loc), format)
+	})
+	return out, count, nil
+}
+
+func TimestampsToEpoch(text string, opts EpochOptions) (string, int, error) {
+	if _, err := opts.pattern(); err != nil {
+		return "", 0, err
+	}
+	loc, err := loadLocation(opts.Zone)
+	if err != nil {
+		return "", 0, err
+	}
+	format := "unix"
+	if opts.Unit == EpochMillis {
+		format = "unixMilli"
+	}
+
+	out := make([]byte, 0, len(text))
+	count := 0
+	for {
+		ts, ok := FindTimestamp(text, loc)
+		if !ok {
+			break
+		}
+		out = append(out, text[:ts.Start]...)
+		out = append(out, FormatTimestamp(ts.Time, format)...)
+		text = text[ts.End:]
+		count++
+	}
+	return string(append(out, text...)), count, nil
+}
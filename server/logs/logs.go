@@ -0,0 +1,131 @@
+package logs
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+type Options struct {
+	InputZone  string `json:"inputZone"`
+	OutputZone string `json:"outputZone"`
+	Format     string `json:"format"`
+}
+
+type entry struct {
+	lines []string
+	ts    *Timestamp
+}
+
+func NormalizeTimestamps(text string, opts Options) (string, int, error) {
+	assume, err := loadLocation(opts.InputZone)
+	if err != nil {
+		return "", 0, err
+	}
+	target, err := loadLocation(opts.OutputZone)
+	if err != nil {
+		return "", 0, err
+	}
+	format := opts.Format
+	if format == "" {
+		format = "RFC3339"
+	}
+
+	lines := strings.Split(text, "\n")
+	count := 0
+	for i, line := range lines {
+		ts, ok := FindTimestamp(line, assume)
+		if !ok {
+			continue
+		}
+		lines[i] = line[:ts.Start] + FormatTimestamp(ts.Time.In(target), format) + line[ts.End:]
+		count++
+	}
+	return strings.Join(lines, "\n"), count, nil
+}
+
+func Parse(text string, opts Options) ([]Record, error) {
+	loc, err := loadLocation(opts.InputZone)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for i, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		format, fields := ParseLine(line)
+		record := Record{Line: i + 1, Format: format, Fields: fields, Raw: line}
+		if ts, ok := FindTimestamp(line, loc); ok {
+			record.Timestamp = &ts
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func entries(text string, loc *time.Location) []entry {
+	var out []entry
+	for _, line := range strings.Split(text, "\n") {
+		ts, ok := FindTimestamp(line, loc)
+		if ok || len(out) == 0 {
+			e := entry{lines: []string{line}}
+			if ok {
+				e.ts = &ts
+			}
+			out = append(out, e)
+			continue
+		}
+		last := &out[len(out)-1]
+		last.lines = append(last.lines, line)
+	}
+	return out
+}
+
+func join(es []entry) string {
+	var lines []string
+	for _, e := range es {
+		lines = append(lines, e.lines...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func FilterByTime(text string, from, to time.Time, opts Options) (string, error) {
+	loc, err := loadLocation(opts.InputZone)
+	if err != nil {
+		return "", err
+	}
+
+	var kept []entry
+	for _, e := range entries(text, loc) {
+		if e.ts == nil {
+			continue
+		}
+		if !from.IsZero() && e.ts.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !e.ts.Time.Before(to) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return join(kept), nil
+}
+
+func SortByTime(text string, opts Options) (string, error) {
+	loc, err := loadLocation(opts.InputZone)
+	if err != nil {
+		return "", err
+	}
+
+	es := entries(text, loc)
+	sort.SliceStable(es, func(i, j int) bool {
+		a, b := es[i].ts, es[j].ts
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return a.Time.Before(b.Time)
+	})
+	return join(es), nil
+}
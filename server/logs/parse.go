@@ -0,0 +1,112 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+	FormatText   = "text"
+)
+
+type Record struct {
+	Line      int                    `json:"line"`
+	Format    string                 `json:"format"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Timestamp *Timestamp             `json:"timestamp,omitempty"`
+	Raw       string                 `json:"raw"`
+}
+
+func ParseLine(line string) (string, map[string]interface{}) {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "{") {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(trimmed), &fields); err == nil {
+			return FormatJSON, fields
+		}
+	}
+	if fields, err := ParseLogfmt(trimmed); err == nil && len(fields) > 0 {
+		return FormatLogfmt, fields
+	}
+	return FormatText, nil
+}
+
+func ParseLogfmt(line string) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	pairs := 0
+	i := 0
+	for i < len(line) {
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+		if i >= len(line) {
+			break
+		}
+
+		start := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' && line[i] != '"' {
+			i++
+		}
+		key := line[start:i]
+		if key == "" {
+			return nil, fmt.Errorf("expected key at column %d", start+1)
+		}
+		if i >= len(line) || line[i] == ' ' {
+			fields[key] = true
+			continue
+		}
+		if line[i] != '=' {
+			return nil, fmt.Errorf("unexpected quote at column %d", i+1)
+		}
+		i++
+		pairs++
+
+		if i < len(line) && line[i] == '"' {
+			var b strings.Builder
+			i++
+			closed := false
+			for i < len(line) {
+				c := line[i]
+				if c == '\\' && i+1 < len(line) {
+					next := line[i+1]
+					switch next {
+					case 'n':
+						b.WriteByte('\n')
+					case 't':
+						b.WriteByte('\t')
+					default:
+						b.WriteByte(next)
+					}
+					i += 2
+					continue
+				}
+				if c == '"' {
+					closed = true
+					i++
+					break
+				}
+				b.WriteByte(c)
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated quoted value for %q", key)
+			}
+			fields[key] = b.String()
+			continue
+		}
+
+		start = i
+		for i < len(line) && line[i] != ' ' {
+			i++
+		}
+		fields[key] = line[start:i]
+	}
+
+	if pairs == 0 {
+		return nil, fmt.Errorf("no key=value pairs")
+	}
+	return fields, nil
+}
@@ -0,0 +1,115 @@
+package logs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type timestampFormat struct {
+	name    string
+	pattern *regexp.Regexp
+	layouts []string
+	noYear  bool
+}
+
+var timestampFormats = []timestampFormat{
+	{
+		name:    "iso8601",
+		pattern: regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d{1,9})?(?:Z|[+-]\d{2}:?\d{2})?`),
+		layouts: []string{"2006-01-02T15:04:05.999999999Z07:00", "2006-01-02T15:04:05.999999999Z0700", "2006-01-02T15:04:05.999999999"},
+	},
+	{
+		name:    "clf",
+		pattern: regexp.MustCompile(`\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}`),
+		layouts: []string{"02/Jan/2006:15:04:05 -0700"},
+	},
+	{
+		name:    "rfc1123",
+		pattern: regexp.MustCompile(`[A-Z][a-z]{2}, \d{2} [A-Z][a-z]{2} \d{4} \d{2}:\d{2}:\d{2} (?:[A-Z]{3,4}|[+-]\d{4})`),
+		layouts: []string{time.RFC1123Z, time.RFC1123},
+	},
+	{
+		name:    "syslog",
+		pattern: regexp.MustCompile(`\b[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}(?:\.\d{1,9})?\b`),
+		layouts: []string{time.Stamp},
+		noYear:  true,
+	},
+}
+
+var namedLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"clf":         "02/Jan/2006:15:04:05 -0700",
+	"syslog":      time.Stamp,
+	"datetime":    time.DateTime,
+}
+
+type Timestamp struct {
+	Time   time.Time `json:"time"`
+	Format string    `json:"format"`
+	Start  int       `json:"start"`
+	End    int       `json:"end"`
+}
+
+func FindTimestamp(line string, loc *time.Location) (Timestamp, bool) {
+	best := Timestamp{Start: -1}
+	for _, f := range timestampFormats {
+		m := f.pattern.FindStringIndex(line)
+		if m == nil || (best.Start >= 0 && m[0] >= best.Start) {
+			continue
+		}
+		t, ok := f.parse(line[m[0]:m[1]], loc)
+		if !ok {
+			continue
+		}
+		best = Timestamp{Time: t, Format: f.name, Start: m[0], End: m[1]}
+	}
+	return best, best.Start >= 0
+}
+
+func (f timestampFormat) parse(s string, loc *time.Location) (time.Time, bool) {
+	if f.name == "iso8601" {
+		s = strings.Replace(s, " ", "T", 1)
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	for _, layout := range f.layouts {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err != nil {
+			continue
+		}
+		if f.noYear {
+			t = t.AddDate(time.Now().In(loc).Year(), 0, 0)
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+func FormatTimestamp(t time.Time, format string) string {
+	switch format {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixMilli":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+	if layout, ok := namedLayouts[format]; ok {
+		return t.Format(layout)
+	}
+	return t.Format(format)
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return loc, nil
+}
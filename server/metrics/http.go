@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Middleware(r *Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		op := strings.TrimPrefix(c.FullPath(), "/")
+		if op == "" {
+			op = "unmatched"
+		}
+		r.Observe(op, c.Writer.Status(), time.Since(start).Seconds(), c.Request.ContentLength)
+	}
+}
+
+func Handler(r *Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteTo(c.Writer)
+	}
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const QueueDepthGauge = "textforge_job_queue_depth"
+
+var (
+	LatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	SizeBuckets    = []float64{64, 256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20}
+)
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+type operationStats struct {
+	requests map[string]uint64
+	errors   uint64
+	latency  *histogram
+	size     *histogram
+}
+
+type Registry struct {
+	mu         sync.Mutex
+	operations map[string]*operationStats
+	gauges     map[string]func() float64
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		operations: make(map[string]*operationStats),
+		gauges:     make(map[string]func() float64),
+	}
+}
+
+var Default = NewRegistry()
+
+func (r *Registry) Observe(operation string, status int, seconds float64, inputBytes int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, ok := r.operations[operation]
+	if !ok {
+		stats = &operationStats{
+			requests: make(map[string]uint64),
+			latency:  newHistogram(LatencyBuckets),
+			size:     newHistogram(SizeBuckets),
+		}
+		r.operations[operation] = stats
+	}
+
+	stats.requests[fmt.Sprint(status)]++
+	if status >= 400 {
+		stats.errors++
+	}
+	stats.latency.observe(seconds)
+	if inputBytes >= 0 {
+		stats.size.observe(float64(inputBytes))
+	}
+}
+
+func (r *Registry) RegisterGauge(name string, fn func() float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name] = fn
+}
+
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	ops := make([]string, 0, len(r.operations))
+	for op := range r.operations {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	b.WriteString("# HELP textforge_requests_total Requests handled per operation and status.\n")
+	b.WriteString("# TYPE textforge_requests_total counter\n")
+	for _, op := range ops {
+		statuses := make([]string, 0, len(r.operations[op].requests))
+		for s := range r.operations[op].requests {
+			statuses = append(statuses, s)
+		}
+		sort.Strings(statuses)
+		for _, s := range statuses {
+			fmt.Fprintf(&b, "textforge_requests_total{operation=%q,status=%q} %d\n", op, s, r.operations[op].requests[s])
+		}
+	}
+
+	b.WriteString("# HELP textforge_errors_total Requests per operation that ended with a 4xx or 5xx status.\n")
+	b.WriteString("# TYPE textforge_errors_total counter\n")
+	for _, op := range ops {
+		fmt.Fprintf(&b, "textforge_errors_total{operation=%q} %d\n", op, r.operations[op].errors)
+	}
+
+	b.WriteString("# HELP textforge_request_duration_seconds Request latency per operation.\n")
+	b.WriteString("# TYPE textforge_request_duration_seconds histogram\n")
+	for _, op := range ops {
+		writeHistogram(&b, "textforge_request_duration_seconds", op, r.operations[op].latency)
+	}
+
+	b.WriteString("# HELP textforge_input_bytes Request body size per operation.\n")
+	b.WriteString("# TYPE textforge_input_bytes histogram\n")
+	for _, op := range ops {
+		writeHistogram(&b, "textforge_input_bytes", op, r.operations[op].size)
+	}
+
+	names := make([]string, 0, len(r.gauges))
+	for name := range r.gauges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "# TYPE %s gauge\n%s %g\n", name, name, r.gauges[name]())
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func writeHistogram(b *strings.Builder, name, op string, h *histogram) {
+	for i, bound := range h.buckets {
+		fmt.Fprintf(b, "%s_bucket{operation=%q,le=\"%g\"} %d\n", name, op, bound, h.counts[i])
+	}
+	fmt.Fprintf(b, "%s_bucket{operation=%q,le=\"+Inf\"} %d\n", name, op, h.count)
+	fmt.Fprintf(b, "%s_sum{operation=%q} %g\n", name, op, h.sum)
+	fmt.Fprintf(b, "%s_count{operation=%q} %d\n", name, op, h.count)
+}
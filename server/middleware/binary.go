@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+// RejectBinary turns binary uploads away with 415 before a text handler can
+// turn them into garbage. JSON bodies are text by construction, so for those
+// only invalid UTF-8 (which the decoder would silently replace) and escaped
+// null bytes count. Multipart uploads are left to their handlers, which
+// check each file.
+func RejectBinary() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		contentType := c.ContentType()
+		if c.Request.Body == nil || c.Request.Method == http.MethodGet || strings.HasPrefix(contentType, "multipart/") {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if len(body) == 0 {
+			c.Next()
+			return
+		}
+
+		if strings.HasSuffix(contentType, "json") {
+			switch {
+			case !utf8.Valid(body):
+				c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": utils.ErrBinaryInput.Error() + ": request body is not valid UTF-8"})
+				return
+			case bytes.Contains(body, []byte(`\u0000`)):
+				c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": utils.ErrBinaryInput.Error() + ": request body contains null characters"})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if report := utils.DetectBinary(body); report.Binary {
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": report.Err().Error(), "details": report})
+			return
+		}
+		c.Next()
+	}
+}
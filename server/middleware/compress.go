@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CompressionConfig struct {
+	MinSize         int   `json:"minSize"`
+	Level           int   `json:"level"`
+	MaxDecompressed int64 `json:"maxDecompressed"`
+}
+
+var errBodyTooLarge = errors.New("decompressed request body too large")
+
+type limitedReadCloser struct {
+	r      io.Reader
+	closer io.Closer
+	left   int64
+}
+
+func (l *limitedReadCloser) Read(p []byte) (int, error) {
+	if l.left <= 0 {
+		// a body of exactly the limit is fine; only more data is not
+		var probe [1]byte
+		n, err := l.r.Read(probe[:])
+		if n > 0 {
+			return 0, errBodyTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.left {
+		p = p[:l.left]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	return n, err
+}
+
+func (l *limitedReadCloser) Close() error {
+	return l.closer.Close()
+}
+
+type compressWriter struct {
+	gin.ResponseWriter
+	cfg     CompressionConfig
+	pool    *sync.Pool
+	buf     bytes.Buffer
+	gz      *gzip.Writer
+	status  int
+	decided bool
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *compressWriter) WriteHeaderNow() {}
+
+func (w *compressWriter) Status() int {
+	if w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	w.buf.Write(b)
+	if w.buf.Len() >= w.cfg.MinSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) decide(large bool) error {
+	w.decided = true
+	h := w.ResponseWriter.Header()
+
+	compress := large && h.Get("Content-Encoding") == "" && compressible(h.Get("Content-Type"))
+	if compress {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		w.writeStatus()
+		w.gz = w.pool.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+		_, err := w.gz.Write(w.buf.Bytes())
+		w.buf.Reset()
+		return err
+	}
+
+	if w.buf.Len() > 0 {
+		h.Set("Content-Length", strconv.Itoa(w.buf.Len()))
+	}
+	w.writeStatus()
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+func (w *compressWriter) writeStatus() {
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *compressWriter) finish() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		w.pool.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+func compressible(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	for _, prefix := range []string{"text/", "application/json", "application/xml", "application/javascript", "image/svg+xml"} {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		if strings.TrimSpace(fields[0]) != "gzip" {
+			continue
+		}
+		for _, f := range fields[1:] {
+			if q := strings.TrimSpace(f); q == "q=0" || q == "q=0.0" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func Compression(cfg CompressionConfig) gin.HandlerFunc {
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = 1024
+	}
+	if cfg.Level == 0 {
+		cfg.Level = gzip.DefaultCompression
+	}
+	// checked here, or every pooled writer would be nil
+	if _, err := gzip.NewWriterLevel(io.Discard, cfg.Level); err != nil {
+		panic(fmt.Sprintf("middleware: invalid compression level %d", cfg.Level))
+	}
+	if cfg.MaxDecompressed <= 0 {
+		cfg.MaxDecompressed = 64 << 20
+	}
+
+	pool := &sync.Pool{New: func() interface{} {
+		gz, _ := gzip.NewWriterLevel(io.Discard, cfg.Level)
+		return gz
+	}}
+
+	return func(c *gin.Context) {
+		// every response depends on Accept-Encoding, compressed or not
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		switch strings.ToLower(c.GetHeader("Content-Encoding")) {
+		case "":
+		case "gzip":
+			gz, err := gzip.NewReader(c.Request.Body)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid gzip request body"})
+				return
+			}
+			c.Request.Body = &limitedReadCloser{r: gz, closer: c.Request.Body, left: cfg.MaxDecompressed}
+			c.Request.Header.Del("Content-Encoding")
+			c.Request.ContentLength = -1
+		case "deflate":
+			// HTTP deflate is the zlib format, not a raw deflate stream
+			zr, err := zlib.NewReader(c.Request.Body)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid deflate request body"})
+				return
+			}
+			c.Request.Body = &limitedReadCloser{r: zr, closer: c.Request.Body, left: cfg.MaxDecompressed}
+			c.Request.Header.Del("Content-Encoding")
+			c.Request.ContentLength = -1
+		default:
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": "unsupported content encoding"})
+			return
+		}
+
+		if !acceptsGzip(c.GetHeader("Accept-Encoding")) || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, cfg: cfg, pool: pool}
+		c.Writer = w
+		defer w.finish()
+		c.Next()
+	}
+}
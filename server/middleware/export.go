@@ -0,0 +1,286 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type ExportFormat string
+
+const (
+	ExportJSON ExportFormat = "json"
+	ExportText ExportFormat = "text"
+	ExportHTML ExportFormat = "html"
+)
+
+var ExportFormats = []ExportFormat{ExportJSON, ExportText, ExportHTML}
+
+func (ExportFormat) Values() []string {
+	return utils.EnumValues(ExportFormats)
+}
+
+func (f ExportFormat) Valid() bool {
+	return utils.EnumValid(ExportFormats, f)
+}
+
+var exportTypes = map[ExportFormat]struct{ contentType, extension string }{
+	ExportJSON: {"application/json; charset=utf-8", ".json"},
+	ExportText: {"text/plain; charset=utf-8", ".txt"},
+	ExportHTML: {"text/html; charset=utf-8", ".html"},
+}
+
+var unsafeFilename = regexp.MustCompile(`[^\p{L}\p{N}._ -]+`)
+
+type exportWriter struct {
+	gin.ResponseWriter
+	buf    bytes.Buffer
+	status int
+}
+
+func (w *exportWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *exportWriter) WriteHeaderNow() {}
+
+func (w *exportWriter) Status() int {
+	if w.status != 0 {
+		return w.status
+	}
+	return http.StatusOK
+}
+
+func (w *exportWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+func (w *exportWriter) WriteString(s string) (int, error) {
+	return w.buf.WriteString(s)
+}
+
+// Export lets every JSON endpoint answer in another shape: ?output=text
+// returns the result as plain text, ?output=html as a page with regex
+// matches highlighted, and ?download=name sends either, or the JSON, as an
+// attachment. Errors and non-JSON responses go out untouched. It buffers
+// the whole response, so it belongs inside Compression.
+func Export() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := ExportFormat(c.Query("output"))
+		filename, download := c.GetQuery("download")
+		if format == "" && !download {
+			c.Next()
+			return
+		}
+		if format == "" {
+			format = ExportJSON
+		}
+		if !format.Valid() {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("output must be one of %s", strings.Join(format.Values(), ", "))})
+			return
+		}
+
+		// highlighting needs the text the offsets point into
+		var request []byte
+		if format == ExportHTML && c.Request.Body != nil {
+			var err error
+			if request, err = io.ReadAll(c.Request.Body); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(request))
+		}
+
+		original := c.Writer
+		w := &exportWriter{ResponseWriter: original}
+		c.Writer = w
+		c.Next()
+		c.Writer = original
+
+		body := w.buf.Bytes()
+		if w.Status() != http.StatusOK || !strings.HasPrefix(original.Header().Get("Content-Type"), "application/json") {
+			original.WriteHeader(w.Status())
+			original.Write(body)
+			return
+		}
+
+		var out []byte
+		switch format {
+		case ExportText:
+			out = []byte(exportText(body))
+		case ExportHTML:
+			out = []byte(exportHTML(c.Request.URL.Path, body, request))
+		default:
+			out = body
+		}
+
+		h := original.Header()
+		h.Set("Content-Type", exportTypes[format].contentType)
+		if download {
+			h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": exportFilename(filename, c.Request.URL.Path, format)}))
+		}
+		h.Del("Content-Length")
+		original.WriteHeader(http.StatusOK)
+		original.Write(out)
+	}
+}
+
+func exportFilename(name, route string, format ExportFormat) string {
+	name = strings.TrimSpace(unsafeFilename.ReplaceAllString(path.Base(name), "_"))
+	if name == "" || name == "." || name == "true" || name == "1" {
+		name = path.Base(route)
+	}
+	if name == "" || name == "/" || name == "." {
+		name = "result"
+	}
+	if path.Ext(name) == "" {
+		name += exportTypes[format].extension
+	}
+	return name
+}
+
+func decodeJSON(body []byte) (interface{}, bool) {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	return v, d.Decode(&v) == nil
+}
+
+// exportText writes a "result" on its own, a string as is and a list one
+// item per line; anything else becomes indented "key: value" lines
+func exportText(body []byte) string {
+	v, ok := decodeJSON(body)
+	if !ok {
+		return string(body)
+	}
+	if m, isMap := v.(map[string]interface{}); isMap {
+		if result, has := m["result"]; has {
+			v = result
+		}
+	}
+	var b strings.Builder
+	writePlain(&b, v, "")
+	text := strings.TrimRight(b.String(), "\n")
+	if text != "" {
+		text += "\n"
+	}
+	return text
+}
+
+func writePlain(b *strings.Builder, v interface{}, indent string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if s, ok := plainScalar(v[k]); ok && !strings.Contains(s, "\n") {
+				b.WriteString(indent + k + ": " + s + "\n")
+				continue
+			}
+			b.WriteString(indent + k + ":\n")
+			writePlain(b, v[k], indent+"  ")
+		}
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := plainScalar(item); ok && !strings.Contains(s, "\n") {
+				b.WriteString(indent + s + "\n")
+				continue
+			}
+			b.WriteString(indent + "-\n")
+			writePlain(b, item, indent+"  ")
+		}
+	default:
+		s, _ := plainScalar(v)
+		for _, line := range strings.Split(s, "\n") {
+			b.WriteString(indent + line + "\n")
+		}
+	}
+}
+
+func plainScalar(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return fmt.Sprint(v), true
+	case nil:
+		return "", true
+	}
+	return "", false
+}
+
+type span struct{ start, end int }
+
+// exportHTML renders the text version as a page; when the response lists
+// matches by byte offset into the request's text, as the regex tester does,
+// the text is shown with them marked instead
+func exportHTML(route string, body, request []byte) string {
+	content := html.EscapeString(exportText(body))
+	if text, spans := highlightSpans(body, request); spans != nil {
+		var b strings.Builder
+		last := 0
+		for _, s := range spans {
+			b.WriteString(html.EscapeString(text[last:s.start]))
+			b.WriteString("<mark>" + html.EscapeString(text[s.start:s.end]) + "</mark>")
+			last = s.end
+		}
+		b.WriteString(html.EscapeString(text[last:]))
+		content = b.String()
+	}
+	return "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>" + html.EscapeString(route) + "</title>\n" +
+		"<style>body{font-family:system-ui,sans-serif;margin:2rem}pre{white-space:pre-wrap;word-wrap:break-word}mark{background:#ffe58a}</style>\n" +
+		"</head>\n<body>\n<pre>" + content + "</pre>\n</body>\n</html>\n"
+}
+
+func highlightSpans(body, request []byte) (string, []span) {
+	var req struct {
+		Text string `json:"text"`
+	}
+	var res struct {
+		Matches []struct {
+			Start *int `json:"start"`
+			End   *int `json:"end"`
+		} `json:"matches"`
+	}
+	if json.Unmarshal(request, &req) != nil || req.Text == "" || json.Unmarshal(body, &res) != nil || len(res.Matches) == 0 {
+		return "", nil
+	}
+	spans := make([]span, 0, len(res.Matches))
+	for _, m := range res.Matches {
+		if m.Start == nil || m.End == nil || *m.Start < 0 || *m.End > len(req.Text) || *m.Start >= *m.End {
+			continue
+		}
+		spans = append(spans, span{*m.Start, *m.End})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	// overlapping matches merge into one mark
+	merged := spans[:0]
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start <= merged[n-1].end {
+			merged[n-1].end = max(merged[n-1].end, s.end)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	if len(merged) == 0 {
+		return "", nil
+	}
+	return req.Text, merged
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+// limitWriter holds the response back until the handler is done, so that an
+// oversized or late result can still be replaced by a limit error
+type limitWriter struct {
+	gin.ResponseWriter
+	buf    bytes.Buffer
+	status int
+}
+
+func (w *limitWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *limitWriter) WriteHeaderNow() {}
+
+func (w *limitWriter) Status() int {
+	if w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *limitWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+func (w *limitWriter) WriteString(s string) (int, error) {
+	return w.buf.WriteString(s)
+}
+
+func (w *limitWriter) Size() int {
+	return w.buf.Len()
+}
+
+func (w *limitWriter) Written() bool {
+	return w.status != 0 || w.buf.Len() > 0
+}
+
+func abortWithLimit(c *gin.Context, lerr *utils.LimitError) {
+	c.AbortWithStatusJSON(lerr.Status(), gin.H{"error": lerr.Error(), "details": lerr})
+}
+
+// EnforceLimits applies the global limits, overridden per request by whatever
+// perRequest returns (typically the caller's API key limits). It must run
+// after request decompression so it sees the real body size.
+func EnforceLimits(global utils.Limits, perRequest func(*gin.Context) (utils.Limits, bool)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limits := global
+		if perRequest != nil {
+			if l, ok := perRequest(c); ok {
+				limits = limits.Override(l)
+			}
+		}
+
+		var lerr *utils.LimitError
+		if err := limits.CheckInput(c.Request.ContentLength); errors.As(err, &lerr) {
+			abortWithLimit(c, lerr)
+			return
+		}
+		inputBytes := max(c.Request.ContentLength, 0)
+		if limits.MaxInputBytes > 0 && c.Request.Body != nil {
+			// the length may be unknown (chunked or decompressed), so read the body once here
+			body, err := io.ReadAll(io.LimitReader(c.Request.Body, limits.MaxInputBytes+1))
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err := limits.CheckInput(int64(len(body))); errors.As(err, &lerr) {
+				abortWithLimit(c, lerr)
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			inputBytes = int64(len(body))
+		}
+
+		started := time.Now()
+		ctx := utils.WithLimits(c.Request.Context(), limits)
+		if limits.TimeBudget > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, limits.TimeBudget)
+			defer cancel()
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		if limits.MaxExpansion <= 0 && limits.TimeBudget <= 0 {
+			c.Next()
+			return
+		}
+
+		w := &limitWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			abortWithLimit(c, &utils.LimitError{Limit: utils.LimitTimeBudget, Max: limits.TimeBudget.Seconds(), Actual: time.Since(started).Round(time.Millisecond).Seconds()})
+			return
+		}
+		if err := limits.CheckExpansion(inputBytes, int64(w.buf.Len())); errors.As(err, &lerr) {
+			abortWithLimit(c, lerr)
+			return
+		}
+		if w.status != 0 {
+			c.Writer.WriteHeader(w.status)
+		}
+		if w.buf.Len() > 0 {
+			c.Writer.Header().Set("Content-Length", strconv.Itoa(w.buf.Len()))
+			c.Writer.Write(w.buf.Bytes())
+		} else {
+			c.Writer.WriteHeaderNow()
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+func TestEnforceLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, "%s", body)
+	}
+	expand := func(c *gin.Context) {
+		c.String(http.StatusOK, strings.Repeat("x", 100<<10))
+	}
+	slow := func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.String(http.StatusOK, "late")
+	}
+	limitsOf := func(c *gin.Context) {
+		c.JSON(http.StatusOK, utils.LimitsFrom(c.Request.Context()))
+	}
+	keyLimits := func(c *gin.Context) (utils.Limits, bool) {
+		if c.GetHeader("X-Key") == "big" {
+			return utils.Limits{MaxInputBytes: 100}, true
+		}
+		return utils.Limits{}, false
+	}
+
+	tests := []struct {
+		name      string
+		global    utils.Limits
+		handler   gin.HandlerFunc
+		body      string
+		chunked   bool
+		key       string
+		wantCode  int
+		wantBody  string
+		wantLimit string
+	}{
+		{
+			name:     "passes small bodies through",
+			global:   utils.Limits{MaxInputBytes: 10},
+			handler:  echo,
+			body:     "hello",
+			wantCode: http.StatusCreated,
+			wantBody: "hello",
+		},
+		{
+			name:      "rejects declared length",
+			global:    utils.Limits{MaxInputBytes: 10},
+			handler:   echo,
+			body:      strings.Repeat("a", 11),
+			wantCode:  http.StatusRequestEntityTooLarge,
+			wantLimit: utils.LimitInputBytes,
+		},
+		{
+			name:      "rejects chunked bodies once read",
+			global:    utils.Limits{MaxInputBytes: 10},
+			handler:   echo,
+			body:      strings.Repeat("a", 11),
+			chunked:   true,
+			wantCode:  http.StatusRequestEntityTooLarge,
+			wantLimit: utils.LimitInputBytes,
+		},
+		{
+			name:     "per-request limits override global ones",
+			global:   utils.Limits{MaxInputBytes: 10},
+			handler:  echo,
+			body:     strings.Repeat("a", 50),
+			key:      "big",
+			wantCode: http.StatusCreated,
+			wantBody: strings.Repeat("a", 50),
+		},
+		{
+			name:      "rejects oversized expansion",
+			global:    utils.Limits{MaxExpansion: 10},
+			handler:   expand,
+			body:      "x",
+			wantCode:  http.StatusRequestEntityTooLarge,
+			wantLimit: utils.LimitExpansion,
+		},
+		{
+			name:     "allows small outputs to grow",
+			global:   utils.Limits{MaxExpansion: 1},
+			handler:  func(c *gin.Context) { c.String(http.StatusOK, strings.Repeat("x", 1000)) },
+			body:     "x",
+			wantCode: http.StatusOK,
+			wantBody: strings.Repeat("x", 1000),
+		},
+		{
+			name:      "replaces late responses",
+			global:    utils.Limits{TimeBudget: 20 * time.Millisecond},
+			handler:   slow,
+			wantCode:  http.StatusServiceUnavailable,
+			wantLimit: utils.LimitTimeBudget,
+		},
+		{
+			name:     "exposes the limits to handlers",
+			global:   utils.Limits{MaxInputBytes: 10, MaxRegexComplexity: 5},
+			handler:  limitsOf,
+			key:      "big",
+			wantCode: http.StatusOK,
+			wantBody: `{"maxInputBytes":100,"maxRegexComplexity":5,"maxExpansion":0,"timeBudget":0}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.POST("/", EnforceLimits(tt.global, keyLimits), tt.handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			if tt.key != "" {
+				req.Header.Set("X-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantLimit != "" {
+				var resp struct {
+					Details utils.LimitError `json:"details"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				if resp.Details.Limit != tt.wantLimit {
+					t.Errorf("limit = %q, want %q", resp.Details.Limit, tt.wantLimit)
+				}
+				return
+			}
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("body = %.60q, want %.60q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
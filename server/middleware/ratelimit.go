@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+type RateLimitConfig struct {
+	Rate    float64       `json:"rate"`
+	Burst   int           `json:"burst"`
+	IdleTTL time.Duration `json:"idleTtl"`
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type RateLimiter struct {
+	mu      sync.Mutex
+	cfg     RateLimitConfig
+	buckets map[string]*bucket
+	sweep   time.Time
+}
+
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	if cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+	if cfg.IdleTTL <= 0 {
+		cfg.IdleTTL = 10 * time.Minute
+	}
+	return &RateLimiter{cfg: cfg, buckets: make(map[string]*bucket), sweep: time.Now()}
+}
+
+func (l *RateLimiter) Allow(key string) (bool, int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.sweep) > l.cfg.IdleTTL {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > l.cfg.IdleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.sweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.cfg.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(l.cfg.Burst), b.tokens+now.Sub(b.last).Seconds()*l.cfg.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, int(b.tokens), l.wait(float64(l.cfg.Burst) - b.tokens)
+	}
+	return false, 0, l.wait(1 - b.tokens)
+}
+
+func (l *RateLimiter) wait(tokens float64) time.Duration {
+	if l.cfg.Rate <= 0 {
+		return time.Hour
+	}
+	return time.Duration(tokens / l.cfg.Rate * float64(time.Second))
+}
+
+// ClientKey names the caller's bucket: the ID of an API key keyID
+// recognises, otherwise the client IP. The raw header is never used, since
+// a made-up key on every request would get a fresh bucket each time.
+func ClientKey(c *gin.Context, keyID func(*gin.Context) (string, bool)) string {
+	if keyID != nil {
+		if id, ok := keyID(c); ok {
+			return "key:" + id
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimit limits each caller as named by ClientKey; keyID is typically
+// the API key store's KeyID
+func RateLimit(l *RateLimiter, keyID func(*gin.Context) (string, bool)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, remaining, reset := l.Allow(ClientKey(c, keyID))
+
+		c.Header("RateLimit-Limit", strconv.Itoa(l.cfg.Burst))
+		c.Header("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil(reset.Seconds()))))
+
+		if !allowed {
+			retry := int(math.Ceil(reset.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retry))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":      "rate limit exceeded",
+				"retryAfter": retry,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
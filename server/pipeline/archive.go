@@ -0,0 +1,251 @@
+package pipeline
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"toolkit-backend/utils"
+)
+
+type ArchiveOptions struct {
+	Include      []string `json:"include"`
+	MaxFiles     int      `json:"maxFiles"`
+	MaxFileBytes int64    `json:"maxFileBytes"`
+	// MaxTotalBytes caps the decompressed size of the whole archive
+	MaxTotalBytes int64 `json:"maxTotalBytes"`
+}
+
+type ArchiveFileResult struct {
+	Name      string `json:"name"`
+	Processed bool   `json:"processed"`
+	Skipped   string `json:"skipped,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+func matcher(patterns []string) (func(string) bool, error) {
+	if len(patterns) == 0 {
+		return func(string) bool { return true }, nil
+	}
+	// a glob without a slash matches the base name in any directory
+	type glob struct {
+		re       *regexp.Regexp
+		baseName bool
+	}
+	var globs []glob
+	for _, p := range patterns {
+		re, err := globRegexp(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %v", p, err)
+		}
+		globs = append(globs, glob{re: re, baseName: !strings.Contains(p, "/")})
+	}
+	return func(name string) bool {
+		for _, g := range globs {
+			if g.re.MatchString(name) || (g.baseName && g.re.MatchString(path.Base(name))) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func (o ArchiveOptions) withDefaults() ArchiveOptions {
+	if o.MaxFiles <= 0 {
+		o.MaxFiles = 1000
+	}
+	if o.MaxFileBytes <= 0 {
+		o.MaxFileBytes = 10 << 20
+	}
+	if o.MaxTotalBytes <= 0 {
+		o.MaxTotalBytes = 100 << 20
+	}
+	return o
+}
+
+func (o ArchiveOptions) Validate(zr *zip.Reader) error {
+	o = o.withDefaults()
+	if len(zr.File) > o.MaxFiles {
+		return fmt.Errorf("archive contains %d files, limit is %d", len(zr.File), o.MaxFiles)
+	}
+	var total uint64
+	for _, f := range zr.File {
+		total += f.UncompressedSize64
+		if total > uint64(o.MaxTotalBytes) {
+			return errTotalSize(o.MaxTotalBytes)
+		}
+	}
+	_, err := matcher(o.Include)
+	return err
+}
+
+func errTotalSize(max int64) error {
+	return fmt.Errorf("archive expands to more than %d bytes", max)
+}
+
+// unsafePath reports whether a cleaned entry name would land outside the
+// archive root
+func unsafePath(name string) bool {
+	return name == ".." || strings.HasPrefix(name, "../") || path.IsAbs(name)
+}
+
+func ProcessArchive(zr *zip.Reader, w io.Writer, opts ArchiveOptions, fn func(name, text string) (string, error)) ([]ArchiveFileResult, error) {
+	opts = opts.withDefaults()
+	if err := opts.Validate(zr); err != nil {
+		return nil, err
+	}
+	include, _ := matcher(opts.Include)
+
+	zw := zip.NewWriter(w)
+	var results []ArchiveFileResult
+	var total int64
+
+	for _, f := range zr.File {
+		name := path.Clean(strings.ReplaceAll(f.Name, "\\", "/"))
+		if unsafePath(name) {
+			results = append(results, ArchiveFileResult{Name: f.Name, Skipped: "unsafe path"})
+			continue
+		}
+
+		header := f.FileHeader
+		header.Name = name
+		if f.FileInfo().IsDir() {
+			header.Name = name + "/"
+			if _, err := zw.CreateHeader(&header); err != nil {
+				return results, err
+			}
+			continue
+		}
+
+		data, err := readZipFile(f, opts.MaxFileBytes)
+		if total += int64(len(data)); total > opts.MaxTotalBytes {
+			return results, errTotalSize(opts.MaxTotalBytes)
+		}
+		detected := utils.DetectBinary(data)
+		result := ArchiveFileResult{Name: name}
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case !include(name):
+			result.Skipped = "not matched"
+		case detected.Binary:
+			result.Skipped = "binary file (" + detected.MIMEType + ")"
+		default:
+			out, err := fn(name, string(data))
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				data = []byte(out)
+				result.Processed = true
+			}
+		}
+		results = append(results, result)
+
+		if data == nil {
+			continue
+		}
+		header.Method = zip.Deflate
+		header.CompressedSize64 = 0
+		header.UncompressedSize64 = 0
+		header.CRC32 = 0
+		entry, err := zw.CreateHeader(&header)
+		if err != nil {
+			return results, err
+		}
+		if _, err := entry.Write(data); err != nil {
+			return results, err
+		}
+	}
+
+	return results, zw.Close()
+}
+
+func readZipFile(f *zip.File, max int64) ([]byte, error) {
+	if f.UncompressedSize64 > uint64(max) {
+		return nil, fmt.Errorf("file exceeds %d bytes", max)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open: %v", err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, max+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read: %v", err)
+	}
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("file exceeds %d bytes", max)
+	}
+	return data, nil
+}
+
+func ReadArchiveTexts(zr *zip.Reader, opts ArchiveOptions) ([]utils.NamedText, []ArchiveFileResult, error) {
+	opts = opts.withDefaults()
+	if err := opts.Validate(zr); err != nil {
+		return nil, nil, err
+	}
+	include, _ := matcher(opts.Include)
+
+	var texts []utils.NamedText
+	var skipped []ArchiveFileResult
+	var total int64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name := path.Clean(strings.ReplaceAll(f.Name, "\\", "/"))
+		if unsafePath(name) {
+			skipped = append(skipped, ArchiveFileResult{Name: f.Name, Skipped: "unsafe path"})
+			continue
+		}
+		if !include(name) {
+			skipped = append(skipped, ArchiveFileResult{Name: name, Skipped: "not matched"})
+			continue
+		}
+		data, err := readZipFile(f, opts.MaxFileBytes)
+		if total += int64(len(data)); total > opts.MaxTotalBytes {
+			return nil, nil, errTotalSize(opts.MaxTotalBytes)
+		}
+		detected := utils.DetectBinary(data)
+		switch {
+		case err != nil:
+			skipped = append(skipped, ArchiveFileResult{Name: name, Skipped: err.Error()})
+		case detected.Binary:
+			skipped = append(skipped, ArchiveFileResult{Name: name, Skipped: "binary file (" + detected.MIMEType + ")"})
+		default:
+			texts = append(texts, utils.NamedText{Name: name, Text: string(data)})
+		}
+	}
+	return texts, skipped, nil
+}
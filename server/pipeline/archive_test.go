@@ -0,0 +1,205 @@
+package pipeline
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type archiveEntry struct {
+	name, body string
+}
+
+func buildArchive(t *testing.T, entries ...archiveEntry) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, e.body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zr
+}
+
+func readArchive(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(body)
+	}
+	return files
+}
+
+func TestProcessArchive(t *testing.T) {
+	upper := func(name, text string) (string, error) {
+		if text == "fail" {
+			return "", errors.New("boom")
+		}
+		return strings.ToUpper(text), nil
+	}
+	tests := []struct {
+		name        string
+		entries     []archiveEntry
+		opts        ArchiveOptions
+		wantResults []ArchiveFileResult
+		wantFiles   map[string]string
+	}{
+		{
+			name:    "processes text files",
+			entries: []archiveEntry{{"a.txt", "a"}, {"dir/", ""}, {"dir/b.txt", "b"}},
+			wantResults: []ArchiveFileResult{
+				{Name: "a.txt", Processed: true},
+				{Name: "dir/b.txt", Processed: true},
+			},
+			wantFiles: map[string]string{"a.txt": "A", "dir/": "", "dir/b.txt": "B"},
+		},
+		{
+			name:    "skips entries escaping the root",
+			entries: []archiveEntry{{"../evil.txt", "x"}, {"a/../../evil.txt", "x"}, {"/abs.txt", "x"}, {"..\\win.txt", "x"}, {"a/../ok.txt", "ok"}},
+			wantResults: []ArchiveFileResult{
+				{Name: "../evil.txt", Skipped: "unsafe path"},
+				{Name: "a/../../evil.txt", Skipped: "unsafe path"},
+				{Name: "/abs.txt", Skipped: "unsafe path"},
+				{Name: "..\\win.txt", Skipped: "unsafe path"},
+				{Name: "ok.txt", Processed: true},
+			},
+			wantFiles: map[string]string{"ok.txt": "OK"},
+		},
+		{
+			name:    "copies unmatched and binary files unchanged",
+			entries: []archiveEntry{{"a.md", "a"}, {"b.txt", "b"}, {"c.txt", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"}},
+			opts:    ArchiveOptions{Include: []string{"*.txt"}},
+			wantResults: []ArchiveFileResult{
+				{Name: "a.md", Skipped: "not matched"},
+				{Name: "b.txt", Processed: true},
+				{Name: "c.txt", Skipped: "binary file (image/png)"},
+			},
+			wantFiles: map[string]string{"a.md": "a", "b.txt": "B", "c.txt": "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"},
+		},
+		{
+			name:    "reports per-file errors and keeps going",
+			entries: []archiveEntry{{"big.txt", "0123456789"}, {"bad.txt", "fail"}, {"ok.txt", "ok"}},
+			opts:    ArchiveOptions{MaxFileBytes: 5},
+			wantResults: []ArchiveFileResult{
+				{Name: "big.txt", Error: "file exceeds 5 bytes"},
+				{Name: "bad.txt", Error: "boom"},
+				{Name: "ok.txt", Processed: true},
+			},
+			wantFiles: map[string]string{"bad.txt": "fail", "ok.txt": "OK"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			results, err := ProcessArchive(buildArchive(t, tt.entries...), &out, tt.opts, upper)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(results, tt.wantResults) {
+				t.Errorf("results = %+v, want %+v", results, tt.wantResults)
+			}
+			if files := readArchive(t, out.Bytes()); !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("files = %q, want %q", files, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestProcessArchiveLimits(t *testing.T) {
+	identity := func(name, text string) (string, error) { return text, nil }
+	tests := []struct {
+		name    string
+		entries []archiveEntry
+		opts    ArchiveOptions
+		wantErr string
+	}{
+		{
+			name:    "too many files",
+			entries: []archiveEntry{{"a", "a"}, {"b", "b"}, {"c", "c"}},
+			opts:    ArchiveOptions{MaxFiles: 2},
+			wantErr: "archive contains 3 files, limit is 2",
+		},
+		{
+			name:    "total size",
+			entries: []archiveEntry{{"a", strings.Repeat("a", 6)}, {"b", strings.Repeat("b", 6)}},
+			opts:    ArchiveOptions{MaxTotalBytes: 10},
+			wantErr: "archive expands to more than 10 bytes",
+		},
+		{
+			name:    "total size at the limit",
+			entries: []archiveEntry{{"a", strings.Repeat("a", 5)}, {"b", strings.Repeat("b", 5)}},
+			opts:    ArchiveOptions{MaxTotalBytes: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ProcessArchive(buildArchive(t, tt.entries...), io.Discard, tt.opts, identity)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*.txt", "a.txt", true},
+		{"*.txt", "dir/a.txt", true},
+		{"dir/*.txt", "dir/a.txt", true},
+		{"dir/*.txt", "dir/sub/a.txt", false},
+		{"dir/**/*.txt", "dir/a.txt", true},
+		{"dir/**/*.txt", "dir/sub/deep/a.txt", true},
+		{"**", "any/thing", true},
+		{"?.md", "a.md", true},
+		{"?.md", "ab.md", false},
+		{"a+b.txt", "a+b.txt", true},
+		{"a+b.txt", "aab.txt", false},
+	}
+	for _, tt := range tests {
+		match, err := matcher([]string{tt.pattern})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := match(tt.name); got != tt.want {
+			t.Errorf("glob %q on %q = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
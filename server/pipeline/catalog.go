@@ -0,0 +1,138 @@
+package pipeline
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"toolkit-backend/utils"
+)
+
+type ParamSpec struct {
+	Name     string      `json:"name"`
+	Type     string      `json:"type"`
+	Default  interface{} `json:"default,omitempty"`
+	Enum     []string    `json:"enum,omitempty"`
+	Minimum  *int        `json:"minimum,omitempty"`
+	Maximum  *int        `json:"maximum,omitempty"`
+	Required bool        `json:"required,omitempty"`
+}
+
+type OperationSpec struct {
+	Name   string      `json:"name"`
+	Params []ParamSpec `json:"params"`
+}
+
+type enumerable interface {
+	Values() []string
+}
+
+func ParamsOf(defaults interface{}) []ParamSpec {
+	v := reflect.ValueOf(defaults)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	specs := []ParamSpec{}
+	eachParam(v, func(name string, field reflect.StructField, value reflect.Value) {
+		spec := ParamSpec{
+			Name:     name,
+			Type:     paramType(field.Type),
+			Required: strings.Contains(field.Tag.Get("binding"), "required"),
+		}
+		if !spec.Required && !(value.Kind() == reflect.Slice && value.IsNil()) {
+			spec.Default = value.Interface()
+		}
+		if e, ok := value.Interface().(enumerable); ok {
+			spec.Enum = e.Values()
+		}
+		if min, err := strconv.Atoi(field.Tag.Get("min")); err == nil {
+			spec.Minimum = &min
+		}
+		if max, err := strconv.Atoi(field.Tag.Get("max")); err == nil {
+			spec.Maximum = &max
+		}
+		specs = append(specs, spec)
+	})
+	return specs
+}
+
+func (p Params) Decode(target interface{}) {
+	eachParam(reflect.ValueOf(target).Elem(), func(name string, _ reflect.StructField, value reflect.Value) {
+		switch value.Kind() {
+		case reflect.Bool:
+			value.SetBool(p.Bool(name, value.Bool()))
+		case reflect.Int, reflect.Int64:
+			value.SetInt(int64(p.Int(name, int(value.Int()))))
+		case reflect.String:
+			value.SetString(p.String(name, value.String()))
+		case reflect.Slice:
+			def, _ := value.Interface().([]string)
+			value.Set(reflect.ValueOf(p.Strings(name, def)).Convert(value.Type()))
+		}
+	})
+}
+
+func eachParam(v reflect.Value, fn func(name string, field reflect.StructField, value reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			eachParam(v.Field(i), fn)
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !field.IsExported() || paramType(field.Type) == "" {
+			continue
+		}
+		fn(name, field, v.Field(i))
+	}
+}
+
+func paramType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.String:
+		return "string"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return "array"
+		}
+	}
+	return ""
+}
+
+func checkParams(specs []ParamSpec, params Params) error {
+	for _, spec := range specs {
+		v, ok := params[spec.Name]
+		if !ok || v == nil {
+			if spec.Required {
+				return fmt.Errorf("%w: missing required parameter %q", utils.ErrInvalidOption, spec.Name)
+			}
+			continue
+		}
+		if len(spec.Enum) > 0 {
+			s := params.String(spec.Name, "")
+			valid := false
+			for _, e := range spec.Enum {
+				if s == e {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return fmt.Errorf("%w: parameter %q must be one of %s", utils.ErrInvalidOption, spec.Name, strings.Join(spec.Enum, ", "))
+			}
+		}
+		if spec.Minimum != nil && params.Int(spec.Name, *spec.Minimum) < *spec.Minimum {
+			return fmt.Errorf("%w: parameter %q must be at least %d", utils.ErrInvalidOption, spec.Name, *spec.Minimum)
+		}
+		if spec.Maximum != nil && params.Int(spec.Name, *spec.Maximum) > *spec.Maximum {
+			return fmt.Errorf("%w: parameter %q must be at most %d", utils.ErrInvalidOption, spec.Name, *spec.Maximum)
+		}
+	}
+	return nil
+}
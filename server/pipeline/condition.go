@@ -0,0 +1,380 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"toolkit-backend/utils"
+)
+
+// Conditions are small boolean expressions over the step input (for "when")
+// or over each line (for "lines"):
+//
+//	lines >= 10 and not contains "DRAFT"
+//	/^WARN/i or length > 120
+//
+// Comparisons take a numeric variable, one of < <= > >= == != and an integer.
+// A bare /regex/ is shorthand for "matches /regex/".
+type condition func(conditionEnv) bool
+
+type conditionEnv struct {
+	subject string
+	vars    func(name string) int
+}
+
+// conditions come from requests, so both their length and how deeply
+// parentheses and "not" nest are bounded; long "or" chains evaluate as deeply
+// nested closures too, which the length cap keeps shallow
+const (
+	MaxConditionLength = 4096
+	MaxConditionDepth  = 32
+)
+
+var (
+	textConditionVars = []string{"lines", "words", "chars", "bytes"}
+	lineConditionVars = []string{"number", "length", "words", "bytes"}
+)
+
+func textEnv(text string) conditionEnv {
+	return conditionEnv{subject: text, vars: func(name string) int {
+		switch name {
+		case "lines":
+			if text == "" {
+				return 0
+			}
+			return strings.Count(text, "\n") + 1
+		case "words":
+			return len(strings.Fields(text))
+		case "chars":
+			return utf8.RuneCountInString(text)
+		}
+		return len(text)
+	}}
+}
+
+func lineEnv(line string, number int) conditionEnv {
+	return conditionEnv{subject: line, vars: func(name string) int {
+		switch name {
+		case "number":
+			return number
+		case "length":
+			return utils.GraphemeCount(line)
+		case "words":
+			return len(strings.Fields(line))
+		}
+		return len(line)
+	}}
+}
+
+type condToken struct {
+	kind  string // ident, number, string, regex, op, (, )
+	text  string
+	flags string
+	pos   int
+}
+
+func tokenizeCondition(src string) ([]condToken, error) {
+	var tokens []condToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, condToken{kind: string(c), text: string(c), pos: i})
+			i++
+		case strings.ContainsRune("<>=!", rune(c)):
+			j := i + 1
+			if j < len(src) && src[j] == '=' {
+				j++
+			}
+			op := src[i:j]
+			if op == "=" || op == "!" {
+				return nil, fmt.Errorf("unexpected %q at %d", op, i+1)
+			}
+			tokens = append(tokens, condToken{kind: "op", text: op, pos: i})
+			i = j
+		case c == '"':
+			s, n, err := scanQuoted(src[i:], '"')
+			if err != nil {
+				return nil, fmt.Errorf("%v at %d", err, i+1)
+			}
+			tokens = append(tokens, condToken{kind: "string", text: s, pos: i})
+			i += n
+		case c == '/':
+			s, n, err := scanQuoted(src[i:], '/')
+			if err != nil {
+				return nil, fmt.Errorf("%v at %d", err, i+1)
+			}
+			j := i + n
+			for j < len(src) && (src[j] == 'i' || src[j] == 's' || src[j] == 'm') {
+				j++
+			}
+			tokens = append(tokens, condToken{kind: "regex", text: s, flags: src[i+n : j], pos: i})
+			i = j
+		case c >= '0' && c <= '9' || c == '-':
+			j := i + 1
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			tokens = append(tokens, condToken{kind: "number", text: src[i:j], pos: i})
+			i = j
+		case unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(src) && (unicode.IsLetter(rune(src[j])) || src[j] >= '0' && src[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, condToken{kind: "ident", text: src[i:j], pos: i})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", c, i+1)
+		}
+	}
+	return tokens, nil
+}
+
+// scanQuoted reads a delimited literal; a backslash escapes the delimiter and
+// is otherwise kept, so regex escapes like \d survive untouched
+func scanQuoted(src string, delim byte) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(src); i++ {
+		switch {
+		case src[i] == '\\' && i+1 < len(src) && src[i+1] == delim:
+			b.WriteByte(delim)
+			i++
+		case src[i] == '\\' && delim == '"' && i+1 < len(src) && src[i+1] == '\\':
+			b.WriteByte('\\')
+			i++
+		case src[i] == delim:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(src[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated %c", delim)
+}
+
+type conditionParser struct {
+	tokens []condToken
+	pos    int
+	vars   []string
+	depth  int
+	limits utils.Limits
+}
+
+func parseCondition(src string, vars []string, limits utils.Limits) (condition, error) {
+	if len(src) > MaxConditionLength {
+		return nil, fmt.Errorf("condition is longer than %d bytes", MaxConditionLength)
+	}
+	tokens, err := tokenizeCondition(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty condition")
+	}
+	p := &conditionParser{tokens: tokens, vars: vars, limits: limits}
+	cond, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos+1)
+	}
+	return cond, nil
+}
+
+func (p *conditionParser) peek() (condToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return condToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *conditionParser) next() (condToken, error) {
+	t, ok := p.peek()
+	if !ok {
+		return condToken{}, fmt.Errorf("unexpected end of condition")
+	}
+	p.pos++
+	return t, nil
+}
+
+func (p *conditionParser) keyword(word string) bool {
+	if t, ok := p.peek(); ok && t.kind == "ident" && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) or() (condition, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env conditionEnv) bool { return l(env) || right(env) }
+	}
+	return left, nil
+}
+
+func (p *conditionParser) and() (condition, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env conditionEnv) bool { return l(env) && right(env) }
+	}
+	return left, nil
+}
+
+func (p *conditionParser) unary() (condition, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > MaxConditionDepth {
+		t, _ := p.peek()
+		return nil, fmt.Errorf("condition nests deeper than %d levels at %d", MaxConditionDepth, t.pos+1)
+	}
+	if p.keyword("not") {
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(env conditionEnv) bool { return !inner(env) }, nil
+	}
+	return p.primary()
+}
+
+func (p *conditionParser) primary() (condition, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch t.kind {
+	case "(":
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if closing, err := p.next(); err != nil || closing.kind != ")" {
+			return nil, fmt.Errorf("missing ) for ( at %d", t.pos+1)
+		}
+		return inner, nil
+	case "regex":
+		return p.regexCondition(t)
+	case "ident":
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos+1)
+	}
+
+	switch word := strings.ToLower(t.text); word {
+	case "matches":
+		re, err := p.next()
+		if err != nil || re.kind != "regex" {
+			return nil, fmt.Errorf("matches at %d needs a /regex/", t.pos+1)
+		}
+		return p.regexCondition(re)
+	case "contains", "startswith", "endswith":
+		s, err := p.next()
+		if err != nil || s.kind != "string" {
+			return nil, fmt.Errorf("%s at %d needs a quoted string", t.text, t.pos+1)
+		}
+		test := map[string]func(string, string) bool{"contains": strings.Contains, "startswith": strings.HasPrefix, "endswith": strings.HasSuffix}[word]
+		return func(env conditionEnv) bool { return test(env.subject, s.text) }, nil
+	case "empty", "blank":
+		return func(env conditionEnv) bool { return strings.TrimSpace(env.subject) == "" }, nil
+	}
+
+	name := strings.ToLower(t.text)
+	known := false
+	for _, v := range p.vars {
+		known = known || v == name
+	}
+	if !known {
+		return nil, fmt.Errorf("unknown variable %q at %d, want one of %s", t.text, t.pos+1, strings.Join(p.vars, ", "))
+	}
+	op, err := p.next()
+	if err != nil || op.kind != "op" {
+		return nil, fmt.Errorf("%s at %d needs a comparison", t.text, t.pos+1)
+	}
+	num, err := p.next()
+	if err != nil || num.kind != "number" {
+		return nil, fmt.Errorf("%s %s at %d needs a number", t.text, op.text, op.pos+1)
+	}
+	n, err := strconv.Atoi(num.text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q at %d", num.text, num.pos+1)
+	}
+	compare := map[string]func(a, b int) bool{
+		"<": func(a, b int) bool { return a < b }, "<=": func(a, b int) bool { return a <= b },
+		">": func(a, b int) bool { return a > b }, ">=": func(a, b int) bool { return a >= b },
+		"==": func(a, b int) bool { return a == b }, "!=": func(a, b int) bool { return a != b },
+	}[op.text]
+	return func(env conditionEnv) bool { return compare(env.vars(name), n) }, nil
+}
+
+func (p *conditionParser) regexCondition(t condToken) (condition, error) {
+	flags := utils.RegexFlags{
+		IgnoreCase: strings.Contains(t.flags, "i"),
+		Multiline:  strings.Contains(t.flags, "m"),
+		DotAll:     strings.Contains(t.flags, "s"),
+	}
+	if err := p.limits.CheckRegex(t.text, flags); err != nil {
+		return nil, fmt.Errorf("regex at %d: %w", t.pos+1, err)
+	}
+	re, err := utils.CompileRegex(t.text, flags)
+	if err != nil {
+		return nil, fmt.Errorf("regex at %d: %w", t.pos+1, err)
+	}
+	return func(env conditionEnv) bool { return re.MatchString(env.subject) }, nil
+}
+
+// compileConditions parses a step's when/lines expressions, holding their
+// regexes to limits; a nil condition means unset
+func (s Step) compileConditions(limits utils.Limits) (when, lines condition, err error) {
+	if s.When != "" {
+		if when, err = parseCondition(s.When, textConditionVars, limits); err != nil {
+			return nil, nil, fmt.Errorf("when: %w", err)
+		}
+	}
+	if s.Lines != "" {
+		if lines, err = parseCondition(s.Lines, lineConditionVars, limits); err != nil {
+			return nil, nil, fmt.Errorf("lines: %w", err)
+		}
+	}
+	return when, lines, nil
+}
+
+// applyToLines runs op on each selected line by itself, leaving the others and
+// any \r line endings untouched
+func applyToLines(ctx context.Context, op ContextOperation, text string, params Params, selected condition) (string, error) {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		body := strings.TrimSuffix(line, "\r")
+		if !selected(lineEnv(body, i+1)) {
+			continue
+		}
+		out, err := op(ctx, body, params)
+		if err != nil {
+			return "", fmt.Errorf("line %d: %w", i+1, err)
+		}
+		lines[i] = out + line[len(body):]
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,160 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"toolkit-backend/utils"
+)
+
+func TestConditionText(t *testing.T) {
+	tests := []struct {
+		cond string
+		text string
+		want bool
+	}{
+		{`lines >= 2`, "a\nb", true},
+		{`lines >= 2`, "a", false},
+		{`lines == 0`, "", true},
+		{`words == 3 and chars < 10`, "a b c", true},
+		{`chars == 2 and bytes == 4`, "éé", true},
+		{`contains "DRAFT"`, "a DRAFT b", true},
+		{`not contains "DRAFT"`, "a DRAFT b", false},
+		{`startswith "a" and endswith "z"`, "a to z", true},
+		{`STARTSWITH "a" AND NOT endswith "z"`, "a to z", false},
+		{`contains "say \"hi\""`, `they say "hi"`, true},
+		{`contains "a\\b"`, `a\b`, true},
+		{`empty`, " \n\t", true},
+		{`blank or lines > 5`, "x", false},
+		{`/^WARN/i`, "warn: disk", true},
+		{`matches /\d{3}/`, "id 12", false},
+		{`/a\/b/`, "a/b", true},
+		{`/^b$/m`, "a\nb", true},
+		{`/a.b/s`, "a\nb", true},
+		{`/a.b/`, "a\nb", false},
+		{`lines > 1 or contains "x" and contains "y"`, "x", false},
+		{`(lines > 1 or contains "x") and contains "y"`, "x y", true},
+		{`not not empty`, "", true},
+		{`bytes != -1`, "", true},
+	}
+	for _, tt := range tests {
+		cond, err := parseCondition(tt.cond, textConditionVars, utils.Limits{})
+		if err != nil {
+			t.Errorf("parseCondition(%q): %v", tt.cond, err)
+			continue
+		}
+		if got := cond(textEnv(tt.text)); got != tt.want {
+			t.Errorf("%s on %q = %v, want %v", tt.cond, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestConditionLine(t *testing.T) {
+	tests := []struct {
+		cond   string
+		line   string
+		number int
+		want   bool
+	}{
+		{`number == 3`, "x", 3, true},
+		{`number <= 2`, "x", 3, false},
+		{`length == 2`, "éé", 1, true},
+		{`bytes > 2`, "éé", 1, true},
+		{`words >= 2 and not /^#/`, "# a b", 1, false},
+	}
+	for _, tt := range tests {
+		cond, err := parseCondition(tt.cond, lineConditionVars, utils.Limits{})
+		if err != nil {
+			t.Errorf("parseCondition(%q): %v", tt.cond, err)
+			continue
+		}
+		if got := cond(lineEnv(tt.line, tt.number)); got != tt.want {
+			t.Errorf("%s on line %d %q = %v, want %v", tt.cond, tt.number, tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestConditionErrors(t *testing.T) {
+	tests := []struct {
+		cond    string
+		limits  utils.Limits
+		wantErr string
+	}{
+		{``, utils.Limits{}, "empty condition"},
+		{`   `, utils.Limits{}, "empty condition"},
+		{`lines = 2`, utils.Limits{}, `unexpected "=" at 7`},
+		{`lines !`, utils.Limits{}, `unexpected "!" at 7`},
+		{`lines > 2 &`, utils.Limits{}, `unexpected '&' at 11`},
+		{`contains "open`, utils.Limits{}, "unterminated \" at 10"},
+		{`/open`, utils.Limits{}, "unterminated / at 1"},
+		{`number > 1`, utils.Limits{}, `unknown variable "number" at 1`},
+		{`lines`, utils.Limits{}, "lines at 1 needs a comparison"},
+		{`lines >`, utils.Limits{}, "lines > at 7 needs a number"},
+		{`lines > 99999999999999999999`, utils.Limits{}, "invalid number"},
+		{`contains lines`, utils.Limits{}, "contains at 1 needs a quoted string"},
+		{`matches "x"`, utils.Limits{}, "matches at 1 needs a /regex/"},
+		{`(lines > 1`, utils.Limits{}, "missing ) for ( at 1"},
+		{`lines > 1)`, utils.Limits{}, `unexpected ")" at 10`},
+		{`lines > 1 and`, utils.Limits{}, "unexpected end of condition"},
+		{`/(/`, utils.Limits{}, "regex at 1"},
+		{`empty or /(a{30}){30}/`, utils.Limits{MaxRegexComplexity: 100}, "regex at 10: limit exceeded"},
+		{strings.Repeat("(", MaxConditionDepth) + "empty" + strings.Repeat(")", MaxConditionDepth), utils.Limits{}, "nests deeper than 32 levels"},
+		{strings.Repeat("not ", MaxConditionDepth) + "empty", utils.Limits{}, "nests deeper than 32 levels"},
+		{strings.Repeat("empty or ", MaxConditionLength/9) + "empty", utils.Limits{}, "longer than 4096 bytes"},
+	}
+	for _, tt := range tests {
+		_, err := parseCondition(tt.cond, textConditionVars, tt.limits)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseCondition(%.40q) error = %v, want %q", tt.cond, err, tt.wantErr)
+		}
+	}
+}
+
+func TestConditionDepthBoundary(t *testing.T) {
+	src := strings.Repeat("(", MaxConditionDepth-1) + "empty" + strings.Repeat(")", MaxConditionDepth-1)
+	if _, err := parseCondition(src, textConditionVars, utils.Limits{}); err != nil {
+		t.Fatalf("%d levels: %v", MaxConditionDepth-1, err)
+	}
+}
+
+func TestApplyToLines(t *testing.T) {
+	upper := func(_ context.Context, text string, _ Params) (string, error) {
+		return strings.ToUpper(text), nil
+	}
+	tests := []struct {
+		cond string
+		text string
+		want string
+	}{
+		{`number != 2`, "a\nb\nc", "A\nb\nC"},
+		{`/^x/`, "xa\r\nya\r\nxb", "XA\r\nya\r\nXB"},
+		{`empty`, "a\n\nb", "a\n\nb"},
+		{`length > 1`, "ab\nc\r\n", "AB\nc\r\n"},
+	}
+	for _, tt := range tests {
+		cond, err := parseCondition(tt.cond, lineConditionVars, utils.Limits{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := applyToLines(context.Background(), upper, tt.text, nil, cond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("applyToLines(%s, %q) = %q, want %q", tt.cond, tt.text, got, tt.want)
+		}
+	}
+
+	failing := func(_ context.Context, text string, _ Params) (string, error) {
+		if text == "bad" {
+			return "", errors.New("boom")
+		}
+		return text, nil
+	}
+	all, _ := parseCondition(`not empty`, lineConditionVars, utils.Limits{})
+	if _, err := applyToLines(context.Background(), failing, "ok\nbad", nil, all); err == nil || err.Error() != "line 2: boom" {
+		t.Errorf("applyToLines error = %v, want line 2: boom", err)
+	}
+}
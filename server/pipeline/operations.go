@@ -0,0 +1,649 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"toolkit-backend/logs"
+	"toolkit-backend/snippets"
+	"toolkit-backend/utils"
+	"toolkit-backend/wordlists"
+)
+
+var Default = NewRegistry()
+
+func simple(fn func(string) string) Operation {
+	return func(text string, _ Params) (string, error) {
+		return fn(text), nil
+	}
+}
+
+type regexReplaceOptions struct {
+	Pattern     string `json:"pattern" binding:"required"`
+	Replacement string `json:"replacement"`
+	utils.RegexFlags
+}
+
+type numberLinesOptions struct {
+	Start  int    `json:"start"`
+	Format string `json:"format"`
+}
+
+type lineLengthOptions struct {
+	Limit int `json:"limit" min:"1"`
+}
+
+type mapColumnOptions struct {
+	utils.ColumnTransformOptions
+	Operation string `json:"operation" binding:"required"`
+}
+
+type splitLimitOptions struct {
+	utils.SplitLimitOptions
+	Limit int `json:"limit" min:"0"`
+}
+
+type insertColumnOptions struct {
+	utils.LineFilter
+	Column int    `json:"column" min:"1"`
+	Text   string `json:"text"`
+}
+
+type deleteColumnsOptions struct {
+	utils.LineFilter
+	From int `json:"from" min:"1"`
+	To   int `json:"to" min:"0"`
+}
+
+type lineAffixOptions struct {
+	utils.LineFilter
+	Text string `json:"text"`
+}
+
+// acronymLists names the caller's stored acronym wordlists
+type convertCaseOptions struct {
+	utils.CaseOptions
+	AcronymLists []string `json:"acronymLists"`
+}
+
+type shoutingOptions struct {
+	utils.ShoutingOptions
+	AcronymLists []string `json:"acronymLists"`
+}
+
+type sampleLinesOptions struct {
+	Count int   `json:"count" binding:"required" min:"1"`
+	Seed  int64 `json:"seed"`
+}
+
+type dedupeParagraphsOptions struct {
+	utils.DedupeOptions
+	Similarity int `json:"similarity" min:"1"`
+	MinWords   int `json:"minWords" min:"0"`
+}
+
+type pickLinesOptions struct {
+	Count int `json:"count" binding:"required" min:"1"`
+	utils.PickOptions
+}
+
+func init() {
+	Default.Register("uppercase", simple(utils.ToUpperCase))
+	Default.Register("lowercase", simple(utils.ToLowerCase))
+	Default.Register("titlecase", simple(utils.ToTitleCase))
+	Default.Register("reverse", simple(utils.ReverseText))
+	Default.Register("reverseWords", simple(utils.ReverseWords))
+	Default.Register("reverseSentences", simple(utils.ReverseSentences))
+	Default.Register("trim", simple(utils.TrimText))
+	Default.Register("stripInvisible", simple(utils.StripInvisible))
+	Default.Register("stripBidiControls", simple(utils.StripBidiControls))
+	Default.Register("emojiToShortcode", simple(utils.EmojiToShortcode))
+	Default.Register("shortcodeToEmoji", simple(utils.ShortcodeToEmoji))
+	Default.Register("visualizeWhitespace", simple(func(text string) string {
+		return utils.VisualizeWhitespace(text).Text
+	}))
+
+	caseDefaults := convertCaseOptions{}
+	Default.RegisterContext("convertCase", func(ctx context.Context, text string, p Params) (string, error) {
+		opts := caseDefaults
+		p.Decode(&opts)
+		named, err := wordlists.Resolve(ctx, wordlists.KindAcronyms, opts.AcronymLists)
+		if err != nil {
+			return "", err
+		}
+		opts.Acronyms = append(opts.Acronyms, named...)
+		return utils.ConvertCaseWithOptions(text, opts.CaseOptions)
+	})
+	Default.Describe("convertCase", ParamsOf(caseDefaults))
+
+	// variables come as "name=value" pairs, the only shape a pipeline param
+	// can carry for a map
+	Default.RegisterContext("expandSnippets", func(ctx context.Context, text string, p Params) (string, error) {
+		opts := snippets.Options{Timezone: p.String("timezone", ""), Variables: map[string]string{}}
+		for _, pair := range p.Strings("variables", nil) {
+			name, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return "", fmt.Errorf("%w: variable %q is not name=value", utils.ErrInvalidOption, pair)
+			}
+			opts.Variables[strings.TrimSpace(name)] = value
+		}
+		return snippets.ExpandSnippets(ctx, text, p.String("set", ""), opts)
+	})
+	Default.Describe("expandSnippets", []ParamSpec{
+		{Name: "set", Type: "string", Required: true},
+		{Name: "timezone", Type: "string", Default: ""},
+		{Name: "variables", Type: "array"},
+	})
+
+	replaceDefaults := utils.ReplacePair{CaseSensitive: true}
+	Default.RegisterContext("findReplace", func(ctx context.Context, text string, p Params) (string, error) {
+		opts := replaceDefaults
+		p.Decode(&opts)
+		return utils.ReplaceTextContext(ctx, text, opts)
+	})
+	Default.Describe("findReplace", ParamsOf(replaceDefaults))
+
+	dedupeDefaults := utils.DedupeOptions{}
+	Default.RegisterContext("dedupe", func(ctx context.Context, text string, p Params) (string, error) {
+		opts := dedupeDefaults
+		p.Decode(&opts)
+		return utils.RemoveDuplicateLinesContext(ctx, text, opts)
+	})
+	Default.Describe("dedupe", ParamsOf(dedupeDefaults))
+
+	// pipeline params carry no floats, so the threshold is a percentage
+	paragraphDefaults := dedupeParagraphsOptions{Similarity: 100}
+	Default.Register("dedupeParagraphs", func(text string, p Params) (string, error) {
+		opts := paragraphDefaults
+		p.Decode(&opts)
+		out, _, err := utils.RemoveDuplicateParagraphs(text, utils.ParagraphDedupeOptions{
+			DedupeOptions: opts.DedupeOptions,
+			Threshold:     float64(opts.Similarity) / 100,
+			MinWords:      opts.MinWords,
+		})
+		return out, err
+	})
+	Default.Describe("dedupeParagraphs", ParamsOf(paragraphDefaults))
+
+	sortDefaults := utils.SortOptions{Ascending: true, Compare: utils.CompareLexical}
+	Default.RegisterContext("sort", func(ctx context.Context, text string, p Params) (string, error) {
+		opts := sortDefaults
+		p.Decode(&opts)
+		if err := opts.Validate(); err != nil {
+			return "", err
+		}
+		return utils.SortLinesContext(ctx, text, opts)
+	})
+	Default.Describe("sort", ParamsOf(sortDefaults))
+	Default.Register("sortParagraphs", func(text string, p Params) (string, error) {
+		opts := sortDefaults
+		p.Decode(&opts)
+		if err := opts.Validate(); err != nil {
+			return "", err
+		}
+		return utils.SortParagraphs(text, opts), nil
+	})
+	Default.Describe("sortParagraphs", ParamsOf(sortDefaults))
+	Default.Register("sortWords", func(text string, p Params) (string, error) {
+		opts := sortDefaults
+		p.Decode(&opts)
+		if err := opts.Validate(); err != nil {
+			return "", err
+		}
+		return utils.SortWords(text, opts), nil
+	})
+	Default.Describe("sortWords", ParamsOf(sortDefaults))
+
+	regexDefaults := regexReplaceOptions{}
+	Default.RegisterContext("regexReplace", func(ctx context.Context, text string, p Params) (string, error) {
+		opts := regexDefaults
+		p.Decode(&opts)
+		out, _, err := utils.RegexReplaceContext(ctx, text, opts.Pattern, opts.Replacement, opts.RegexFlags)
+		return out, err
+	})
+	Default.Describe("regexReplace", ParamsOf(regexDefaults))
+
+	pseudonymDefaults := utils.PseudonymOptions{}
+	Default.Register("pseudonymize", func(text string, p Params) (string, error) {
+		opts := pseudonymDefaults
+		p.Decode(&opts)
+		result, err := utils.Pseudonymize(text, opts)
+		return result.Text, err
+	})
+	Default.Describe("pseudonymize", ParamsOf(pseudonymDefaults))
+	Default.Register("redactSecrets", func(text string, _ Params) (string, error) {
+		out, _ := utils.RedactSecrets(text)
+		return out, nil
+	})
+
+	Default.Register("encodeQuotedPrintable", func(text string, _ Params) (string, error) {
+		return utils.EncodeQuotedPrintable(text)
+	})
+	Default.Register("decodeQuotedPrintable", func(text string, _ Params) (string, error) {
+		return utils.DecodeQuotedPrintable(text)
+	})
+	Default.Register("encodeMimeHeader", func(text string, p Params) (string, error) {
+		return utils.EncodeMIMEHeader(text, p.String("encoding", "B"))
+	})
+	Default.Describe("encodeMimeHeader", []ParamSpec{{Name: "encoding", Type: "string", Default: "B", Enum: []string{"B", "Q"}}})
+	Default.Register("decodeMimeHeader", func(text string, _ Params) (string, error) {
+		return utils.DecodeMIMEHeader(text)
+	})
+
+	logDefaults := logs.Options{Format: "RFC3339"}
+	Default.Register("normalizeTimestamps", func(text string, p Params) (string, error) {
+		opts := logDefaults
+		p.Decode(&opts)
+		out, _, err := logs.NormalizeTimestamps(text, opts)
+		return out, err
+	})
+	Default.Describe("normalizeTimestamps", ParamsOf(logDefaults))
+	Default.Register("sortLogs", func(text string, p Params) (string, error) {
+		opts := logDefaults
+		p.Decode(&opts)
+		return logs.SortByTime(text, opts)
+	})
+	Default.Describe("sortLogs", []ParamSpec{{Name: "inputZone", Type: "string", Default: ""}})
+
+	epochParams := []ParamSpec{
+		{Name: "zone", Type: "string", Default: ""},
+		{Name: "format", Type: "string", Default: ""},
+		{Name: "unit", Type: "string", Default: string(logs.EpochAuto), Enum: logs.EpochAuto.Values()},
+	}
+	Default.Register("expandEpochs", func(text string, p Params) (string, error) {
+		var opts logs.EpochOptions
+		p.Decode(&opts)
+		out, _, err := logs.EpochsToTimestamps(text, opts)
+		return out, err
+	})
+	Default.Describe("expandEpochs", epochParams)
+	Default.Register("collapseTimestamps", func(text string, p Params) (string, error) {
+		var opts logs.EpochOptions
+		p.Decode(&opts)
+		out, _, err := logs.TimestampsToEpoch(text, opts)
+		return out, err
+	})
+	Default.Describe("collapseTimestamps", epochParams)
+
+	alignDefaults := utils.AlignOptions{Gap: 1}
+	Default.Register("alignColumns", func(text string, p Params) (string, error) {
+		opts := alignDefaults
+		p.Decode(&opts)
+		return utils.AlignColumns(text, opts), nil
+	})
+	Default.Describe("alignColumns", ParamsOf(alignDefaults))
+
+	Default.Register("matchPhonetic", func(text string, p Params) (string, error) {
+		return utils.MatchPhonetic(text, p.String("query", "")), nil
+	})
+	Default.Describe("matchPhonetic", []ParamSpec{{Name: "query", Type: "string", Required: true}})
+
+	// the closest lines first, one per line
+	Default.Register("nearestLines", func(text string, p Params) (string, error) {
+		matches, err := utils.NearestLinesWithOptions(text, p.String("query", ""), utils.NearestOptions{
+			Limit:         p.Int("limit", utils.DefaultNearestLines),
+			MaxDistance:   p.Int("maxDistance", 0),
+			CaseSensitive: p.Bool("caseSensitive", false),
+		})
+		if err != nil {
+			return "", err
+		}
+		lines := make([]string, len(matches))
+		for i, m := range matches {
+			lines[i] = m.Text
+		}
+		return strings.Join(lines, "\n"), nil
+	})
+	Default.Describe("nearestLines", append([]ParamSpec{{Name: "query", Type: "string", Required: true}},
+		ParamsOf(utils.NearestOptions{Limit: utils.DefaultNearestLines})...))
+
+	Default.Register("sampleLines", func(text string, p Params) (string, error) {
+		var opts sampleLinesOptions
+		p.Decode(&opts)
+		return utils.SampleLines(text, opts.Count, opts.Seed)
+	})
+	Default.Describe("sampleLines", ParamsOf(sampleLinesOptions{}))
+
+	Default.Register("pickLines", func(text string, p Params) (string, error) {
+		var opts pickLinesOptions
+		p.Decode(&opts)
+		return utils.PickLines(text, opts.Count, opts.PickOptions)
+	})
+	Default.Describe("pickLines", ParamsOf(pickLinesOptions{}))
+
+	validateDefaults := utils.ValidateOptions{Mode: utils.ValidationAnnotate}
+	Default.Register("validateLines", func(text string, p Params) (string, error) {
+		opts := validateDefaults
+		p.Decode(&opts)
+		out, _, err := utils.ValidateLines(text, opts)
+		return out, err
+	})
+	Default.Describe("validateLines", ParamsOf(validateDefaults))
+
+	Default.Register("splitSentences", func(text string, p Params) (string, error) {
+		sentences, err := utils.SplitSentences(text, p.String("lang", "en"))
+		if err != nil {
+			return "", err
+		}
+		lines := make([]string, len(sentences))
+		for i, s := range sentences {
+			lines[i] = strings.Join(strings.Fields(s.Text), " ")
+		}
+		return strings.Join(lines, "\n"), nil
+	})
+	// no enum for lang: languages can be added after the catalog is built,
+	// and the operation rejects unknown ones itself
+	Default.Describe("splitSentences", []ParamSpec{{Name: "lang", Type: "string", Default: "en"}})
+
+	Default.RegisterContext("removeStopWords", func(ctx context.Context, text string, p Params) (string, error) {
+		extra := p.Strings("stopWords", nil)
+		named, err := wordlists.Resolve(ctx, wordlists.KindStopWords, p.Strings("stopWordLists", nil))
+		if err != nil {
+			return "", err
+		}
+		return utils.RemoveStopWords(text, p.String("lang", "en"), append(extra, named...))
+	})
+	Default.Describe("removeStopWords", []ParamSpec{
+		{Name: "lang", Type: "string", Default: "en"},
+		{Name: "stopWords", Type: "array"},
+		{Name: "stopWordLists", Type: "array"},
+	})
+
+	Default.Register("filterLanguage", func(text string, p Params) (string, error) {
+		return utils.FilterByLanguage(text, utils.LanguageFilterOptions{
+			Language: p.String("language", ""),
+			Unit:     utils.LanguageUnit(p.String("unit", string(utils.LanguageUnitLine))),
+		})
+	})
+	Default.Describe("filterLanguage", ParamsOf(utils.LanguageFilterOptions{Unit: utils.LanguageUnitLine}))
+
+	Default.Register("tableOfContents", func(text string, p Params) (string, error) {
+		outline, err := utils.ExtractOutline(text, utils.OutlineFormat(p.String("format", string(utils.OutlineAuto))))
+		return outline.TableOfContents, err
+	})
+	Default.Describe("tableOfContents", []ParamSpec{{Name: "format", Type: "string", Default: string(utils.OutlineAuto), Enum: utils.OutlineAuto.Values()}})
+
+	Default.Register("unwrap", simple(utils.UnwrapText))
+	Default.Register("stripFrontMatter", simple(utils.StripFrontMatter))
+	Default.Register("stripCommonPrefix", simple(utils.StripCommonPrefix))
+	Default.Register("stripCommonSuffix", simple(utils.StripCommonSuffix))
+
+	Default.Register("cutColumns", func(text string, p Params) (string, error) {
+		return utils.CutColumns(text, p.String("ranges", ""))
+	})
+	Default.Describe("cutColumns", []ParamSpec{{Name: "ranges", Type: "string", Required: true}})
+
+	Default.Register("convertFixedWidth", func(text string, p Params) (string, error) {
+		opts := utils.FixedWidthOptions{Header: p.Bool("header", false), Format: p.String("format", "csv")}
+		for _, w := range p.Strings("widths", nil) {
+			if strings.TrimSpace(w) == "" {
+				continue
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(w))
+			if err != nil {
+				return "", fmt.Errorf("%w: invalid column width %q", utils.ErrInvalidOption, w)
+			}
+			opts.Widths = append(opts.Widths, n)
+		}
+		return utils.ConvertFixedWidth(text, opts)
+	})
+	Default.Describe("convertFixedWidth", []ParamSpec{
+		{Name: "widths", Type: "array"},
+		{Name: "header", Type: "boolean", Default: false},
+		{Name: "format", Type: "string", Default: "csv", Enum: []string{"csv", "json"}},
+	})
+
+	wrapDefaults := utils.WrapLineOptions{Separator: "\n"}
+	Default.Register("wrapLines", func(text string, p Params) (string, error) {
+		opts := wrapDefaults
+		p.Decode(&opts)
+		return utils.WrapLinesWithOptions(text, opts), nil
+	})
+	Default.Describe("wrapLines", ParamsOf(wrapDefaults))
+
+	columnDefaults := utils.ColumnLayoutOptions{Columns: 2, Width: 40, Gap: 4}
+	Default.Register("newspaperColumns", func(text string, p Params) (string, error) {
+		opts := columnDefaults
+		p.Decode(&opts)
+		return utils.NewspaperColumns(text, opts)
+	})
+	Default.Describe("newspaperColumns", ParamsOf(columnDefaults))
+	Default.Register("verticalText", func(text string, p Params) (string, error) {
+		return utils.VerticalText(text, utils.VerticalMode(p.String("mode", string(utils.VerticalStack))))
+	})
+	Default.Describe("verticalText", []ParamSpec{{Name: "mode", Type: "string", Default: string(utils.VerticalStack), Enum: utils.VerticalStack.Values()}})
+
+	Default.Register("convertQuotes", func(text string, p Params) (string, error) {
+		return utils.ConvertQuotes(text, utils.QuoteStyle(p.String("style", string(utils.QuoteUS))))
+	})
+	Default.Describe("convertQuotes", []ParamSpec{{Name: "style", Type: "string", Default: string(utils.QuoteUS), Enum: utils.QuoteUS.Values()}})
+
+	// pairs come as "from=to"; the first "=" after the first character
+	// splits them, so "==" maps "=" to nothing
+	Default.Register("mapCharacters", func(text string, p Params) (string, error) {
+		opts := utils.CharMapOptions{Table: utils.CharMap(p.String("table", "")), From: p.String("from", ""), To: p.String("to", "")}
+		for _, pair := range p.Strings("pairs", nil) {
+			_, size := utf8.DecodeRuneInString(pair)
+			eq := strings.Index(pair[size:], "=")
+			if pair == "" || eq < 0 {
+				return "", fmt.Errorf("%w: pair %q is not from=to", utils.ErrInvalidOption, pair)
+			}
+			if opts.Pairs == nil {
+				opts.Pairs = map[string]string{}
+			}
+			opts.Pairs[pair[:size+eq]] = pair[size+eq+1:]
+		}
+		return utils.MapCharactersWithOptions(text, opts)
+	})
+	Default.Describe("mapCharacters", []ParamSpec{
+		{Name: "table", Type: "string", Default: "", Enum: utils.CharMapASCII.Values()},
+		{Name: "pairs", Type: "array"},
+		{Name: "from", Type: "string", Default: ""},
+		{Name: "to", Type: "string", Default: ""},
+	})
+
+	pasteDefaults := utils.PasteOptions{Source: utils.PasteGeneric, Quotes: utils.QuoteStraight}
+	Default.Register("cleanPaste", func(text string, p Params) (string, error) {
+		opts := pasteDefaults
+		p.Decode(&opts)
+		return utils.CleanPasteWithOptions(text, opts)
+	})
+	Default.Describe("cleanPaste", ParamsOf(pasteDefaults))
+
+	Default.Register("convertMarkup", func(text string, p Params) (string, error) {
+		from := utils.MarkupFormat(p.String("from", string(utils.MarkupHTML)))
+		to := utils.MarkupFormat(p.String("to", string(utils.MarkupMarkdown)))
+		return utils.ConvertMarkup(text, from, to)
+	})
+	Default.Describe("convertMarkup", []ParamSpec{
+		{Name: "from", Type: "string", Default: string(utils.MarkupHTML), Enum: utils.MarkupHTML.Values()},
+		{Name: "to", Type: "string", Default: string(utils.MarkupMarkdown), Enum: utils.MarkupHTML.Values()},
+	})
+
+	initialsDefaults := utils.InitialsOptions{Unit: utils.InitialsLines, Case: utils.InitialsPreserve}
+	Default.Register("extractInitials", func(text string, p Params) (string, error) {
+		opts := initialsDefaults
+		p.Decode(&opts)
+		return utils.ExtractInitials(text, opts)
+	})
+	Default.Describe("extractInitials", ParamsOf(initialsDefaults))
+
+	reverseDefaults := utils.ReverseOptions{Unit: utils.ReverseCharacters}
+	Default.Register("reverseBy", func(text string, p Params) (string, error) {
+		opts := reverseDefaults
+		p.Decode(&opts)
+		return utils.ReverseWithOptions(text, opts)
+	})
+	Default.Describe("reverseBy", ParamsOf(reverseDefaults))
+
+	lineLengthDefaults := lineLengthOptions{Limit: 72}
+	Default.Register("fixLineLengths", func(text string, p Params) (string, error) {
+		opts := lineLengthDefaults
+		p.Decode(&opts)
+		return utils.FixLineLengths(text, opts.Limit)
+	})
+	Default.Describe("fixLineLengths", ParamsOf(lineLengthDefaults))
+
+	Default.RegisterContext("mapColumn", func(ctx context.Context, text string, p Params) (string, error) {
+		var opts mapColumnOptions
+		p.Decode(&opts)
+		step := Step{Operation: opts.Operation}
+		switch inner := p["params"].(type) {
+		case Params:
+			step.Params = inner
+		case map[string]interface{}:
+			step.Params = Params(inner)
+		}
+		if err := Default.Validate([]Step{step}); err != nil {
+			return "", err
+		}
+		op, _ := Default.Lookup(step.Operation)
+		return utils.TransformColumn(text, opts.ColumnTransformOptions, func(cell string) (string, error) {
+			return op(ctx, cell, step.Params)
+		})
+	})
+	Default.Describe("mapColumn", append(ParamsOf(mapColumnOptions{}), ParamSpec{Name: "params", Type: "object"}))
+
+	emailDefaults := utils.EmailCleanOptions{}
+	Default.Register("cleanEmail", func(text string, p Params) (string, error) {
+		opts := emailDefaults
+		p.Decode(&opts)
+		return utils.CleanEmailTextWithOptions(text, opts)
+	})
+	Default.Describe("cleanEmail", ParamsOf(emailDefaults))
+
+	Default.Register("isolateBidi", func(text string, p Params) (string, error) {
+		return utils.IsolateBidi(text, utils.BidiDirection(p.String("direction", string(utils.BidiAuto))))
+	})
+	Default.Describe("isolateBidi", []ParamSpec{{Name: "direction", Type: "string", Default: string(utils.BidiAuto), Enum: utils.BidiAuto.Values()}})
+
+	shoutingDefaults := shoutingOptions{}
+	Default.RegisterContext("normalizeShouting", func(ctx context.Context, text string, p Params) (string, error) {
+		opts := shoutingDefaults
+		p.Decode(&opts)
+		named, err := wordlists.Resolve(ctx, wordlists.KindAcronyms, opts.AcronymLists)
+		if err != nil {
+			return "", err
+		}
+		opts.Acronyms = append(opts.Acronyms, named...)
+		return utils.NormalizeShouting(text, opts.ShoutingOptions), nil
+	})
+	Default.Describe("normalizeShouting", ParamsOf(shoutingDefaults))
+
+	capitalizationDefaults := utils.CapitalizationOptions{}
+	Default.Register("fixCapitalization", func(text string, p Params) (string, error) {
+		opts := capitalizationDefaults
+		p.Decode(&opts)
+		return utils.FixCapitalizationWithOptions(text, opts), nil
+	})
+	Default.Describe("fixCapitalization", ParamsOf(capitalizationDefaults))
+
+	splitLimitDefaults := splitLimitOptions{SplitLimitOptions: utils.SplitLimitOptions{Preset: utils.PresetTweet}}
+	Default.Register("splitForLimit", func(text string, p Params) (string, error) {
+		opts := splitLimitDefaults
+		p.Decode(&opts)
+		parts, err := utils.SplitForLimit(text, opts.Limit, opts.SplitLimitOptions)
+		return strings.Join(parts, "\n\n"), err
+	})
+	Default.Describe("splitForLimit", ParamsOf(splitLimitDefaults))
+
+	Default.Register("convertKeyboardLayout", func(text string, p Params) (string, error) {
+		from := utils.KeyboardLayout(p.String("from", string(utils.LayoutQWERTY)))
+		to := utils.KeyboardLayout(p.String("to", string(utils.LayoutJCUKEN)))
+		return utils.ConvertKeyboardLayout(text, from, to)
+	})
+	Default.Describe("convertKeyboardLayout", []ParamSpec{
+		{Name: "from", Type: "string", Default: string(utils.LayoutQWERTY), Enum: utils.LayoutQWERTY.Values()},
+		{Name: "to", Type: "string", Default: string(utils.LayoutJCUKEN), Enum: utils.LayoutQWERTY.Values()},
+	})
+
+	widthParams := []ParamSpec{{Name: "scope", Type: "string", Default: string(utils.WidthAll), Enum: utils.WidthAll.Values()}}
+	Default.Register("toHalfWidth", func(text string, p Params) (string, error) {
+		return utils.ToHalfWidth(text, utils.WidthScope(p.String("scope", string(utils.WidthAll))))
+	})
+	Default.Describe("toHalfWidth", widthParams)
+	Default.Register("toFullWidth", func(text string, p Params) (string, error) {
+		return utils.ToFullWidth(text, utils.WidthScope(p.String("scope", string(utils.WidthAll))))
+	})
+	Default.Describe("toFullWidth", widthParams)
+
+	Default.Register("transliterate", func(text string, p Params) (string, error) {
+		return utils.Transliterate(text, p.String("from", utils.ScriptAuto), p.String("to", utils.ScriptLatin))
+	})
+	Default.Describe("transliterate", []ParamSpec{
+		{Name: "from", Type: "string", Default: utils.ScriptAuto, Enum: utils.TransliterationScripts},
+		{Name: "to", Type: "string", Default: utils.ScriptLatin, Enum: utils.TransliterationScripts},
+	})
+
+	slugDefaults := utils.SlugOptions{Separator: "-"}
+	Default.Register("slugify", func(text string, p Params) (string, error) {
+		opts := slugDefaults
+		p.Decode(&opts)
+		return utils.SlugifyWithOptions(text, opts)
+	})
+	Default.Describe("slugify", ParamsOf(slugDefaults))
+
+	Default.Register("extractLinks", func(text string, p Params) (string, error) {
+		links, err := utils.ExtractLinks(text, p.String("format", utils.LinkFormatAuto))
+		if err != nil {
+			return "", err
+		}
+		urls := make([]string, len(links))
+		for i, l := range links {
+			urls[i] = l.URL
+		}
+		return strings.Join(urls, "\n"), nil
+	})
+	Default.Describe("extractLinks", []ParamSpec{{Name: "format", Type: "string", Default: utils.LinkFormatAuto, Enum: utils.LinkFormats}})
+
+	csvDefaults := utils.CSVOptions{Pad: true}
+	Default.Register("fixCsv", func(text string, p Params) (string, error) {
+		opts := csvDefaults
+		p.Decode(&opts)
+		out, _, err := utils.FixCSV(text, opts)
+		return out, err
+	})
+	Default.Describe("fixCsv", ParamsOf(csvDefaults))
+
+	insertColumnDefaults := insertColumnOptions{Column: 1}
+	Default.RegisterContext("insertAtColumn", func(ctx context.Context, text string, p Params) (string, error) {
+		opts := insertColumnDefaults
+		p.Decode(&opts)
+		return utils.InsertAtColumnContext(ctx, text, opts.Column, opts.Text, opts.LineFilter)
+	})
+	Default.Describe("insertAtColumn", ParamsOf(insertColumnDefaults))
+	deleteColumnsDefaults := deleteColumnsOptions{From: 1}
+	Default.RegisterContext("deleteColumns", func(ctx context.Context, text string, p Params) (string, error) {
+		opts := deleteColumnsDefaults
+		p.Decode(&opts)
+		return utils.DeleteColumnsContext(ctx, text, opts.From, opts.To, opts.LineFilter)
+	})
+	Default.Describe("deleteColumns", ParamsOf(deleteColumnsDefaults))
+	Default.RegisterContext("prependLines", func(ctx context.Context, text string, p Params) (string, error) {
+		var opts lineAffixOptions
+		p.Decode(&opts)
+		return utils.PrependLinesContext(ctx, text, opts.Text, opts.LineFilter)
+	})
+	Default.Describe("prependLines", ParamsOf(lineAffixOptions{}))
+	Default.RegisterContext("appendLines", func(ctx context.Context, text string, p Params) (string, error) {
+		var opts lineAffixOptions
+		p.Decode(&opts)
+		return utils.AppendLinesContext(ctx, text, opts.Text, opts.LineFilter)
+	})
+	Default.Describe("appendLines", ParamsOf(lineAffixOptions{}))
+
+	Default.Register("trimLines", func(text string, _ Params) (string, error) {
+		return utils.TrimLines(text, utils.ParallelOptions{}), nil
+	})
+
+	numberDefaults := numberLinesOptions{Start: 1}
+	Default.Register("numberLines", func(text string, p Params) (string, error) {
+		opts := numberDefaults
+		p.Decode(&opts)
+		return utils.NumberLines(text, opts.Start, opts.Format, utils.ParallelOptions{}), nil
+	})
+	Default.Describe("numberLines", ParamsOf(numberDefaults))
+}
@@ -0,0 +1,153 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"toolkit-backend/utils"
+)
+
+type Operation func(text string, params Params) (string, error)
+
+type ContextOperation func(ctx context.Context, text string, params Params) (string, error)
+
+type Step struct {
+	Operation string `json:"operation"`
+	Params    Params `json:"params,omitempty"`
+	// When skips the step unless the input satisfies it; Lines restricts the
+	// step to the lines that satisfy it. See condition.go for the syntax.
+	When  string `json:"when,omitempty"`
+	Lines string `json:"lines,omitempty"`
+}
+
+type Registry struct {
+	mu    sync.RWMutex
+	ops   map[string]ContextOperation
+	specs map[string][]ParamSpec
+
+	costs  map[string]CostModel
+	budget Budget
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		ops:   make(map[string]ContextOperation),
+		specs: make(map[string][]ParamSpec),
+		costs: make(map[string]CostModel),
+	}
+}
+
+func (r *Registry) Register(name string, op Operation) {
+	r.RegisterContext(name, func(_ context.Context, text string, params Params) (string, error) {
+		return op(text, params)
+	})
+}
+
+func (r *Registry) RegisterContext(name string, op ContextOperation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops[name] = op
+}
+
+func (r *Registry) Lookup(name string) (ContextOperation, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	op, ok := r.ops[name]
+	return op, ok
+}
+
+func (r *Registry) Describe(name string, params []ParamSpec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.specs[name] = params
+}
+
+func (r *Registry) Catalog() []OperationSpec {
+	names := r.Names()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	catalog := make([]OperationSpec, len(names))
+	for i, name := range names {
+		params := r.specs[name]
+		if params == nil {
+			params = []ParamSpec{}
+		}
+		catalog[i] = OperationSpec{Name: name, Params: params}
+	}
+	return catalog
+}
+
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.ops))
+	for name := range r.ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Registry) Validate(steps []Step) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("pipeline has no steps")
+	}
+	for i, step := range steps {
+		if _, ok := r.Lookup(step.Operation); !ok {
+			return fmt.Errorf("step %d: unknown operation %q", i+1, step.Operation)
+		}
+		r.mu.RLock()
+		specs := r.specs[step.Operation]
+		r.mu.RUnlock()
+		if err := checkParams(specs, step.Params); err != nil {
+			return fmt.Errorf("step %d (%s): %w", i+1, step.Operation, err)
+		}
+		if _, _, err := step.compileConditions(utils.Limits{}); err != nil {
+			return fmt.Errorf("step %d (%s): %w", i+1, step.Operation, err)
+		}
+	}
+	return nil
+}
+
+func (r *Registry) Run(text string, steps []Step) (string, error) {
+	return r.RunContext(context.Background(), text, steps)
+}
+
+func (r *Registry) RunContext(ctx context.Context, text string, steps []Step) (string, error) {
+	if err := r.Validate(steps); err != nil {
+		return "", err
+	}
+	if err := r.checkBudget(steps, text); err != nil {
+		return "", err
+	}
+	limits, inputBytes := utils.LimitsFrom(ctx), int64(len(text))
+	for i, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		op, _ := r.Lookup(step.Operation)
+		when, lines, err := step.compileConditions(limits)
+		if err != nil {
+			return "", fmt.Errorf("step %d (%s): %w", i+1, step.Operation, err)
+		}
+		if when != nil && !when(textEnv(text)) {
+			continue
+		}
+		var out string
+		if lines != nil {
+			out, err = applyToLines(ctx, op, text, step.Params, lines)
+		} else {
+			out, err = op(ctx, text, step.Params)
+		}
+		if err != nil {
+			return "", fmt.Errorf("step %d (%s): %w", i+1, step.Operation, err)
+		}
+		if err := limits.CheckExpansion(inputBytes, int64(len(out))); err != nil {
+			return "", fmt.Errorf("step %d (%s): %w", i+1, step.Operation, err)
+		}
+		text = out
+	}
+	return text, nil
+}
@@ -0,0 +1,227 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"toolkit-backend/utils"
+)
+
+var ErrOverBudget = fmt.Errorf("%w: pipeline exceeds its budget", utils.ErrInputTooLarge)
+
+// Size describes a text without the text itself, so a job can be planned from
+// metadata alone
+type Size struct {
+	Bytes int64 `json:"bytes"`
+	Lines int64 `json:"lines"`
+}
+
+func SizeOf(text string) Size {
+	return Size{Bytes: int64(len(text)), Lines: int64(strings.Count(text, "\n") + 1)}
+}
+
+// Cost is a deliberately rough linear model of one operation. Estimates err on
+// the large side: conditional steps are planned as if they always run.
+type Cost struct {
+	Output       float64 // output bytes per input byte
+	PerLine      int64   // bytes added per line
+	LineFactor   float64 // output lines per input line
+	Memory       float64 // working memory per input byte, on top of input and output
+	NanosPerByte float64
+	Sorts        bool // adds an n log n term over the lines
+}
+
+// CostModel adjusts the default cost of an operation for its parameters
+type CostModel func(c Cost, p Params) Cost
+
+var defaultCost = Cost{Output: 1, LineFactor: 1, Memory: 1, NanosPerByte: 10}
+
+const nanosPerComparison = 60
+
+// Budget caps what a single run may use; zero fields are unlimited
+type Budget struct {
+	MaxInputBytes  int64         `json:"maxInputBytes"`
+	MaxOutputBytes int64         `json:"maxOutputBytes"`
+	MaxMemoryBytes int64         `json:"maxMemoryBytes"`
+	MaxDuration    time.Duration `json:"maxDuration"`
+}
+
+type StepEstimate struct {
+	Step        int     `json:"step"`
+	Operation   string  `json:"operation"`
+	Conditional bool    `json:"conditional,omitempty"`
+	Input       Size    `json:"input"`
+	Output      Size    `json:"output"`
+	MemoryBytes int64   `json:"memoryBytes"`
+	Millis      float64 `json:"millis"`
+}
+
+type Plan struct {
+	Steps           []StepEstimate `json:"steps"`
+	Input           Size           `json:"input"`
+	Output          Size           `json:"output"`
+	PeakMemoryBytes int64          `json:"peakMemoryBytes"`
+	Millis          float64        `json:"millis"`
+	Budget          Budget         `json:"budget"`
+	Exceeded        []string       `json:"exceeded"`
+	Accepted        bool           `json:"accepted"`
+}
+
+func (r *Registry) SetCost(name string, model CostModel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.costs[name] = model
+}
+
+func (r *Registry) SetBudget(b Budget) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.budget = b
+}
+
+func (r *Registry) Budget() Budget {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.budget
+}
+
+// Plan estimates a run over input of the given size without running anything
+func (r *Registry) Plan(steps []Step, input Size) (Plan, error) {
+	if err := r.Validate(steps); err != nil {
+		return Plan{}, err
+	}
+	if input.Bytes < 0 || input.Lines < 0 {
+		return Plan{}, fmt.Errorf("%w: input size must not be negative", utils.ErrInvalidOption)
+	}
+	if input.Lines == 0 && input.Bytes > 0 {
+		input.Lines = 1
+	}
+
+	plan := Plan{Input: input, Budget: r.Budget(), Steps: make([]StepEstimate, len(steps)), Exceeded: []string{}}
+	size := input
+	for i, step := range steps {
+		r.mu.RLock()
+		model := r.costs[step.Operation]
+		r.mu.RUnlock()
+		c := defaultCost
+		if model != nil {
+			c = model(c, step.Params)
+		}
+
+		out := Size{
+			Bytes: max(0, int64(float64(size.Bytes)*c.Output)+c.PerLine*size.Lines),
+			Lines: int64(math.Ceil(float64(size.Lines) * c.LineFactor)),
+		}
+		nanos := float64(size.Bytes) * c.NanosPerByte
+		if c.Sorts && size.Lines > 1 {
+			nanos += float64(size.Lines) * math.Log2(float64(size.Lines)) * nanosPerComparison
+		}
+		est := StepEstimate{
+			Step:        i + 1,
+			Operation:   step.Operation,
+			Conditional: step.When != "" || step.Lines != "",
+			Input:       size,
+			Output:      out,
+			MemoryBytes: size.Bytes + out.Bytes + int64(float64(size.Bytes)*c.Memory),
+			Millis:      round2(nanos / 1e6),
+		}
+		plan.Steps[i] = est
+		plan.PeakMemoryBytes = max(plan.PeakMemoryBytes, est.MemoryBytes)
+		plan.Millis += est.Millis
+		size = out
+	}
+	plan.Output = size
+	plan.Millis = round2(plan.Millis)
+
+	b := plan.Budget
+	if b.MaxInputBytes > 0 && input.Bytes > b.MaxInputBytes {
+		plan.Exceeded = append(plan.Exceeded, fmt.Sprintf("input of %d bytes exceeds %d", input.Bytes, b.MaxInputBytes))
+	}
+	if b.MaxOutputBytes > 0 && plan.Output.Bytes > b.MaxOutputBytes {
+		plan.Exceeded = append(plan.Exceeded, fmt.Sprintf("estimated output of %d bytes exceeds %d", plan.Output.Bytes, b.MaxOutputBytes))
+	}
+	if b.MaxMemoryBytes > 0 && plan.PeakMemoryBytes > b.MaxMemoryBytes {
+		plan.Exceeded = append(plan.Exceeded, fmt.Sprintf("estimated memory of %d bytes exceeds %d", plan.PeakMemoryBytes, b.MaxMemoryBytes))
+	}
+	if b.MaxDuration > 0 && time.Duration(plan.Millis*float64(time.Millisecond)) > b.MaxDuration {
+		plan.Exceeded = append(plan.Exceeded, fmt.Sprintf("estimated %.0fms exceeds %s", plan.Millis, b.MaxDuration))
+	}
+	plan.Accepted = len(plan.Exceeded) == 0
+	return plan, nil
+}
+
+func (r *Registry) checkBudget(steps []Step, text string) error {
+	if r.Budget() == (Budget{}) {
+		return nil
+	}
+	plan, err := r.Plan(steps, SizeOf(text))
+	if err != nil {
+		return err
+	}
+	if !plan.Accepted {
+		return fmt.Errorf("%w: %s", ErrOverBudget, strings.Join(plan.Exceeded, "; "))
+	}
+	return nil
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+func init() {
+	sorting := func(c Cost, _ Params) Cost {
+		c.Sorts, c.Memory = true, 2
+		return c
+	}
+	for _, name := range []string{"sort", "sortParagraphs", "sortWords", "sortLogs", "sampleLines"} {
+		Default.SetCost(name, sorting)
+	}
+	scanning := func(c Cost, _ Params) Cost {
+		c.NanosPerByte, c.Memory = 50, 2
+		return c
+	}
+	for _, name := range []string{"findReplace", "regexReplace", "pseudonymize", "redactSecrets", "matchPhonetic", "fixCapitalization", "transliterate"} {
+		Default.SetCost(name, scanning)
+	}
+	Default.SetCost("dedupe", func(c Cost, _ Params) Cost {
+		c.Memory = 2
+		return c
+	})
+	grows := func(factor float64) CostModel {
+		return func(c Cost, _ Params) Cost {
+			c.Output = factor
+			return c
+		}
+	}
+	// worst cases: every byte escaped, every ASCII letter widened to three bytes
+	Default.SetCost("encodeQuotedPrintable", grows(3))
+	Default.SetCost("encodeMimeHeader", grows(3))
+	Default.SetCost("toFullWidth", grows(3))
+	Default.SetCost("visualizeWhitespace", grows(3))
+	Default.SetCost("verticalText", grows(2))
+	for _, name := range []string{"alignColumns", "newspaperColumns", "convertFixedWidth"} {
+		Default.SetCost(name, func(c Cost, _ Params) Cost {
+			c.Output, c.Memory = 1.5, 2
+			return c
+		})
+	}
+	Default.SetCost("extractLinks", func(c Cost, _ Params) Cost {
+		c.Output, c.NanosPerByte = 0.5, 50
+		return c
+	})
+	Default.SetCost("numberLines", func(c Cost, p Params) Cost {
+		format := p.String("format", "{n}. ")
+		c.PerLine = 8 + int64(len(format))
+		c.Output = max(1, float64(strings.Count(format, "{line}")))
+		return c
+	})
+	affix := func(c Cost, p Params) Cost {
+		c.PerLine = int64(len(p.String("text", "")))
+		return c
+	}
+	for _, name := range []string{"prependLines", "appendLines", "insertAtColumn"} {
+		Default.SetCost(name, affix)
+	}
+}
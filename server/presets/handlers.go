@@ -0,0 +1,108 @@
+package presets
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/auth"
+	"toolkit-backend/pipeline"
+	"toolkit-backend/utils"
+)
+
+type SaveRequest struct {
+	Description string          `json:"description"`
+	Steps       []pipeline.Step `json:"steps" binding:"required"`
+}
+
+type RunRequest struct {
+	Text string `json:"text"`
+}
+
+func owner(c *gin.Context) (string, bool) {
+	key, ok := auth.CurrentKey(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "presets require an API key"})
+		return "", false
+	}
+	return key.ID, true
+}
+
+var errorStatuses = []utils.ErrorStatus{
+	{Err: ErrNotFound, Status: http.StatusNotFound},
+}
+
+func statusFor(err error) int {
+	return utils.StatusFor(err, http.StatusBadRequest, errorStatuses...)
+}
+
+func RegisterRoutes(r gin.IRouter, s *Store) {
+	r.GET("/presets", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"presets": s.List(id)})
+	})
+
+	r.GET("/presets/:name", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		preset, err := s.Get(id, c.Param("name"))
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, preset)
+	})
+
+	r.PUT("/presets/:name", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		var req SaveRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		preset, err := s.Save(id, Preset{Name: c.Param("name"), Description: req.Description, Steps: req.Steps})
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, preset)
+	})
+
+	r.DELETE("/presets/:name", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		if err := s.Delete(id, c.Param("name")); err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	r.POST("/presets/:name/run", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		var req RunRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := s.Run(c.Request.Context(), id, c.Param("name"), req.Text)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"result": result})
+	})
+}
@@ -0,0 +1,109 @@
+package presets
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"toolkit-backend/pipeline"
+)
+
+var (
+	ErrNotFound    = errors.New("preset not found")
+	ErrInvalidName = errors.New("preset names may contain letters, digits, '-', '_' and '.' only")
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type Preset struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Steps       []pipeline.Step `json:"steps"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+type Store struct {
+	mu       sync.RWMutex
+	registry *pipeline.Registry
+	owners   map[string]map[string]*Preset
+}
+
+func NewStore(registry *pipeline.Registry) *Store {
+	return &Store{registry: registry, owners: make(map[string]map[string]*Preset)}
+}
+
+func (s *Store) Save(owner string, preset Preset) (Preset, error) {
+	if !validName.MatchString(preset.Name) {
+		return Preset{}, ErrInvalidName
+	}
+	if err := s.registry.Validate(preset.Steps); err != nil {
+		return Preset{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	presets, ok := s.owners[owner]
+	if !ok {
+		presets = make(map[string]*Preset)
+		s.owners[owner] = presets
+	}
+
+	now := time.Now()
+	preset.UpdatedAt = now
+	if existing, ok := presets[preset.Name]; ok {
+		preset.CreatedAt = existing.CreatedAt
+	} else {
+		preset.CreatedAt = now
+	}
+	presets[preset.Name] = &preset
+	return preset, nil
+}
+
+func (s *Store) Get(owner, name string) (Preset, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	preset, ok := s.owners[owner][name]
+	if !ok {
+		return Preset{}, ErrNotFound
+	}
+	return *preset, nil
+}
+
+func (s *Store) List(owner string) []Preset {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]Preset, 0, len(s.owners[owner]))
+	for _, p := range s.owners[owner] {
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func (s *Store) Delete(owner, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.owners[owner][name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.owners[owner], name)
+	return nil
+}
+
+// Run runs the preset under ctx, normally the request's, so its limits,
+// deadline and cancellation apply to every step
+func (s *Store) Run(ctx context.Context, owner, name, text string) (string, error) {
+	preset, err := s.Get(owner, name)
+	if err != nil {
+		return "", err
+	}
+	return s.registry.RunContext(ctx, text, preset.Steps)
+}
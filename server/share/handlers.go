@@ -0,0 +1,67 @@
+package share
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+type SaveRequest struct {
+	Content     string `json:"content" binding:"required"`
+	ContentType string `json:"contentType"`
+	TTLSeconds  int    `json:"ttlSeconds"`
+	Password    string `json:"password"`
+}
+
+var errorStatuses = []utils.ErrorStatus{
+	{Err: ErrNotFound, Status: http.StatusNotFound},
+	{Err: ErrExpired, Status: http.StatusNotFound},
+	{Err: ErrTooLarge, Status: http.StatusRequestEntityTooLarge},
+	{Err: ErrPasswordRequired, Status: http.StatusUnauthorized},
+	{Err: ErrWrongPassword, Status: http.StatusUnauthorized},
+}
+
+func statusFor(err error) int {
+	return utils.StatusFor(err, http.StatusInternalServerError, errorStatuses...)
+}
+
+func RegisterRoutes(r gin.IRouter, s *Service) {
+	r.POST("/shares", func(c *gin.Context) {
+		var req SaveRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.ContentType == "" {
+			req.ContentType = "text/plain"
+		}
+
+		entry, err := s.Save(req.Content, req.ContentType, time.Duration(req.TTLSeconds)*time.Second, req.Password)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{
+			"token":     entry.Token,
+			"expiresAt": entry.ExpiresAt,
+			"protected": len(entry.PasswordHash) > 0,
+		})
+	})
+
+	r.GET("/shares/:token", func(c *gin.Context) {
+		entry, err := s.Load(c.Param("token"), c.GetHeader("X-Share-Password"))
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"content":     entry.Content,
+			"contentType": entry.ContentType,
+			"createdAt":   entry.CreatedAt,
+			"expiresAt":   entry.ExpiresAt,
+		})
+	})
+}
@@ -0,0 +1,146 @@
+package share
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrExpired          = errors.New("share expired")
+	ErrTooLarge         = errors.New("share content too large")
+	ErrPasswordRequired = errors.New("share is password protected")
+	ErrWrongPassword    = errors.New("incorrect share password")
+)
+
+const tokenAlphabet = "23456789abcdefghjkmnpqrstuvwxyzABCDEFGHJKMNPQRSTUVWXYZ"
+
+type Config struct {
+	DefaultTTL  time.Duration `json:"defaultTtl"`
+	MaxTTL      time.Duration `json:"maxTtl"`
+	MaxSize     int           `json:"maxSize"`
+	TokenLength int           `json:"tokenLength"`
+}
+
+type Service struct {
+	store Store
+	cfg   Config
+}
+
+func NewService(store Store, cfg Config) *Service {
+	if cfg.DefaultTTL <= 0 {
+		cfg.DefaultTTL = 24 * time.Hour
+	}
+	if cfg.MaxTTL <= 0 {
+		cfg.MaxTTL = 30 * 24 * time.Hour
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = 1 << 20
+	}
+	if cfg.TokenLength <= 0 {
+		cfg.TokenLength = 10
+	}
+	return &Service{store: store, cfg: cfg}
+}
+
+func newToken(n int) (string, error) {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(tokenAlphabet)))
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = tokenAlphabet[idx.Int64()]
+	}
+	return string(b), nil
+}
+
+func (s *Service) Save(content, contentType string, ttl time.Duration, password string) (*Entry, error) {
+	if len(content) > s.cfg.MaxSize {
+		return nil, ErrTooLarge
+	}
+	if ttl <= 0 {
+		ttl = s.cfg.DefaultTTL
+	}
+	if ttl > s.cfg.MaxTTL {
+		ttl = s.cfg.MaxTTL
+	}
+
+	token, err := newToken(s.cfg.TokenLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %v", err)
+	}
+
+	now := time.Now()
+	entry := &Entry{
+		Token:       token,
+		Content:     content,
+		ContentType: contentType,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %v", err)
+		}
+		entry.PasswordHash = hash
+	}
+
+	if err := s.store.Put(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *Service) Load(token, password string) (*Entry, error) {
+	entry, err := s.store.Get(token)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Expired(time.Now()) {
+		s.store.Delete(token)
+		return nil, ErrExpired
+	}
+	if len(entry.PasswordHash) > 0 {
+		if password == "" {
+			return nil, ErrPasswordRequired
+		}
+		if bcrypt.CompareHashAndPassword(entry.PasswordHash, []byte(password)) != nil {
+			return nil, ErrWrongPassword
+		}
+	}
+	return entry, nil
+}
+
+func (s *Service) Delete(token string) error {
+	return s.store.Delete(token)
+}
+
+func (s *Service) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			removed, err := s.store.DeleteExpired(now)
+			if err != nil {
+				slog.ErrorContext(ctx, "share cleanup failed", "error", err)
+				continue
+			}
+			if removed > 0 {
+				slog.InfoContext(ctx, "removed expired shares", "count", removed)
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package share
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var ErrNotFound = errors.New("share not found")
+
+type Entry struct {
+	Token        string    `json:"token"`
+	Content      string    `json:"content"`
+	ContentType  string    `json:"contentType"`
+	PasswordHash []byte    `json:"passwordHash,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+func (e *Entry) Expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
+
+type Store interface {
+	Put(entry *Entry) error
+	Get(token string) (*Entry, error)
+	Delete(token string) error
+	DeleteExpired(now time.Time) (int, error)
+}
+
+type MemoryStore struct {
+	mu      sync.RWMutex
+	entries map[string]*Entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*Entry)}
+}
+
+func (s *MemoryStore) Put(entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[entry.Token] = entry
+	return nil
+}
+
+func (s *MemoryStore) Get(token string) (*Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.entries[token]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return entry, nil
+}
+
+func (s *MemoryStore) Delete(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, token)
+	return nil
+}
+
+func (s *MemoryStore) DeleteExpired(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := 0
+	for token, entry := range s.entries {
+		if entry.Expired(now) {
+			delete(s.entries, token)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create share directory: %v", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(token string) string {
+	return filepath.Join(s.dir, token+".json")
+}
+
+func (s *FileStore) Put(entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp := s.path(entry.Token) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write share: %v", err)
+	}
+	return os.Rename(tmp, s.path(entry.Token))
+}
+
+func (s *FileStore) Get(token string) (*Entry, error) {
+	data, err := os.ReadFile(s.path(token))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read share: %v", err)
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("corrupt share %s: %v", token, err)
+	}
+	return &entry, nil
+}
+
+func (s *FileStore) Delete(token string) error {
+	err := os.Remove(s.path(token))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) DeleteExpired(now time.Time) (int, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, f := range files {
+		token := filepath.Base(f[:len(f)-len(".json")])
+		entry, err := s.Get(token)
+		if err != nil {
+			continue
+		}
+		if entry.Expired(now) {
+			if err := s.Delete(token); err == nil {
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,38 @@
+package snippets
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/auth"
+)
+
+var ErrUnavailable = errors.New("snippet sets require an API key")
+
+type resolverKey struct{}
+
+type resolver func(name string) (Set, error)
+
+// Middleware lets operations further down the request resolve the caller's
+// snippet sets by name. It has to run after auth.Authenticate.
+func Middleware(s *Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key, ok := auth.CurrentKey(c); ok {
+			ctx := context.WithValue(c.Request.Context(), resolverKey{}, resolver(func(name string) (Set, error) {
+				return s.Get(key.ID, name)
+			}))
+			c.Request = c.Request.WithContext(ctx)
+		}
+		c.Next()
+	}
+}
+
+func Resolve(ctx context.Context, name string) (Set, error) {
+	r, ok := ctx.Value(resolverKey{}).(resolver)
+	if !ok {
+		return Set{}, ErrUnavailable
+	}
+	return r(name)
+}
@@ -0,0 +1,141 @@
+package snippets
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"toolkit-backend/utils"
+)
+
+// {{name}}, {{name:format}} and {{name|default}}
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z][A-Za-z0-9_]*)(?::([^}|]*))?(?:\|([^}]*))?\s*\}\}`)
+
+// date formats are written with the usual tokens rather than Go layouts;
+// longer tokens come first so that MMMM is not read as MM twice
+var dateTokens = strings.NewReplacer(
+	"YYYY", "2006", "YY", "06",
+	"MMMM", "January", "MMM", "Jan", "MM", "01",
+	"dddd", "Monday", "ddd", "Mon", "DD", "02",
+	"HH", "15", "hh", "03", "mm", "04", "ss", "05", "A", "PM",
+)
+
+var defaultFormats = map[string]string{
+	"date":     "YYYY-MM-DD",
+	"time":     "HH:mm",
+	"datetime": "YYYY-MM-DD HH:mm",
+	"year":     "YYYY",
+	"month":    "MM",
+	"day":      "DD",
+	"weekday":  "dddd",
+}
+
+type Options struct {
+	// Variables fill the placeholders of the same name and take precedence
+	// over the built-in date and time variables
+	Variables map[string]string `json:"variables"`
+	Timezone  string            `json:"timezone"`
+	Now       time.Time         `json:"-"`
+}
+
+// Expand replaces every abbreviation of the set that stands on its own (not
+// inside a longer word) with its expansion, and returns how many it replaced.
+// Placeholders without a value or default are left in place. The output is
+// held to the expansion limit in ctx as it is written.
+func Expand(ctx context.Context, text string, set Set, opts Options) (string, int, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if opts.Timezone != "" {
+		loc, err := time.LoadLocation(opts.Timezone)
+		if err != nil {
+			return "", 0, fmt.Errorf("%w %q", ErrInvalidTimezone, opts.Timezone)
+		}
+		now = now.In(loc)
+	}
+	if len(set.Snippets) == 0 {
+		return text, 0, nil
+	}
+
+	bySnippet := make(map[string]Snippet, len(set.Snippets))
+	alternatives := make([]string, 0, len(set.Snippets))
+	for _, sn := range set.Snippets {
+		bySnippet[sn.Abbreviation] = sn
+		alternatives = append(alternatives, regexp.QuoteMeta(sn.Abbreviation))
+	}
+	// the leftmost alternative wins, so the longest abbreviations go first
+	sort.Slice(alternatives, func(i, j int) bool { return len(alternatives[i]) > len(alternatives[j]) })
+	pattern := regexp.MustCompile(strings.Join(alternatives, "|"))
+
+	limits := utils.LimitsFrom(ctx)
+	var b strings.Builder
+	count, last := 0, 0
+	for _, m := range pattern.FindAllStringIndex(text, -1) {
+		if err := ctx.Err(); err != nil {
+			return "", 0, err
+		}
+		abbr := text[m[0]:m[1]]
+		first, _ := utf8.DecodeRuneInString(abbr)
+		final, _ := utf8.DecodeLastRuneInString(abbr)
+		before, _ := utf8.DecodeLastRuneInString(text[:m[0]])
+		after, _ := utf8.DecodeRuneInString(text[m[1]:])
+		if isWord(first) && m[0] > 0 && isWord(before) || isWord(final) && m[1] < len(text) && isWord(after) {
+			continue
+		}
+		b.WriteString(text[last:m[0]])
+		b.WriteString(fill(bySnippet[abbr].Expansion, opts.Variables, now))
+		last = m[1]
+		count++
+		// a short abbreviation can stand for a long expansion, so stop
+		// before the whole output is built
+		if err := limits.CheckExpansion(int64(len(text)), int64(b.Len())); err != nil {
+			return "", 0, err
+		}
+	}
+	b.WriteString(text[last:])
+	return b.String(), count, nil
+}
+
+// ExpandSnippets expands the caller's snippet set of the given name
+func ExpandSnippets(ctx context.Context, text, setName string, opts Options) (string, error) {
+	set, err := Resolve(ctx, setName)
+	if err != nil {
+		return "", err
+	}
+	out, _, err := Expand(ctx, text, set, opts)
+	return out, err
+}
+
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func fill(expansion string, vars map[string]string, now time.Time) string {
+	return placeholderPattern.ReplaceAllStringFunc(expansion, func(m string) string {
+		sub := placeholderPattern.FindStringSubmatch(m)
+		name, format, def := sub[1], strings.TrimSpace(sub[2]), sub[3]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		if name == "timestamp" {
+			return strconv.FormatInt(now.Unix(), 10)
+		}
+		if layout, ok := defaultFormats[name]; ok {
+			if format != "" {
+				layout = format
+			}
+			return now.Format(dateTokens.Replace(layout))
+		}
+		if strings.Contains(m, "|") {
+			return def
+		}
+		return m
+	})
+}
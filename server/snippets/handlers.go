@@ -0,0 +1,147 @@
+package snippets
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/auth"
+	"toolkit-backend/utils"
+)
+
+type SaveRequest struct {
+	Description string    `json:"description"`
+	Snippets    []Snippet `json:"snippets"`
+}
+
+type ExpandRequest struct {
+	Text string `json:"text"`
+	Options
+}
+
+func owner(c *gin.Context) (string, bool) {
+	key, ok := auth.CurrentKey(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "snippet sets require an API key"})
+		return "", false
+	}
+	return key.ID, true
+}
+
+var errorStatuses = []utils.ErrorStatus{
+	{Err: ErrNotFound, Status: http.StatusNotFound},
+	{Err: ErrTooLarge, Status: http.StatusRequestEntityTooLarge},
+	{Err: ErrExpansionTooLarge, Status: http.StatusRequestEntityTooLarge},
+}
+
+func statusFor(err error) int {
+	return utils.StatusFor(err, http.StatusBadRequest, errorStatuses...)
+}
+
+func RegisterRoutes(r gin.IRouter, s *Store) {
+	r.GET("/snippets", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"sets": s.List(id)})
+	})
+
+	r.GET("/snippets/:name", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		set, err := s.Get(id, c.Param("name"))
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, set)
+	})
+
+	r.PUT("/snippets/:name", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		var req SaveRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		set, err := s.Save(id, Set{Name: c.Param("name"), Description: req.Description, Snippets: req.Snippets})
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, set)
+	})
+
+	r.PUT("/snippets/:name/entries/:abbreviation", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		var sn Snippet
+		if err := c.ShouldBindJSON(&sn); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		sn.Abbreviation = c.Param("abbreviation")
+		set, err := s.PutSnippet(id, c.Param("name"), sn)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, set)
+	})
+
+	r.DELETE("/snippets/:name/entries/:abbreviation", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		set, err := s.RemoveSnippet(id, c.Param("name"), c.Param("abbreviation"))
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, set)
+	})
+
+	r.DELETE("/snippets/:name", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		if err := s.Delete(id, c.Param("name")); err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	r.POST("/snippets/:name/expand", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		var req ExpandRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		set, err := s.Get(id, c.Param("name"))
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		result, count, err := Expand(c.Request.Context(), req.Text, set, req.Options)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"result": result, "expanded": count})
+	})
+}
@@ -0,0 +1,187 @@
+package snippets
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	MaxSnippets     = 1000
+	MaxExpansionLen = 16 << 10
+	// MaxSets is how many snippet sets one key may keep
+	MaxSets = 100
+)
+
+var (
+	ErrNotFound          = errors.New("snippet set not found")
+	ErrInvalidName       = errors.New("snippet set names may contain letters, digits, '-', '_' and '.' only")
+	ErrInvalidSnippet    = errors.New("invalid snippet")
+	ErrTooLarge          = errors.New("snippet set limit exceeded")
+	ErrDuplicateSnippet  = errors.New("duplicate abbreviation")
+	ErrExpansionTooLarge = fmt.Errorf("expansions are limited to %d bytes", MaxExpansionLen)
+	ErrInvalidTimezone   = errors.New("unknown timezone")
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Snippet maps an abbreviation to the text it expands to. The expansion may
+// hold placeholders, see Expand.
+type Snippet struct {
+	Abbreviation string `json:"abbreviation"`
+	Expansion    string `json:"expansion"`
+	Description  string `json:"description,omitempty"`
+}
+
+type Set struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Snippets    []Snippet `json:"snippets"`
+	Count       int       `json:"count"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type Store struct {
+	mu     sync.RWMutex
+	owners map[string]map[string]*Set
+}
+
+func NewStore() *Store {
+	return &Store{owners: make(map[string]map[string]*Set)}
+}
+
+func validate(snippets []Snippet) error {
+	if len(snippets) > MaxSnippets {
+		return fmt.Errorf("%w: snippet sets hold at most %d snippets", ErrTooLarge, MaxSnippets)
+	}
+	seen := make(map[string]bool, len(snippets))
+	for _, sn := range snippets {
+		switch {
+		case sn.Abbreviation == "" || len(sn.Abbreviation) > 64 || strings.IndexFunc(sn.Abbreviation, unicode.IsSpace) >= 0:
+			return fmt.Errorf("%w: abbreviation %q must be 1-64 characters without spaces", ErrInvalidSnippet, sn.Abbreviation)
+		case seen[sn.Abbreviation]:
+			return fmt.Errorf("%w %q", ErrDuplicateSnippet, sn.Abbreviation)
+		case len(sn.Expansion) > MaxExpansionLen:
+			return fmt.Errorf("%s: %w", sn.Abbreviation, ErrExpansionTooLarge)
+		}
+		seen[sn.Abbreviation] = true
+	}
+	return nil
+}
+
+func (s *Store) Save(owner string, set Set) (Set, error) {
+	if !validName.MatchString(set.Name) {
+		return Set{}, ErrInvalidName
+	}
+	if set.Snippets == nil {
+		set.Snippets = []Snippet{}
+	}
+	if err := validate(set.Snippets); err != nil {
+		return Set{}, err
+	}
+	set.Count = len(set.Snippets)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sets, ok := s.owners[owner]
+	if !ok {
+		sets = make(map[string]*Set)
+		s.owners[owner] = sets
+	}
+
+	now := time.Now()
+	set.UpdatedAt = now
+	if existing, ok := sets[set.Name]; ok {
+		set.CreatedAt = existing.CreatedAt
+	} else if len(sets) >= MaxSets {
+		return Set{}, fmt.Errorf("%w: at most %d snippet sets per key", ErrTooLarge, MaxSets)
+	} else {
+		set.CreatedAt = now
+	}
+	sets[set.Name] = &set
+	return set, nil
+}
+
+// PutSnippet adds a snippet to an existing set or replaces the one with the
+// same abbreviation
+func (s *Store) PutSnippet(owner, name string, sn Snippet) (Set, error) {
+	set, err := s.Get(owner, name)
+	if err != nil {
+		return Set{}, err
+	}
+	replaced := false
+	for i, existing := range set.Snippets {
+		if existing.Abbreviation == sn.Abbreviation {
+			set.Snippets[i] = sn
+			replaced = true
+		}
+	}
+	if !replaced {
+		set.Snippets = append(set.Snippets, sn)
+	}
+	return s.Save(owner, set)
+}
+
+func (s *Store) RemoveSnippet(owner, name, abbreviation string) (Set, error) {
+	set, err := s.Get(owner, name)
+	if err != nil {
+		return Set{}, err
+	}
+	kept := make([]Snippet, 0, len(set.Snippets))
+	for _, sn := range set.Snippets {
+		if sn.Abbreviation != abbreviation {
+			kept = append(kept, sn)
+		}
+	}
+	if len(kept) == len(set.Snippets) {
+		return Set{}, fmt.Errorf("%w: no snippet %q", ErrNotFound, abbreviation)
+	}
+	set.Snippets = kept
+	return s.Save(owner, set)
+}
+
+func (s *Store) Get(owner, name string) (Set, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set, ok := s.owners[owner][name]
+	if !ok {
+		return Set{}, ErrNotFound
+	}
+	out := *set
+	out.Snippets = append([]Snippet(nil), set.Snippets...)
+	return out, nil
+}
+
+// List leaves out the snippets themselves; fetch a set by name to read them
+func (s *Store) List(owner string) []Set {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]Set, 0, len(s.owners[owner]))
+	for _, set := range s.owners[owner] {
+		summary := *set
+		summary.Snippets = []Snippet{}
+		out = append(out, summary)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (s *Store) Delete(owner, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.owners[owner][name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.owners[owner], name)
+	return nil
+}
@@ -0,0 +1,231 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"toolkit-backend/utils"
+)
+
+var (
+	ErrSchemeNotAllowed = errors.New("URL scheme not allowed")
+	ErrHostNotAllowed   = errors.New("URL host not allowed")
+	ErrTooLarge         = errors.New("remote content exceeds size limit")
+	ErrNotText          = errors.New("remote content is not text")
+)
+
+type FetchConfig struct {
+	AllowedSchemes []string      `json:"allowedSchemes"`
+	AllowedHosts   []string      `json:"allowedHosts"`
+	AllowPrivate   bool          `json:"allowPrivate"`
+	MaxBytes       int64         `json:"maxBytes"`
+	Timeout        time.Duration `json:"timeout"`
+	// MaxRequestBytes bounds the JSON bodies Middleware reads looking for a
+	// source URL
+	MaxRequestBytes int64 `json:"maxRequestBytes"`
+}
+
+type Fetcher struct {
+	cfg    FetchConfig
+	client *http.Client
+}
+
+func NewFetcher(cfg FetchConfig) *Fetcher {
+	if len(cfg.AllowedSchemes) == 0 {
+		cfg.AllowedSchemes = []string{"https"}
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = 10 << 20
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 15 * time.Second
+	}
+	if cfg.MaxRequestBytes <= 0 {
+		cfg.MaxRequestBytes = 10 << 20
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivate {
+		dialer.Control = PublicOnly
+	}
+
+	// no proxy: through one, only the proxy's address would reach the dial
+	// check, never the target's
+	f := &Fetcher{cfg: cfg}
+	f.client = &http.Client{
+		Timeout: cfg.Timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: cfg.Timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			return f.check(req.URL)
+		},
+	}
+	return f
+}
+
+// PublicOnly is a net.Dialer Control that refuses every address IsPublicIP
+// rejects. Checking at dial time covers every address a name resolves to,
+// and redirects too.
+func PublicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+		return ErrHostNotAllowed
+	}
+	return nil
+}
+
+// nonPublic lists the special-purpose ranges (RFC 6890 and friends) a
+// request on a user's behalf must never reach: "this network", private,
+// shared CGNAT space (where some clouds keep their metadata service),
+// loopback, link-local, benchmarking, documentation, multicast and reserved.
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.88.99.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+var (
+	nat64  = netip.MustParsePrefix("64:ff9b::/96")
+	sixTo4 = netip.MustParsePrefix("2002::/16")
+)
+
+// IsPublicIP reports whether ip is a globally routable unicast address.
+// IPv4-mapped, NAT64 and 6to4 addresses are judged by the IPv4 address they
+// carry.
+func IsPublicIP(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	if b := addr.As16(); nat64.Contains(addr) {
+		addr = netip.AddrFrom4([4]byte(b[12:]))
+	} else if sixTo4.Contains(addr) {
+		addr = netip.AddrFrom4([4]byte(b[2:6]))
+	}
+	for _, p := range nonPublic {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *Fetcher) check(u *url.URL) error {
+	allowed := false
+	for _, s := range f.cfg.AllowedSchemes {
+		if strings.EqualFold(u.Scheme, s) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s", ErrSchemeNotAllowed, u.Scheme)
+	}
+
+	if len(f.cfg.AllowedHosts) == 0 {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range f.cfg.AllowedHosts {
+		h = strings.ToLower(h)
+		if host == h || (strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:])) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid source URL: %v", err)
+	}
+	if err := f.check(u); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/*, application/json, application/xml;q=0.9, */*;q=0.1")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch source: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("source returned status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > f.cfg.MaxBytes {
+		return "", ErrTooLarge
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.cfg.MaxBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read source: %v", err)
+	}
+	if int64(len(body)) > f.cfg.MaxBytes {
+		return "", ErrTooLarge
+	}
+
+	if !IsTextContent(resp.Header.Get("Content-Type"), body) {
+		return "", fmt.Errorf("%w: looks like %s", ErrNotText, utils.DetectBinary(body).MIMEType)
+	}
+	return string(body), nil
+}
+
+// IsTextContent goes by the body: a declared text type cannot make binary
+// data text, though a declared image, audio or video type rules it out
+func IsTextContent(declared string, body []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(declared); err == nil {
+		for _, prefix := range []string{"image/", "audio/", "video/"} {
+			if strings.HasPrefix(mediaType, prefix) {
+				return false
+			}
+		}
+	}
+	return !utils.DetectBinary(body).Binary
+}
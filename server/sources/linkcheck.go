@@ -0,0 +1,142 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"toolkit-backend/utils"
+)
+
+const (
+	MaxCheckedLinks        = 200
+	DefaultLinkTimeout     = 10 * time.Second
+	DefaultLinkConcurrency = 8
+	maxLinkConcurrency     = 32
+)
+
+type LinkCheckOptions struct {
+	TimeoutMillis int `json:"timeoutMillis"`
+	Concurrency   int `json:"concurrency"`
+}
+
+type LinkStatus struct {
+	URL        string `json:"url"`
+	Status     int    `json:"status,omitempty"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+	Redirected string `json:"redirected,omitempty"`
+	Millis     int64  `json:"millis"`
+}
+
+// the checker fetches user-supplied URLs, so it must not reach into the server's own network
+var linkClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext:           (&net.Dialer{Timeout: 5 * time.Second, Control: PublicOnly}).DialContext,
+		MaxIdleConnsPerHost:   4,
+		ResponseHeaderTimeout: 5 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	},
+}
+
+// CheckLinks probes each distinct http(s) URL once. The timeout is a budget for
+// the whole run; links still pending when it runs out report the deadline error.
+func CheckLinks(ctx context.Context, links []utils.Link, opts LinkCheckOptions) ([]LinkStatus, error) {
+	timeout := DefaultLinkTimeout
+	if opts.TimeoutMillis > 0 {
+		timeout = time.Duration(opts.TimeoutMillis) * time.Millisecond
+	}
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultLinkConcurrency
+	}
+	workers = min(workers, maxLinkConcurrency)
+
+	var urls []string
+	seen := make(map[string]bool)
+	for _, l := range links {
+		if !seen[l.URL] {
+			seen[l.URL] = true
+			urls = append(urls, l.URL)
+		}
+	}
+	if len(urls) > MaxCheckedLinks {
+		return nil, fmt.Errorf("%w: at most %d distinct links can be checked, got %d", utils.ErrInputTooLarge, MaxCheckedLinks, len(urls))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	statuses := make([]LinkStatus, len(urls))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(urls); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				statuses[i] = checkLink(ctx, urls[i])
+			}
+		}()
+	}
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return statuses, nil
+}
+
+func checkLink(ctx context.Context, raw string) (status LinkStatus) {
+	status.URL = raw
+	target := raw
+	if strings.HasPrefix(target, "www.") {
+		target = "https://" + target
+	}
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		status.Error = "not an absolute http(s) URL"
+		return status
+	}
+
+	started := time.Now()
+	defer func() { status.Millis = time.Since(started).Milliseconds() }()
+
+	resp, err := fetchLink(ctx, http.MethodHead, u.String())
+	// plenty of servers reject HEAD outright; retry those with GET
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented || resp.StatusCode == http.StatusForbidden) {
+		resp.Body.Close()
+		resp, err = fetchLink(ctx, http.MethodGet, u.String())
+	}
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	resp.Body.Close()
+
+	status.Status = resp.StatusCode
+	status.OK = resp.StatusCode < 400
+	if final := resp.Request.URL.String(); final != u.String() {
+		status.Redirected = final
+	}
+	return status
+}
+
+func fetchLink(ctx context.Context, method, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "text-forge-linkcheck/1.0")
+	return linkClient.Do(req)
+}
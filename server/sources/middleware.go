@@ -0,0 +1,80 @@
+package sources
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/utils"
+)
+
+const SourceURLField = "sourceUrl"
+
+var errorStatuses = []utils.ErrorStatus{
+	{Err: ErrSchemeNotAllowed, Status: http.StatusForbidden},
+	{Err: ErrHostNotAllowed, Status: http.StatusForbidden},
+	{Err: ErrTooLarge, Status: http.StatusRequestEntityTooLarge},
+	{Err: ErrNotText, Status: http.StatusUnsupportedMediaType},
+}
+
+func statusFor(err error) int {
+	return utils.StatusFor(err, http.StatusBadGateway, errorStatuses...)
+}
+
+func Middleware(f *Fetcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost || !strings.HasPrefix(c.ContentType(), "application/json") {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, f.cfg.MaxRequestBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if !bytes.Contains(body, []byte(`"`+SourceURLField+`"`)) {
+			c.Next()
+			return
+		}
+
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			c.Next()
+			return
+		}
+		source, ok := payload[SourceURLField].(string)
+		if !ok || source == "" {
+			c.Next()
+			return
+		}
+
+		text, err := f.Fetch(c.Request.Context(), source)
+		if err != nil {
+			c.AbortWithStatusJSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		delete(payload, SourceURLField)
+		payload["text"] = text
+		rewritten, err := json.Marshal(payload)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(rewritten))
+		c.Request.ContentLength = int64(len(rewritten))
+		c.Next()
+	}
+}
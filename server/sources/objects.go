@@ -0,0 +1,331 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Credentials struct {
+	AccessKeyID     string `json:"accessKeyId,omitempty"`
+	SecretAccessKey string `json:"secretAccessKey,omitempty"`
+	SessionToken    string `json:"sessionToken,omitempty"`
+	Region          string `json:"region,omitempty"`
+	Token           string `json:"token,omitempty"`
+}
+
+// ObjectConfig is the server's object store setup. Endpoints come only from
+// here, never from a request, so callers cannot aim signed requests at
+// internal hosts.
+type ObjectConfig struct {
+	// S3Endpoint and GCSEndpoint replace the public endpoints, for
+	// S3-compatible stores and emulators
+	S3Endpoint  string `json:"s3Endpoint"`
+	GCSEndpoint string `json:"gcsEndpoint"`
+	// AllowPrivate lets connections reach loopback and private addresses,
+	// for a local store in development only
+	AllowPrivate bool          `json:"allowPrivate"`
+	Timeout      time.Duration `json:"timeout"`
+}
+
+// regions and buckets become part of the host a request is sent to, so
+// only the characters real ones use are accepted
+var (
+	validRegion = regexp.MustCompile(`^[a-z0-9-]{1,32}$`)
+	validBucket = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{1,220}[a-z0-9]$`)
+)
+
+type ObjectClient interface {
+	Read(ctx context.Context, bucket, key string, maxBytes int64) ([]byte, error)
+	Write(ctx context.Context, bucket, key string, data []byte, contentType string) error
+}
+
+func ParseObjectURI(uri string) (string, string, string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid object URI: %v", err)
+	}
+	if u.Scheme != "s3" && u.Scheme != "gs" {
+		return "", "", "", fmt.Errorf("%w: %s", ErrSchemeNotAllowed, u.Scheme)
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return "", "", "", fmt.Errorf("object URI must be of the form %s://bucket/key", u.Scheme)
+	}
+	if !validBucket.MatchString(u.Host) {
+		return "", "", "", fmt.Errorf("invalid bucket name %q", u.Host)
+	}
+	return u.Scheme, u.Host, key, nil
+}
+
+func NewObjectClient(scheme string, creds Credentials, cfg ObjectConfig) (ObjectClient, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 60 * time.Second
+	}
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivate {
+		dialer.Control = PublicOnly
+	}
+	client := &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: cfg.Timeout},
+	}
+	switch scheme {
+	case "s3":
+		if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+			return nil, fmt.Errorf("s3 access requires an access key id and secret")
+		}
+		if creds.Region == "" {
+			creds.Region = "us-east-1"
+		}
+		if !validRegion.MatchString(creds.Region) {
+			return nil, fmt.Errorf("invalid s3 region %q", creds.Region)
+		}
+		return &s3Client{creds: creds, endpoint: cfg.S3Endpoint, http: client}, nil
+	case "gs":
+		if creds.Token == "" {
+			return nil, fmt.Errorf("gcs access requires an OAuth access token")
+		}
+		return &gcsClient{creds: creds, endpoint: cfg.GCSEndpoint, http: client}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrSchemeNotAllowed, scheme)
+	}
+}
+
+func ReadObject(ctx context.Context, uri string, creds Credentials, cfg ObjectConfig, maxBytes int64) (string, error) {
+	scheme, bucket, key, err := ParseObjectURI(uri)
+	if err != nil {
+		return "", err
+	}
+	client, err := NewObjectClient(scheme, creds, cfg)
+	if err != nil {
+		return "", err
+	}
+	data, err := client.Read(ctx, bucket, key, maxBytes)
+	if err != nil {
+		return "", err
+	}
+	if !IsTextContent("", data) {
+		return "", ErrNotText
+	}
+	return string(data), nil
+}
+
+func WriteObject(ctx context.Context, uri string, creds Credentials, cfg ObjectConfig, text string) error {
+	scheme, bucket, key, err := ParseObjectURI(uri)
+	if err != nil {
+		return err
+	}
+	client, err := NewObjectClient(scheme, creds, cfg)
+	if err != nil {
+		return err
+	}
+	return client.Write(ctx, bucket, key, []byte(text), "text/plain; charset=utf-8")
+}
+
+// readLimited and checkWrite report only the status: the body of an error
+// response is not ours to hand back to the caller
+func readLimited(resp *http.Response, maxBytes int64) ([]byte, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("object read failed with status %d", resp.StatusCode)
+	}
+	if maxBytes <= 0 {
+		maxBytes = 10 << 20
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %v", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
+
+func checkWrite(resp *http.Response) error {
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("object write failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type s3Client struct {
+	creds    Credentials
+	endpoint string
+	http     *http.Client
+}
+
+func (c *s3Client) objectURL(bucket, key string) string {
+	var escaped []string
+	for _, seg := range strings.Split(key, "/") {
+		escaped = append(escaped, awsEscape(seg))
+	}
+	path := strings.Join(escaped, "/")
+	if c.endpoint != "" {
+		return strings.TrimSuffix(c.endpoint, "/") + "/" + bucket + "/" + path
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, c.creds.Region, path)
+}
+
+func (c *s3Client) Read(ctx context.Context, bucket, key string, maxBytes int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.objectURL(bucket, key), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.sign(req, nil, time.Now().UTC())
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 request failed: %v", err)
+	}
+	return readLimited(resp, maxBytes)
+}
+
+func (c *s3Client) Write(ctx context.Context, bucket, key string, data []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.objectURL(bucket, key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	c.sign(req, data, time.Now().UTC())
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("s3 request failed: %v", err)
+	}
+	return checkWrite(resp)
+}
+
+func (c *s3Client) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.creds.SessionToken)
+	}
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-date":           amzDate,
+		"x-amz-content-sha256": payloadHash,
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		headers["content-type"] = ct
+	}
+	if c.creds.SessionToken != "" {
+		headers["x-amz-security-token"] = c.creds.SessionToken
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + c.creds.Region + "/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonical))
+
+	key := hmacSHA256([]byte("AWS4"+c.creds.SecretAccessKey), date)
+	key = hmacSHA256(key, c.creds.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') || ch == '-' || ch == '_' || ch == '.' || ch == '~' {
+			b.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+type gcsClient struct {
+	creds    Credentials
+	endpoint string
+	http     *http.Client
+}
+
+func (c *gcsClient) base() string {
+	if c.endpoint != "" {
+		return strings.TrimSuffix(c.endpoint, "/")
+	}
+	return "https://storage.googleapis.com"
+}
+
+func (c *gcsClient) Read(ctx context.Context, bucket, key string, maxBytes int64) ([]byte, error) {
+	target := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", c.base(), url.PathEscape(bucket), url.PathEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.creds.Token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gcs request failed: %v", err)
+	}
+	return readLimited(resp, maxBytes)
+}
+
+func (c *gcsClient) Write(ctx context.Context, bucket, key string, data []byte, contentType string) error {
+	target := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s", c.base(), url.PathEscape(bucket), url.QueryEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.creds.Token)
+	req.Header.Set("Content-Type", contentType)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("gcs request failed: %v", err)
+	}
+	return checkWrite(resp)
+}
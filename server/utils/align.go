@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type AlignOptions struct {
+	Delimiter  string `json:"delimiter"`
+	All        bool   `json:"all"`
+	Right      bool   `json:"right"`
+	AttachLeft bool   `json:"attachLeft"`
+	Gap        int    `json:"gap" min:"0" max:"100"`
+}
+
+// MaxAlignGap is the widest gap AlignColumns puts between columns
+const MaxAlignGap = 100
+
+func AlignColumns(text string, opts AlignOptions) string {
+	if opts.Gap <= 0 {
+		opts.Gap = 1
+	}
+	opts.Gap = min(opts.Gap, MaxAlignGap)
+
+	lines := strings.Split(text, "\n")
+	var block []int
+	var rows [][]string
+
+	flush := func() {
+		if len(block) > 1 {
+			for i, line := range formatAligned(rows, opts) {
+				lines[block[i]] = line
+			}
+		}
+		block, rows = block[:0], rows[:0]
+	}
+
+	for i, line := range lines {
+		cells, ok := alignCells(line, opts)
+		if !ok {
+			flush()
+			continue
+		}
+		block = append(block, i)
+		rows = append(rows, cells)
+	}
+	flush()
+
+	return strings.Join(lines, "\n")
+}
+
+func alignCells(line string, opts AlignOptions) ([]string, bool) {
+	if opts.Delimiter == "" {
+		rest := strings.TrimLeftFunc(line, unicode.IsSpace)
+		if rest == "" {
+			return nil, false
+		}
+		cells := strings.Fields(rest)
+		cells[0] = line[:len(line)-len(rest)] + cells[0]
+		return cells, true
+	}
+
+	if !strings.Contains(line, opts.Delimiter) {
+		return nil, false
+	}
+	n := 2
+	if opts.All {
+		n = -1
+	}
+	cells := strings.SplitN(line, opts.Delimiter, n)
+	for i := range cells {
+		if i == 0 {
+			cells[i] = strings.TrimRightFunc(cells[i], unicode.IsSpace)
+		} else {
+			cells[i] = strings.TrimSpace(cells[i])
+		}
+		if opts.AttachLeft && i < len(cells)-1 {
+			cells[i] += opts.Delimiter
+		}
+	}
+	return cells, true
+}
+
+func formatAligned(rows [][]string, opts AlignOptions) []string {
+	var widths []int
+	for _, cells := range rows {
+		for i, cell := range cells {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := utf8.RuneCountInString(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	gap := strings.Repeat(" ", opts.Gap)
+	out := make([]string, len(rows))
+	for r, cells := range rows {
+		var b strings.Builder
+		for i, cell := range cells {
+			if i > 0 {
+				b.WriteString(gap)
+				if opts.Delimiter != "" && !opts.AttachLeft {
+					b.WriteString(opts.Delimiter)
+					b.WriteString(" ")
+				}
+			}
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			switch {
+			case opts.Right && opts.Delimiter == "":
+				b.WriteString(pad)
+				b.WriteString(cell)
+			case i < len(cells)-1:
+				b.WriteString(cell)
+				b.WriteString(pad)
+			default:
+				b.WriteString(cell)
+			}
+		}
+		out[r] = strings.TrimRightFunc(b.String(), unicode.IsSpace)
+	}
+	return out
+}
@@ -0,0 +1,373 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// [color] and [size] values end up in a style attribute, so they are held
+// to plain color names, hex and rgb() colors and numbers
+var (
+	bbcodeColorPattern = regexp.MustCompile(`^(?:#[0-9A-Fa-f]{3,8}|[A-Za-z]{1,32}|rgba?\([0-9., %]{1,40}\))$`)
+	bbcodeSizePattern  = regexp.MustCompile(`^([0-9]{1,3})%?$`)
+)
+
+var bbcodeTagPattern = regexp.MustCompile(`\[(/?)([A-Za-z][A-Za-z0-9]*|\*)(?:=("[^"\]]*"|[^\]]*))?\]`)
+
+// BBCode sizes are either 1-7, like the old font element, or percentages
+var bbcodeSizes = []string{"", "x-small", "small", "medium", "large", "x-large", "xx-large", "xxx-large"}
+
+// tags whose content is taken as is rather than parsed
+var bbcodeRawTags = map[string]bool{"code": true, "img": true, "url": true, "email": true}
+
+func parseBBCode(text string) *html.Node {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	root := element("div")
+	type open struct {
+		tag  string
+		node *html.Node
+	}
+	stack := []open{{node: root}}
+	top := func() *html.Node { return stack[len(stack)-1].node }
+	addText := func(s string) {
+		if t := top(); (t.Data == "ul" || t.Data == "ol") && strings.TrimSpace(s) == "" {
+			return
+		}
+		for i, part := range strings.Split(s, "\n") {
+			if i > 0 {
+				top().AppendChild(element("br"))
+			}
+			if part != "" {
+				top().AppendChild(textNode(part))
+			}
+		}
+	}
+
+	pos := 0
+	for pos < len(text) {
+		loc := bbcodeTagPattern.FindStringSubmatchIndex(text[pos:])
+		if loc == nil {
+			break
+		}
+		start, end := pos+loc[0], pos+loc[1]
+		tag := strings.ToLower(text[pos+loc[4] : pos+loc[5]])
+		closing := loc[3] > loc[2]
+		value := ""
+		if loc[6] >= 0 {
+			value = strings.Trim(text[pos+loc[6]:pos+loc[7]], `"`)
+		}
+		addText(text[pos:start])
+		pos = end
+
+		if closing {
+			depth := -1
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].tag == tag {
+					depth = i
+					break
+				}
+			}
+			if depth < 0 {
+				addText(text[start:end])
+				continue
+			}
+			stack = stack[:depth]
+			continue
+		}
+
+		if bbcodeRawTags[tag] && (tag != "url" && tag != "email" || value == "") {
+			closeAt := strings.Index(strings.ToLower(text[end:]), "[/"+tag+"]")
+			if closeAt < 0 {
+				addText(text[start:end])
+				continue
+			}
+			content := text[end : end+closeAt]
+			pos = end + closeAt + len(tag) + 3
+			top().AppendChild(bbcodeRawElement(tag, value, content))
+			continue
+		}
+
+		switch tag {
+		case "*":
+			i := len(stack) - 1
+			for i > 0 && stack[i].node.Data != "ul" && stack[i].node.Data != "ol" {
+				i--
+			}
+			if i == 0 {
+				addText(text[start:end])
+				continue
+			}
+			stack = stack[:i+1]
+			li := element("li")
+			top().AppendChild(li)
+			stack = append(stack, open{tag: tag, node: li})
+			continue
+		case "hr":
+			top().AppendChild(element("hr"))
+			continue
+		}
+
+		n := bbcodeElement(tag, value)
+		if n == nil {
+			addText(text[start:end])
+			continue
+		}
+		top().AppendChild(n)
+		stack = append(stack, open{tag: tag, node: n})
+	}
+	if pos < len(text) {
+		addText(text[pos:])
+	}
+	trimBreaks(root)
+	return root
+}
+
+func bbcodeElement(tag, value string) *html.Node {
+	switch tag {
+	case "b":
+		return element("strong")
+	case "i":
+		return element("em")
+	case "u":
+		return element("u")
+	case "s", "strike":
+		return element("del")
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		return element(tag)
+	case "center", "left", "right":
+		return element("div", "style", "text-align: "+tag)
+	case "quote":
+		if value != "" {
+			return element("blockquote", "data-author", value)
+		}
+		return element("blockquote")
+	case "url":
+		return element("a", "href", safeURL(value))
+	case "email":
+		return element("a", "href", "mailto:"+value)
+	case "color":
+		if !bbcodeColorPattern.MatchString(value) {
+			return element("span")
+		}
+		return element("span", "style", "color: "+value)
+	case "size":
+		m := bbcodeSizePattern.FindStringSubmatch(value)
+		if m == nil {
+			return element("span")
+		}
+		size := m[1] + "%"
+		if n, _ := strconv.Atoi(m[1]); n >= 1 && n < len(bbcodeSizes) && m[0] == m[1] {
+			size = bbcodeSizes[n]
+		}
+		return element("span", "style", "font-size: "+size)
+	case "list":
+		switch value {
+		case "":
+			return element("ul")
+		case "1":
+			return element("ol")
+		default:
+			return element("ol", "type", value)
+		}
+	}
+	return nil
+}
+
+func bbcodeRawElement(tag, value, content string) *html.Node {
+	switch tag {
+	case "code":
+		code := element("code")
+		if value != "" {
+			code.Attr = append(code.Attr, html.Attribute{Key: "class", Val: "language-" + value})
+		}
+		code.AppendChild(textNode(strings.TrimPrefix(strings.TrimRight(content, "\n"), "\n")))
+		pre := element("pre")
+		pre.AppendChild(code)
+		return pre
+	case "img":
+		return element("img", "src", safeURL(strings.TrimSpace(content)), "alt", "")
+	case "email":
+		a := element("a", "href", "mailto:"+strings.TrimSpace(content))
+		a.AppendChild(textNode(content))
+		return a
+	default:
+		a := element("a", "href", safeURL(strings.TrimSpace(content)))
+		a.AppendChild(textNode(content))
+		return a
+	}
+}
+
+// trimBreaks drops the line breaks BBCode authors put around block tags,
+// which HTML and Markdown express with the block itself
+func trimBreaks(n *html.Node) {
+	isBreak := func(c *html.Node) bool {
+		return c != nil && (c.Type == html.ElementNode && c.Data == "br" || c.Type == html.TextNode && strings.TrimSpace(c.Data) == "")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			trimBreaks(c)
+		}
+	}
+	if isBlockNode(n) {
+		for isBreak(n.FirstChild) {
+			n.RemoveChild(n.FirstChild)
+		}
+		for isBreak(n.LastChild) {
+			n.RemoveChild(n.LastChild)
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if !isBlockNode(c) {
+			continue
+		}
+		if next := c.NextSibling; next != nil && next.Type == html.ElementNode && next.Data == "br" {
+			n.RemoveChild(next)
+		}
+		if prev := c.PrevSibling; prev != nil && prev.Type == html.ElementNode && prev.Data == "br" {
+			n.RemoveChild(prev)
+		}
+	}
+}
+
+func renderBBCode(root *html.Node) string {
+	return strings.TrimSpace(bbBlocks(root, "\n\n"))
+}
+
+func bbBlocks(n *html.Node, sep string) string {
+	var blocks []string
+	eachBlock(n, func(run []*html.Node) {
+		var b strings.Builder
+		for _, c := range run {
+			b.WriteString(bbInline(c))
+		}
+		var paragraphs []string
+		for _, lines := range paragraphLines(b.String()) {
+			paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+		}
+		if len(paragraphs) > 0 {
+			blocks = append(blocks, strings.Join(paragraphs, "\n\n"))
+		}
+	}, func(c *html.Node) {
+		if block := bbBlock(c); block != "" {
+			blocks = append(blocks, block)
+		}
+	})
+	return strings.Join(blocks, sep)
+}
+
+func bbBlock(n *html.Node) string {
+	// headings have no portable BBCode, bold is the usual stand-in
+	if headingLevel(n) > 0 {
+		if text := strings.TrimSpace(bbChildren(n)); text != "" {
+			return "[b]" + text + "[/b]"
+		}
+		return ""
+	}
+
+	switch n.Data {
+	case "hr":
+		return "[hr]"
+	case "pre":
+		return "[code]" + strings.TrimRight(textContent(n), "\n") + "[/code]"
+	case "blockquote":
+		inner := bbBlocks(n, "\n\n")
+		if author := attrOf(n, "data-author"); author != "" {
+			return "[quote=" + author + "]" + inner + "[/quote]"
+		}
+		return "[quote]" + inner + "[/quote]"
+	case "ul", "ol":
+		open := "[list]"
+		if n.Data == "ol" {
+			open = "[list=1]"
+			if t := attrOf(n, "type"); t != "" {
+				open = "[list=" + t + "]"
+			}
+		}
+		var b strings.Builder
+		b.WriteString(open + "\n")
+		for li := n.FirstChild; li != nil; li = li.NextSibling {
+			if li.Type == html.ElementNode && li.Data == "li" {
+				b.WriteString("[*]" + bbBlocks(li, "\n") + "\n")
+			}
+		}
+		b.WriteString("[/list]")
+		return b.String()
+	}
+
+	inner := bbBlocks(n, "\n\n")
+	switch align := styleOf(n, "text-align"); {
+	case n.Data == "center" || align == "center":
+		return "[center]" + inner + "[/center]"
+	case align == "left" || align == "right":
+		return "[" + align + "]" + inner + "[/" + align + "]"
+	}
+	return inner
+}
+
+func bbChildren(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(bbInline(c))
+	}
+	return b.String()
+}
+
+func bbInline(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return collapseSpace(n.Data)
+	}
+	if n.Type != html.ElementNode || isHidden(n) {
+		return ""
+	}
+
+	switch n.Data {
+	case "br":
+		return "\n"
+	case "strong", "b":
+		return wrapInline(bbChildren(n), "[b]", "[/b]")
+	case "em", "i", "cite":
+		return wrapInline(bbChildren(n), "[i]", "[/i]")
+	case "u", "ins":
+		return wrapInline(bbChildren(n), "[u]", "[/u]")
+	case "del", "s", "strike":
+		return wrapInline(bbChildren(n), "[s]", "[/s]")
+	case "code", "kbd", "samp", "tt":
+		return "[code]" + textContent(n) + "[/code]"
+	case "img":
+		if src := attrOf(n, "src"); src != "" {
+			return "[img]" + src + "[/img]"
+		}
+		return ""
+	case "a":
+		inner, href := bbChildren(n), attrOf(n, "href")
+		switch {
+		case href == "":
+			return inner
+		case strings.TrimSpace(inner) == "" || strings.TrimSpace(textContent(n)) == href:
+			return "[url]" + href + "[/url]"
+		}
+		return wrapInline(inner, "[url="+href+"]", "[/url]")
+	}
+
+	inner := bbChildren(n)
+	color, size := styleOf(n, "color"), styleOf(n, "font-size")
+	if n.Data == "font" {
+		color = attrOf(n, "color")
+		size = attrOf(n, "size")
+	}
+	if size != "" {
+		for i, named := range bbcodeSizes {
+			if named != "" && size == named {
+				size = strconv.Itoa(i)
+			}
+		}
+		inner = wrapInline(inner, "[size="+strings.TrimSuffix(size, "%")+"]", "[/size]")
+	}
+	if color != "" {
+		inner = wrapInline(inner, "[color="+color+"]", "[/color]")
+	}
+	return inner
+}
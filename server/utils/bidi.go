@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/bidi"
+)
+
+type BidiDirection string
+
+const (
+	BidiAuto    BidiDirection = "auto"
+	BidiLTR     BidiDirection = "ltr"
+	BidiRTL     BidiDirection = "rtl"
+	BidiNeutral BidiDirection = "neutral"
+)
+
+var BidiIsolateDirections = []BidiDirection{BidiAuto, BidiLTR, BidiRTL}
+
+func (BidiDirection) Values() []string {
+	return EnumValues(BidiIsolateDirections)
+}
+
+const (
+	leftToRightIsolate    = '\u2066'
+	rightToLeftIsolate    = '\u2067'
+	firstStrongIsolate    = '\u2068'
+	popDirectionalIsolate = '\u2069'
+)
+
+type BidiRun struct {
+	Direction BidiDirection `json:"direction"`
+	Text      string        `json:"text"`
+	Start     int           `json:"start"`
+	End       int           `json:"end"`
+}
+
+type BidiReport struct {
+	Direction BidiDirection `json:"direction"`
+	Mixed     bool          `json:"mixed"`
+	Runs      []BidiRun     `json:"runs"`
+	Controls  int           `json:"controls"`
+}
+
+func strongDirection(r rune) BidiDirection {
+	props, _ := bidi.LookupRune(r)
+	switch props.Class() {
+	case bidi.L:
+		return BidiLTR
+	case bidi.R, bidi.AL:
+		return BidiRTL
+	}
+	return BidiNeutral
+}
+
+func isBidiControl(r rune) bool {
+	switch r {
+	case '\u061c', '\u200e', '\u200f':
+		return true
+	}
+	return r >= '\u202a' && r <= '\u202e' || r >= '\u2066' && r <= '\u2069'
+}
+
+func DominantDirection(text string) BidiDirection {
+	// paragraph-level rule: the first strong character wins
+	for _, r := range text {
+		if d := strongDirection(r); d != BidiNeutral {
+			return d
+		}
+	}
+	return BidiNeutral
+}
+
+func BidiRuns(text string) []BidiRun {
+	runs := []BidiRun{}
+	for i, r := range text {
+		d := strongDirection(r)
+		if d == BidiNeutral {
+			continue
+		}
+		end := i + utf8.RuneLen(r)
+		switch {
+		case len(runs) > 0 && runs[len(runs)-1].Direction == d:
+			runs[len(runs)-1].End = end
+		case len(runs) == 0:
+			runs = append(runs, BidiRun{Direction: d, Start: 0, End: end})
+		default:
+			// neutrals between opposite runs stay with the run they follow
+			runs[len(runs)-1].End = i
+			runs = append(runs, BidiRun{Direction: d, Start: i, End: end})
+		}
+	}
+	if len(runs) == 0 {
+		if text != "" {
+			runs = append(runs, BidiRun{Direction: BidiNeutral, Start: 0, End: len(text)})
+		}
+	} else {
+		runs[len(runs)-1].End = len(text)
+	}
+	for i := range runs {
+		runs[i].Text = text[runs[i].Start:runs[i].End]
+	}
+	return runs
+}
+
+func AnalyzeBidi(text string) BidiReport {
+	report := BidiReport{Direction: DominantDirection(text), Runs: BidiRuns(text)}
+	seen := make(map[BidiDirection]bool)
+	for _, run := range report.Runs {
+		seen[run.Direction] = true
+	}
+	report.Mixed = seen[BidiLTR] && seen[BidiRTL]
+	for _, r := range text {
+		if isBidiControl(r) {
+			report.Controls++
+		}
+	}
+	return report
+}
+
+func StripBidiControls(text string) string {
+	return strings.Map(func(r rune) rune {
+		if isBidiControl(r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+func IsolateBidi(fragment string, direction BidiDirection) (string, error) {
+	var open rune
+	switch direction {
+	case "", BidiAuto:
+		open = firstStrongIsolate
+	case BidiLTR:
+		open = leftToRightIsolate
+	case BidiRTL:
+		open = rightToLeftIsolate
+	default:
+		return "", fmt.Errorf("%w: unknown bidi direction %q", ErrInvalidOption, direction)
+	}
+	// controls inside the fragment are dropped so it cannot leak reordering past the isolate
+	return string(open) + StripBidiControls(fragment) + string(popDirectionalIsolate), nil
+}
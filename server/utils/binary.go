@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// like git, only the start of the data is inspected
+const binarySniffBytes = 8 << 10
+
+type BinaryReport struct {
+	Binary       bool    `json:"binary"`
+	MIMEType     string  `json:"mimeType"`
+	Encoding     string  `json:"encoding,omitempty"`
+	NullBytes    int     `json:"nullBytes"`
+	InvalidRatio float64 `json:"invalidUtf8Ratio"`
+	ControlRatio float64 `json:"controlRatio"`
+	Reason       string  `json:"reason,omitempty"`
+}
+
+func (r BinaryReport) Err() error {
+	if !r.Binary {
+		return nil
+	}
+	return fmt.Errorf("%w: input looks like %s (%s)", ErrBinaryInput, r.MIMEType, r.Reason)
+}
+
+func DetectBinary(data []byte) BinaryReport {
+	report := BinaryReport{MIMEType: http.DetectContentType(data)}
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		report.Encoding = "utf-16le"
+		return report
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		report.Encoding = "utf-16be"
+		return report
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		report.Encoding = "utf-8"
+	}
+
+	sample := data[:min(len(data), binarySniffBytes)]
+	// a character cut in half by the sample is not invalid UTF-8
+	if len(sample) < len(data) {
+		for i := 0; i < utf8.UTFMax-1 && len(sample) > 0 && !utf8.FullRune(sample[lastRuneStart(sample):]); i++ {
+			sample = sample[:len(sample)-1]
+		}
+	}
+
+	chars, invalid, control := 0, 0, 0
+	for len(sample) > 0 {
+		r, size := utf8.DecodeRune(sample)
+		sample = sample[size:]
+		chars++
+		switch {
+		case r == utf8.RuneError && size == 1:
+			invalid++
+		case r == 0:
+			report.NullBytes++
+		case r < 0x20 && !strings.ContainsRune("\t\n\v\f\r\x1b", r), r == 0x7F:
+			control++
+		}
+	}
+	if chars > 0 {
+		report.InvalidRatio = round2(float64(invalid) / float64(chars))
+		report.ControlRatio = round2(float64(control) / float64(chars))
+	}
+
+	mediaType, _, _ := strings.Cut(report.MIMEType, ";")
+	switch {
+	case report.NullBytes > 0:
+		report.Reason = "contains null bytes"
+	case report.InvalidRatio > 0.1:
+		report.Reason = "mostly invalid UTF-8"
+	case report.ControlRatio > 0.1:
+		report.Reason = "mostly control characters"
+	case !strings.HasPrefix(mediaType, "text/") && mediaType != "application/octet-stream" && mediaType != "application/json":
+		report.Reason = "has a binary file signature"
+	}
+	report.Binary = report.Reason != ""
+	if !report.Binary && report.Encoding == "" && invalid == 0 {
+		report.Encoding = "utf-8"
+	}
+	return report
+}
+
+func lastRuneStart(b []byte) int {
+	i := len(b) - 1
+	for i > 0 && !utf8.RuneStart(b[i]) {
+		i--
+	}
+	return max(i, 0)
+}
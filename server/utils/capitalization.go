@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type CapitalizationOptions struct {
+	ProperNouns     []string `json:"properNouns"`
+	KeepMidSentence bool     `json:"keepMidSentence"`
+}
+
+func capitalizeFirst(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToTitle(r)) + word[size:]
+}
+
+// "THe", "HEllo": caps lock released a letter too late
+func isCapsSlip(word string) bool {
+	runes := []rune(word)
+	if len(runes) < 3 || !unicode.IsUpper(runes[0]) || !unicode.IsUpper(runes[1]) {
+		return false
+	}
+	for _, r := range runes[2:] {
+		if !unicode.IsLower(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasInnerCapital(word string) bool {
+	for i, r := range word {
+		if i > 0 && unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func FixCapitalization(text string) string {
+	return FixCapitalizationWithOptions(text, CapitalizationOptions{})
+}
+
+func FixCapitalizationWithOptions(text string, opts CapitalizationOptions) string {
+	proper := make(map[string]string)
+	var phrases []string
+	for _, noun := range opts.ProperNouns {
+		noun = strings.TrimSpace(noun)
+		switch {
+		case noun == "":
+		case strings.ContainsAny(noun, " \t-"):
+			phrases = append(phrases, noun)
+		default:
+			proper[strings.ToLower(noun)] = noun
+		}
+	}
+
+	sentences, _ := SplitSentences(text, "en")
+	var b strings.Builder
+	last := 0
+	for _, s := range sentences {
+		b.WriteString(text[last:s.Start])
+		first := true
+		b.WriteString(shoutWordPattern.ReplaceAllStringFunc(s.Text, func(word string) string {
+			defer func() { first = false }()
+			lower := strings.ToLower(word)
+			if form, ok := proper[lower]; ok {
+				if first {
+					return capitalizeFirst(form)
+				}
+				return form
+			}
+			if lower == "i" || strings.HasPrefix(lower, "i'") || strings.HasPrefix(lower, "i’") {
+				return "I" + lower[1:]
+			}
+			if isCapsSlip(word) {
+				word = lower
+			}
+			switch {
+			case first:
+				return capitalizeFirst(word)
+			case opts.KeepMidSentence || isAllCaps(word) && utf8.RuneCountInString(word) > 1 || hasInnerCapital(word):
+				return word
+			}
+			return lower
+		}))
+		last = s.End
+	}
+	b.WriteString(text[last:])
+	out := b.String()
+
+	if len(phrases) > 0 {
+		sort.Slice(phrases, func(i, j int) bool { return len(phrases[i]) > len(phrases[j]) })
+		forms := make(map[string]string, len(phrases))
+		quoted := make([]string, len(phrases))
+		for i, p := range phrases {
+			forms[strings.ToLower(p)] = p
+			quoted[i] = regexp.QuoteMeta(p)
+		}
+		re := regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+		out = re.ReplaceAllStringFunc(out, func(m string) string {
+			return forms[strings.ToLower(m)]
+		})
+	}
+	return out
+}
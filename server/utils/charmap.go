@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type CharMap string
+
+const (
+	// CharMapASCII replaces what it can with a plain-ASCII fallback: accented
+	// Latin letters, ligatures, punctuation, symbols and fractions
+	CharMapASCII CharMap = "ascii"
+	// CharMapTypography folds look-alike variants onto one form each (space
+	// and hyphen variants, figure dashes, reversed quotes) but stays Unicode
+	CharMapTypography CharMap = "typography"
+)
+
+var CharMaps = []CharMap{CharMapASCII, CharMapTypography}
+
+func (CharMap) Values() []string {
+	return EnumValues(CharMaps)
+}
+
+func (m CharMap) Valid() bool {
+	return EnumValid(CharMaps, m)
+}
+
+// Table returns a copy of the built-in table, for extending or overriding
+func (m CharMap) Table() map[string]string {
+	table := make(map[string]string, len(charMapTables[m]))
+	for k, v := range charMapTables[m] {
+		table[k] = v
+	}
+	return table
+}
+
+var ligatureTable = map[string]string{
+	"\ufb00": "ff", "\ufb01": "fi", "\ufb02": "fl", "\ufb03": "ffi", "\ufb04": "ffl",
+	"\ufb05": "st", "\ufb06": "st", "\u0132": "IJ", "\u0133": "ij",
+}
+
+var typographyTable = map[string]string{
+	// spaces of every width, including no-break ones
+	"\u00a0": " ", "\u2000": " ", "\u2001": " ", "\u2002": " ", "\u2003": " ", "\u2004": " ",
+	"\u2005": " ", "\u2006": " ", "\u2007": " ", "\u2008": " ", "\u2009": " ", "\u200a": " ",
+	"\u202f": " ", "\u205f": " ", "\u3000": " ",
+	// hyphens, dashes and quotes that render like another one
+	"\u2010": "-", "\u2011": "-", "\u2012": "\u2013", "\u2015": "\u2014", "\ufe58": "\u2014",
+	"‛": "‘", "‟": "“", "′": "’", "″": "”",
+	"\u2024": ".", "\u2025": "..", "\u2027": "·", "\u2043": "•",
+}
+
+var asciiTable = func() map[string]string {
+	table := map[string]string{
+		"‘": "'", "’": "'", "‚": "'", "‛": "'", "′": "'", "‹": "<", "›": ">",
+		"“": `"`, "”": `"`, "„": `"`, "‟": `"`, "″": `"`, "«": "<<", "»": ">>",
+		"\u2010": "-", "\u2011": "-", "\u2012": "-", "\u2013": "-", "\u2014": "--", "\u2015": "--", "\u2212": "-",
+		"…": "...", "•": "*", "·": "*", "\u2024": ".", "\u2025": "..",
+		"©": "(c)", "®": "(R)", "™": "(TM)", "§": "S", "¶": "P", "°": "deg",
+		"×": "x", "÷": "/", "±": "+/-", "≠": "!=", "≤": "<=", "≥": ">=", "≈": "~",
+		"←": "<-", "→": "->", "↔": "<->", "⇒": "=>",
+		"¼": "1/4", "½": "1/2", "¾": "3/4", "⅓": "1/3", "⅔": "2/3", "⅛": "1/8",
+		"¹": "1", "²": "2", "³": "3",
+		"€": "EUR", "£": "GBP", "¥": "JPY", "¢": "c",
+		"¡": "!", "¿": "?", "\u00ad": "",
+		// letters that do not decompose into a base letter and marks
+		"ß": "ss", "ẞ": "SS", "æ": "ae", "Æ": "AE", "ø": "o", "Ø": "O",
+		"œ": "oe", "Œ": "OE", "ł": "l", "Ł": "L", "đ": "d", "Đ": "D",
+		"þ": "th", "Þ": "TH", "ð": "d", "Ð": "D", "ı": "i",
+	}
+	for k, v := range ligatureTable {
+		table[k] = v
+	}
+	for k, v := range typographyTable {
+		if v == " " {
+			table[k] = v
+		}
+	}
+	// accented Latin letters fall back to their base letter
+	for r := rune(0xc0); r <= 0x24f; r++ {
+		if _, ok := table[string(r)]; ok || !unicode.IsLetter(r) {
+			continue
+		}
+		if base := stripDiacritics(string(r)); len(base) == 1 && base[0] < unicode.MaxASCII {
+			table[string(r)] = base
+		}
+	}
+	return table
+}()
+
+var charMapTables = map[CharMap]map[string]string{
+	CharMapASCII:      asciiTable,
+	CharMapTypography: typographyTable,
+}
+
+var charMapReplacers = map[CharMap]*strings.Replacer{
+	CharMapASCII:      charReplacer(asciiTable),
+	CharMapTypography: charReplacer(typographyTable),
+}
+
+// charReplacer matches the longest key at each position, so that "ffi" wins
+// over "ff"; the replacer itself would take whichever key it was given first
+func charReplacer(table map[string]string) *strings.Replacer {
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	pairs := make([]string, 0, 2*len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k, table[k])
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// MapCharacters replaces every key of table found in text with its value in
+// a single pass: replacements are never matched again, and where keys
+// overlap the longest one wins. Keys can be any string, not just one
+// character.
+func MapCharacters(text string, table map[string]string) (string, error) {
+	if _, ok := table[""]; ok {
+		return "", fmt.Errorf("%w: character map keys cannot be empty", ErrInvalidOption)
+	}
+	return charReplacer(table).Replace(text), nil
+}
+
+type CharMapOptions struct {
+	// Table is a built-in table to start from
+	Table CharMap `json:"table"`
+	// Pairs add to or override the table
+	Pairs map[string]string `json:"pairs"`
+	// From and To add tr-style pairs, with ranges like "a-z"; a shorter To
+	// repeats its last character and an empty one deletes
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func MapCharactersWithOptions(text string, opts CharMapOptions) (string, error) {
+	if opts.Table != "" && !opts.Table.Valid() {
+		return "", fmt.Errorf("%w: unknown character map %q", ErrInvalidOption, opts.Table)
+	}
+	if len(opts.Pairs) == 0 && opts.From == "" && opts.To == "" {
+		if opts.Table == "" {
+			return text, nil
+		}
+		return charMapReplacers[opts.Table].Replace(text), nil
+	}
+
+	table := opts.Table.Table()
+	for k, v := range opts.Pairs {
+		table[k] = v
+	}
+	tr, err := TranslationTable(opts.From, opts.To)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range tr {
+		table[k] = v
+	}
+	return MapCharacters(text, table)
+}
+
+// TranslationTable builds a character map the way tr reads its two sets:
+// the nth character of from maps to the nth of to. "a-z" is a range and a
+// backslash escapes "-" or itself.
+func TranslationTable(from, to string) (map[string]string, error) {
+	src, err := expandCharSet(from)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := expandCharSet(to)
+	if err != nil {
+		return nil, err
+	}
+	if len(src) == 0 && len(dst) > 0 {
+		return nil, fmt.Errorf("%w: to has characters but from has none", ErrInvalidOption)
+	}
+	table := make(map[string]string, len(src))
+	for i, r := range src {
+		switch {
+		case len(dst) == 0:
+			table[string(r)] = ""
+		case i < len(dst):
+			table[string(r)] = string(dst[i])
+		default:
+			table[string(r)] = string(dst[len(dst)-1])
+		}
+	}
+	return table, nil
+}
+
+func expandCharSet(set string) ([]rune, error) {
+	var chars []rune
+	runes := []rune(set)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\\' && i+1 < len(runes) {
+			i++
+			chars = append(chars, runes[i])
+			continue
+		}
+		if i+2 < len(runes) && runes[i+1] == '-' {
+			last := runes[i+2]
+			if last < r {
+				return nil, fmt.Errorf("%w: range %c-%c is reversed", ErrInvalidOption, r, last)
+			}
+			if last-r > 0xffff {
+				return nil, fmt.Errorf("%w: range %c-%c is too large", ErrInvalidOption, r, last)
+			}
+			for c := r; c <= last; c++ {
+				chars = append(chars, c)
+			}
+			i += 2
+			continue
+		}
+		chars = append(chars, r)
+	}
+	return chars, nil
+}
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	ChecksumOK       = "ok"
+	ChecksumMismatch = "mismatch"
+	ChecksumMissing  = "missing"
+)
+
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":      md5.New,
+	"sha1":     sha1.New,
+	"sha224":   sha256.New224,
+	"sha256":   sha256.New,
+	"sha384":   sha512.New384,
+	"sha512":   sha512.New,
+	"sha3-256": func() hash.Hash { return sha3.New256() },
+	"sha3-512": func() hash.Hash { return sha3.New512() },
+	"crc32":    func() hash.Hash { return crc32.NewIEEE() },
+}
+
+var checksumByLength = map[int]string{
+	32:  "md5",
+	40:  "sha1",
+	56:  "sha224",
+	64:  "sha256",
+	96:  "sha384",
+	128: "sha512",
+}
+
+type ChecksumResult struct {
+	File      string `json:"file,omitempty"`
+	Algorithm string `json:"algorithm"`
+	Expected  string `json:"expected"`
+	Actual    string `json:"actual,omitempty"`
+	Match     bool   `json:"match"`
+	Status    string `json:"status"`
+}
+
+func ChecksumAlgorithms() []string {
+	names := make([]string, 0, len(checksumAlgorithms))
+	for name := range checksumAlgorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func Checksum(text, algorithm string) (string, error) {
+	newHash, ok := checksumAlgorithms[strings.ToLower(algorithm)]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown checksum algorithm %q", ErrInvalidOption, algorithm)
+	}
+	h := newHash()
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func normalizeDigest(digest string) string {
+	digest = strings.ToLower(strings.TrimSpace(digest))
+	if _, rest, ok := strings.Cut(digest, ":"); ok {
+		digest = rest
+	}
+	return digest
+}
+
+func VerifyChecksum(text, algorithm, expected string) (ChecksumResult, error) {
+	expected = normalizeDigest(expected)
+	if algorithm == "" {
+		algorithm = checksumByLength[len(expected)]
+	}
+	actual, err := Checksum(text, algorithm)
+	if err != nil {
+		return ChecksumResult{}, err
+	}
+
+	result := ChecksumResult{
+		Algorithm: strings.ToLower(algorithm),
+		Expected:  expected,
+		Actual:    actual,
+		Match:     subtle.ConstantTimeCompare([]byte(actual), []byte(expected)) == 1,
+		Status:    ChecksumMismatch,
+	}
+	if result.Match {
+		result.Status = ChecksumOK
+	}
+	return result, nil
+}
+
+var (
+	gnuChecksumLine = regexp.MustCompile(`^([0-9a-fA-F]+) [ *](.+)$`)
+	bsdChecksumLine = regexp.MustCompile(`^([A-Za-z0-9-]+) ?\((.+)\) ?= ?([0-9a-fA-F]+)$`)
+)
+
+func VerifyChecksumList(list string, files map[string]string, algorithm string) ([]ChecksumResult, error) {
+	results := []ChecksumResult{}
+	for i, line := range strings.Split(list, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var name, digest, algo string
+		if m := gnuChecksumLine.FindStringSubmatch(line); m != nil {
+			digest, name, algo = m[1], m[2], algorithm
+		} else if m := bsdChecksumLine.FindStringSubmatch(line); m != nil {
+			algo, name, digest = strings.ToLower(m[1]), m[2], m[3]
+		} else {
+			return nil, fmt.Errorf("%w: line %d is not a checksum entry", ErrInvalidOption, i+1)
+		}
+
+		content, ok := files[name]
+		if !ok {
+			if algo == "" {
+				algo = checksumByLength[len(digest)]
+			}
+			results = append(results, ChecksumResult{File: name, Algorithm: algo, Expected: normalizeDigest(digest), Status: ChecksumMissing})
+			continue
+		}
+
+		result, err := VerifyChecksum(content, algo, digest)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		result.File = name
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+type ChunkUnit string
+
+const (
+	ChunkCharacters ChunkUnit = "characters"
+	ChunkWords      ChunkUnit = "words"
+	ChunkTokens     ChunkUnit = "tokens"
+)
+
+var ChunkUnits = []ChunkUnit{ChunkCharacters, ChunkWords, ChunkTokens}
+
+func (ChunkUnit) Values() []string {
+	return EnumValues(ChunkUnits)
+}
+
+func (u ChunkUnit) Valid() bool {
+	return u == "" || EnumValid(ChunkUnits, u)
+}
+
+type ChunkOptions struct {
+	Size    int       `json:"size" binding:"required" min:"1"`
+	Unit    ChunkUnit `json:"unit"`
+	Overlap int       `json:"overlap" min:"0"`
+	Model   string    `json:"model"`
+}
+
+func (o ChunkOptions) Validate() error {
+	if !o.Unit.Valid() {
+		return fmt.Errorf("%w: unknown chunk unit %q", ErrInvalidOption, o.Unit)
+	}
+	if o.Size <= 0 {
+		return fmt.Errorf("%w: chunk size must be positive, got %d", ErrInvalidOption, o.Size)
+	}
+	if o.Overlap < 0 || o.Overlap >= o.Size {
+		return fmt.Errorf("%w: overlap must be between 0 and size-1, got %d", ErrInvalidOption, o.Overlap)
+	}
+	if _, ok := lookupTokenizer(o.Model); !ok {
+		return fmt.Errorf("%w: unknown tokenizer model %q", ErrInvalidOption, o.Model)
+	}
+	return nil
+}
+
+type Chunk struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Size  int    `json:"size"`
+}
+
+const (
+	breakWord = iota
+	breakLine
+	breakSentence
+	breakParagraph
+)
+
+type chunkPiece struct {
+	start, end int
+	size       int
+	strength   int
+}
+
+var chunkWordPattern = regexp.MustCompile(`\S+`)
+
+func ChunkText(text string, opts ChunkOptions) ([]Chunk, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	pieces := chunkPieces(text, opts)
+	chunks := []Chunk{}
+	for i := 0; i < len(pieces); {
+		j, total := i, 0
+		for j < len(pieces) && (j == i || total+pieces[j].size <= opts.Size) {
+			total += pieces[j].size
+			j++
+		}
+
+		if j < len(pieces) {
+			best, acc := j, 0
+			strength := -1
+			for k := i; k < j; k++ {
+				acc += pieces[k].size
+				if acc*2 >= opts.Size && pieces[k].strength >= strength {
+					best, strength = k+1, pieces[k].strength
+				}
+			}
+			j = best
+		}
+
+		start, end := pieces[i].start, pieces[j-1].end
+		body := strings.TrimRight(text[start:end], " \t\r\n")
+		size := 0
+		for _, p := range pieces[i:j] {
+			size += p.size
+		}
+		chunks = append(chunks, Chunk{
+			Index: len(chunks),
+			Text:  body,
+			Start: start,
+			End:   start + len(body),
+			Size:  size,
+		})
+
+		if j == len(pieces) {
+			break
+		}
+		next, overlap := j, 0
+		for next-1 > i && overlap+pieces[next-1].size <= opts.Overlap {
+			next--
+			overlap += pieces[next].size
+		}
+		i = next
+	}
+	return chunks, nil
+}
+
+func chunkPieces(text string, opts ChunkOptions) []chunkPiece {
+	locs := chunkWordPattern.FindAllStringIndex(text, -1)
+	tokenizer, _ := lookupTokenizer(opts.Model)
+	var pieces []chunkPiece
+	for n, loc := range locs {
+		next := len(text)
+		if n+1 < len(locs) {
+			next = locs[n+1][0]
+		}
+		word, gap := text[loc[0]:loc[1]], text[loc[1]:next]
+
+		strength := breakWord
+		switch {
+		case strings.Count(gap, "\n") >= 2:
+			strength = breakParagraph
+		case endsSentence(word):
+			strength = breakSentence
+		case strings.Contains(gap, "\n"):
+			strength = breakLine
+		}
+
+		if opts.Unit == ChunkCharacters || opts.Unit == "" {
+			pieces = append(pieces, splitLongWord(text, loc[0], loc[1], next, opts.Size, strength)...)
+			continue
+		}
+		size := 1
+		if opts.Unit == ChunkTokens {
+			size = tokenizer.count(word)
+			if strings.Contains(gap, "\n") {
+				size++
+			}
+		}
+		pieces = append(pieces, chunkPiece{start: loc[0], end: next, size: size, strength: strength})
+	}
+	return pieces
+}
+
+func splitLongWord(text string, start, end, next, max, strength int) []chunkPiece {
+	var pieces []chunkPiece
+	for utf8.RuneCountInString(text[start:end]) > max {
+		cut := start
+		for n := 0; n < max; n++ {
+			_, w := utf8.DecodeRuneInString(text[cut:])
+			cut += w
+		}
+		pieces = append(pieces, chunkPiece{start: start, end: cut, size: max, strength: breakWord})
+		start = cut
+	}
+	size := utf8.RuneCountInString(text[start:next])
+	return append(pieces, chunkPiece{start: start, end: next, size: size, strength: strength})
+}
+
+func endsSentence(word string) bool {
+	trimmed := strings.TrimRightFunc(word, isClosingQuote)
+	last, _ := utf8.DecodeLastRuneInString(trimmed)
+	if !isTerminator(last) {
+		return false
+	}
+	return last != '.' || !isAbbreviation(strings.TrimRight(trimmed, "."), abbreviationsFor("en"))
+}
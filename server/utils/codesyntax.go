@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type codeSyntax struct {
+	identExtra    string
+	lineComments  []string
+	blockComments [][2]string
+	// longest delimiters first so """ wins over "
+	quotes    []string
+	rawQuotes []string
+}
+
+var (
+	cLikeSyntax  = codeSyntax{lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"`, `'`}}
+	scriptSyntax = codeSyntax{lineComments: []string{"#"}, quotes: []string{`"`, `'`}}
+)
+
+var codeSyntaxes = map[string]codeSyntax{
+	"c":          cLikeSyntax,
+	"cpp":        cLikeSyntax,
+	"csharp":     cLikeSyntax,
+	"java":       cLikeSyntax,
+	"kotlin":     {lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"""`, `"`, `'`}},
+	"swift":      {lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"""`, `"`}},
+	"rust":       {lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"`}},
+	"go":         {lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"`, `'`}, rawQuotes: []string{"`"}},
+	"javascript": {identExtra: "$", lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"`, `'`, "`"}},
+	"typescript": {identExtra: "$", lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"`, `'`, "`"}},
+	"php":        {identExtra: "$", lineComments: []string{"//", "#"}, blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"`, `'`}},
+	"css":        {identExtra: "-", blockComments: [][2]string{{"/*", "*/"}}, quotes: []string{`"`, `'`}},
+	"python":     {lineComments: []string{"#"}, quotes: []string{`"""`, `'''`, `"`, `'`}},
+	"ruby":       {identExtra: "?!", lineComments: []string{"#"}, quotes: []string{`"`, `'`}},
+	"shell":      {lineComments: []string{"#"}, quotes: []string{`"`}, rawQuotes: []string{`'`}},
+	"perl":       {identExtra: "$@%", lineComments: []string{"#"}, quotes: []string{`"`, `'`}},
+	"yaml":       scriptSyntax,
+	"toml":       {lineComments: []string{"#"}, quotes: []string{`"""`, `"`}, rawQuotes: []string{`'''`, `'`}},
+	"sql":        {lineComments: []string{"--"}, blockComments: [][2]string{{"/*", "*/"}}, rawQuotes: []string{`'`, `"`}},
+	"lua":        {lineComments: []string{"--"}, blockComments: [][2]string{{"--[[", "]]"}}, quotes: []string{`"`, `'`}},
+	"html":       {identExtra: "-", blockComments: [][2]string{{"<!--", "-->"}}, rawQuotes: []string{`"`, `'`}},
+	"xml":        {identExtra: "-:.", blockComments: [][2]string{{"<!--", "-->"}}, rawQuotes: []string{`"`, `'`}},
+}
+
+var codeLanguageAliases = map[string]string{
+	"golang": "go", "js": "javascript", "jsx": "javascript", "ts": "typescript", "tsx": "typescript",
+	"py": "python", "rb": "ruby", "sh": "shell", "bash": "shell", "zsh": "shell", "c++": "cpp",
+	"cs": "csharp", "kt": "kotlin", "rs": "rust", "yml": "yaml", "scss": "css", "h": "c",
+}
+
+func CodeLanguages() []string {
+	names := make([]string, 0, len(codeSyntaxes))
+	for name := range codeSyntaxes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupCodeSyntax(language string) (codeSyntax, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if alias, ok := codeLanguageAliases[language]; ok {
+		language = alias
+	}
+	if language == "" {
+		return cLikeSyntax, nil
+	}
+	syntax, ok := codeSyntaxes[language]
+	if !ok {
+		return codeSyntax{}, fmt.Errorf("%w: unknown code language %q", ErrInvalidOption, language)
+	}
+	return syntax, nil
+}
+
+func (s codeSyntax) isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || strings.ContainsRune(s.identExtra, r)
+}
+
+type codeSpan struct {
+	start, end int
+	comment    bool
+}
+
+// codeSpans finds string literals and comments; an unterminated one runs to the end of the text
+func (s codeSyntax) codeSpans(text string) []codeSpan {
+	var spans []codeSpan
+	hasPrefix := func(pos int, delims []string) string {
+		for _, d := range delims {
+			if strings.HasPrefix(text[pos:], d) {
+				return d
+			}
+		}
+		return ""
+	}
+	for pos := 0; pos < len(text); {
+		if open := s.blockOpener(text[pos:]); open[0] != "" {
+			end := strings.Index(text[pos+len(open[0]):], open[1])
+			if end < 0 {
+				end = len(text)
+			} else {
+				end += pos + len(open[0]) + len(open[1])
+			}
+			spans = append(spans, codeSpan{start: pos, end: end, comment: true})
+			pos = end
+			continue
+		}
+		if d := hasPrefix(pos, s.lineComments); d != "" {
+			end := strings.IndexByte(text[pos:], '\n')
+			if end < 0 {
+				end = len(text)
+			} else {
+				end += pos
+			}
+			spans = append(spans, codeSpan{start: pos, end: end, comment: true})
+			pos = end
+			continue
+		}
+		raw := false
+		q := hasPrefix(pos, s.quotes)
+		if r := hasPrefix(pos, s.rawQuotes); len(r) > len(q) {
+			q, raw = r, true
+		}
+		if q != "" {
+			end := closingQuote(text, pos+len(q), q, raw)
+			spans = append(spans, codeSpan{start: pos, end: end})
+			pos = end
+			continue
+		}
+		pos++
+	}
+	return spans
+}
+
+func (s codeSyntax) blockOpener(text string) [2]string {
+	for _, b := range s.blockComments {
+		if strings.HasPrefix(text, b[0]) {
+			return b
+		}
+	}
+	return [2]string{}
+}
+
+func closingQuote(text string, pos int, quote string, raw bool) int {
+	for pos < len(text) {
+		switch {
+		case !raw && text[pos] == '\\':
+			pos += 2
+			continue
+		case strings.HasPrefix(text[pos:], quote):
+			// doubled quotes ('it''s') are an escape in raw-quoted languages like SQL
+			if raw && len(quote) == 1 && strings.HasPrefix(text[pos+1:], quote) {
+				pos += 2
+				continue
+			}
+			return pos + len(quote)
+		case len(quote) == 1 && text[pos] == '\n' && quote != "`":
+			return pos
+		}
+		pos++
+	}
+	return len(text)
+}
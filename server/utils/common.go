@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+func CommonPrefix(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	prefix := lines[0]
+	for _, line := range lines[1:] {
+		n := 0
+		for n < len(prefix) && n < len(line) && prefix[n] == line[n] {
+			n++
+		}
+		prefix = prefix[:n]
+		if prefix == "" {
+			break
+		}
+	}
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
+
+func CommonSuffix(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	suffix := lines[0]
+	for _, line := range lines[1:] {
+		n := 0
+		for n < len(suffix) && n < len(line) && suffix[len(suffix)-1-n] == line[len(line)-1-n] {
+			n++
+		}
+		suffix = suffix[len(suffix)-n:]
+		if suffix == "" {
+			break
+		}
+	}
+	for !utf8.ValidString(suffix) {
+		suffix = suffix[1:]
+	}
+	return suffix
+}
+
+func StripCommonPrefix(text string) string {
+	lines := strings.Split(text, "\n")
+	prefix := CommonPrefix(nonBlank(lines))
+	if prefix == "" {
+		return text
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, prefix)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func StripCommonSuffix(text string) string {
+	lines := strings.Split(text, "\n")
+	suffix := CommonSuffix(nonBlank(lines))
+	if suffix == "" {
+		return text
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, suffix)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func nonBlank(lines []string) []string {
+	var out []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+type samState struct {
+	next   map[rune]int
+	link   int
+	length int
+}
+
+func LongestCommonSubstring(a, b string) string {
+	if a == "" || b == "" {
+		return ""
+	}
+
+	states := []samState{{next: map[rune]int{}, link: -1}}
+	last := 0
+	for _, r := range a {
+		cur := len(states)
+		states = append(states, samState{next: map[rune]int{}, length: states[last].length + 1})
+		p := last
+		for p != -1 {
+			if _, ok := states[p].next[r]; ok {
+				break
+			}
+			states[p].next[r] = cur
+			p = states[p].link
+		}
+		switch {
+		case p == -1:
+			states[cur].link = 0
+		case states[states[p].next[r]].length == states[p].length+1:
+			states[cur].link = states[p].next[r]
+		default:
+			q := states[p].next[r]
+			clone := len(states)
+			next := make(map[rune]int, len(states[q].next))
+			for k, v := range states[q].next {
+				next[k] = v
+			}
+			states = append(states, samState{next: next, link: states[q].link, length: states[p].length + 1})
+			for p != -1 && states[p].next[r] == q {
+				states[p].next[r] = clone
+				p = states[p].link
+			}
+			states[q].link = clone
+			states[cur].link = clone
+		}
+		last = cur
+	}
+
+	runes := []rune(b)
+	state, length, best, end := 0, 0, 0, 0
+	for i, r := range runes {
+		for state != 0 {
+			if _, ok := states[state].next[r]; ok {
+				break
+			}
+			state = states[state].link
+			length = states[state].length
+		}
+		if next, ok := states[state].next[r]; ok {
+			state = next
+			length++
+		}
+		if length > best {
+			best, end = length, i+1
+		}
+	}
+	return string(runes[end-best : end])
+}
@@ -0,0 +1,62 @@
+package utils
+
+import "strings"
+
+type CompareOptions struct {
+	DedupeOptions
+	SkipBlank bool `json:"skipBlank"`
+}
+
+type ListComparison struct {
+	OnlyA []string `json:"onlyA"`
+	OnlyB []string `json:"onlyB"`
+	Both  []string `json:"both"`
+}
+
+func CompareLists(a, b string, opts CompareOptions) ListComparison {
+	keysA, linesA := opts.uniqueLines(a)
+	keysB, linesB := opts.uniqueLines(b)
+
+	inA := make(map[string]bool, len(keysA))
+	for _, k := range keysA {
+		inA[k] = true
+	}
+	inB := make(map[string]bool, len(keysB))
+	for _, k := range keysB {
+		inB[k] = true
+	}
+
+	result := ListComparison{OnlyA: []string{}, OnlyB: []string{}, Both: []string{}}
+	for i, k := range keysA {
+		if inB[k] {
+			result.Both = append(result.Both, linesA[i])
+		} else {
+			result.OnlyA = append(result.OnlyA, linesA[i])
+		}
+	}
+	for i, k := range keysB {
+		if !inA[k] {
+			result.OnlyB = append(result.OnlyB, linesB[i])
+		}
+	}
+	return result
+}
+
+func (o CompareOptions) uniqueLines(text string) ([]string, []string) {
+	seen := make(map[string]bool)
+	var keys, lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if o.SkipBlank && strings.TrimSpace(line) == "" {
+			continue
+		}
+		k := o.key(line)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		keys = append(keys, k)
+		lines = append(lines, line)
+	}
+	return keys, lines
+}
@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+)
+
+const cancelCheckInterval = 4096
+
+func SortLinesContext(ctx context.Context, text string, opts SortOptions) (string, error) {
+	if len(text) > ExternalSortThreshold {
+		return viaStream(ctx, text, func(ctx context.Context, r io.Reader, w io.Writer) error {
+			return SortLinesStreamContext(ctx, r, w, opts)
+		})
+	}
+	lines := strings.Split(text, "\n")
+	keys := make([]string, len(lines))
+	for i, line := range lines {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+		}
+		keys[i] = sortKey(line, opts)
+	}
+
+	idx := make([]int, len(lines))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	less := lessFunc(opts.Compare)
+	calls := 0
+	cancelled := false
+	sort.SliceStable(idx, func(i, j int) bool {
+		if cancelled {
+			return false
+		}
+		calls++
+		if calls%cancelCheckInterval == 0 && ctx.Err() != nil {
+			cancelled = true
+			return false
+		}
+		if opts.Ascending {
+			return less(keys[idx[i]], keys[idx[j]])
+		}
+		return less(keys[idx[j]], keys[idx[i]])
+	})
+	if cancelled {
+		return "", ctx.Err()
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	for i, k := range idx {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(lines[k])
+	}
+	return b.String(), nil
+}
+
+func RemoveDuplicateLinesContext(ctx context.Context, text string, opts DedupeOptions) (string, error) {
+	if len(text) > ExternalSortThreshold {
+		return viaStream(ctx, text, func(ctx context.Context, r io.Reader, w io.Writer) error {
+			return RemoveDuplicateLinesStreamContext(ctx, r, w, opts)
+		})
+	}
+	lines := strings.Split(text, "\n")
+	seen := make(map[string]bool)
+	var result []string
+
+	for i, line := range lines {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+		}
+		k := opts.key(line)
+		if !seen[k] {
+			seen[k] = true
+			result = append(result, line)
+		}
+	}
+
+	return strings.Join(result, "\n"), nil
+}
+
+// ReplaceTextContext works out the size of a plain replacement before
+// building it, and checks the others as they are written, so a short find
+// with a long replacement cannot run past the expansion limit
+func ReplaceTextContext(ctx context.Context, text string, pair ReplacePair) (string, error) {
+	if pair.Find == "" {
+		return "", fmt.Errorf("%w: find must not be empty", ErrInvalidPattern)
+	}
+	if pair.CaseSensitive && !pair.WholeWord && !pair.PreserveCase && !pair.codeMode() {
+		n := int64(strings.Count(text, pair.Find))
+		size := int64(len(text)) + n*int64(len(pair.Replace)-len(pair.Find))
+		if err := LimitsFrom(ctx).CheckExpansion(int64(len(text)), size); err != nil {
+			return "", err
+		}
+		return strings.ReplaceAll(text, pair.Find, pair.Replace), nil
+	}
+	result, _, err := findReplaceMany(ctx, text, []ReplacePair{pair})
+	return result, err
+}
+
+func RegexReplace(text, pattern, replacement string, flags RegexFlags) (string, int, error) {
+	return RegexReplaceContext(context.Background(), text, pattern, replacement, flags)
+}
+
+func RegexReplaceContext(ctx context.Context, text, pattern, replacement string, flags RegexFlags) (string, int, error) {
+	limits := LimitsFrom(ctx)
+	if err := limits.CheckRegex(pattern, flags); err != nil {
+		return "", 0, err
+	}
+	re, err := CompileRegex(pattern, flags)
+	if err != nil {
+		return "", 0, err
+	}
+
+	segments := []string{text}
+	if lineLocal(re) {
+		segments = splitSegments(text, 64<<10)
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	count := 0
+	for i, seg := range segments {
+		if err := ctx.Err(); err != nil {
+			return "", 0, err
+		}
+		prev := 0
+		for _, loc := range re.FindAllStringSubmatchIndex(seg, -1) {
+			if loc[0] == len(seg) && i < len(segments)-1 {
+				break
+			}
+			b.WriteString(seg[prev:loc[0]])
+			b.Write(re.ExpandString(nil, replacement, seg, loc))
+			prev = loc[1]
+			count++
+		}
+		b.WriteString(seg[prev:])
+		// checked per segment so a replacement bomb stops before it is fully built
+		if err := limits.CheckExpansion(int64(len(text)), int64(b.Len())); err != nil {
+			return "", 0, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return "", 0, err
+	}
+
+	return b.String(), count, nil
+}
+
+func splitSegments(text string, size int) []string {
+	var segments []string
+	for len(text) > size {
+		cut := strings.IndexByte(text[size:], '\n')
+		if cut < 0 {
+			break
+		}
+		cut += size + 1
+		segments = append(segments, text[:cut])
+		text = text[cut:]
+	}
+	return append(segments, text)
+}
+
+func lineLocal(re *regexp.Regexp) bool {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return false
+	}
+	return !canCrossLine(parsed)
+}
+
+func canCrossLine(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpAnyChar, syntax.OpBeginText, syntax.OpEndText:
+		return true
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if r == '\n' {
+				return true
+			}
+		}
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if re.Rune[i] <= '\n' && '\n' <= re.Rune[i+1] {
+				return true
+			}
+		}
+	}
+	for _, sub := range re.Sub {
+		if canCrossLine(sub) {
+			return true
+		}
+	}
+	return false
+}
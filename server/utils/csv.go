@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type ColumnTransformOptions struct {
+	Column    string `json:"column" binding:"required"`
+	Delimiter string `json:"delimiter"`
+	Header    bool   `json:"header"`
+}
+
+func csvDelimiter(delimiter string) (rune, error) {
+	switch delimiter {
+	case "":
+		return ',', nil
+	case `\t`, "tab":
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return 0, fmt.Errorf("%w: delimiter must be a single character, got %q", ErrInvalidOption, delimiter)
+	}
+	return r, nil
+}
+
+func columnIndex(column string, header []string) (int, error) {
+	if n, err := strconv.Atoi(column); err == nil {
+		if n < 1 {
+			return 0, fmt.Errorf("%w: column numbers start at 1, got %d", ErrInvalidOption, n)
+		}
+		return n - 1, nil
+	}
+	for i, name := range header {
+		if strings.TrimSpace(name) == column {
+			return i, nil
+		}
+	}
+	if header == nil {
+		return 0, fmt.Errorf("%w: column %q must be a 1-based number unless header is set", ErrInvalidOption, column)
+	}
+	return 0, fmt.Errorf("%w: no column named %q", ErrInvalidOption, column)
+}
+
+func TransformColumn(text string, opts ColumnTransformOptions, fn func(string) (string, error)) (string, error) {
+	comma, err := csvDelimiter(opts.Delimiter)
+	if err != nil {
+		return "", err
+	}
+	r := csv.NewReader(strings.NewReader(text))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return "", fmt.Errorf("invalid delimited input: %v", err)
+	}
+
+	var header []string
+	if opts.Header && len(records) > 0 {
+		header = records[0]
+	}
+	col, err := columnIndex(opts.Column, header)
+	if err != nil {
+		return "", err
+	}
+
+	for i, record := range records {
+		if i == 0 && opts.Header || col >= len(record) {
+			continue
+		}
+		out, err := fn(record[col])
+		if err != nil {
+			return "", fmt.Errorf("row %d: %w", i+1, err)
+		}
+		record[col] = out
+	}
+
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Comma = comma
+	w.UseCRLF = strings.Contains(text, "\r\n")
+	if err := w.WriteAll(records); err != nil {
+		return "", err
+	}
+	out := b.String()
+	if !strings.HasSuffix(text, "\n") {
+		out = strings.TrimRight(out, "\r\n")
+	}
+	return out, nil
+}
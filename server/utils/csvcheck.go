@@ -0,0 +1,380 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type CSVOptions struct {
+	Delimiter string `json:"delimiter"`
+	Header    bool   `json:"header"`
+	Columns   int    `json:"columns"`
+
+	Pad             bool   `json:"pad"`
+	Truncate        bool   `json:"truncate"`
+	QuoteAll        bool   `json:"quoteAll"`
+	OutputDelimiter string `json:"outputDelimiter"`
+}
+
+// MaxCSVColumns is the widest row columns may ask for, the same as a
+// spreadsheet's limit
+const MaxCSVColumns = 16384
+
+func (o CSVOptions) Validate() error {
+	if o.Columns < 0 || o.Columns > MaxCSVColumns {
+		return fmt.Errorf("%w: columns must be between 0 and %d, got %d", ErrInvalidOption, MaxCSVColumns, o.Columns)
+	}
+	if o.Delimiter != "auto" {
+		if _, err := csvDelimiter(o.Delimiter); err != nil {
+			return err
+		}
+	}
+	if o.OutputDelimiter != "" {
+		if _, err := csvDelimiter(o.OutputDelimiter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	CSVIssueColumns = "columns"
+	CSVIssueQuote   = "quote"
+	CSVIssueType    = "type"
+)
+
+type CSVIssue struct {
+	Row     int    `json:"row"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+type CSVColumn struct {
+	Column     int    `json:"column"`
+	Name       string `json:"name,omitempty"`
+	Type       string `json:"type"`
+	Mismatches int    `json:"mismatches"`
+}
+
+type CSVReport struct {
+	Rows      int         `json:"rows"`
+	Columns   int         `json:"columns"`
+	Delimiter string      `json:"delimiter"`
+	Valid     bool        `json:"valid"`
+	Issues    []CSVIssue  `json:"issues"`
+	Types     []CSVColumn `json:"types"`
+}
+
+type csvRecord struct {
+	fields []string
+	line   int
+	issues []string
+}
+
+var (
+	csvIntegerPattern = regexp.MustCompile(`^[+-]?\d+$`)
+	csvNumberPattern  = regexp.MustCompile(`^[+-]?(?:\d+\.?\d*|\.\d+)(?:[eE][+-]?\d+)?$`)
+	csvDatePattern    = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?)?$`)
+)
+
+func csvValueType(v string) string {
+	v = strings.TrimSpace(v)
+	switch {
+	case v == "":
+		return "empty"
+	case csvIntegerPattern.MatchString(v):
+		return "integer"
+	case csvNumberPattern.MatchString(v):
+		return "number"
+	case csvDatePattern.MatchString(v):
+		return "date"
+	}
+	switch strings.ToLower(v) {
+	case "true", "false", "yes", "no":
+		return "boolean"
+	}
+	return "string"
+}
+
+func csvTypeFits(value, column string) bool {
+	return value == column || value == "empty" || column == "string" || value == "integer" && column == "number"
+}
+
+// the candidate that splits the most lines into the same, largest field count wins
+func sniffDelimiter(text string) rune {
+	lines := strings.Split(text, "\n")
+	if len(lines) > 20 {
+		lines = lines[:20]
+	}
+	best, bestScore := ',', 0
+	for _, c := range []rune{',', ';', '\t', '|'} {
+		counts := make(map[int]int)
+		for _, line := range lines {
+			if strings.TrimSpace(line) != "" {
+				counts[strings.Count(line, string(c))]++
+			}
+		}
+		for n, hits := range counts {
+			if score := n * hits; n > 0 && score > bestScore {
+				best, bestScore = c, score
+			}
+		}
+	}
+	return best
+}
+
+// parseCSVRecords is deliberately forgiving: a quote that never closes is
+// reported and then read literally up to the end of its line.
+func parseCSVRecords(text string, comma rune) []csvRecord {
+	var records []csvRecord
+	runes := []rune(strings.ReplaceAll(text, "\r\n", "\n"))
+	line := 1
+	for pos := 0; pos < len(runes); {
+		rec := csvRecord{line: line}
+		next, lines, ok := parseCSVRecord(runes, pos, comma, false, &rec)
+		if !ok {
+			rec = csvRecord{line: line, issues: []string{"unbalanced quote"}}
+			next, lines, _ = parseCSVRecord(runes, pos, comma, true, &rec)
+		}
+		pos = next
+		line += lines
+		if len(rec.fields) == 1 && rec.fields[0] == "" && len(rec.issues) == 0 {
+			continue
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func parseCSVRecord(runes []rune, pos int, comma rune, literal bool, rec *csvRecord) (int, int, bool) {
+	var field strings.Builder
+	lines := 0
+	inQuotes, fieldStart := false, true
+	for ; pos < len(runes); pos++ {
+		c := runes[pos]
+		if inQuotes {
+			switch {
+			case c == '"' && pos+1 < len(runes) && runes[pos+1] == '"':
+				field.WriteRune('"')
+				pos++
+			case c == '"':
+				inQuotes = false
+				if pos+1 < len(runes) && runes[pos+1] != comma && runes[pos+1] != '\n' {
+					rec.issues = append(rec.issues, fmt.Sprintf("text after closing quote in field %d", len(rec.fields)+1))
+				}
+			default:
+				if c == '\n' {
+					lines++
+				}
+				field.WriteRune(c)
+			}
+			continue
+		}
+		switch {
+		case c == comma:
+			rec.fields = append(rec.fields, field.String())
+			field.Reset()
+			fieldStart = true
+			continue
+		case c == '\n':
+			rec.fields = append(rec.fields, field.String())
+			return pos + 1, lines + 1, true
+		case c == '"' && fieldStart && !literal:
+			inQuotes = true
+		case c == '"' && !literal:
+			rec.issues = append(rec.issues, fmt.Sprintf("bare quote in field %d", len(rec.fields)+1))
+			field.WriteRune(c)
+		default:
+			field.WriteRune(c)
+		}
+		fieldStart = false
+	}
+	if inQuotes {
+		return pos, lines, false
+	}
+	rec.fields = append(rec.fields, field.String())
+	return pos, lines, true
+}
+
+func ValidateCSV(text string, opts CSVOptions) (CSVReport, error) {
+	report, _, err := checkCSV(text, opts)
+	return report, err
+}
+
+func checkCSV(text string, opts CSVOptions) (CSVReport, []csvRecord, error) {
+	if err := opts.Validate(); err != nil {
+		return CSVReport{}, nil, err
+	}
+	var comma rune
+	if opts.Delimiter == "auto" {
+		comma = sniffDelimiter(text)
+	} else {
+		comma, _ = csvDelimiter(opts.Delimiter)
+	}
+
+	records := parseCSVRecords(text, comma)
+	report := CSVReport{Rows: len(records), Delimiter: string(comma), Issues: []CSVIssue{}, Types: []CSVColumn{}}
+
+	expected := opts.Columns
+	if expected == 0 && opts.Header && len(records) > 0 {
+		expected = len(records[0].fields)
+	}
+	if expected == 0 {
+		counts := make(map[int]int)
+		for _, r := range records {
+			counts[len(r.fields)]++
+		}
+		for n, c := range counts {
+			if c > counts[expected] || c == counts[expected] && n > expected {
+				expected = n
+			}
+		}
+	}
+	report.Columns = expected
+
+	for i, r := range records {
+		for _, issue := range r.issues {
+			report.Issues = append(report.Issues, CSVIssue{Row: i + 1, Line: r.line, Kind: CSVIssueQuote, Message: issue})
+		}
+		if len(r.fields) != expected {
+			report.Issues = append(report.Issues, CSVIssue{Row: i + 1, Line: r.line, Kind: CSVIssueColumns, Message: fmt.Sprintf("expected %d fields, got %d", expected, len(r.fields))})
+		}
+	}
+
+	body := records
+	if opts.Header && len(body) > 0 {
+		body = body[1:]
+	}
+	for col := 0; col < expected; col++ {
+		votes := make(map[string]int)
+		for _, r := range body {
+			if col < len(r.fields) {
+				if t := csvValueType(r.fields[col]); t != "empty" {
+					votes[t]++
+				}
+			}
+		}
+		if votes["integer"] > 0 && votes["number"] > 0 {
+			votes["number"] += votes["integer"]
+			delete(votes, "integer")
+		}
+		colType, best := "empty", 0
+		for t, n := range votes {
+			if n > best || n == best && t < colType {
+				colType, best = t, n
+			}
+		}
+		column := CSVColumn{Column: col + 1, Type: colType}
+		if opts.Header && len(records) > 0 && col < len(records[0].fields) {
+			column.Name = records[0].fields[col]
+		}
+		// a column is only typed when most of its values agree
+		if total := sumValues(votes); colType != "string" && best*2 > total {
+			for i, r := range body {
+				if col >= len(r.fields) || csvTypeFits(csvValueType(r.fields[col]), colType) {
+					continue
+				}
+				column.Mismatches++
+				row := i + 1
+				if opts.Header {
+					row++
+				}
+				report.Issues = append(report.Issues, CSVIssue{Row: row, Line: r.line, Column: col + 1, Kind: CSVIssueType, Message: fmt.Sprintf("expected %s, got %q", colType, r.fields[col])})
+			}
+		} else if colType != "empty" {
+			column.Type = "string"
+		}
+		report.Types = append(report.Types, column)
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool { return report.Issues[i].Row < report.Issues[j].Row })
+	report.Valid = len(report.Issues) == 0
+	return report, records, nil
+}
+
+func sumValues(m map[string]int) int {
+	total := 0
+	for _, n := range m {
+		total += n
+	}
+	return total
+}
+
+func FixCSV(text string, opts CSVOptions) (string, CSVReport, error) {
+	report, records, err := checkCSV(text, opts)
+	if err != nil {
+		return "", report, err
+	}
+	if opts.Pad {
+		// padding every row out to a width no row has would let a short
+		// input grow without bound
+		widest := 0
+		for _, r := range records {
+			widest = max(widest, len(r.fields))
+		}
+		if report.Columns > widest {
+			return "", report, fmt.Errorf("%w: columns %d is wider than the widest row (%d fields)", ErrInvalidOption, report.Columns, widest)
+		}
+	}
+	comma := []rune(report.Delimiter)[0]
+	if opts.OutputDelimiter != "" {
+		comma, _ = csvDelimiter(opts.OutputDelimiter)
+	}
+
+	rows := make([][]string, len(records))
+	for i, r := range records {
+		fields := r.fields
+		if opts.Pad && len(fields) < report.Columns {
+			fields = append(fields, make([]string, report.Columns-len(fields))...)
+		}
+		if opts.Truncate && len(fields) > report.Columns {
+			fields = fields[:report.Columns]
+		}
+		rows[i] = fields
+	}
+
+	crlf := strings.Contains(text, "\r\n")
+	var out string
+	if opts.QuoteAll {
+		out = quoteAllCSV(rows, comma, crlf)
+	} else {
+		var b bytes.Buffer
+		w := csv.NewWriter(&b)
+		w.Comma = comma
+		w.UseCRLF = crlf
+		if err := w.WriteAll(rows); err != nil {
+			return "", report, err
+		}
+		out = b.String()
+	}
+	if !strings.HasSuffix(text, "\n") {
+		out = strings.TrimRight(out, "\r\n")
+	}
+	return out, report, nil
+}
+
+func quoteAllCSV(rows [][]string, comma rune, crlf bool) string {
+	eol := "\n"
+	if crlf {
+		eol = "\r\n"
+	}
+	var b strings.Builder
+	for _, row := range rows {
+		for i, field := range row {
+			if i > 0 {
+				b.WriteRune(comma)
+			}
+			b.WriteByte('"')
+			b.WriteString(strings.ReplaceAll(field, `"`, `""`))
+			b.WriteByte('"')
+		}
+		b.WriteString(eol)
+	}
+	return b.String()
+}
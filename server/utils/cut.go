@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type columnRange struct {
+	from, to int
+}
+
+func parseColumnRanges(spec string) ([]columnRange, error) {
+	var ranges []columnRange
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		r := columnRange{from: 1, to: -1}
+		var err error
+		if lo != "" {
+			if r.from, err = strconv.Atoi(lo); err != nil || r.from < 1 {
+				return nil, fmt.Errorf("%w: invalid column range %q", ErrInvalidOption, part)
+			}
+		}
+		switch {
+		case !isRange:
+			r.to = r.from
+		case hi != "":
+			if r.to, err = strconv.Atoi(hi); err != nil || r.to < r.from {
+				return nil, fmt.Errorf("%w: invalid column range %q", ErrInvalidOption, part)
+			}
+		case lo == "":
+			return nil, fmt.Errorf("%w: invalid column range %q", ErrInvalidOption, part)
+		}
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("%w: column ranges must not be empty", ErrInvalidOption)
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].from < ranges[j].from })
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if last.to < 0 || r.from <= last.to+1 {
+			if last.to >= 0 && (r.to < 0 || r.to > last.to) {
+				last.to = r.to
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged, nil
+}
+
+func CutColumns(text string, ranges string) (string, error) {
+	parsed, err := parseColumnRanges(ranges)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		body := strings.TrimSuffix(line, "\r")
+		clusters := Graphemes(body)
+		var b strings.Builder
+		for _, r := range parsed {
+			end := r.to
+			if end < 0 || end > len(clusters) {
+				end = len(clusters)
+			}
+			for c := r.from - 1; c < end; c++ {
+				b.WriteString(clusters[c])
+			}
+		}
+		if len(body) < len(line) {
+			b.WriteByte('\r')
+		}
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n"), nil
+}
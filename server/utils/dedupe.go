@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+)
+
+type DedupeOptions struct {
+	Trim              bool        `json:"trim"`
+	IgnoreCase        bool        `json:"ignoreCase"`
+	NormalizeUnicode  bool        `json:"normalizeUnicode"`
+	IgnorePunctuation bool        `json:"ignorePunctuation"`
+	Equivalence       Equivalence `json:"equivalence"`
+}
+
+type DuplicateLine struct {
+	Line        string `json:"line"`
+	Count       int    `json:"count"`
+	LineNumbers []int  `json:"lineNumbers"`
+}
+
+type DuplicateReport struct {
+	TotalLines  int             `json:"totalLines"`
+	UniqueLines int             `json:"uniqueLines"`
+	Histogram   map[string]int  `json:"histogram"`
+	Duplicates  []DuplicateLine `json:"duplicates"`
+}
+
+func (o DedupeOptions) key(line string) string {
+	switch {
+	case o.Equivalence != "" && o.Equivalence != EquivalenceNone:
+		line = o.Equivalence.Normalize(line)
+	case o.NormalizeUnicode:
+		line = EquivalenceCanonical.Normalize(line)
+	}
+	if o.IgnorePunctuation {
+		line = strings.Map(func(r rune) rune {
+			if unicode.IsPunct(r) {
+				return -1
+			}
+			return r
+		}, line)
+	}
+	if o.Trim {
+		line = strings.TrimSpace(line)
+	}
+	if o.IgnoreCase {
+		line = cases.Fold().String(line)
+	}
+	return line
+}
+
+// RemoveDuplicateLinesWithOptions switches to an external merge sort above
+// ExternalSortThreshold, staying in memory if temporary files fail
+func RemoveDuplicateLinesWithOptions(text string, opts DedupeOptions) string {
+	if len(text) > ExternalSortThreshold {
+		if out, err := RemoveDuplicateLinesContext(context.Background(), text, opts); err == nil {
+			return out
+		}
+	}
+	lines := strings.Split(text, "\n")
+	seen := make(map[string]bool)
+	var result []string
+
+	for _, line := range lines {
+		k := opts.key(line)
+		if !seen[k] {
+			seen[k] = true
+			result = append(result, line)
+		}
+	}
+
+	return strings.Join(result, "\n")
+}
+
+func FindDuplicateLines(text string, opts DedupeOptions) DuplicateReport {
+	lines := strings.Split(text, "\n")
+	index := make(map[string]int)
+	var groups []DuplicateLine
+
+	for i, line := range lines {
+		k := opts.key(line)
+		g, ok := index[k]
+		if !ok {
+			g = len(groups)
+			index[k] = g
+			groups = append(groups, DuplicateLine{Line: line})
+		}
+		groups[g].Count++
+		groups[g].LineNumbers = append(groups[g].LineNumbers, i+1)
+	}
+
+	report := DuplicateReport{
+		TotalLines:  len(lines),
+		UniqueLines: len(groups),
+		Histogram:   make(map[string]int, len(groups)),
+		Duplicates:  []DuplicateLine{},
+	}
+	for _, g := range groups {
+		report.Histogram[g.Line] = g.Count
+		if g.Count > 1 {
+			report.Duplicates = append(report.Duplicates, g)
+		}
+	}
+
+	return report
+}
+
+type ParagraphDedupeOptions struct {
+	DedupeOptions
+	// Threshold is the similarity, in (0, 1], at which two paragraphs count
+	// as the same; below 1 it uses near-duplicate detection
+	Threshold float64 `json:"threshold"`
+	// MinWords spares shorter paragraphs, such as repeated headings or
+	// sign-offs, from being removed
+	MinWords int `json:"minWords" min:"0"`
+}
+
+type RemovedParagraph struct {
+	Index       int     `json:"index"`
+	DuplicateOf int     `json:"duplicateOf"`
+	Similarity  float64 `json:"similarity"`
+	Text        string  `json:"text"`
+}
+
+// RemoveDuplicateParagraphs keeps the first of every group of repeated
+// blank-line separated paragraphs. Paragraphs are compared with their
+// whitespace collapsed, so the same text wrapped differently still matches.
+func RemoveDuplicateParagraphs(text string, opts ParagraphDedupeOptions) (string, []RemovedParagraph, error) {
+	if opts.Threshold == 0 {
+		opts.Threshold = 1
+	}
+	if opts.Threshold < 0 || opts.Threshold > 1 {
+		return "", nil, fmt.Errorf("%w: threshold must be in (0, 1], got %v", ErrInvalidOption, opts.Threshold)
+	}
+	if opts.MinWords < 0 {
+		return "", nil, fmt.Errorf("%w: minWords cannot be negative, got %d", ErrInvalidOption, opts.MinWords)
+	}
+
+	body := strings.TrimRight(text, "\n")
+	paragraphs := paragraphBreak.Split(body, -1)
+	keys := make([]string, len(paragraphs))
+	for i, p := range paragraphs {
+		words := strings.Fields(opts.key(p))
+		// too short to be dropped, and an empty key never matches
+		if len(words) >= max(opts.MinWords, 1) {
+			keys[i] = strings.Join(words, " ")
+		}
+	}
+
+	duplicateOf := make([]int, len(paragraphs))
+	similarities := make([]float64, len(paragraphs))
+	for i := range duplicateOf {
+		duplicateOf[i] = -1
+	}
+	if opts.Threshold == 1 {
+		first := make(map[string]int)
+		for i, k := range keys {
+			if k == "" {
+				continue
+			}
+			if j, ok := first[k]; ok {
+				duplicateOf[i], similarities[i] = j, 1
+				continue
+			}
+			first[k] = i
+		}
+	} else {
+		clusters, err := NearDuplicateDocuments(keys, opts.Threshold)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, cluster := range clusters {
+			first := cluster.Items[0].Index
+			kept := minHash(shingles(keys[first]))
+			for _, item := range cluster.Items[1:] {
+				duplicateOf[item.Index] = first
+				similarities[item.Index] = similarity(kept, minHash(shingles(keys[item.Index])))
+			}
+		}
+	}
+
+	removed := []RemovedParagraph{}
+	kept := make([]string, 0, len(paragraphs))
+	for i, p := range paragraphs {
+		if duplicateOf[i] < 0 {
+			kept = append(kept, p)
+			continue
+		}
+		removed = append(removed, RemovedParagraph{Index: i, DuplicateOf: duplicateOf[i], Similarity: similarities[i], Text: p})
+	}
+	return strings.Join(kept, "\n\n") + text[len(body):], removed, nil
+}
@@ -0,0 +1,333 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/cases"
+)
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+type DiffOp struct {
+	Kind   string   `json:"kind"`
+	AStart int      `json:"aStart"`
+	BStart int      `json:"bStart"`
+	Lines  []string `json:"lines"`
+}
+
+type DiffOptions struct {
+	IgnoreCase  bool        `json:"ignoreCase"`
+	Equivalence Equivalence `json:"equivalence"`
+}
+
+func (o DiffOptions) key(line string) string {
+	line = o.Equivalence.Normalize(line)
+	if o.IgnoreCase {
+		line = cases.Fold().String(line)
+	}
+	return line
+}
+
+func DiffLines(a, b string) []DiffOp {
+	ops, _ := DiffLinesContext(context.Background(), a, b)
+	return ops
+}
+
+func DiffLinesContext(ctx context.Context, a, b string) ([]DiffOp, error) {
+	return DiffLinesWithOptions(ctx, a, b, DiffOptions{})
+}
+
+func DiffLinesWithOptions(ctx context.Context, a, b string, opts DiffOptions) ([]DiffOp, error) {
+	if err := opts.Equivalence.Validate(); err != nil {
+		return nil, err
+	}
+	return diffSlices(ctx, strings.Split(a, "\n"), strings.Split(b, "\n"), opts.key)
+}
+
+func diffSlices(ctx context.Context, a, b []string, key func(string) string) ([]DiffOp, error) {
+	ids := make(map[string]int)
+	intern := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, l := range lines {
+			k := key(l)
+			id, ok := ids[k]
+			if !ok {
+				id = len(ids)
+				ids[k] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	x, y := intern(a), intern(b)
+
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	edits, err := myers(ctx, x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []DiffOp
+	push := func(kind string, ai, bi int, line string) {
+		if n := len(ops); n > 0 && ops[n-1].Kind == kind {
+			ops[n-1].Lines = append(ops[n-1].Lines, line)
+			return
+		}
+		ops = append(ops, DiffOp{Kind: kind, AStart: ai, BStart: bi, Lines: []string{line}})
+	}
+
+	for i := 0; i < prefix; i++ {
+		push(DiffEqual, i, i, a[i])
+	}
+	ai, bi := prefix, prefix
+	for _, e := range edits {
+		switch e {
+		case DiffEqual:
+			push(DiffEqual, ai, bi, a[ai])
+			ai++
+			bi++
+		case DiffDelete:
+			push(DiffDelete, ai, bi, a[ai])
+			ai++
+		case DiffInsert:
+			push(DiffInsert, ai, bi, b[bi])
+			bi++
+		}
+	}
+	for ai < len(a) {
+		push(DiffEqual, ai, bi, a[ai])
+		ai++
+		bi++
+	}
+
+	return ops, nil
+}
+
+// myers finds a shortest edit script in linear space: the middle snake of
+// the forward and backward searches splits the problem in two, so memory
+// stays O(N+M) however far apart the inputs are
+func myers(ctx context.Context, x, y []int) ([]string, error) {
+	if len(x)+len(y) == 0 {
+		return nil, nil
+	}
+	m := &myersDiff{ctx: ctx, x: x, y: y}
+	if err := m.compare(0, len(x), 0, len(y)); err != nil {
+		return nil, err
+	}
+	return m.edits, nil
+}
+
+type myersDiff struct {
+	ctx   context.Context
+	x, y  []int
+	edits []string
+}
+
+func (m *myersDiff) emit(kind string, n int) {
+	for ; n > 0; n-- {
+		m.edits = append(m.edits, kind)
+	}
+}
+
+func (m *myersDiff) compare(x0, x1, y0, y1 int) error {
+	prefix := 0
+	for x0+prefix < x1 && y0+prefix < y1 && m.x[x0+prefix] == m.y[y0+prefix] {
+		prefix++
+	}
+	m.emit(DiffEqual, prefix)
+	x0, y0 = x0+prefix, y0+prefix
+	suffix := 0
+	for x0 < x1-suffix && y0 < y1-suffix && m.x[x1-1-suffix] == m.y[y1-1-suffix] {
+		suffix++
+	}
+	x1, y1 = x1-suffix, y1-suffix
+
+	switch {
+	case x0 == x1:
+		m.emit(DiffInsert, y1-y0)
+	case y0 == y1:
+		m.emit(DiffDelete, x1-x0)
+	default:
+		// with the common ends gone both sides differ by two edits or more,
+		// so each half is strictly smaller than the whole
+		xs, ys, xe, ye, err := m.middleSnake(x0, x1, y0, y1)
+		if err != nil {
+			return err
+		}
+		if err := m.compare(x0, xs, y0, ys); err != nil {
+			return err
+		}
+		m.emit(DiffEqual, xe-xs)
+		if err := m.compare(xe, x1, ye, y1); err != nil {
+			return err
+		}
+	}
+	m.emit(DiffEqual, suffix)
+	return nil
+}
+
+// middleSnake runs the forward search from the start and the backward one
+// from the end until they overlap, and returns the snake where they do
+func (m *myersDiff) middleSnake(x0, x1, y0, y1 int) (xs, ys, xe, ye int, err error) {
+	n, mm := x1-x0, y1-y0
+	delta := n - mm
+	limit := (n+mm+1)/2 + 1
+	offset := limit + 1
+	vf := make([]int, 2*offset+1)
+	vb := make([]int, 2*offset+1)
+	vf[1+offset], vb[1+offset] = 0, 0
+
+	for d := 0; d < limit; d++ {
+		if d%64 == 0 {
+			if err := m.ctx.Err(); err != nil {
+				return 0, 0, 0, 0, err
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[k-1+offset] < vf[k+1+offset]) {
+				x = vf[k+1+offset]
+			} else {
+				x = vf[k-1+offset] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < mm && m.x[x0+x] == m.y[y0+y] {
+				x++
+				y++
+			}
+			vf[k+offset] = x
+			if kb := delta - k; delta%2 != 0 && kb >= -(d-1) && kb <= d-1 && x+vb[kb+offset] >= n {
+				return x0 + sx, y0 + sy, x0 + x, y0 + y, nil
+			}
+		}
+		// the backward search walks the reversed inputs
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vb[k-1+offset] < vb[k+1+offset]) {
+				x = vb[k+1+offset]
+			} else {
+				x = vb[k-1+offset] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < mm && m.x[x1-1-x] == m.y[y1-1-y] {
+				x++
+				y++
+			}
+			vb[k+offset] = x
+			if kf := delta - k; delta%2 == 0 && kf >= -d && kf <= d && x+vf[kf+offset] >= n {
+				return x1 - x, y1 - y, x1 - sx, y1 - sy, nil
+			}
+		}
+	}
+	return 0, 0, 0, 0, fmt.Errorf("diff did not converge")
+}
+
+func UnifiedDiff(a, b, nameA, nameB string, context int) string {
+	ops := DiffLines(a, b)
+	return FormatUnifiedDiff(ops, nameA, nameB, context)
+}
+
+func FormatUnifiedDiff(ops []DiffOp, nameA, nameB string, context int) string {
+	type line struct {
+		kind   string
+		text   string
+		ai, bi int
+	}
+	var lines []line
+	for _, op := range ops {
+		for i, l := range op.Lines {
+			ln := line{kind: op.Kind, text: l}
+			switch op.Kind {
+			case DiffEqual:
+				ln.ai, ln.bi = op.AStart+i, op.BStart+i
+			case DiffDelete:
+				ln.ai, ln.bi = op.AStart+i, op.BStart
+			case DiffInsert:
+				ln.ai, ln.bi = op.AStart, op.BStart+i
+			}
+			lines = append(lines, ln)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", nameA, nameB)
+
+	for i := 0; i < len(lines); {
+		if lines[i].kind == DiffEqual {
+			i++
+			continue
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(lines) {
+			if lines[end].kind != DiffEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].kind == DiffEqual {
+				run++
+			}
+			if run-end > 2*context || run == len(lines) {
+				end += context
+				if end > len(lines) {
+					end = len(lines)
+				}
+				break
+			}
+			end = run
+		}
+
+		aCount, bCount := 0, 0
+		for _, l := range lines[start:end] {
+			if l.kind != DiffInsert {
+				aCount++
+			}
+			if l.kind != DiffDelete {
+				bCount++
+			}
+		}
+		aStart, bStart := lines[start].ai+1, lines[start].bi+1
+		if aCount == 0 {
+			aStart--
+		}
+		if bCount == 0 {
+			bStart--
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, l := range lines[start:end] {
+			switch l.kind {
+			case DiffEqual:
+				b.WriteString(" ")
+			case DiffDelete:
+				b.WriteString("-")
+			case DiffInsert:
+				b.WriteString("+")
+			}
+			b.WriteString(l.text)
+			b.WriteString("\n")
+		}
+		i = end
+	}
+
+	return b.String()
+}
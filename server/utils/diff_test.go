@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// applyDiff rebuilds both sides from ops, checking the positions they carry
+func applyDiff(t *testing.T, ops []DiffOp) (a, b []string) {
+	t.Helper()
+	for _, op := range ops {
+		if op.AStart != len(a) || op.BStart != len(b) {
+			t.Fatalf("op %+v starts at a=%d b=%d", op, len(a), len(b))
+		}
+		if op.Kind != DiffInsert {
+			a = append(a, op.Lines...)
+		}
+		if op.Kind != DiffDelete {
+			b = append(b, op.Lines...)
+		}
+	}
+	return a, b
+}
+
+func editCount(ops []DiffOp) int {
+	n := 0
+	for _, op := range ops {
+		if op.Kind != DiffEqual {
+			n += len(op.Lines)
+		}
+	}
+	return n
+}
+
+// lcsEdits is the textbook quadratic edit distance the diff must match
+func lcsEdits(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return len(a) + len(b) - 2*prev[len(b)]
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []DiffOp
+	}{
+		{"a\nb", "a\nb", []DiffOp{{Kind: DiffEqual, Lines: []string{"a", "b"}}}},
+		{"a\nb\nc", "a\nc", []DiffOp{
+			{Kind: DiffEqual, Lines: []string{"a"}},
+			{Kind: DiffDelete, AStart: 1, BStart: 1, Lines: []string{"b"}},
+			{Kind: DiffEqual, AStart: 2, BStart: 1, Lines: []string{"c"}},
+		}},
+		{"a\nc", "a\nb\nc", []DiffOp{
+			{Kind: DiffEqual, Lines: []string{"a"}},
+			{Kind: DiffInsert, AStart: 1, BStart: 1, Lines: []string{"b"}},
+			{Kind: DiffEqual, AStart: 1, BStart: 2, Lines: []string{"c"}},
+		}},
+		{"x", "y", []DiffOp{
+			{Kind: DiffDelete, Lines: []string{"x"}},
+			{Kind: DiffInsert, AStart: 1, Lines: []string{"y"}},
+		}},
+	}
+	for _, tt := range tests {
+		if got := DiffLines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DiffLines(%q, %q) = %+v, want %+v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDiffLinesMinimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() []string {
+		lines := make([]string, rng.Intn(40))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+	for i := 0; i < 500; i++ {
+		a, b := random(), random()
+		ops := DiffLines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+		// Split never returns an empty slice, so empty input is one empty line
+		wantA, wantB := strings.Split(strings.Join(a, "\n"), "\n"), strings.Split(strings.Join(b, "\n"), "\n")
+		gotA, gotB := applyDiff(t, ops)
+		if !reflect.DeepEqual(gotA, wantA) || !reflect.DeepEqual(gotB, wantB) {
+			t.Fatalf("ops for %q -> %q rebuild %q -> %q", wantA, wantB, gotA, gotB)
+		}
+		if got, want := editCount(ops), lcsEdits(wantA, wantB); got != want {
+			t.Fatalf("%q -> %q: %d edits, shortest is %d", wantA, wantB, got, want)
+		}
+	}
+}
+
+func TestDiffLinesOptions(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		opts  DiffOptions
+		edits int
+	}{
+		{"Hello\nWorld", "hello\nworld", DiffOptions{}, 4},
+		{"Hello\nWorld", "hello\nworld", DiffOptions{IgnoreCase: true}, 0},
+		{"Straße", "STRASSE", DiffOptions{IgnoreCase: true}, 0},
+	}
+	for _, tt := range tests {
+		ops, err := DiffLinesWithOptions(context.Background(), tt.a, tt.b, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := editCount(ops); got != tt.edits {
+			t.Errorf("DiffLinesWithOptions(%q, %q, %+v) has %d edits, want %d", tt.a, tt.b, tt.opts, got, tt.edits)
+		}
+	}
+}
+
+func TestDiffLinesCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var a, b strings.Builder
+	for i := 0; i < 2000; i++ {
+		a.WriteString(string(rune('a'+i%7)) + "\n")
+		b.WriteString(string(rune('a'+i%5)) + "\n")
+	}
+	if _, err := DiffLinesContext(ctx, a.String(), b.String()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10"
+	want := "--- a\n+++ b\n" +
+		"@@ -2,3 +2,3 @@\n 2\n-3\n+three\n 4\n" +
+		"@@ -9,1 +9,2 @@\n 9\n+10\n"
+	if got := UnifiedDiff(a, b, "a", "b", 1); got != want {
+		t.Errorf("UnifiedDiff =\n%s\nwant\n%s", got, want)
+	}
+	if got := UnifiedDiff(a, a, "a", "b", 3); got != "--- a\n+++ b\n" {
+		t.Errorf("UnifiedDiff of equal inputs = %q", got)
+	}
+}
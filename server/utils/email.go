@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type EmailCleanOptions struct {
+	KeepQuoteLevels int  `json:"keepQuoteLevels" min:"0"`
+	KeepSignatures  bool `json:"keepSignatures"`
+}
+
+var (
+	attributionPattern      = regexp.MustCompile(`^(?:On\s.+\swrote|Am\s.+\sschrieb\s.+|Le\s.+\sa écrit\s?|El\s.+\sescribió|Op\s.+\sschreef\s.+)\s?:$`)
+	attributionStartPattern = regexp.MustCompile(`^(?:On|Am|Le|El|Op)\s.*\d`)
+	forwardedHeaderPattern  = regexp.MustCompile(`^(?:-{2,}\s*(?:Original Message|Forwarded message|Ursprüngliche Nachricht|Message d'origine)\s*-{2,}|_{20,})$`)
+)
+
+func quoteDepth(line string) (int, string) {
+	prefix, body := splitQuotePrefix(line)
+	return strings.Count(prefix, ">"), body
+}
+
+func CleanEmailText(text string) string {
+	out, _ := CleanEmailTextWithOptions(text, EmailCleanOptions{})
+	return out
+}
+
+func CleanEmailTextWithOptions(text string, opts EmailCleanOptions) (string, error) {
+	if opts.KeepQuoteLevels < 0 {
+		return "", fmt.Errorf("%w: keepQuoteLevels cannot be negative, got %d", ErrInvalidOption, opts.KeepQuoteLevels)
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var out []string
+	signatureDepth := -1
+	// forwarded or Outlook-style quoted messages carry no ">" prefix; everything after the header is one level deeper
+	extraDepth := 0
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		depth, body := quoteDepth(line)
+		depth += extraDepth
+		trimmed := strings.TrimSpace(body)
+
+		if forwardedHeaderPattern.MatchString(trimmed) {
+			extraDepth++
+			if depth+1 > opts.KeepQuoteLevels {
+				break
+			}
+			out = append(out, line)
+			continue
+		}
+
+		if attributionPattern.MatchString(trimmed) || attributionStartPattern.MatchString(trimmed) && i+1 < len(lines) && joinedAttribution(trimmed, lines[i+1]) {
+			if !attributionPattern.MatchString(trimmed) {
+				i++
+			}
+			signatureDepth = -1
+			if depth+1 > opts.KeepQuoteLevels {
+				continue
+			}
+			out = append(out, line)
+			if !attributionPattern.MatchString(trimmed) {
+				out = append(out, lines[i])
+			}
+			continue
+		}
+
+		if signatureDepth >= 0 && depth == signatureDepth {
+			continue
+		}
+		signatureDepth = -1
+		if !opts.KeepSignatures && (body == "-- " || trimmed == "--") {
+			signatureDepth = depth
+			continue
+		}
+
+		if depth > opts.KeepQuoteLevels {
+			continue
+		}
+		out = append(out, line)
+	}
+
+	var cleaned []string
+	for i, line := range out {
+		depth, body := quoteDepth(line)
+		if depth > 0 && strings.TrimSpace(body) == "" {
+			// quoted blank lines at the edge of a quote block are leftovers of removed content
+			if i+1 == len(out) || len(cleaned) == 0 {
+				continue
+			}
+			if next, _ := quoteDepth(out[i+1]); next != depth {
+				continue
+			}
+			if prev, _ := quoteDepth(cleaned[len(cleaned)-1]); prev != depth {
+				continue
+			}
+		}
+		blank := strings.TrimSpace(line) == ""
+		if blank && (len(cleaned) == 0 || strings.TrimSpace(cleaned[len(cleaned)-1]) == "") {
+			continue
+		}
+		cleaned = append(cleaned, line)
+	}
+	for len(cleaned) > 0 && strings.TrimSpace(cleaned[len(cleaned)-1]) == "" {
+		cleaned = cleaned[:len(cleaned)-1]
+	}
+	return strings.Join(cleaned, "\n"), nil
+}
+
+// mail clients often wrap long attribution lines before the "wrote:"
+func joinedAttribution(first, next string) bool {
+	_, body := quoteDepth(next)
+	return attributionPattern.MatchString(first + " " + strings.TrimSpace(body))
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	_ "embed"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Each line of the emoji list is the code points of a fully-qualified emoji,
+// joined by "-" and followed by "+" when it takes a skin tone, then its
+// shortcode and any aliases. Shortcodes are the Unicode (CLDR) names in
+// snake_case; aliases are the familiar GitHub and Slack ones.
+//
+//go:embed data/emoji.txt
+var emojiList string
+
+type emojiEntry struct {
+	emoji string
+	name  string
+	// text-default characters like © are only emoji when followed by U+FE0F
+	needsSelector bool
+	toneable      bool
+}
+
+const (
+	emojiSelector = '\uFE0F'
+	skinToneFirst = '\U0001F3FB'
+	skinToneLast  = '\U0001F3FF'
+)
+
+var emojiShortcodePattern = regexp.MustCompile(`:([a-z0-9_+-]+):(?::skin-tone-([2-6]):)?`)
+
+var emojiByName, emojiByKey, emojiMaxRunes, emojiStarts = func() (map[string]*emojiEntry, map[string]*emojiEntry, int, map[rune]bool) {
+	byName := make(map[string]*emojiEntry)
+	byKey := make(map[string]*emojiEntry)
+	starts := make(map[rune]bool)
+	longest := 0
+	for _, line := range strings.Split(emojiList, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		points, toneable := strings.CutSuffix(fields[0], "+")
+		var runes []rune
+		for _, hex := range strings.Split(points, "-") {
+			cp, err := strconv.ParseUint(hex, 16, 32)
+			if err != nil {
+				runes = nil
+				break
+			}
+			runes = append(runes, rune(cp))
+		}
+		if len(runes) == 0 {
+			continue
+		}
+		e := &emojiEntry{
+			emoji:         string(runes),
+			name:          fields[1],
+			needsSelector: len(runes) == 2 && runes[1] == emojiSelector,
+			toneable:      toneable,
+		}
+		for _, name := range fields[1:] {
+			byName[name] = e
+		}
+		byKey[emojiKey(runes)] = e
+		starts[runes[0]] = true
+		longest = max(longest, len(runes))
+	}
+	return byName, byKey, longest, starts
+}()
+
+func isSkinTone(r rune) bool {
+	return r >= skinToneFirst && r <= skinToneLast
+}
+
+// emojiKey drops variation selectors and skin tones, so every spelling of an
+// emoji finds the same entry
+func emojiKey(runes []rune) string {
+	var b strings.Builder
+	for _, r := range runes {
+		if r != emojiSelector && !isSkinTone(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// EmojiToShortcode replaces emoji with their shortcodes, ":rocket:" for U+1F680.
+// A skin tone follows as Slack writes it, ":thumbs_up::skin-tone-4:".
+func EmojiToShortcode(text string) string {
+	runes := []rune(text)
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		end, e := matchEmoji(runes, i)
+		if e == nil {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		b.WriteString(":" + e.name + ":")
+		for _, r := range runes[i:end] {
+			if isSkinTone(r) {
+				b.WriteString(":skin-tone-" + strconv.Itoa(int(r-skinToneFirst)+2) + ":")
+				break
+			}
+		}
+		i = end
+	}
+	return b.String()
+}
+
+// matchEmoji finds the longest emoji starting at runes[i]
+func matchEmoji(runes []rune, i int) (int, *emojiEntry) {
+	if !emojiStarts[runes[i]] {
+		return 0, nil
+	}
+	// skin tones and selectors can add a few runes to the longest entry
+	for end := min(len(runes), i+emojiMaxRunes+4); end > i; end-- {
+		e, ok := emojiByKey[emojiKey(runes[i:end])]
+		if !ok {
+			continue
+		}
+		if e.needsSelector && (end-i < 2 || runes[i+1] != emojiSelector) {
+			continue
+		}
+		// take a trailing selector or tone along rather than leave it dangling
+		for end < len(runes) && (runes[end] == emojiSelector || isSkinTone(runes[end])) {
+			end++
+		}
+		return end, e
+	}
+	return 0, nil
+}
+
+// ShortcodeToEmoji replaces known shortcodes and aliases with their emoji and
+// leaves anything else between colons alone
+func ShortcodeToEmoji(text string) string {
+	return emojiShortcodePattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := emojiShortcodePattern.FindStringSubmatch(m)
+		e, ok := emojiByName[sub[1]]
+		if !ok {
+			return m
+		}
+		if sub[2] == "" {
+			return e.emoji
+		}
+		tone := string(skinToneFirst + rune(sub[2][0]-'2'))
+		if !e.toneable {
+			return e.emoji + ":skin-tone-" + sub[2] + ":"
+		}
+		// the tone goes straight after the base character, replacing its selector
+		first, rest := []rune(e.emoji)[0], []rune(e.emoji)[1:]
+		if len(rest) > 0 && rest[0] == emojiSelector {
+			rest = rest[1:]
+		}
+		return string(first) + tone + string(rest)
+	})
+}
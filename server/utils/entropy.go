@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MinEntropyTokenLength  = 16
+	HexEntropyThreshold    = 3.0
+	Base64EntropyThreshold = 4.2
+)
+
+type EntropyToken struct {
+	Token   string  `json:"token"`
+	Column  int     `json:"column"`
+	Entropy float64 `json:"entropy"`
+	Charset string  `json:"charset"`
+}
+
+type LineEntropy struct {
+	Line    int            `json:"line"`
+	Entropy float64        `json:"entropy"`
+	Tokens  []EntropyToken `json:"tokens"`
+}
+
+type EntropyReport struct {
+	Entropy          float64       `json:"entropy"`
+	Bytes            int           `json:"bytes"`
+	CompressedBytes  int           `json:"compressedBytes"`
+	CompressionRatio float64       `json:"compressionRatio"`
+	FlaggedLines     []LineEntropy `json:"flaggedLines"`
+}
+
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func gzipSize(text string) int {
+	var b bytes.Buffer
+	w, _ := gzip.NewWriterLevel(&b, gzip.BestCompression)
+	w.Write([]byte(text))
+	w.Close()
+	return b.Len()
+}
+
+func tokenCharset(token string) string {
+	hex, b64 := true, true
+	for _, r := range token {
+		isHex := (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+		isB64 := (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || strings.ContainsRune("+/=-_", r)
+		hex = hex && isHex
+		b64 = b64 && isB64
+	}
+	switch {
+	case hex:
+		return "hex"
+	case b64:
+		return "base64"
+	}
+	return ""
+}
+
+func HighEntropyTokens(line string) []EntropyToken {
+	var tokens []EntropyToken
+	column := 0
+	for _, field := range strings.FieldsFunc(line, func(r rune) bool {
+		return strings.ContainsRune(" \t\"'`=:,;()[]{}<>", r)
+	}) {
+		idx := strings.Index(line[column:], field) + column
+		column = idx + len(field)
+		if len(field) < MinEntropyTokenLength {
+			continue
+		}
+		charset := tokenCharset(field)
+		entropy := ShannonEntropy(field)
+		if (charset == "hex" && entropy >= HexEntropyThreshold) || (charset == "base64" && entropy >= Base64EntropyThreshold) {
+			tokens = append(tokens, EntropyToken{
+				Token:   field,
+				Column:  utf8.RuneCountInString(line[:idx]) + 1,
+				Entropy: entropy,
+				Charset: charset,
+			})
+		}
+	}
+	return tokens
+}
+
+func AnalyzeEntropy(text string) EntropyReport {
+	report := EntropyReport{
+		Entropy:      ShannonEntropy(text),
+		Bytes:        len(text),
+		FlaggedLines: []LineEntropy{},
+	}
+	if len(text) > 0 {
+		report.CompressedBytes = gzipSize(text)
+		report.CompressionRatio = float64(report.CompressedBytes) / float64(len(text))
+	}
+
+	for i, line := range strings.Split(text, "\n") {
+		if tokens := HighEntropyTokens(line); len(tokens) > 0 {
+			report.FlaggedLines = append(report.FlaggedLines, LineEntropy{
+				Line:    i + 1,
+				Entropy: ShannonEntropy(line),
+				Tokens:  tokens,
+			})
+		}
+	}
+	return report
+}
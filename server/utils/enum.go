@@ -0,0 +1,17 @@
+package utils
+
+import "slices"
+
+// EnumValues lists the values of a string enum, for its Values method
+func EnumValues[T ~string](all []T) []string {
+	values := make([]string, len(all))
+	for i, v := range all {
+		values[i] = string(v)
+	}
+	return values
+}
+
+// EnumValid reports whether v is one of all, for an enum's Valid method
+func EnumValid[T ~string](all []T, v T) bool {
+	return slices.Contains(all, v)
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"fmt"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type Equivalence string
+
+const (
+	EquivalenceNone          Equivalence = "none"
+	EquivalenceCanonical     Equivalence = "canonical"
+	EquivalenceCompatibility Equivalence = "compatibility"
+)
+
+var Equivalences = []Equivalence{EquivalenceNone, EquivalenceCanonical, EquivalenceCompatibility}
+
+func (Equivalence) Values() []string {
+	return EnumValues(Equivalences)
+}
+
+func (e Equivalence) Valid() bool {
+	switch e {
+	case "", EquivalenceNone, EquivalenceCanonical, EquivalenceCompatibility:
+		return true
+	}
+	return false
+}
+
+func (e Equivalence) Validate() error {
+	if !e.Valid() {
+		return fmt.Errorf("%w: unknown equivalence %q", ErrInvalidOption, e)
+	}
+	return nil
+}
+
+func (e Equivalence) Normalize(s string) string {
+	switch e {
+	case EquivalenceCanonical:
+		return norm.NFC.String(s)
+	case EquivalenceCompatibility:
+		return norm.NFKC.String(s)
+	default:
+		return s
+	}
+}
+
+func Equivalent(a, b string, e Equivalence) bool {
+	return e.Normalize(a) == e.Normalize(b)
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrUnknownCaseType = errors.New("unknown case type")
+	ErrInvalidPattern  = errors.New("invalid pattern")
+	ErrInputTooLarge   = errors.New("input too large")
+	ErrInvalidOption   = errors.New("invalid option")
+	ErrLimitExceeded   = errors.New("limit exceeded")
+	ErrBinaryInput     = errors.New("binary input")
+)
+
+// ErrorStatus pairs a sentinel error with the HTTP status it maps to
+type ErrorStatus struct {
+	Err    error
+	Status int
+}
+
+// StatusFor maps err to an HTTP status. Limit errors carry their own; then
+// come the package's own sentinels in statuses, in order, then the ones
+// shared by every package, and fallback for anything else.
+func StatusFor(err error, fallback int, statuses ...ErrorStatus) int {
+	var lerr *LimitError
+	if errors.As(err, &lerr) {
+		return lerr.Status()
+	}
+	for _, s := range statuses {
+		if errors.Is(err, s.Err) {
+			return s.Status
+		}
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, ErrInputTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrBinaryInput):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrInvalidPattern),
+		errors.Is(err, ErrUnknownCaseType),
+		errors.Is(err, ErrInvalidOption):
+		return http.StatusBadRequest
+	}
+	return fallback
+}
@@ -0,0 +1,359 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ExternalSortThreshold = 64 << 20
+	ExternalRunSize       = 16 << 20
+)
+
+// viaStream runs one of the stream variants over text already held in
+// memory, which still saves the keys, indexes and copies an in-memory sort
+// of a very large text would allocate
+func viaStream(ctx context.Context, text string, stream func(context.Context, io.Reader, io.Writer) error) (string, error) {
+	var b strings.Builder
+	b.Grow(len(text))
+	if err := stream(ctx, strings.NewReader(text), &b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func SortLinesStream(r io.Reader, w io.Writer, opts SortOptions) error {
+	return SortLinesStreamContext(context.Background(), r, w, opts)
+}
+
+func SortLinesStreamContext(ctx context.Context, r io.Reader, w io.Writer, opts SortOptions) error {
+	head, rest, err := readHead(r, ExternalSortThreshold)
+	if err != nil {
+		return err
+	}
+	if rest == nil {
+		_, err := io.WriteString(w, SortLinesWithOptions(string(head), opts))
+		return err
+	}
+
+	compare := lessFunc(opts.Compare)
+	less := func(a, b string) bool {
+		if opts.Ascending {
+			return compare(sortKey(a, opts), sortKey(b, opts))
+		}
+		return compare(sortKey(b, opts), sortKey(a, opts))
+	}
+
+	out := newLineWriter(w)
+	input := io.MultiReader(bytes.NewReader(head), rest)
+	feed := func(add func(string) error) error {
+		return readLines(input, add)
+	}
+	if err := externalSort(ctx, feed, less, ExternalRunSize, out.write); err != nil {
+		return err
+	}
+	return out.flush()
+}
+
+func RemoveDuplicateLinesStream(r io.Reader, w io.Writer, opts DedupeOptions) error {
+	return RemoveDuplicateLinesStreamContext(context.Background(), r, w, opts)
+}
+
+func RemoveDuplicateLinesStreamContext(ctx context.Context, r io.Reader, w io.Writer, opts DedupeOptions) error {
+	head, rest, err := readHead(r, ExternalSortThreshold)
+	if err != nil {
+		return err
+	}
+	if rest == nil {
+		_, err := io.WriteString(w, RemoveDuplicateLinesWithOptions(string(head), opts))
+		return err
+	}
+
+	tagged, err := os.CreateTemp("", "textforge-dedupe-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tagged.Name())
+	defer tagged.Close()
+
+	byKey := func(a, b string) bool {
+		ka, kb := opts.key(a[17:]), opts.key(b[17:])
+		if ka != kb {
+			return ka < kb
+		}
+		return a[:16] < b[:16]
+	}
+
+	firsts := bufio.NewWriter(tagged)
+	var lastKey *string
+	input := io.MultiReader(bytes.NewReader(head), rest)
+	var seq int64
+	tag := func(add func(string) error) error {
+		return readLines(input, func(line string) error {
+			seq++
+			return add(fmt.Sprintf("%016x\t%s", seq, line))
+		})
+	}
+
+	err = externalSort(ctx, tag, byKey, ExternalRunSize, func(rec string) error {
+		k := opts.key(rec[17:])
+		if lastKey != nil && *lastKey == k {
+			return nil
+		}
+		lastKey = &k
+		_, err := firsts.WriteString(rec + "\n")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := firsts.Flush(); err != nil {
+		return err
+	}
+	if _, err := tagged.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	bySeq := func(a, b string) bool {
+		return a[:16] < b[:16]
+	}
+
+	out := newLineWriter(w)
+	survivors := func(add func(string) error) error {
+		br := bufio.NewReader(tagged)
+		for {
+			rec, err := br.ReadString('\n')
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := add(rec[:len(rec)-1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	err = externalSort(ctx, survivors, bySeq, ExternalRunSize, func(rec string) error {
+		return out.write(rec[17:])
+	})
+	if err != nil {
+		return err
+	}
+	return out.flush()
+}
+
+func readHead(r io.Reader, limit int) ([]byte, io.Reader, error) {
+	head, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(head) <= limit {
+		return head, nil, nil
+	}
+	return head, r, nil
+}
+
+type lineWriter struct {
+	w     *bufio.Writer
+	first bool
+}
+
+func newLineWriter(w io.Writer) *lineWriter {
+	return &lineWriter{w: bufio.NewWriter(w), first: true}
+}
+
+func (l *lineWriter) write(line string) error {
+	if !l.first {
+		if err := l.w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	l.first = false
+	_, err := l.w.WriteString(line)
+	return err
+}
+
+func (l *lineWriter) flush() error {
+	return l.w.Flush()
+}
+
+func readLines(r io.Reader, fn func(string) error) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if err == io.EOF {
+			return fn(line)
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(line[:len(line)-1]); err != nil {
+			return err
+		}
+	}
+}
+
+func externalSort(ctx context.Context, feed func(add func(string) error) error, less func(a, b string) bool, runSize int, emit func(string) error) error {
+	var runs []*os.File
+	defer func() {
+		for _, f := range runs {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	var buf []string
+	size := 0
+	spill := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		sort.SliceStable(buf, func(i, j int) bool { return less(buf[i], buf[j]) })
+		f, err := os.CreateTemp("", "textforge-run-*")
+		if err != nil {
+			return fmt.Errorf("failed to create temp file: %v", err)
+		}
+		runs = append(runs, f)
+		slog.DebugContext(ctx, "external sort spilled run", "run", len(runs), "lines", len(buf), "bytes", size)
+		bw := bufio.NewWriter(f)
+		for _, line := range buf {
+			bw.WriteString(strconv.Itoa(len(line)))
+			bw.WriteByte(':')
+			bw.WriteString(line)
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		buf = buf[:0]
+		size = 0
+		return nil
+	}
+
+	err := feed(func(line string) error {
+		buf = append(buf, line)
+		size += len(line) + 1
+		if size >= runSize {
+			return spill()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(runs) == 0 {
+		sort.SliceStable(buf, func(i, j int) bool { return less(buf[i], buf[j]) })
+		for _, line := range buf {
+			if err := emit(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(buf) > 0 {
+		if err := spill(); err != nil {
+			return err
+		}
+	}
+
+	h := &runHeap{less: less}
+	for i, f := range runs {
+		rr := &runReader{r: bufio.NewReader(f), run: i}
+		ok, err := rr.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			h.items = append(h.items, rr)
+		}
+	}
+	heap.Init(h)
+
+	for h.Len() > 0 {
+		top := h.items[0]
+		if err := emit(top.line); err != nil {
+			return err
+		}
+		ok, err := top.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+
+	return nil
+}
+
+type runReader struct {
+	r    *bufio.Reader
+	run  int
+	line string
+}
+
+func (rr *runReader) next() (bool, error) {
+	prefix, err := rr.r.ReadString(':')
+	if err == io.EOF && prefix == "" {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("corrupt sort run: %v", err)
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(prefix, ":"))
+	if err != nil {
+		return false, fmt.Errorf("corrupt sort run: %v", err)
+	}
+	line := make([]byte, n)
+	if _, err := io.ReadFull(rr.r, line); err != nil {
+		return false, fmt.Errorf("corrupt sort run: %v", err)
+	}
+	rr.line = string(line)
+	return true, nil
+}
+
+type runHeap struct {
+	items []*runReader
+	less  func(a, b string) bool
+}
+
+func (h *runHeap) Len() int { return len(h.items) }
+
+func (h *runHeap) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.less(a.line, b.line) {
+		return true
+	}
+	if h.less(b.line, a.line) {
+		return false
+	}
+	return a.run < b.run
+}
+
+func (h *runHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *runHeap) Push(x interface{}) { h.items = append(h.items, x.(*runReader)) }
+
+func (h *runHeap) Pop() interface{} {
+	old := h.items
+	n := len(old)
+	item := old[n-1]
+	h.items = old[:n-1]
+	return item
+}
@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type FixedWidthOptions struct {
+	Widths []int  `json:"widths"`
+	Header bool   `json:"header"`
+	Format string `json:"format"`
+}
+
+type FixedWidthTable struct {
+	Widths  []int      `json:"widths"`
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+}
+
+func DetectColumnWidths(text string) []int {
+	var rows [][]string
+	maxLen := 0
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		clusters := Graphemes(line)
+		rows = append(rows, clusters)
+		if len(clusters) > maxLen {
+			maxLen = len(clusters)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	blank := make([]bool, maxLen)
+	for i := range blank {
+		blank[i] = true
+		for _, row := range rows {
+			if i < len(row) && strings.TrimSpace(row[i]) != "" {
+				blank[i] = false
+				break
+			}
+		}
+	}
+
+	var starts []int
+	for i := range blank {
+		if !blank[i] && (i == 0 || blank[i-1]) {
+			starts = append(starts, i)
+		}
+	}
+	if len(starts) == 0 {
+		return []int{maxLen}
+	}
+	starts[0] = 0
+
+	widths := make([]int, len(starts))
+	for i := range starts {
+		end := maxLen
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		widths[i] = end - starts[i]
+	}
+	return widths
+}
+
+func ParseFixedWidth(text string, opts FixedWidthOptions) (FixedWidthTable, error) {
+	widths := opts.Widths
+	if len(widths) == 0 {
+		widths = DetectColumnWidths(text)
+	}
+	for _, w := range widths {
+		if w <= 0 {
+			return FixedWidthTable{}, fmt.Errorf("%w: column widths must be positive, got %d", ErrInvalidOption, w)
+		}
+	}
+
+	table := FixedWidthTable{Widths: widths, Rows: [][]string{}}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		cells := sliceFixedWidth(Graphemes(line), widths)
+		if opts.Header && table.Columns == nil {
+			table.Columns = cells
+			continue
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+
+	if table.Columns == nil {
+		table.Columns = make([]string, len(widths))
+		for i := range widths {
+			table.Columns[i] = fmt.Sprintf("column%d", i+1)
+		}
+	}
+	for i, name := range table.Columns {
+		if name == "" {
+			table.Columns[i] = fmt.Sprintf("column%d", i+1)
+		}
+	}
+	return table, nil
+}
+
+func sliceFixedWidth(clusters []string, widths []int) []string {
+	cells := make([]string, len(widths))
+	pos := 0
+	for i, w := range widths {
+		end := pos + w
+		if i == len(widths)-1 || end > len(clusters) {
+			end = len(clusters)
+		}
+		if pos < end {
+			cells[i] = strings.TrimSpace(strings.Join(clusters[pos:end], ""))
+		}
+		pos = end
+	}
+	return cells
+}
+
+func (t FixedWidthTable) CSV() (string, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.Write(t.Columns); err != nil {
+		return "", err
+	}
+	if err := w.WriteAll(t.Rows); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (t FixedWidthTable) JSON() (string, error) {
+	var b bytes.Buffer
+	b.WriteString("[")
+	for i, row := range t.Rows {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  {")
+		for j, name := range t.Columns {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			key, _ := json.Marshal(name)
+			value, _ := json.Marshal(row[j])
+			b.Write(key)
+			b.WriteString(": ")
+			b.Write(value)
+		}
+		b.WriteString("}")
+	}
+	if len(t.Rows) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]")
+	return b.String(), nil
+}
+
+func ConvertFixedWidth(text string, opts FixedWidthOptions) (string, error) {
+	table, err := ParseFixedWidth(text, opts)
+	if err != nil {
+		return "", err
+	}
+	switch opts.Format {
+	case "", "csv":
+		return table.CSV()
+	case "json":
+		return table.JSON()
+	}
+	return "", fmt.Errorf("%w: unknown fixed-width output format %q", ErrInvalidOption, opts.Format)
+}
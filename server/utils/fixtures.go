@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const MaxFixtureBytes = 32 << 20
+
+type FixtureKind string
+
+const (
+	FixtureRepeat    FixtureKind = "repeat"
+	FixtureNumbered  FixtureKind = "numbered"
+	FixtureWords     FixtureKind = "words"
+	FixtureSentences FixtureKind = "sentences"
+)
+
+var FixtureKinds = []FixtureKind{FixtureRepeat, FixtureNumbered, FixtureWords, FixtureSentences}
+
+func (FixtureKind) Values() []string {
+	return EnumValues(FixtureKinds)
+}
+
+// Pattern is the repeated text for "repeat" and the line template for
+// "numbered", where {n} is replaced by the line number counting from Start
+// (1 when unset).
+type FixtureOptions struct {
+	Kind         FixtureKind `json:"kind"`
+	Lines        int         `json:"lines"`
+	Bytes        int         `json:"bytes"`
+	Pattern      string      `json:"pattern"`
+	Start        int         `json:"start"`
+	WordsPerLine int         `json:"wordsPerLine"`
+	Seed         int64       `json:"seed"`
+}
+
+func (o FixtureOptions) Validate() error {
+	switch o.Kind {
+	case FixtureRepeat:
+		if o.Pattern == "" {
+			return fmt.Errorf("%w: repeat fixtures need a pattern", ErrInvalidOption)
+		}
+	case "", FixtureNumbered, FixtureWords, FixtureSentences:
+	default:
+		return fmt.Errorf("%w: unknown fixture kind %q", ErrInvalidOption, o.Kind)
+	}
+	if (o.Lines > 0) == (o.Bytes > 0) {
+		return fmt.Errorf("%w: set exactly one of lines or bytes", ErrInvalidOption)
+	}
+	if o.Lines < 0 || o.Bytes < 0 || o.WordsPerLine < 0 {
+		return fmt.Errorf("%w: lines, bytes and wordsPerLine must not be negative", ErrInvalidOption)
+	}
+	if o.Bytes > MaxFixtureBytes {
+		return fmt.Errorf("%w: fixtures are limited to %d bytes", ErrInputTooLarge, MaxFixtureBytes)
+	}
+	return nil
+}
+
+var fixtureWords = strings.Fields(englishFrequencyList)
+
+func GenerateFixture(opts FixtureOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+	if opts.Kind == "" {
+		opts.Kind = FixtureNumbered
+	}
+	if opts.Pattern == "" {
+		opts.Pattern = "line {n}"
+	}
+	if opts.Start == 0 {
+		opts.Start = 1
+	}
+	if opts.WordsPerLine == 0 {
+		opts.WordsPerLine = 10
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	line := func(i int) string {
+		switch opts.Kind {
+		case FixtureRepeat:
+			return opts.Pattern
+		case FixtureNumbered:
+			return strings.ReplaceAll(opts.Pattern, "{n}", strconv.Itoa(opts.Start+i))
+		case FixtureWords:
+			return randomWords(rng, opts.WordsPerLine)
+		}
+		return randomSentence(rng)
+	}
+
+	var b strings.Builder
+	for i := 0; ; i++ {
+		if opts.Lines > 0 && i == opts.Lines || opts.Bytes > 0 && b.Len() >= opts.Bytes {
+			break
+		}
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(line(i))
+		if b.Len() > MaxFixtureBytes {
+			return "", fmt.Errorf("%w: fixtures are limited to %d bytes", ErrInputTooLarge, MaxFixtureBytes)
+		}
+	}
+
+	out := b.String()
+	if opts.Bytes > 0 && len(out) > opts.Bytes {
+		// cut back to a rune boundary so the output stays valid UTF-8
+		end := opts.Bytes
+		for end > 0 && !utf8.RuneStart(out[end]) {
+			end--
+		}
+		out = out[:end]
+	}
+	return out, nil
+}
+
+func randomWords(rng *rand.Rand, n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = fixtureWords[rng.Intn(len(fixtureWords))]
+	}
+	return strings.Join(words, " ")
+}
+
+func randomSentence(rng *rand.Rand) string {
+	sentence := randomWords(rng, 5+rng.Intn(11))
+	if rng.Intn(4) == 0 {
+		words := strings.Fields(sentence)
+		at := 1 + rng.Intn(len(words)-1)
+		words[at-1] += ","
+		sentence = strings.Join(words, " ")
+	}
+	end := "."
+	switch rng.Intn(10) {
+	case 0:
+		end = "?"
+	case 1:
+		end = "!"
+	}
+	return capitalizeFirst(sentence) + end
+}
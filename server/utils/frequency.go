@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type FrequencyOptions struct {
+	FoldCase         bool `json:"foldCase"`
+	IgnoreWhitespace bool `json:"ignoreWhitespace"`
+}
+
+type FrequencyEntry struct {
+	Value   string  `json:"value"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+type FrequencyReport struct {
+	Characters      int              `json:"characters"`
+	Bigrams         int              `json:"bigrams"`
+	CharacterCounts []FrequencyEntry `json:"characterCounts"`
+	BigramCounts    []FrequencyEntry `json:"bigramCounts"`
+}
+
+func CharFrequency(text string) FrequencyReport {
+	return CharFrequencyWithOptions(text, FrequencyOptions{})
+}
+
+func CharFrequencyWithOptions(text string, opts FrequencyOptions) FrequencyReport {
+	if opts.FoldCase {
+		text = strings.ToLower(text)
+	}
+
+	chars := make(map[string]int)
+	bigrams := make(map[string]int)
+	report := FrequencyReport{}
+	prev := ""
+	for _, cluster := range Graphemes(text) {
+		// whitespace still breaks a bigram when it isn't counted itself
+		if opts.IgnoreWhitespace && strings.TrimFunc(cluster, unicode.IsSpace) == "" {
+			prev = ""
+			continue
+		}
+		chars[cluster]++
+		report.Characters++
+		if prev != "" {
+			bigrams[prev+cluster]++
+			report.Bigrams++
+		}
+		prev = cluster
+	}
+
+	report.CharacterCounts = frequencyEntries(chars, report.Characters)
+	report.BigramCounts = frequencyEntries(bigrams, report.Bigrams)
+	return report
+}
+
+func frequencyEntries(counts map[string]int, total int) []FrequencyEntry {
+	entries := make([]FrequencyEntry, 0, len(counts))
+	for value, n := range counts {
+		entries = append(entries, FrequencyEntry{Value: value, Count: n, Percent: round2(float64(n) * 100 / float64(total))})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Value < entries[j].Value
+	})
+	return entries
+}
@@ -0,0 +1,277 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	FrontMatterYAML = "yaml"
+	FrontMatterTOML = "toml"
+)
+
+type FrontMatter struct {
+	Format string                 `json:"format"`
+	Fields map[string]interface{} `json:"fields"`
+	Body   string                 `json:"body"`
+}
+
+type frontMatterBlock struct {
+	format string
+	body   string
+	fields interface{}
+}
+
+func ExtractFrontMatter(doc string) (FrontMatter, error) {
+	block, err := parseFrontMatter(doc)
+	if err != nil {
+		return FrontMatter{}, err
+	}
+	fields, _ := plainValue(block.fields).(map[string]interface{})
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	return FrontMatter{Format: block.format, Fields: fields, Body: block.body}, nil
+}
+
+func StripFrontMatter(doc string) string {
+	format, _, body := splitFrontMatter(doc)
+	if format == "" {
+		return doc
+	}
+	return body
+}
+
+func FrontMatterField(doc, key string) (interface{}, bool, error) {
+	block, err := parseFrontMatter(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := lookupPath(block.fields, strings.Split(key, "."))
+	return plainValue(value), ok, nil
+}
+
+func SetFrontMatter(doc string, set map[string]interface{}, unset []string) (string, error) {
+	block, err := parseFrontMatter(doc)
+	if err != nil {
+		return "", err
+	}
+	if block.format == "" {
+		block.format = FrontMatterYAML
+		block.fields = yaml.MapSlice{}
+	}
+
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		block.fields = setPath(block.fields, strings.Split(key, "."), set[key], block.format)
+	}
+	for _, key := range unset {
+		block.fields = deletePath(block.fields, strings.Split(key, "."))
+	}
+
+	var encoded []byte
+	delim := "---"
+	if block.format == FrontMatterTOML {
+		delim = "+++"
+		encoded, err = toml.Marshal(block.fields)
+	} else {
+		encoded, err = yaml.Marshal(block.fields)
+	}
+	if err != nil {
+		return "", fmt.Errorf("encode %s front matter: %v", block.format, err)
+	}
+
+	header := strings.TrimRight(string(encoded), "\n")
+	if header == "{}" || header == "[]" {
+		header = ""
+	}
+	if header == "" {
+		return delim + "\n" + delim + "\n" + block.body, nil
+	}
+	return delim + "\n" + header + "\n" + delim + "\n" + block.body, nil
+}
+
+func ValidateFrontMatter(doc string, required []string) ([]string, error) {
+	block, err := parseFrontMatter(doc)
+	if err != nil {
+		return nil, err
+	}
+	problems := []string{}
+	if block.format == "" {
+		problems = append(problems, "document has no front matter")
+	}
+	for _, key := range required {
+		value, ok := lookupPath(block.fields, strings.Split(key, "."))
+		if !ok || value == nil || value == "" {
+			problems = append(problems, fmt.Sprintf("missing required field %q", key))
+		}
+	}
+	return problems, nil
+}
+
+func splitFrontMatter(doc string) (format, raw, body string) {
+	text := strings.TrimPrefix(doc, "\ufeff")
+	first, rest, ok := strings.Cut(text, "\n")
+	if !ok {
+		return "", "", doc
+	}
+
+	closers := map[string][]string{"---": {"---", "..."}, "+++": {"+++"}}
+	ends, found := closers[strings.TrimRight(first, " \t\r")]
+	if !found {
+		return "", "", doc
+	}
+
+	offset := 0
+	for offset <= len(rest) {
+		line, next, more := strings.Cut(rest[offset:], "\n")
+		trimmed := strings.TrimRight(line, " \t\r")
+		for _, end := range ends {
+			if trimmed == end {
+				format = FrontMatterYAML
+				if end == "+++" {
+					format = FrontMatterTOML
+				}
+				return format, rest[:offset], next
+			}
+		}
+		if !more {
+			break
+		}
+		offset = len(rest) - len(next)
+	}
+	return "", "", doc
+}
+
+func parseFrontMatter(doc string) (frontMatterBlock, error) {
+	format, raw, body := splitFrontMatter(doc)
+	block := frontMatterBlock{format: format, body: body}
+	switch format {
+	case FrontMatterYAML:
+		var fields yaml.MapSlice
+		if err := yaml.UnmarshalWithOptions([]byte(raw), &fields, yaml.UseOrderedMap()); err != nil {
+			return block, fmt.Errorf("invalid yaml front matter: %v", err)
+		}
+		if fields == nil {
+			fields = yaml.MapSlice{}
+		}
+		block.fields = fields
+	case FrontMatterTOML:
+		fields := map[string]interface{}{}
+		if err := toml.Unmarshal([]byte(raw), &fields); err != nil {
+			return block, fmt.Errorf("invalid toml front matter: %v", err)
+		}
+		block.fields = fields
+	}
+	return block, nil
+}
+
+func plainValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		out := make(map[string]interface{}, len(v))
+		for _, item := range v {
+			out[fmt.Sprint(item.Key)] = plainValue(item.Value)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = plainValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = plainValue(item)
+		}
+		return out
+	}
+	return v
+}
+
+func lookupPath(node interface{}, path []string) (interface{}, bool) {
+	for _, key := range path {
+		switch n := node.(type) {
+		case yaml.MapSlice:
+			found := false
+			for _, item := range n {
+				if fmt.Sprint(item.Key) == key {
+					node, found = item.Value, true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		case map[string]interface{}:
+			value, ok := n[key]
+			if !ok {
+				return nil, false
+			}
+			node = value
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+func setPath(node interface{}, path []string, value interface{}, format string) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+	key := path[0]
+	switch n := node.(type) {
+	case yaml.MapSlice:
+		for i, item := range n {
+			if fmt.Sprint(item.Key) == key {
+				n[i].Value = setPath(item.Value, path[1:], value, format)
+				return n
+			}
+		}
+		return append(n, yaml.MapItem{Key: key, Value: setPath(nil, path[1:], value, format)})
+	case map[string]interface{}:
+		n[key] = setPath(n[key], path[1:], value, format)
+		return n
+	}
+	if format == FrontMatterTOML {
+		return map[string]interface{}{key: setPath(nil, path[1:], value, format)}
+	}
+	return yaml.MapSlice{{Key: key, Value: setPath(nil, path[1:], value, format)}}
+}
+
+func deletePath(node interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return node
+	}
+	key := path[0]
+	switch n := node.(type) {
+	case yaml.MapSlice:
+		for i, item := range n {
+			if fmt.Sprint(item.Key) != key {
+				continue
+			}
+			if len(path) == 1 {
+				return append(n[:i:i], n[i+1:]...)
+			}
+			n[i].Value = deletePath(item.Value, path[1:])
+			return n
+		}
+	case map[string]interface{}:
+		if len(path) == 1 {
+			delete(n, key)
+		} else if child, ok := n[key]; ok {
+			n[key] = deletePath(child, path[1:])
+		}
+	}
+	return node
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+type WidthScope string
+
+const (
+	WidthAll      WidthScope = "all"
+	WidthASCII    WidthScope = "ascii"
+	WidthKatakana WidthScope = "katakana"
+)
+
+var WidthScopes = []WidthScope{WidthAll, WidthASCII, WidthKatakana}
+
+func (WidthScope) Values() []string {
+	return EnumValues(WidthScopes)
+}
+
+const (
+	combiningVoicedMark     = '\u3099'
+	combiningSemiVoicedMark = '\u309a'
+	halfwidthVoicedMark     = '\uff9e'
+	halfwidthSemiVoicedMark = '\uff9f'
+)
+
+func (s WidthScope) includes(narrow rune) (bool, error) {
+	switch s {
+	case "", WidthAll:
+		return true, nil
+	case WidthASCII:
+		return narrow >= 0x20 && narrow <= 0x7e, nil
+	case WidthKatakana:
+		return narrow >= '\uff61' && narrow <= '\uff9f', nil
+	}
+	return false, fmt.Errorf("%w: unknown width scope %q", ErrInvalidOption, s)
+}
+
+func ToHalfWidth(text string, scope WidthScope) (string, error) {
+	if _, err := scope.includes(0); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, r := range text {
+		// voiced katakana have no single half-width form; split off the mark first
+		if r >= '\u30a0' && r <= '\u30ff' {
+			if d := []rune(norm.NFD.String(string(r))); len(d) == 2 {
+				if base := width.LookupRune(d[0]).Narrow(); base != 0 {
+					if ok, _ := scope.includes(base); ok {
+						b.WriteRune(base)
+						if d[1] == combiningVoicedMark {
+							b.WriteRune(halfwidthVoicedMark)
+						} else {
+							b.WriteRune(halfwidthSemiVoicedMark)
+						}
+						continue
+					}
+				}
+			}
+		}
+		if narrow := width.LookupRune(r).Narrow(); narrow != 0 {
+			if ok, _ := scope.includes(narrow); ok {
+				r = narrow
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), nil
+}
+
+func ToFullWidth(text string, scope WidthScope) (string, error) {
+	if _, err := scope.includes(0); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		ok, _ := scope.includes(r)
+		wide := width.LookupRune(r).Wide()
+		if !ok || wide == 0 {
+			b.WriteRune(r)
+			continue
+		}
+		if next, n := utf8.DecodeRuneInString(text[i:]); next == halfwidthVoicedMark || next == halfwidthSemiVoicedMark {
+			mark := combiningVoicedMark
+			if next == halfwidthSemiVoicedMark {
+				mark = combiningSemiVoicedMark
+			}
+			if composed := norm.NFC.String(string([]rune{wide, mark})); utf8.RuneCountInString(composed) == 1 {
+				b.WriteString(composed)
+				i += n
+				continue
+			}
+		}
+		b.WriteRune(wide)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+func Graphemes(s string) []string {
+	var clusters []string
+	for len(s) > 0 {
+		n := nextGrapheme(s)
+		clusters = append(clusters, s[:n])
+		s = s[n:]
+	}
+	return clusters
+}
+
+func GraphemeCount(s string) int {
+	count := 0
+	for len(s) > 0 {
+		s = s[nextGrapheme(s):]
+		count++
+	}
+	return count
+}
+
+func nextGrapheme(s string) int {
+	if len(s) >= 2 && s[0] == '\r' && s[1] == '\n' {
+		return 2
+	}
+	first, i := utf8.DecodeRuneInString(s)
+	if first == '\r' || first == '\n' || unicode.IsControl(first) {
+		return i
+	}
+
+	if isRegionalIndicator(first) {
+		if r, w := utf8.DecodeRuneInString(s[i:]); isRegionalIndicator(r) {
+			i += w
+		}
+	}
+
+	for i < len(s) {
+		r, w := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '\u200d':
+			i += w
+			if i < len(s) {
+				_, w = utf8.DecodeRuneInString(s[i:])
+				i += w
+			}
+		case isGraphemeExtend(r):
+			i += w
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func isGraphemeExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r >= 0xFE00 && r <= 0xFE0F ||
+		r >= 0x1F3FB && r <= 0x1F3FF ||
+		r >= 0xE0020 && r <= 0xE007F ||
+		r >= 0xE0100 && r <= 0xE01EF ||
+		r >= 0x1160 && r <= 0x11FF
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+func DisplayWidth(s string) int {
+	total := 0
+	for len(s) > 0 {
+		n := nextGrapheme(s)
+		total += clusterWidth(s[:n])
+		s = s[n:]
+	}
+	return total
+}
+
+func clusterWidth(cluster string) int {
+	r, _ := utf8.DecodeRuneInString(cluster)
+	if unicode.IsControl(r) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	if r >= 0x1F300 && r <= 0x1FAFF {
+		return 2
+	}
+	return 1
+}
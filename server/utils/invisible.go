@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const MaxInvisibleReported = 1000
+
+var invisibleNames = map[rune]string{
+	0x00AD: "SOFT HYPHEN",
+	0x034F: "COMBINING GRAPHEME JOINER",
+	0x061C: "ARABIC LETTER MARK",
+	0x180E: "MONGOLIAN VOWEL SEPARATOR",
+	0x200B: "ZERO WIDTH SPACE",
+	0x200C: "ZERO WIDTH NON-JOINER",
+	0x200D: "ZERO WIDTH JOINER",
+	0x200E: "LEFT-TO-RIGHT MARK",
+	0x200F: "RIGHT-TO-LEFT MARK",
+	0x202A: "LEFT-TO-RIGHT EMBEDDING",
+	0x202B: "RIGHT-TO-LEFT EMBEDDING",
+	0x202C: "POP DIRECTIONAL FORMATTING",
+	0x202D: "LEFT-TO-RIGHT OVERRIDE",
+	0x202E: "RIGHT-TO-LEFT OVERRIDE",
+	0x2060: "WORD JOINER",
+	0x2061: "FUNCTION APPLICATION",
+	0x2062: "INVISIBLE TIMES",
+	0x2063: "INVISIBLE SEPARATOR",
+	0x2064: "INVISIBLE PLUS",
+	0x2066: "LEFT-TO-RIGHT ISOLATE",
+	0x2067: "RIGHT-TO-LEFT ISOLATE",
+	0x2068: "FIRST STRONG ISOLATE",
+	0x2069: "POP DIRECTIONAL ISOLATE",
+	0xFEFF: "ZERO WIDTH NO-BREAK SPACE",
+}
+
+type InvisibleChar struct {
+	CodePoint string `json:"codePoint"`
+	Name      string `json:"name"`
+	Offset    int    `json:"offset"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+}
+
+type InvisibleReport struct {
+	Count         int             `json:"count"`
+	Characters    []InvisibleChar `json:"characters"`
+	Truncated     bool            `json:"truncated"`
+	HiddenMessage *string         `json:"hiddenMessage,omitempty"`
+}
+
+func invisibleName(r rune) (string, bool) {
+	if name, ok := invisibleNames[r]; ok {
+		return name, true
+	}
+	if r >= 0xE0000 && r <= 0xE007F {
+		return "TAG CHARACTER", true
+	}
+	if unicode.Is(unicode.Cf, r) {
+		return "FORMAT CHARACTER", true
+	}
+	return "", false
+}
+
+func DetectInvisible(text string) InvisibleReport {
+	report := InvisibleReport{Characters: []InvisibleChar{}}
+	line, column, offset := 1, 1, 0
+	for _, r := range text {
+		if name, ok := invisibleName(r); ok {
+			report.Count++
+			if len(report.Characters) < MaxInvisibleReported {
+				report.Characters = append(report.Characters, InvisibleChar{
+					CodePoint: fmt.Sprintf("U+%04X", r),
+					Name:      name,
+					Offset:    offset,
+					Line:      line,
+					Column:    column,
+				})
+			} else {
+				report.Truncated = true
+			}
+		}
+		offset++
+		column++
+		if r == '\n' {
+			line++
+			column = 1
+		}
+	}
+
+	if message, err := RevealMessage(text); err == nil {
+		report.HiddenMessage = &message
+	}
+	return report
+}
+
+func StripInvisible(text string) string {
+	return strings.Map(func(r rune) rune {
+		if _, ok := invisibleName(r); ok {
+			return -1
+		}
+		return r
+	}, text)
+}
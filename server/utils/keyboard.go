@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+type KeyboardLayout string
+
+const (
+	LayoutQWERTY KeyboardLayout = "qwerty"
+	LayoutJCUKEN KeyboardLayout = "jcuken"
+	LayoutAZERTY KeyboardLayout = "azerty"
+	LayoutQWERTZ KeyboardLayout = "qwertz"
+)
+
+var KeyboardLayouts = []KeyboardLayout{LayoutQWERTY, LayoutJCUKEN, LayoutAZERTY, LayoutQWERTZ}
+
+func (KeyboardLayout) Values() []string {
+	return EnumValues(KeyboardLayouts)
+}
+
+// each layout lists the characters on the same physical keys, in the same order,
+// unshifted then shifted
+var keyboardLayoutKeys = map[KeyboardLayout]string{
+	LayoutQWERTY: "`1234567890-=qwertyuiop[]\\asdfghjkl;'zxcvbnm,./" +
+		"~!@#$%^&*()_+QWERTYUIOP{}|ASDFGHJKL:\"ZXCVBNM<>?",
+	LayoutJCUKEN: "ё1234567890-=йцукенгшщзхъ\\фывапролджэячсмитьбю." +
+		"Ё!\"№;%:?*()_+ЙЦУКЕНГШЩЗХЪ/ФЫВАПРОЛДЖЭЯЧСМИТЬБЮ,",
+	LayoutAZERTY: "²&é\"'(-è_çà)=azertyuiop^$*qsdfghjklmùwxcvbn,;:!" +
+		"³1234567890°+AZERTYUIOP¨£µQSDFGHJKLM%WXCVBN?./§",
+	LayoutQWERTZ: "^1234567890ß´qwertzuiopü+#asdfghjklöäyxcvbnm,.-" +
+		"°!\"§$%&/()=?`QWERTZUIOPÜ*'ASDFGHJKLÖÄYXCVBNM;:_",
+}
+
+func ConvertKeyboardLayout(text string, from, to KeyboardLayout) (string, error) {
+	src, ok := keyboardLayoutKeys[from]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown keyboard layout %q", ErrInvalidOption, from)
+	}
+	dst, ok := keyboardLayoutKeys[to]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown keyboard layout %q", ErrInvalidOption, to)
+	}
+
+	srcKeys, dstKeys := []rune(src), []rune(dst)
+	mapping := make(map[rune]rune, len(srcKeys))
+	for i, r := range srcKeys {
+		mapping[r] = dstKeys[i]
+	}
+	return strings.Map(func(r rune) rune {
+		if m, ok := mapping[r]; ok {
+			return m
+		}
+		return r
+	}, text), nil
+}
@@ -0,0 +1,274 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"toolkit-backend/languages"
+)
+
+type LanguageUnit string
+
+const (
+	LanguageUnitLine LanguageUnit = "line"
+	// LanguageUnitParagraph tags runs of lines between blank lines, which
+	// gives the detector more words to go by
+	LanguageUnitParagraph LanguageUnit = "paragraph"
+)
+
+var LanguageUnits = []LanguageUnit{LanguageUnitLine, LanguageUnitParagraph}
+
+func (LanguageUnit) Values() []string {
+	return EnumValues(LanguageUnits)
+}
+
+func (u LanguageUnit) Valid() bool {
+	return EnumValid(LanguageUnits, u)
+}
+
+// UndeterminedLanguage is the BCP 47 tag for text the detector cannot place
+const UndeterminedLanguage = "und"
+
+// scripts that are written in (nearly) one language tag it on their own when
+// no registered language uses them; Cyrillic, Arabic and Devanagari serve
+// too many languages to guess from
+var scriptLanguages = map[string]string{
+	"Greek":    "el",
+	"Hebrew":   "he",
+	"Armenian": "hy",
+	"Georgian": "ka",
+	"Thai":     "th",
+	"Hangul":   "ko",
+	"Hiragana": "ja",
+	"Katakana": "ja",
+	"Han":      "zh",
+}
+
+var detectScripts = []string{"Latin", "Cyrillic", "Greek", "Arabic", "Hebrew", "Devanagari", "Armenian", "Georgian", "Thai", "Hangul", "Hiragana", "Katakana", "Han"}
+
+type LanguageSpan struct {
+	// Start and End are byte offsets of the span in the text
+	Start int `json:"start"`
+	End   int `json:"end"`
+	// Line is the span's first line, from 1
+	Line     int    `json:"line"`
+	Language string `json:"language"`
+	Script   string `json:"script,omitempty"`
+	// Confidence runs from 0 to 1; undetermined spans have 0
+	Confidence float64 `json:"confidence"`
+	Text       string  `json:"text"`
+}
+
+type LanguageTagOptions struct {
+	Unit LanguageUnit `json:"unit"`
+}
+
+// TagLineLanguages detects the language of every non-blank line
+func TagLineLanguages(text string) []LanguageSpan {
+	spans, _ := TagLanguages(text, LanguageTagOptions{Unit: LanguageUnitLine})
+	return spans
+}
+
+// TagLanguages detects the language of every line or paragraph. The script
+// of the letters narrows the candidates to the registered languages written
+// in it, which are told apart by their stop words and distinctive letters;
+// a script that belongs to one language settles it alone. Blank units get no
+// span and a unit the detector cannot place is tagged "und".
+func TagLanguages(text string, opts LanguageTagOptions) ([]LanguageSpan, error) {
+	if opts.Unit == "" {
+		opts.Unit = LanguageUnitLine
+	}
+	if !opts.Unit.Valid() {
+		return nil, fmt.Errorf("%w: unknown language unit %q", ErrInvalidOption, opts.Unit)
+	}
+	candidates := detectCandidates()
+	spans := []LanguageSpan{}
+	for _, u := range languageUnits(text, opts.Unit) {
+		lang, script, confidence := detectLanguage(u.Text, candidates)
+		u.Language, u.Script, u.Confidence = lang, script, confidence
+		spans = append(spans, u)
+	}
+	return spans, nil
+}
+
+type LanguageFilterOptions struct {
+	Language string       `json:"language" binding:"required"`
+	Unit     LanguageUnit `json:"unit"`
+}
+
+// FilterByLanguage keeps the lines or paragraphs detected as the chosen
+// language, "und" included, joined as they were split
+func FilterByLanguage(text string, opts LanguageFilterOptions) (string, error) {
+	want := strings.ToLower(strings.TrimSpace(opts.Language))
+	if !knownLanguageTag(want) {
+		return "", fmt.Errorf("%w: unknown language %q", ErrInvalidOption, opts.Language)
+	}
+	spans, err := TagLanguages(text, LanguageTagOptions{Unit: opts.Unit})
+	if err != nil {
+		return "", err
+	}
+	var kept []string
+	for _, s := range spans {
+		if s.Language == want {
+			kept = append(kept, s.Text)
+		}
+	}
+	sep := "\n"
+	if opts.Unit == LanguageUnitParagraph {
+		sep = "\n\n"
+	}
+	return strings.Join(kept, sep), nil
+}
+
+// DetectableLanguages lists the tags detection can give: the registered
+// languages with a script and those the script-only fallback uses
+func DetectableLanguages() []string {
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, code := range languages.Default.Codes(func(l *languages.Language) bool { return l.Script != "" }) {
+		seen[code] = true
+		tags = append(tags, code)
+	}
+	for _, script := range detectScripts {
+		if lang, ok := scriptLanguages[script]; ok && !seen[lang] {
+			seen[lang] = true
+			tags = append(tags, lang)
+		}
+	}
+	return append(tags, UndeterminedLanguage)
+}
+
+func knownLanguageTag(tag string) bool {
+	for _, known := range DetectableLanguages() {
+		if tag == known {
+			return true
+		}
+	}
+	return false
+}
+
+func detectCandidates() map[string][]*languages.Language {
+	candidates := map[string][]*languages.Language{}
+	for _, code := range languages.Default.Codes(func(l *languages.Language) bool { return l.Script != "" }) {
+		if l, err := languages.Default.Get(code); err == nil {
+			candidates[l.Script] = append(candidates[l.Script], l)
+		}
+	}
+	return candidates
+}
+
+// languageUnits splits text into non-blank lines or paragraphs with their
+// offsets; a line's trailing "\r" is left out of its span
+func languageUnits(text string, unit LanguageUnit) []LanguageSpan {
+	var units []LanguageSpan
+	line, counted := 1, 0
+	add := func(start, end int) {
+		chunk := strings.TrimRight(text[start:end], "\r")
+		if strings.TrimSpace(chunk) == "" {
+			return
+		}
+		line += strings.Count(text[counted:start], "\n")
+		counted = start
+		units = append(units, LanguageSpan{Start: start, End: start + len(chunk), Line: line, Text: chunk})
+	}
+	if unit == LanguageUnitParagraph {
+		start := 0
+		for _, br := range paragraphBreak.FindAllStringIndex(text, -1) {
+			add(start, br[0])
+			start = br[1]
+		}
+		add(start, len(text))
+		return units
+	}
+	start := 0
+	for start <= len(text) {
+		end := strings.IndexByte(text[start:], '\n')
+		if end < 0 {
+			add(start, len(text))
+			break
+		}
+		add(start, start+end)
+		start += end + 1
+	}
+	return units
+}
+
+func detectLanguage(text string, candidates map[string][]*languages.Language) (string, string, float64) {
+	counts := map[string]int{}
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, name := range detectScripts {
+			if unicode.Is(unicode.Scripts[name], r) {
+				counts[name]++
+				break
+			}
+		}
+	}
+	script := ""
+	for _, name := range detectScripts {
+		if counts[name] > counts[script] {
+			script = name
+		}
+	}
+	if script == "" {
+		return UndeterminedLanguage, "", 0
+	}
+	// Japanese mixes kanji with kana, which Chinese never has
+	if kana := counts["Hiragana"] + counts["Katakana"]; kana > 0 && script == "Han" {
+		script = "Hiragana"
+		if counts["Katakana"] > counts["Hiragana"] {
+			script = "Katakana"
+		}
+	}
+
+	if len(candidates[script]) == 0 {
+		lang, ok := scriptLanguages[script]
+		if !ok {
+			return UndeterminedLanguage, script, 0
+		}
+		if script == "Hiragana" || script == "Katakana" {
+			return lang, script, roundConfidence(float64(counts["Hiragana"]+counts["Katakana"]+counts["Han"]) / float64(letters))
+		}
+		return lang, script, roundConfidence(float64(counts[script]) / float64(letters))
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.Is(unicode.Mn, r) })
+	var best *languages.Language
+	bestScore, second := 0.0, 0.0
+	for _, l := range candidates[script] {
+		score := 0.0
+		for _, w := range words {
+			if l.StopWords[w] {
+				score++
+			}
+			// a distinctive letter is weaker evidence than a whole word
+			if l.Letters != "" && strings.ContainsAny(w, l.Letters) {
+				score += 0.5
+			}
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, second = l, score, bestScore
+		case score > second:
+			second = score
+		}
+	}
+	if best == nil || bestScore == second {
+		return UndeterminedLanguage, script, 0
+	}
+	// how clearly the winner leads, scaled down when the evidence is thin:
+	// running text is a third or more stop words, so a quarter counts as full
+	margin := (bestScore - second) / bestScore
+	coverage := math.Min(1, bestScore/(0.25*float64(len(words))))
+	return best.Code, script, roundConfidence(margin * coverage)
+}
+
+func roundConfidence(c float64) float64 {
+	return math.Round(c*100) / 100
+}
@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+type VerticalMode string
+
+const (
+	VerticalStack  VerticalMode = "stack"
+	VerticalRotate VerticalMode = "rotate"
+)
+
+var VerticalModes = []VerticalMode{VerticalStack, VerticalRotate}
+
+func (VerticalMode) Values() []string {
+	return EnumValues(VerticalModes)
+}
+
+type ColumnLayoutOptions struct {
+	Columns int `json:"columns" min:"1"`
+	Width   int `json:"width" min:"1" max:"1000"`
+	Gap     int `json:"gap" min:"0" max:"1000"`
+}
+
+// MaxColumnWidth bounds both the column width and the gap, which every
+// output row is padded out to
+const MaxColumnWidth = 1000
+
+func NewspaperColumns(text string, opts ColumnLayoutOptions) (string, error) {
+	if opts.Columns < 1 || opts.Width < 1 || opts.Gap < 0 {
+		return "", fmt.Errorf("%w: columns and width must be positive and gap non-negative", ErrInvalidOption)
+	}
+	if opts.Width > MaxColumnWidth || opts.Gap > MaxColumnWidth {
+		return "", fmt.Errorf("%w: width and gap must be at most %d", ErrInvalidOption, MaxColumnWidth)
+	}
+
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		lines = append(lines, wrapToWidth(strings.TrimRight(paragraph, "\r"), opts.Width)...)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	rows := (len(lines) + opts.Columns - 1) / opts.Columns
+	gap := strings.Repeat(" ", opts.Gap)
+	out := make([]string, rows)
+	for r := 0; r < rows; r++ {
+		var b strings.Builder
+		for c := 0; c < opts.Columns; c++ {
+			i := c*rows + r
+			if i >= len(lines) {
+				break
+			}
+			if c > 0 {
+				b.WriteString(gap)
+			}
+			b.WriteString(lines[i])
+			if pad := opts.Width - DisplayWidth(lines[i]); pad > 0 {
+				b.WriteString(strings.Repeat(" ", pad))
+			}
+		}
+		out[r] = strings.TrimRight(b.String(), " ")
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+func wrapToWidth(paragraph string, limit int) []string {
+	words := strings.Fields(paragraph)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	var current strings.Builder
+	used := 0
+	flush := func() {
+		lines = append(lines, current.String())
+		current.Reset()
+		used = 0
+	}
+	for _, word := range words {
+		w := DisplayWidth(word)
+		for w > limit {
+			if used > 0 {
+				flush()
+			}
+			head, rest := splitAtWidth(word, limit)
+			lines = append(lines, head)
+			word, w = rest, DisplayWidth(rest)
+		}
+		if word == "" {
+			continue
+		}
+		if used > 0 && used+1+w > limit {
+			flush()
+		}
+		if used > 0 {
+			current.WriteByte(' ')
+			used++
+		}
+		current.WriteString(word)
+		used += w
+	}
+	if used > 0 {
+		flush()
+	}
+	return lines
+}
+
+func splitAtWidth(s string, limit int) (string, string) {
+	used, i := 0, 0
+	for i < len(s) {
+		n := nextGrapheme(s[i:])
+		w := clusterWidth(s[i : i+n])
+		if used+w > limit && i > 0 {
+			break
+		}
+		used += w
+		i += n
+	}
+	return s[:i], s[i:]
+}
+
+func VerticalText(text string, mode VerticalMode) (string, error) {
+	switch mode {
+	case "", VerticalStack:
+		var out []string
+		for _, line := range strings.Split(text, "\n") {
+			for _, word := range strings.Fields(line) {
+				if len(out) > 0 {
+					out = append(out, "")
+				}
+				out = append(out, Graphemes(word)...)
+			}
+		}
+		return strings.Join(out, "\n"), nil
+	case VerticalRotate:
+		lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+		columns := make([][]string, len(lines))
+		widths := make([]int, len(lines))
+		height := 0
+		for i, line := range lines {
+			c := len(lines) - 1 - i
+			columns[c] = Graphemes(strings.TrimRight(line, "\r"))
+			widths[c] = 1
+			for _, cell := range columns[c] {
+				if w := clusterWidth(cell); w > widths[c] {
+					widths[c] = w
+				}
+			}
+			if len(columns[c]) > height {
+				height = len(columns[c])
+			}
+		}
+		out := make([]string, height)
+		for row := 0; row < height; row++ {
+			var b strings.Builder
+			for c, col := range columns {
+				if c > 0 {
+					b.WriteByte(' ')
+				}
+				cell := ""
+				if row < len(col) {
+					cell = col[row]
+				}
+				b.WriteString(cell)
+				b.WriteString(strings.Repeat(" ", widths[c]-DisplayWidth(cell)))
+			}
+			out[row] = strings.TrimRight(b.String(), " ")
+		}
+		return strings.Join(out, "\n"), nil
+	}
+	return "", fmt.Errorf("%w: unknown vertical mode %q", ErrInvalidOption, mode)
+}
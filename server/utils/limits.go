@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp/syntax"
+	"time"
+)
+
+const (
+	LimitInputBytes      = "maxInputBytes"
+	LimitRegexComplexity = "maxRegexComplexity"
+	LimitExpansion       = "maxExpansion"
+	LimitTimeBudget      = "timeBudget"
+)
+
+// small inputs may always grow to this many bytes, so a short template can
+// still be expanded
+const minExpansionBytes = 64 << 10
+
+// Limits bound what a single request may use; zero fields are unlimited.
+// TimeBudget is wall-clock time, enforced through the request context.
+type Limits struct {
+	MaxInputBytes      int64         `json:"maxInputBytes"`
+	MaxRegexComplexity int           `json:"maxRegexComplexity"`
+	MaxExpansion       float64       `json:"maxExpansion"`
+	TimeBudget         time.Duration `json:"timeBudget"`
+}
+
+// Override returns l with every non-zero field of o taking precedence
+func (l Limits) Override(o Limits) Limits {
+	if o.MaxInputBytes != 0 {
+		l.MaxInputBytes = o.MaxInputBytes
+	}
+	if o.MaxRegexComplexity != 0 {
+		l.MaxRegexComplexity = o.MaxRegexComplexity
+	}
+	if o.MaxExpansion != 0 {
+		l.MaxExpansion = o.MaxExpansion
+	}
+	if o.TimeBudget != 0 {
+		l.TimeBudget = o.TimeBudget
+	}
+	return l
+}
+
+type LimitError struct {
+	Limit  string  `json:"limit"`
+	Max    float64 `json:"max"`
+	Actual float64 `json:"actual"`
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("limit exceeded: %s allows %g, got %g", e.Limit, e.Max, e.Actual)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+func (e *LimitError) Status() int {
+	switch e.Limit {
+	case LimitRegexComplexity:
+		return http.StatusBadRequest
+	case LimitTimeBudget:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusRequestEntityTooLarge
+}
+
+type limitsKey struct{}
+
+func WithLimits(ctx context.Context, l Limits) context.Context {
+	return context.WithValue(ctx, limitsKey{}, l)
+}
+
+func LimitsFrom(ctx context.Context) Limits {
+	l, _ := ctx.Value(limitsKey{}).(Limits)
+	return l
+}
+
+func (l Limits) CheckInput(n int64) error {
+	if l.MaxInputBytes > 0 && n > l.MaxInputBytes {
+		return &LimitError{Limit: LimitInputBytes, Max: float64(l.MaxInputBytes), Actual: float64(n)}
+	}
+	return nil
+}
+
+// CheckExpansion reports output that grew past MaxExpansion times its input
+func (l Limits) CheckExpansion(in, out int64) error {
+	if l.MaxExpansion <= 0 || out <= minExpansionBytes {
+		return nil
+	}
+	if ratio := float64(out) / float64(max(in, 1)); ratio > l.MaxExpansion {
+		return &LimitError{Limit: LimitExpansion, Max: l.MaxExpansion, Actual: round2(ratio)}
+	}
+	return nil
+}
+
+func (l Limits) CheckRegex(pattern string, flags RegexFlags) error {
+	if l.MaxRegexComplexity <= 0 {
+		return nil
+	}
+	n, err := RegexComplexity(pattern, flags)
+	if err != nil {
+		return err
+	}
+	if n > l.MaxRegexComplexity {
+		return &LimitError{Limit: LimitRegexComplexity, Max: float64(l.MaxRegexComplexity), Actual: float64(n)}
+	}
+	return nil
+}
+
+// RegexComplexity is the size of the compiled program, which grows with
+// nested and counted repetition like (a{100}){100}
+func RegexComplexity(pattern string, flags RegexFlags) (int, error) {
+	re, err := CompileRegex(pattern, flags)
+	if err != nil {
+		return 0, err
+	}
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return 0, regexErrorFor(pattern, err)
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return 0, regexErrorFor(pattern, err)
+	}
+	return len(prog.Inst), nil
+}
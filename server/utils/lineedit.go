@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// LineFilter limits a line edit to lines matching Match (or not matching it
+// with Invert). An empty Match selects every line.
+type LineFilter struct {
+	Match      string `json:"match"`
+	IgnoreCase bool   `json:"ignoreCase"`
+	Invert     bool   `json:"invert"`
+}
+
+func (f LineFilter) compile(limits Limits) (func(string) bool, error) {
+	if f.Match == "" {
+		return func(string) bool { return !f.Invert }, nil
+	}
+	flags := RegexFlags{IgnoreCase: f.IgnoreCase}
+	if err := limits.CheckRegex(f.Match, flags); err != nil {
+		return nil, err
+	}
+	re, err := CompileRegex(f.Match, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(line string) bool { return re.MatchString(line) != f.Invert }, nil
+}
+
+// editLines keeps a running total of the output size, so a long prefix or
+// insert on many short lines stops at the expansion limit
+func editLines(ctx context.Context, text string, filter LineFilter, edit func(clusters []string) []string) (string, error) {
+	limits := LimitsFrom(ctx)
+	selected, err := filter.compile(limits)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(text, "\n")
+	size := int64(len(text))
+	for i, line := range lines {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+		}
+		body := strings.TrimSuffix(line, "\r")
+		if !selected(body) {
+			continue
+		}
+		lines[i] = strings.Join(edit(Graphemes(body)), "") + line[len(body):]
+		size += int64(len(lines[i]) - len(line))
+		if err := limits.CheckExpansion(int64(len(text)), size); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// lines shorter than the column get the text at their end, like a column cursor in an editor
+func InsertAtColumn(text string, column int, insert string, filter LineFilter) (string, error) {
+	return InsertAtColumnContext(context.Background(), text, column, insert, filter)
+}
+
+func InsertAtColumnContext(ctx context.Context, text string, column int, insert string, filter LineFilter) (string, error) {
+	if column < 1 {
+		return "", fmt.Errorf("%w: columns start at 1, got %d", ErrInvalidOption, column)
+	}
+	return editLines(ctx, text, filter, func(clusters []string) []string {
+		at := min(column-1, len(clusters))
+		out := make([]string, 0, len(clusters)+1)
+		out = append(out, clusters[:at]...)
+		out = append(out, insert)
+		return append(out, clusters[at:]...)
+	})
+}
+
+// to of 0 deletes through the end of each line
+func DeleteColumns(text string, from, to int, filter LineFilter) (string, error) {
+	return DeleteColumnsContext(context.Background(), text, from, to, filter)
+}
+
+func DeleteColumnsContext(ctx context.Context, text string, from, to int, filter LineFilter) (string, error) {
+	if from < 1 || to != 0 && to < from {
+		return "", fmt.Errorf("%w: invalid column range %d-%d", ErrInvalidOption, from, to)
+	}
+	return editLines(ctx, text, filter, func(clusters []string) []string {
+		if from > len(clusters) {
+			return clusters
+		}
+		end := len(clusters)
+		if to != 0 && to < end {
+			end = to
+		}
+		return append(clusters[:from-1:from-1], clusters[end:]...)
+	})
+}
+
+func PrependLines(text, prefix string, filter LineFilter) (string, error) {
+	return PrependLinesContext(context.Background(), text, prefix, filter)
+}
+
+func PrependLinesContext(ctx context.Context, text, prefix string, filter LineFilter) (string, error) {
+	return editLines(ctx, text, filter, func(clusters []string) []string {
+		return append([]string{prefix}, clusters...)
+	})
+}
+
+func AppendLines(text, suffix string, filter LineFilter) (string, error) {
+	return AppendLinesContext(context.Background(), text, suffix, filter)
+}
+
+func AppendLinesContext(ctx context.Context, text, suffix string, filter LineFilter) (string, error) {
+	return editLines(ctx, text, filter, func(clusters []string) []string {
+		return append(clusters, suffix)
+	})
+}
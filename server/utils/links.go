@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	LinkFormatAuto     = "auto"
+	LinkFormatHTML     = "html"
+	LinkFormatMarkdown = "markdown"
+	LinkFormatText     = "text"
+)
+
+var LinkFormats = []string{LinkFormatAuto, LinkFormatHTML, LinkFormatMarkdown, LinkFormatText}
+
+type Link struct {
+	URL    string `json:"url"`
+	Text   string `json:"text"`
+	Source string `json:"source"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	start  int
+	end    int
+}
+
+var (
+	markdownLinkPattern      = regexp.MustCompile(`(!?)\[((?:[^\[\]]|\[[^\]]*\])*)\]\(\s*<?([^\s()<>]+(?:\([^\s()]*\))?)>?(?:\s+["'(][^)]*["')])?\s*\)`)
+	markdownAutolinkPattern  = regexp.MustCompile(`<((?:https?|ftp|mailto):[^\s<>]+)>`)
+	markdownReferencePattern = regexp.MustCompile(`(?m)^[ ]{0,3}\[([^\]]+)\]:\s*<?(\S+?)>?(?:\s+["'(].*["')])?[ \t]*$`)
+	bareURLPattern           = regexp.MustCompile(`\b(?:https?://|www\.)[^\s<>"'\x60]+`)
+)
+
+func ExtractLinks(text string, format string) ([]Link, error) {
+	var links []Link
+	switch format {
+	case "", LinkFormatAuto:
+		links = append(htmlLinks(text), markdownLinks(text)...)
+	case LinkFormatHTML:
+		links = htmlLinks(text)
+	case LinkFormatMarkdown:
+		links = markdownLinks(text)
+	case LinkFormatText:
+	default:
+		return nil, fmt.Errorf("%w: unknown link format %q", ErrInvalidOption, format)
+	}
+	links = append(links, bareLinks(text)...)
+
+	// an href's URL would otherwise also be picked up again as a bare URL
+	sort.SliceStable(links, func(i, j int) bool { return links[i].start < links[j].start })
+	position := positionIndex(text)
+	kept := []Link{}
+	prev := -1
+	for _, l := range links {
+		if l.start < prev {
+			continue
+		}
+		pos := position(l.start)
+		l.Line, l.Column = pos.Line, pos.Column
+		kept = append(kept, l)
+		prev = l.end
+	}
+	return kept, nil
+}
+
+func htmlLinks(text string) []Link {
+	var links []Link
+	z := html.NewTokenizer(strings.NewReader(text))
+	offset := 0
+	var anchor *Link
+	var anchorText strings.Builder
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		raw := len(z.Raw())
+		tok := z.Token()
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			attrs := make(map[string]string, len(tok.Attr))
+			for _, a := range tok.Attr {
+				attrs[a.Key] = a.Val
+			}
+			if href, ok := attrs["href"]; ok && tok.Data == "a" && tt == html.StartTagToken {
+				anchor = &Link{URL: href, Source: "href", start: offset, end: offset + raw}
+				anchorText.Reset()
+			} else if href, ok := attrs["href"]; ok {
+				links = append(links, Link{URL: href, Source: "href", start: offset, end: offset + raw})
+			}
+			if src, ok := attrs["src"]; ok {
+				links = append(links, Link{URL: src, Text: attrs["alt"], Source: "src", start: offset, end: offset + raw})
+			}
+		case html.TextToken:
+			if anchor != nil {
+				anchorText.WriteString(tok.Data)
+			}
+		case html.EndTagToken:
+			if tok.Data == "a" && anchor != nil {
+				anchor.Text = strings.Join(strings.Fields(anchorText.String()), " ")
+				anchor.end = offset + raw
+				links = append(links, *anchor)
+				anchor = nil
+			}
+		}
+		offset += raw
+	}
+	if anchor != nil {
+		anchor.Text = strings.Join(strings.Fields(anchorText.String()), " ")
+		links = append(links, *anchor)
+	}
+	return links
+}
+
+func markdownLinks(text string) []Link {
+	var links []Link
+	for _, m := range markdownLinkPattern.FindAllStringSubmatchIndex(text, -1) {
+		source := "markdown"
+		if m[3] > m[2] {
+			source = "image"
+		}
+		links = append(links, Link{URL: text[m[6]:m[7]], Text: text[m[4]:m[5]], Source: source, start: m[0], end: m[1]})
+	}
+	for _, m := range markdownAutolinkPattern.FindAllStringSubmatchIndex(text, -1) {
+		links = append(links, Link{URL: text[m[2]:m[3]], Source: "autolink", start: m[0], end: m[1]})
+	}
+	for _, m := range markdownReferencePattern.FindAllStringSubmatchIndex(text, -1) {
+		links = append(links, Link{URL: text[m[4]:m[5]], Text: text[m[2]:m[3]], Source: "reference", start: m[0], end: m[1]})
+	}
+	return links
+}
+
+func bareLinks(text string) []Link {
+	var links []Link
+	for _, m := range bareURLPattern.FindAllStringIndex(text, -1) {
+		url := trimURLPunctuation(text[m[0]:m[1]])
+		links = append(links, Link{URL: url, Source: "bare", start: m[0], end: m[0] + len(url)})
+	}
+	return links
+}
+
+// "(see https://x.y/a)." should not keep the ")." but "https://x.y/wiki/Go_(language)" should keep its ")"
+func trimURLPunctuation(url string) string {
+	for url != "" {
+		last := url[len(url)-1]
+		switch {
+		case strings.IndexByte(".,;:!?*", last) >= 0:
+			url = url[:len(url)-1]
+		case last == ')' && strings.Count(url, "(") < strings.Count(url, ")"),
+			last == ']' && strings.Count(url, "[") < strings.Count(url, "]"):
+			url = url[:len(url)-1]
+		default:
+			return url
+		}
+	}
+	return url
+}
@@ -0,0 +1,616 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	mdHeadingPattern  = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdRulePattern     = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdListItemPattern = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])(?:[ \t]+(.*))?$`)
+	mdAutolinkPattern = regexp.MustCompile(`^<((?:https?|ftp|mailto):[^\s<>]+)>`)
+	// line starts that would turn plain text into a block
+	mdBlockStart = regexp.MustCompile(`^(?:#{1,6}(?:\s|$)|>|[-+*](?:\s|$)|\d{1,9}[.)](?:\s|$)|(?:-{3,}|={3,})$)`)
+)
+
+// mdEscape escapes characters Markdown would read as markup. An underscore
+// inside a word, as in snake_case, cannot start emphasis and is left alone.
+func mdEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' && i > 0 && i+1 < len(s) && isWordByte(s[i-1]) && isWordByte(s[i+1]):
+		case strings.IndexByte("\\*_`[]<", c) >= 0:
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func renderMarkdown(root *html.Node) string {
+	return strings.TrimSpace(mdBlocks(root, "\n\n"))
+}
+
+func mdBlocks(n *html.Node, sep string) string {
+	var blocks []string
+	eachBlock(n, func(run []*html.Node) {
+		var b strings.Builder
+		for _, c := range run {
+			b.WriteString(mdInline(c))
+		}
+		var paragraphs []string
+		for _, lines := range paragraphLines(b.String()) {
+			for i, line := range lines {
+				lines[i] = mdEscapeLineStart(line)
+			}
+			// two trailing spaces are a hard line break
+			paragraphs = append(paragraphs, strings.Join(lines, "  \n"))
+		}
+		if len(paragraphs) > 0 {
+			blocks = append(blocks, strings.Join(paragraphs, "\n\n"))
+		}
+	}, func(c *html.Node) {
+		if block := mdBlock(c); block != "" {
+			blocks = append(blocks, block)
+		}
+	})
+	return strings.Join(blocks, sep)
+}
+
+func mdBlock(n *html.Node) string {
+	if level := headingLevel(n); level > 0 {
+		text := strings.Join(strings.Fields(mdChildren(n)), " ")
+		if text == "" {
+			return ""
+		}
+		return strings.Repeat("#", level) + " " + text
+	}
+
+	switch n.Data {
+	case "hr":
+		return "---"
+	case "pre":
+		code := strings.TrimRight(textContent(n), "\n")
+		lang := ""
+		if c := n.FirstChild; c != nil && c.Data == "code" {
+			lang = strings.TrimPrefix(attrOf(c, "class"), "language-")
+		}
+		fence := "```"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		return fence + lang + "\n" + code + "\n" + fence
+	case "blockquote":
+		inner := mdBlocks(n, "\n\n")
+		if author := attrOf(n, "data-author"); author != "" {
+			inner = "**" + mdEscape(author) + " wrote:**\n\n" + inner
+		}
+		if strings.TrimSpace(inner) == "" {
+			return ""
+		}
+		lines := strings.Split(inner, "\n")
+		for i, line := range lines {
+			if line == "" {
+				lines[i] = ">"
+			} else {
+				lines[i] = "> " + line
+			}
+		}
+		return strings.Join(lines, "\n")
+	case "ul", "ol":
+		number, _ := strconv.Atoi(attrOf(n, "start"))
+		number = max(number, 1)
+		var items []string
+		for li := n.FirstChild; li != nil; li = li.NextSibling {
+			if li.Type != html.ElementNode || li.Data != "li" {
+				continue
+			}
+			marker := "- "
+			if n.Data == "ol" {
+				marker = fmt.Sprintf("%d. ", number)
+				number++
+			}
+			sep := "\n"
+			for c := li.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode && c.Data == "p" {
+					sep = "\n\n"
+				}
+			}
+			items = append(items, indentLines(mdBlocks(li, sep), marker, strings.Repeat(" ", len(marker))))
+		}
+		return strings.Join(items, "\n")
+	default:
+		return mdBlocks(n, "\n\n")
+	}
+}
+
+// mdEscapeLineStart keeps text that happens to start like a heading, quote or
+// list item from becoming one
+func mdEscapeLineStart(line string) string {
+	if !mdBlockStart.MatchString(line) {
+		return line
+	}
+	if digits := len(line) - len(strings.TrimLeft(line, "0123456789")); digits > 0 {
+		return line[:digits] + `\` + line[digits:]
+	}
+	return `\` + line
+}
+
+func mdChildren(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(mdInline(c))
+	}
+	return b.String()
+}
+
+func mdInline(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return mdEscape(collapseSpace(n.Data))
+	}
+	if n.Type != html.ElementNode || isHidden(n) {
+		return ""
+	}
+
+	switch n.Data {
+	case "br":
+		return "\n"
+	case "strong", "b":
+		return wrapInline(mdChildren(n), "**", "**")
+	case "em", "i", "cite":
+		return wrapInline(mdChildren(n), "*", "*")
+	case "del", "s", "strike":
+		return wrapInline(mdChildren(n), "~~", "~~")
+	case "u", "ins":
+		// Markdown has no underline, but inline HTML is valid Markdown
+		return wrapInline(mdChildren(n), "<u>", "</u>")
+	case "code", "kbd", "samp", "tt":
+		return mdCode(textContent(n))
+	case "img":
+		src := attrOf(n, "src")
+		if src == "" {
+			return ""
+		}
+		return "![" + mdEscape(attrOf(n, "alt")) + "](" + mdURL(src) + ")"
+	case "a":
+		inner, href := mdChildren(n), attrOf(n, "href")
+		switch {
+		case href == "":
+			return inner
+		case strings.TrimSpace(textContent(n)) == href && mdAutolinkPattern.MatchString("<"+href+">"):
+			return "<" + href + ">"
+		case strings.TrimSpace(inner) == "":
+			inner = mdEscape(href)
+		}
+		return wrapInline(inner, "[", "]("+mdURL(href)+")")
+	default:
+		return mdChildren(n)
+	}
+}
+
+func mdCode(code string) string {
+	code = strings.ReplaceAll(code, "\n", " ")
+	if code == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+	return fence + code + fence
+}
+
+func mdURL(u string) string {
+	if strings.ContainsAny(u, " ()") {
+		return "<" + u + ">"
+	}
+	return u
+}
+
+// mdMaxNesting bounds how deeply quotes and lists nest; markers past it are
+// kept as paragraph text
+const mdMaxNesting = 32
+
+func parseMarkdown(text string) *html.Node {
+	root := element("div")
+	mdParseBlocks(root, strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), 0)
+	return root
+}
+
+func isFence(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+func mdStartsBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return isFence(trimmed) || strings.HasPrefix(trimmed, ">") || mdHeadingPattern.MatchString(line) ||
+		mdRulePattern.MatchString(line) || mdListItemPattern.MatchString(line)
+}
+
+func mdParseBlocks(parent *html.Node, lines []string, depth int) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			i++
+
+		case isFence(trimmed):
+			marker := trimmed[:3]
+			lang := strings.TrimSpace(strings.TrimLeft(trimmed, marker[:1]))
+			j := i + 1
+			for j < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[j]), marker) {
+				j++
+			}
+			code := element("code")
+			if lang != "" {
+				code.Attr = append(code.Attr, html.Attribute{Key: "class", Val: "language-" + lang})
+			}
+			code.AppendChild(textNode(strings.Join(lines[i+1:min(j, len(lines))], "\n")))
+			pre := element("pre")
+			pre.AppendChild(code)
+			parent.AppendChild(pre)
+			i = j + 1
+
+		case mdHeadingPattern.MatchString(line):
+			m := mdHeadingPattern.FindStringSubmatch(line)
+			h := element(fmt.Sprintf("h%d", len(m[1])))
+			mdParseInline(h, m[2])
+			parent.AppendChild(h)
+			i++
+
+		case mdRulePattern.MatchString(line):
+			parent.AppendChild(element("hr"))
+			i++
+
+		case strings.HasPrefix(trimmed, ">") && depth < mdMaxNesting:
+			var quoted []string
+			for ; i < len(lines); i++ {
+				// trim the same leading space TrimSpace does, so the marker
+				// is always stripped and each level shrinks its input
+				body, ok := strings.CutPrefix(strings.TrimLeftFunc(lines[i], unicode.IsSpace), ">")
+				if !ok {
+					break
+				}
+				quoted = append(quoted, strings.TrimPrefix(body, " "))
+			}
+			quote := element("blockquote")
+			mdParseBlocks(quote, quoted, depth+1)
+			parent.AppendChild(quote)
+
+		case mdListItemPattern.MatchString(line) && depth < mdMaxNesting:
+			i = mdParseList(parent, lines, i, depth)
+
+		case strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t"):
+			var code []string
+			for ; i < len(lines) && (strings.HasPrefix(lines[i], "    ") || strings.HasPrefix(lines[i], "\t") || strings.TrimSpace(lines[i]) == ""); i++ {
+				code = append(code, strings.TrimPrefix(strings.TrimPrefix(lines[i], "\t"), "    "))
+			}
+			for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+				code = code[:len(code)-1]
+			}
+			pre := element("pre")
+			c := element("code")
+			c.AppendChild(textNode(strings.Join(code, "\n")))
+			pre.AppendChild(c)
+			parent.AppendChild(pre)
+
+		default:
+			j := i + 1
+			for j < len(lines) && strings.TrimSpace(lines[j]) != "" && !mdStartsBlock(lines[j]) {
+				j++
+			}
+			paragraph := make([]string, j-i)
+			for k, l := range lines[i:j] {
+				paragraph[k] = strings.TrimLeft(l, " \t")
+			}
+			p := element("p")
+			mdParseInline(p, strings.Join(paragraph, "\n"))
+			parent.AppendChild(p)
+			i = j
+		}
+	}
+}
+
+// mdParseList parses the list starting at lines[i] and returns the index of
+// the first line after it
+func mdParseList(parent *html.Node, lines []string, i, depth int) int {
+	first := mdListItemPattern.FindStringSubmatch(lines[i])
+	ordered := first[2][0] >= '0' && first[2][0] <= '9'
+	list := element("ul")
+	if ordered {
+		list = element("ol")
+		if start, _ := strconv.Atoi(first[2][:len(first[2])-1]); start > 1 {
+			list.Attr = append(list.Attr, html.Attribute{Key: "start", Val: strconv.Itoa(start)})
+		}
+	}
+	parent.AppendChild(list)
+
+	tight := true
+	items := [][]string{{first[3]}}
+	// continuation lines are indented to the item's content
+	indent := len(first[1]) + len(first[2]) + 1
+	isItem := func(line string) bool {
+		m := mdListItemPattern.FindStringSubmatch(line)
+		return m != nil && len(m[1]) < indent && (m[2][0] >= '0' && m[2][0] <= '9') == ordered
+	}
+collect:
+	for i++; i < len(lines); {
+		line := lines[i]
+		if isItem(line) {
+			m := mdListItemPattern.FindStringSubmatch(line)
+			indent = len(m[1]) + len(m[2]) + 1
+			items = append(items, []string{m[3]})
+			i++
+			continue
+		}
+		last := items[len(items)-1]
+		trimmed := strings.TrimSpace(line)
+		leading := len(line) - len(strings.TrimLeft(line, " \t"))
+		switch {
+		case trimmed == "":
+			// a blank line only continues the list if more of it follows
+			next := i + 1
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+			if next == len(lines) {
+				i = next
+				break collect
+			}
+			nextLeading := len(lines[next]) - len(strings.TrimLeft(lines[next], " \t"))
+			if nextLeading < indent && !isItem(lines[next]) {
+				i = next
+				break collect
+			}
+			tight = false
+			items[len(items)-1] = append(last, "")
+			i++
+		case leading >= indent:
+			items[len(items)-1] = append(last, line[min(indent, len(line)):])
+			i++
+		case !mdStartsBlock(line) && last[len(last)-1] != "":
+			// lazy continuation of the item's paragraph
+			items[len(items)-1] = append(last, trimmed)
+			i++
+		default:
+			break collect
+		}
+	}
+
+	for _, item := range items {
+		li := element("li")
+		mdParseBlocks(li, item, depth+1)
+		if tight {
+			for c := li.FirstChild; c != nil; {
+				next := c.NextSibling
+				if c.Data == "p" {
+					for c.FirstChild != nil {
+						child := c.FirstChild
+						c.RemoveChild(child)
+						li.InsertBefore(child, c)
+					}
+					li.RemoveChild(c)
+				}
+				c = next
+			}
+		}
+		list.AppendChild(li)
+	}
+	return i
+}
+
+func mdParseInline(parent *html.Node, s string) {
+	var buf strings.Builder
+	flush := func() {
+		if buf.Len() > 0 {
+			parent.AppendChild(textNode(buf.String()))
+			buf.Reset()
+		}
+	}
+	add := func(n *html.Node) {
+		flush()
+		parent.AppendChild(n)
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			add(element("br"))
+			i += 2
+			continue
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", s[i+1]) >= 0:
+			buf.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '\n':
+			text := buf.String()
+			if strings.HasSuffix(text, "  ") {
+				buf.Reset()
+				buf.WriteString(strings.TrimRight(text, " "))
+				add(element("br"))
+			} else {
+				buf.WriteByte('\n')
+			}
+			i++
+			continue
+
+		case c == '`':
+			run := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+			fence := s[i : i+run]
+			if end := strings.Index(s[i+run:], fence); end >= 0 {
+				code := element("code")
+				body := strings.ReplaceAll(s[i+run:i+run+end], "\n", " ")
+				if len(body) > 2 && body[0] == ' ' && body[len(body)-1] == ' ' {
+					body = body[1 : len(body)-1]
+				}
+				code.AppendChild(textNode(body))
+				add(code)
+				i += run + end + run
+			} else {
+				buf.WriteString(fence)
+				i += run
+			}
+			continue
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if label, href, end, ok := mdLinkAt(s, i+1); ok {
+				add(element("img", "src", safeURL(href), "alt", label))
+				i = end
+				continue
+			}
+		case c == '[':
+			if label, href, end, ok := mdLinkAt(s, i); ok {
+				a := element("a", "href", safeURL(href))
+				mdParseInline(a, label)
+				add(a)
+				i = end
+				continue
+			}
+		case c == '<':
+			if m := mdAutolinkPattern.FindStringSubmatch(s[i:]); m != nil {
+				a := element("a", "href", m[1])
+				a.AppendChild(textNode(m[1]))
+				add(a)
+				i += len(m[0])
+				continue
+			}
+			lower := strings.ToLower(s[i:])
+			if strings.HasPrefix(lower, "<br>") || strings.HasPrefix(lower, "<br/>") || strings.HasPrefix(lower, "<br />") {
+				add(element("br"))
+				i += strings.IndexByte(s[i:], '>') + 1
+				continue
+			}
+			if strings.HasPrefix(lower, "<u>") {
+				if end := strings.Index(lower[3:], "</u>"); end >= 0 {
+					u := element("u")
+					mdParseInline(u, s[i+3:i+3+end])
+					add(u)
+					i += 3 + end + 4
+					continue
+				}
+			}
+
+		case c == '*' || c == '_' || c == '~':
+			delim := s[i : i+1]
+			if i+1 < len(s) && s[i+1] == c {
+				delim = s[i : i+2]
+			}
+			// snake_case and lone tildes are not emphasis
+			wordy := c == '_' && i > 0 && isWordByte(s[i-1])
+			if delim != "~" && !wordy {
+				if end := mdCloser(s, i+len(delim), delim); end >= 0 {
+					tag := "em"
+					switch {
+					case c == '~':
+						tag = "del"
+					case len(delim) == 2:
+						tag = "strong"
+					}
+					el := element(tag)
+					mdParseInline(el, s[i+len(delim):end])
+					add(el)
+					i = end + len(delim)
+					continue
+				}
+			}
+			buf.WriteString(delim)
+			i += len(delim)
+			continue
+		}
+		buf.WriteByte(c)
+		i++
+	}
+	flush()
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b >= 0x80
+}
+
+// mdCloser finds the delimiter closing emphasis opened just before from
+func mdCloser(s string, from int, delim string) int {
+	if from >= len(s) || s[from] == ' ' || s[from] == '\n' {
+		return -1
+	}
+	for k := from + 1; k+len(delim) <= len(s); k++ {
+		switch {
+		case s[k] == '\\':
+			k++
+		case s[k] == '`':
+			if end := strings.IndexByte(s[k+1:], '`'); end >= 0 {
+				k += end + 1
+			}
+		case strings.HasPrefix(s[k:], delim) && s[k-1] != ' ' && s[k-1] != '\n':
+			after := k + len(delim)
+			if len(delim) == 1 && after < len(s) && s[after] == delim[0] {
+				// part of a doubled delimiter, which belongs to nested emphasis
+				k++
+				continue
+			}
+			if delim[0] == '_' && after < len(s) && isWordByte(s[after]) {
+				continue
+			}
+			return k
+		}
+	}
+	return -1
+}
+
+// mdLinkAt parses "[label](url "title")" at s[i] and returns the index after it
+func mdLinkAt(s string, i int) (label, href string, end int, ok bool) {
+	depth := 0
+	j := i
+	for ; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+			continue
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	if j+1 >= len(s) || s[j+1] != '(' {
+		return "", "", 0, false
+	}
+	depth = 0
+	k := j + 1
+	for ; k < len(s); k++ {
+		if s[k] == '(' {
+			depth++
+		} else if s[k] == ')' {
+			if depth--; depth == 0 {
+				break
+			}
+		}
+	}
+	if k >= len(s) {
+		return "", "", 0, false
+	}
+	dest := strings.TrimSpace(s[j+2 : k])
+	if strings.HasPrefix(dest, "<") {
+		if close := strings.IndexByte(dest, '>'); close > 0 {
+			dest = dest[1:close]
+		}
+	} else if fields := strings.Fields(dest); len(fields) > 0 {
+		dest = fields[0]
+	}
+	return s[i+1 : j], dest, k + 1, true
+}
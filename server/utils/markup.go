@@ -0,0 +1,272 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type MarkupFormat string
+
+const (
+	MarkupHTML     MarkupFormat = "html"
+	MarkupMarkdown MarkupFormat = "markdown"
+	MarkupBBCode   MarkupFormat = "bbcode"
+)
+
+var MarkupFormats = []MarkupFormat{MarkupHTML, MarkupMarkdown, MarkupBBCode}
+
+func (MarkupFormat) Values() []string {
+	return EnumValues(MarkupFormats)
+}
+
+func (f MarkupFormat) Valid() bool {
+	return EnumValid(MarkupFormats, f)
+}
+
+// ConvertMarkup converts between HTML, Markdown and BBCode. Every format is
+// parsed into an HTML node tree and rendered from it, so only the common
+// formatting survives: emphasis, links, images, code, quotes, lists,
+// headings and rules. Anything else is reduced to its text.
+func ConvertMarkup(text string, from, to MarkupFormat) (string, error) {
+	for _, f := range []MarkupFormat{from, to} {
+		if !f.Valid() {
+			return "", fmt.Errorf("%w: unknown markup format %q", ErrInvalidOption, f)
+		}
+	}
+	if from == to {
+		return text, nil
+	}
+
+	var root *html.Node
+	switch from {
+	case MarkupHTML:
+		var err error
+		if root, err = parseHTMLMarkup(text); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidOption, err)
+		}
+	case MarkupMarkdown:
+		root = parseMarkdown(text)
+	case MarkupBBCode:
+		root = parseBBCode(text)
+	}
+
+	switch to {
+	case MarkupHTML:
+		return renderHTMLMarkup(root), nil
+	case MarkupMarkdown:
+		return renderMarkdown(root), nil
+	default:
+		return renderBBCode(root), nil
+	}
+}
+
+func parseHTMLMarkup(text string) (*html.Node, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(text), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return nil, err
+	}
+	root := element("div")
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+	return root, nil
+}
+
+func renderHTMLMarkup(root *html.Node) string {
+	var b bytes.Buffer
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		html.Render(&b, c)
+		if isBlockNode(c) {
+			b.WriteByte('\n')
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func element(tag string, attrs ...string) *html.Node {
+	n := &html.Node{Type: html.ElementNode, Data: tag, DataAtom: atom.Lookup([]byte(tag))}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		n.Attr = append(n.Attr, html.Attribute{Key: attrs[i], Val: attrs[i+1]})
+	}
+	return n
+}
+
+// safeURL returns raw if it is relative or uses http, https or mailto, and
+// "" otherwise, so that converted markup can't carry javascript: and data:
+// links into a page. Browsers ignore tabs and newlines anywhere in a URL and
+// leading control characters, so the scheme is read with those removed.
+func safeURL(raw string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, raw)
+	cleaned = strings.TrimLeft(cleaned, "\x00\x01\x02\x03\x04\x05\x06\x07\x08\x0b\x0c\x0e\x0f\x10\x11\x12\x13\x14\x15\x16\x17\x18\x19\x1a\x1b\x1c\x1d\x1e\x1f ")
+	colon := strings.IndexByte(cleaned, ':')
+	if colon < 0 || strings.ContainsAny(cleaned[:colon], "/?#") {
+		return raw
+	}
+	switch strings.ToLower(cleaned[:colon]) {
+	case "http", "https", "mailto":
+		return raw
+	}
+	return ""
+}
+
+func textNode(s string) *html.Node {
+	return &html.Node{Type: html.TextNode, Data: s}
+}
+
+func attrOf(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// styleOf reads one property from an element's inline style
+func styleOf(n *html.Node, property string) string {
+	for _, decl := range strings.Split(attrOf(n, "style"), ";") {
+		if name, value, ok := strings.Cut(decl, ":"); ok && strings.EqualFold(strings.TrimSpace(name), property) {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+var blockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "center": true, "dd": true,
+	"details": true, "div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hr": true, "li": true, "main": true, "nav": true,
+	"ol": true, "p": true, "pre": true, "section": true, "table": true, "tbody": true, "td": true,
+	"tfoot": true, "th": true, "thead": true, "tr": true, "ul": true,
+	// never rendered, but they must not end up inside a paragraph
+	"head": true, "script": true, "style": true, "template": true, "title": true,
+}
+
+func isBlockNode(n *html.Node) bool {
+	return n.Type == html.ElementNode && blockTags[n.Data]
+}
+
+func isHidden(n *html.Node) bool {
+	switch n.Data {
+	case "head", "script", "style", "template", "title":
+		return n.Type == html.ElementNode
+	}
+	return n.Type == html.CommentNode || n.Type == html.DoctypeNode
+}
+
+func headingLevel(n *html.Node) int {
+	if n.Type == html.ElementNode && len(n.Data) == 2 && n.Data[0] == 'h' && n.Data[1] >= '1' && n.Data[1] <= '6' {
+		return int(n.Data[1] - '0')
+	}
+	return 0
+}
+
+// collapseSpace applies HTML whitespace rules: any run of ASCII whitespace
+// is one space. Non-breaking spaces are content and stay.
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// wrapInline puts markers around inner, keeping its outer spaces outside so
+// that "<b>bold </b>x" does not become "**bold **x"
+func wrapInline(inner, open, close string) string {
+	trimmed := strings.TrimSpace(inner)
+	if trimmed == "" {
+		return inner
+	}
+	start := strings.Index(inner, trimmed)
+	return inner[:start] + open + trimmed + close + inner[start+len(trimmed):]
+}
+
+// eachBlock splits the children of n into runs of inline content and block
+// elements, which is how both Markdown and BBCode have to lay them out
+func eachBlock(n *html.Node, inline func([]*html.Node), block func(*html.Node)) {
+	var run []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case isHidden(c):
+		case isBlockNode(c):
+			if len(run) > 0 {
+				inline(run)
+				run = nil
+			}
+			block(c)
+		default:
+			run = append(run, c)
+		}
+	}
+	if len(run) > 0 {
+		inline(run)
+	}
+}
+
+// paragraphLines splits rendered inline content, where line breaks are
+// "\n", into paragraphs of trimmed lines; blank lines separate paragraphs
+func paragraphLines(s string) [][]string {
+	var paragraphs [][]string
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+			continue
+		}
+		if len(lines) > 0 {
+			paragraphs = append(paragraphs, lines)
+			lines = nil
+		}
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, lines)
+	}
+	return paragraphs
+}
+
+func indentLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = first + line
+		case line != "":
+			lines[i] = rest + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+type MergeOptions struct {
+	OursLabel   string `json:"oursLabel"`
+	TheirsLabel string `json:"theirsLabel"`
+	ShowBase    bool   `json:"showBase"`
+}
+
+type MergeConflict struct {
+	Line      int      `json:"line"`
+	BaseStart int      `json:"baseStart"`
+	BaseEnd   int      `json:"baseEnd"`
+	Base      []string `json:"base"`
+	Ours      []string `json:"ours"`
+	Theirs    []string `json:"theirs"`
+}
+
+type MergeResult struct {
+	Text      string          `json:"text"`
+	Clean     bool            `json:"clean"`
+	Conflicts []MergeConflict `json:"conflicts"`
+}
+
+type mergeHunk struct {
+	start, end int
+	lines      []string
+	theirs     bool
+}
+
+func Merge3(base, ours, theirs string) MergeResult {
+	result, _ := Merge3WithOptions(context.Background(), base, ours, theirs, MergeOptions{})
+	return result
+}
+
+func Merge3WithOptions(ctx context.Context, base, ours, theirs string, opts MergeOptions) (MergeResult, error) {
+	if opts.OursLabel == "" {
+		opts.OursLabel = "ours"
+	}
+	if opts.TheirsLabel == "" {
+		opts.TheirsLabel = "theirs"
+	}
+
+	baseLines := strings.Split(base, "\n")
+	oursOps, err := DiffLinesContext(ctx, base, ours)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	theirsOps, err := DiffLinesContext(ctx, base, theirs)
+	if err != nil {
+		return MergeResult{}, err
+	}
+
+	hunks := append(diffHunks(oursOps, false), diffHunks(theirsOps, true)...)
+	sort.SliceStable(hunks, func(i, j int) bool { return hunks[i].start < hunks[j].start })
+
+	result := MergeResult{Clean: true, Conflicts: []MergeConflict{}}
+	var out []string
+	pos := 0
+	for i := 0; i < len(hunks); {
+		lo, hi := hunks[i].start, hunks[i].end
+		j := i + 1
+		for j < len(hunks) && hunks[j].start <= hi {
+			if hunks[j].end > hi {
+				hi = hunks[j].end
+			}
+			j++
+		}
+		group := hunks[i:j]
+		i = j
+
+		out = append(out, baseLines[pos:lo]...)
+		pos = hi
+
+		var oursGroup, theirsGroup []mergeHunk
+		for _, h := range group {
+			if h.theirs {
+				theirsGroup = append(theirsGroup, h)
+			} else {
+				oursGroup = append(oursGroup, h)
+			}
+		}
+		oursSide := applyHunks(baseLines, lo, hi, oursGroup)
+		theirsSide := applyHunks(baseLines, lo, hi, theirsGroup)
+
+		switch {
+		case len(theirsGroup) == 0:
+			out = append(out, oursSide...)
+		case len(oursGroup) == 0:
+			out = append(out, theirsSide...)
+		case equalLines(oursSide, theirsSide):
+			out = append(out, oursSide...)
+		default:
+			result.Clean = false
+			result.Conflicts = append(result.Conflicts, MergeConflict{
+				Line:      len(out) + 1,
+				BaseStart: lo,
+				BaseEnd:   hi,
+				Base:      append([]string{}, baseLines[lo:hi]...),
+				Ours:      oursSide,
+				Theirs:    theirsSide,
+			})
+			out = append(out, "<<<<<<< "+opts.OursLabel)
+			out = append(out, oursSide...)
+			if opts.ShowBase {
+				out = append(out, "||||||| base")
+				out = append(out, baseLines[lo:hi]...)
+			}
+			out = append(out, "=======")
+			out = append(out, theirsSide...)
+			out = append(out, ">>>>>>> "+opts.TheirsLabel)
+		}
+	}
+	out = append(out, baseLines[pos:]...)
+
+	result.Text = strings.Join(out, "\n")
+	return result, nil
+}
+
+func diffHunks(ops []DiffOp, theirs bool) []mergeHunk {
+	var hunks []mergeHunk
+	open := false
+	for _, op := range ops {
+		if op.Kind == DiffEqual {
+			open = false
+			continue
+		}
+		if !open {
+			hunks = append(hunks, mergeHunk{start: op.AStart, end: op.AStart, theirs: theirs})
+			open = true
+		}
+		h := &hunks[len(hunks)-1]
+		if op.Kind == DiffDelete {
+			h.end += len(op.Lines)
+		} else {
+			h.lines = append(h.lines, op.Lines...)
+		}
+	}
+	return hunks
+}
+
+func applyHunks(base []string, lo, hi int, hunks []mergeHunk) []string {
+	out := []string{}
+	pos := lo
+	for _, h := range hunks {
+		out = append(out, base[pos:h.start]...)
+		out = append(out, h.lines...)
+		pos = h.end
+	}
+	return append(out, base[pos:hi]...)
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
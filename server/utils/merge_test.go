@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name         string
+		base         string
+		ours, theirs string
+		opts         MergeOptions
+		want         string
+		conflicts    []MergeConflict
+	}{
+		{
+			name: "no changes",
+			base: "a\nb\nc", ours: "a\nb\nc", theirs: "a\nb\nc",
+			want: "a\nb\nc",
+		},
+		{
+			name: "one side changes",
+			base: "a\nb\nc", ours: "a\nB\nc", theirs: "a\nb\nc",
+			want: "a\nB\nc",
+		},
+		{
+			name: "changes in different places",
+			base: "a\nb\nc\nd\ne", ours: "A\nb\nc\nd\ne", theirs: "a\nb\nc\nd\nE",
+			want: "A\nb\nc\nd\nE",
+		},
+		{
+			name: "same change on both sides",
+			base: "a\nb\nc", ours: "a\nX\nc", theirs: "a\nX\nc",
+			want: "a\nX\nc",
+		},
+		{
+			name: "insert and delete apart",
+			base: "a\nb\nc\nd", ours: "a\nnew\nb\nc\nd", theirs: "a\nb\nc",
+			want: "a\nnew\nb\nc",
+		},
+		{
+			name: "conflicting edits",
+			base: "a\nb\nc", ours: "a\nours\nc", theirs: "a\ntheirs\nc",
+			want: "a\n<<<<<<< ours\nours\n=======\ntheirs\n>>>>>>> theirs\nc",
+			conflicts: []MergeConflict{
+				{Line: 2, BaseStart: 1, BaseEnd: 2, Base: []string{"b"}, Ours: []string{"ours"}, Theirs: []string{"theirs"}},
+			},
+		},
+		{
+			name: "edit against delete with labels and base",
+			base: "a\nb\nc", ours: "a\nB\nc", theirs: "a\nc",
+			opts: MergeOptions{OursLabel: "mine", TheirsLabel: "yours", ShowBase: true},
+			want: "a\n<<<<<<< mine\nB\n||||||| base\nb\n=======\n>>>>>>> yours\nc",
+			conflicts: []MergeConflict{
+				{Line: 2, BaseStart: 1, BaseEnd: 2, Base: []string{"b"}, Ours: []string{"B"}, Theirs: []string{}},
+			},
+		},
+		{
+			name: "overlapping hunks form one conflict",
+			base: "a\nb\nc\nd", ours: "a\nX\nY\nd", theirs: "a\nb\nZ\nd",
+			want: "a\n<<<<<<< ours\nX\nY\n=======\nb\nZ\n>>>>>>> theirs\nd",
+			conflicts: []MergeConflict{
+				{Line: 2, BaseStart: 1, BaseEnd: 3, Base: []string{"b", "c"}, Ours: []string{"X", "Y"}, Theirs: []string{"b", "Z"}},
+			},
+		},
+		{
+			name: "both insert at the same place",
+			base: "a\nb", ours: "a\nx\nb", theirs: "a\ny\nb",
+			want: "a\n<<<<<<< ours\nx\n=======\ny\n>>>>>>> theirs\nb",
+			conflicts: []MergeConflict{
+				{Line: 2, BaseStart: 1, BaseEnd: 1, Base: []string{}, Ours: []string{"x"}, Theirs: []string{"y"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Merge3WithOptions(context.Background(), tt.base, tt.ours, tt.theirs, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Text != tt.want {
+				t.Errorf("text = %q, want %q", got.Text, tt.want)
+			}
+			if tt.conflicts == nil {
+				tt.conflicts = []MergeConflict{}
+			}
+			if got.Clean != (len(tt.conflicts) == 0) {
+				t.Errorf("clean = %v with %d conflicts", got.Clean, len(tt.conflicts))
+			}
+			if !reflect.DeepEqual(got.Conflicts, tt.conflicts) {
+				t.Errorf("conflicts = %+v, want %+v", got.Conflicts, tt.conflicts)
+			}
+		})
+	}
+}
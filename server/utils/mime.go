@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+func EncodeQuotedPrintable(text string) (string, error) {
+	var b bytes.Buffer
+	w := quotedprintable.NewWriter(&b)
+	if _, err := w.Write([]byte(text)); err != nil {
+		return "", fmt.Errorf("failed to encode quoted-printable: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode quoted-printable: %v", err)
+	}
+	return b.String(), nil
+}
+
+func DecodeQuotedPrintable(text string) (string, error) {
+	decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(text)))
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted-printable: %v", err)
+	}
+	return string(decoded), nil
+}
+
+func EncodeMIMEHeader(text, encoding string) (string, error) {
+	switch strings.ToUpper(encoding) {
+	case "", "B":
+		return mime.BEncoding.Encode("UTF-8", text), nil
+	case "Q":
+		return mime.QEncoding.Encode("UTF-8", text), nil
+	default:
+		return "", fmt.Errorf("%w: unknown encoded-word encoding %q", ErrInvalidOption, encoding)
+	}
+}
+
+var mimeHeaderDecoder = &mime.WordDecoder{
+	CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		enc, err := htmlindex.Get(charset)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported charset %q", charset)
+		}
+		return enc.NewDecoder().Reader(input), nil
+	},
+}
+
+func DecodeMIMEHeader(text string) (string, error) {
+	decoded, err := mimeHeaderDecoder.DecodeHeader(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid encoded-word: %v", err)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+)
+
+const (
+	minHashSize      = 128
+	minHashBands     = 32
+	minHashRows      = minHashSize / minHashBands
+	maxBucketCompare = 50
+)
+
+type NearDuplicateItem struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+}
+
+type NearDuplicateCluster struct {
+	Items      []NearDuplicateItem `json:"items"`
+	Similarity float64             `json:"similarity"`
+}
+
+func NearDuplicates(text string, threshold float64) ([]NearDuplicateCluster, error) {
+	return NearDuplicateDocuments(strings.Split(text, "\n"), threshold)
+}
+
+func NearDuplicateDocuments(docs []string, threshold float64) ([]NearDuplicateCluster, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("%w: threshold must be in (0, 1], got %v", ErrInvalidOption, threshold)
+	}
+
+	signatures := make([][]uint64, len(docs))
+	for i, doc := range docs {
+		signatures[i] = minHash(shingles(doc))
+	}
+
+	parent := make([]int, len(docs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for band := 0; band < minHashBands; band++ {
+		buckets := make(map[uint64][]int)
+		for i, sig := range signatures {
+			if sig == nil {
+				continue
+			}
+			key := bandKey(band, sig[band*minHashRows:(band+1)*minHashRows])
+			buckets[key] = append(buckets[key], i)
+		}
+		for _, members := range buckets {
+			for a := 1; a < len(members); a++ {
+				for b := a - 1; b >= 0 && a-b <= maxBucketCompare; b-- {
+					x, y := members[a], members[b]
+					if find(x) == find(y) {
+						break
+					}
+					if similarity(signatures[x], signatures[y]) >= threshold {
+						parent[find(x)] = find(y)
+						break
+					}
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	var roots []int
+	for i := range docs {
+		if signatures[i] == nil {
+			continue
+		}
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	clusters := []NearDuplicateCluster{}
+	for _, root := range roots {
+		members := groups[root]
+		if len(members) < 2 {
+			continue
+		}
+		sort.Ints(members)
+		cluster := NearDuplicateCluster{Similarity: 1}
+		for _, i := range members {
+			cluster.Items = append(cluster.Items, NearDuplicateItem{Index: i, Text: docs[i]})
+			if s := similarity(signatures[members[0]], signatures[i]); s < cluster.Similarity {
+				cluster.Similarity = s
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].Items[0].Index < clusters[j].Items[0].Index
+	})
+
+	return clusters, nil
+}
+
+func shingles(text string) []uint64 {
+	words := strings.Fields(strings.ToLower(text))
+	var grams []string
+	if len(words) >= 3 {
+		for i := 0; i+3 <= len(words); i++ {
+			grams = append(grams, strings.Join(words[i:i+3], " "))
+		}
+	} else {
+		runes := []rune(strings.Join(words, " "))
+		if len(runes) > 0 && len(runes) < 4 {
+			grams = append(grams, string(runes))
+		}
+		for i := 0; i+4 <= len(runes); i++ {
+			grams = append(grams, string(runes[i:i+4]))
+		}
+	}
+
+	hashes := make([]uint64, len(grams))
+	for i, g := range grams {
+		h := fnv.New64a()
+		h.Write([]byte(g))
+		hashes[i] = h.Sum64()
+	}
+	return hashes
+}
+
+func mix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+func minHash(hashes []uint64) []uint64 {
+	if len(hashes) == 0 {
+		return nil
+	}
+	sig := make([]uint64, minHashSize)
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for _, h := range hashes {
+		for i := range sig {
+			if v := mix64(h ^ uint64(i)*0x2545f4914f6cdd1d); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+func bandKey(band int, rows []uint64) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(band))
+	h.Write(buf[:])
+	for _, r := range rows {
+		binary.LittleEndian.PutUint64(buf[:], r)
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+func similarity(a, b []uint64) float64 {
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
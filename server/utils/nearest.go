@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultNearestLines = 10
+	MaxNearestLines     = 1000
+)
+
+type NearestOptions struct {
+	// Limit is how many lines to return, 10 by default
+	Limit int `json:"limit" min:"0"`
+	// MaxDistance drops lines further than this many edits; 0 keeps all
+	MaxDistance   int  `json:"maxDistance" min:"0"`
+	CaseSensitive bool `json:"caseSensitive"`
+}
+
+type NearestMatch struct {
+	// Line is the line number, from 1
+	Line     int    `json:"line"`
+	Text     string `json:"text"`
+	Distance int    `json:"distance"`
+	// Score is 1 minus the distance over the longer of line and query, so 1
+	// is an exact match
+	Score float64 `json:"score"`
+}
+
+// NearestLines returns the n lines closest to query by Levenshtein distance
+func NearestLines(text, query string, n int) ([]NearestMatch, error) {
+	return NearestLinesWithOptions(text, query, NearestOptions{Limit: n})
+}
+
+// NearestLinesWithOptions ranks the non-blank lines by edit distance to the
+// query, closest first and in text order among equals. Runs of whitespace
+// count as one space and case is ignored unless asked for, as messy names
+// differ in both. Identical lines are measured once, and lines are visited
+// in sorted order so each reuses the rows of the prefix it shares with the
+// one before; a line whose length alone puts it further than the current
+// worst match, or whose prefix already does, is not measured at all.
+func NearestLinesWithOptions(text, query string, opts NearestOptions) ([]NearestMatch, error) {
+	if opts.Limit == 0 {
+		opts.Limit = DefaultNearestLines
+	}
+	if opts.Limit < 0 || opts.Limit > MaxNearestLines {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d, got %d", ErrInvalidOption, MaxNearestLines, opts.Limit)
+	}
+	if opts.MaxDistance < 0 {
+		return nil, fmt.Errorf("%w: maxDistance must be positive, got %d", ErrInvalidOption, opts.MaxDistance)
+	}
+	normalize := func(s string) string {
+		s = strings.Join(strings.Fields(s), " ")
+		if !opts.CaseSensitive {
+			s = strings.ToLower(s)
+		}
+		return s
+	}
+
+	// line numbers by normalized line, in text order
+	groups := map[string][]int{}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+		if key := normalize(lines[i]); key != "" {
+			groups[key] = append(groups[key], i)
+		}
+	}
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	q := []rune(normalize(query))
+	best := nearestTop{limit: opts.Limit, bound: -1}
+	if opts.MaxDistance > 0 {
+		best.bound = opts.MaxDistance
+	}
+
+	// rows[i] is the DP row after the first i runes of prev
+	rows := [][]int{make([]int, len(q)+1)}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	var prev []rune
+	dead := -1 // prefix length of prev past which every extension is out of bound
+	for _, key := range keys {
+		r := []rune(key)
+		if best.bound >= 0 && abs(len(r)-len(q)) > best.bound {
+			continue
+		}
+		shared := commonPrefix(prev, r, len(rows)-1)
+		if dead >= 0 && shared >= dead {
+			continue
+		}
+		rows, prev, dead = rows[:shared+1], r, -1
+
+		d := -1
+		for i := shared; i < len(r); i++ {
+			row := editRow(rows[i], q, r[i])
+			rows = append(rows, row)
+			if best.bound >= 0 && minInt(row) > best.bound {
+				dead = i + 1
+				break
+			}
+		}
+		if dead < 0 {
+			d = rows[len(r)][len(q)]
+		}
+		if d < 0 || best.bound >= 0 && d > best.bound {
+			continue
+		}
+		longer := max(len(r), len(q))
+		score := 1.0
+		if longer > 0 {
+			score = 1 - float64(d)/float64(longer)
+		}
+		for _, i := range groups[key] {
+			best.add(NearestMatch{Line: i + 1, Text: lines[i], Distance: d, Score: score})
+		}
+	}
+	return best.sorted(), nil
+}
+
+// editRow extends a Levenshtein row by one rune of the line
+func editRow(prev []int, q []rune, c rune) []int {
+	row := make([]int, len(prev))
+	row[0] = prev[0] + 1
+	for j := 1; j < len(row); j++ {
+		cost := 1
+		if q[j-1] == c {
+			cost = 0
+		}
+		row[j] = min(prev[j]+1, row[j-1]+1, prev[j-1]+cost)
+	}
+	return row
+}
+
+func commonPrefix(a, b []rune, limit int) int {
+	n := 0
+	for n < len(a) && n < len(b) && n < limit && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func minInt(values []int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		m = min(m, v)
+	}
+	return m
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// nearestTop keeps the closest matches seen so far; once full, bound is the
+// distance a line has to beat or tie to get in
+type nearestTop struct {
+	limit   int
+	bound   int
+	matches []NearestMatch
+}
+
+func (t *nearestTop) add(m NearestMatch) {
+	at := sort.Search(len(t.matches), func(i int) bool { return nearestLess(m, t.matches[i]) })
+	if at >= t.limit {
+		return
+	}
+	t.matches = append(t.matches, NearestMatch{})
+	copy(t.matches[at+1:], t.matches[at:])
+	t.matches[at] = m
+	if len(t.matches) > t.limit {
+		t.matches = t.matches[:t.limit]
+	}
+	if len(t.matches) == t.limit {
+		worst := t.matches[len(t.matches)-1].Distance
+		if t.bound < 0 || worst < t.bound {
+			t.bound = worst
+		}
+	}
+}
+
+func nearestLess(a, b NearestMatch) bool {
+	if a.Distance != b.Distance {
+		return a.Distance < b.Distance
+	}
+	return a.Line < b.Line
+}
+
+func (t *nearestTop) sorted() []NearestMatch {
+	if t.matches == nil {
+		return []NearestMatch{}
+	}
+	return t.matches
+}
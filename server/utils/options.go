@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+type CaseType string
+
+const (
+	CaseCamel    CaseType = "camelCase"
+	CasePascal   CaseType = "PascalCase"
+	CaseSnake    CaseType = "snake_case"
+	CaseKebab    CaseType = "kebab-case"
+	CaseConstant CaseType = "CONSTANT_CASE"
+)
+
+var CaseTypes = []CaseType{CaseCamel, CasePascal, CaseSnake, CaseKebab, CaseConstant}
+
+func (CaseType) Values() []string {
+	return EnumValues(CaseTypes)
+}
+
+func (t CaseType) Valid() bool {
+	return EnumValid(CaseTypes, t)
+}
+
+type CompareMode string
+
+const (
+	CompareLexical CompareMode = "lexical"
+	CompareNumeric CompareMode = "numeric"
+	CompareNatural CompareMode = "natural"
+	CompareLength  CompareMode = "length"
+)
+
+var CompareModes = []CompareMode{CompareLexical, CompareNumeric, CompareNatural, CompareLength}
+
+func (CompareMode) Values() []string {
+	return EnumValues(CompareModes)
+}
+
+func (m CompareMode) Valid() bool {
+	return m == "" || EnumValid(CompareModes, m)
+}
+
+func (o SortOptions) Validate() error {
+	if !o.Compare.Valid() {
+		return fmt.Errorf("%w: unknown compare mode %q", ErrInvalidOption, o.Compare)
+	}
+	if err := o.Equivalence.Validate(); err != nil {
+		return err
+	}
+	if o.Field < 0 {
+		return fmt.Errorf("%w: field must be positive, got %d", ErrInvalidOption, o.Field)
+	}
+	if o.Delimiter != "" && o.Field == 0 {
+		return fmt.Errorf("%w: delimiter requires a field", ErrInvalidOption)
+	}
+	return nil
+}
+
+type CaseOptions struct {
+	Type CaseType `json:"caseType" binding:"required"`
+	// Acronyms keep their listed spelling in camelCase and PascalCase, e.g. "ID" gives userID
+	Acronyms []string `json:"acronyms"`
+}
+
+func (o CaseOptions) Validate() error {
+	if !o.Type.Valid() {
+		return fmt.Errorf("%w %q", ErrUnknownCaseType, o.Type)
+	}
+	return nil
+}
+
+// acronymForms maps each acronym's lower-case form to its listed spelling;
+// ConvertCase and NormalizeShouting share it as their acronym dictionary
+func acronymForms(list []string) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	forms := make(map[string]string, len(list))
+	for _, a := range list {
+		if a = strings.TrimSpace(a); a != "" {
+			forms[strings.ToLower(a)] = a
+		}
+	}
+	return forms
+}
+
+func ConvertCaseWithOptions(text string, opts CaseOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+	acronyms := acronymForms(opts.Acronyms)
+	switch opts.Type {
+	case CaseCamel:
+		return toCamelCase(text, acronyms), nil
+	case CasePascal:
+		return toPascalCase(text, acronyms), nil
+	case CaseSnake:
+		return toSnakeCase(text), nil
+	case CaseKebab:
+		return toKebabCase(text), nil
+	default:
+		return toConstantCase(text), nil
+	}
+}
+
+type CountOptions struct {
+	KeepWhitespace bool   `json:"keepWhitespace"`
+	CountRunes     bool   `json:"countRunes"`
+	Model          string `json:"model"`
+	PerLine        bool   `json:"perLine"`
+
+	SplitHyphenated   bool `json:"splitHyphenated"`
+	SplitContractions bool `json:"splitContractions"`
+	SplitURLs         bool `json:"splitUrls"`
+	ExcludeNumbers    bool `json:"excludeNumbers"`
+	ExcludeCode       bool `json:"excludeCode"`
+	StripMarkdown     bool `json:"stripMarkdown"`
+}
+
+func (o CountOptions) Validate() error {
+	if _, ok := lookupTokenizer(o.Model); !ok {
+		return fmt.Errorf("%w: unknown tokenizer model %q", ErrInvalidOption, o.Model)
+	}
+	return nil
+}
+
+type CountResult struct {
+	Words              int `json:"words"`
+	Characters         int `json:"characters"`
+	CharactersNoSpaces int `json:"charactersNoSpaces"`
+	Lines              int `json:"lines"`
+	Paragraphs         int `json:"paragraphs"`
+	Tokens             int `json:"tokens"`
+
+	LineStats *LineStats `json:"lineStats,omitempty"`
+}
+
+type LineStat struct {
+	Line   int `json:"line"`
+	Length int `json:"length"`
+	Words  int `json:"words"`
+	Bytes  int `json:"bytes"`
+}
+
+type LineStats struct {
+	Lines             []LineStat `json:"lines"`
+	LongestLine       int        `json:"longestLine"`
+	LongestLength     int        `json:"longestLength"`
+	AverageLength     float64    `json:"averageLength"`
+	StandardDeviation float64    `json:"standardDeviation"`
+}
+
+func lineStatistics(text string, opts CountOptions) *LineStats {
+	stats := &LineStats{Lines: []LineStat{}}
+	total := 0
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		stat := LineStat{Line: i + 1, Length: GraphemeCount(line), Words: opts.countFields(line), Bytes: len(line)}
+		if stat.Length > stats.LongestLength || stats.LongestLine == 0 {
+			stats.LongestLine, stats.LongestLength = stat.Line, stat.Length
+		}
+		total += stat.Length
+		stats.Lines = append(stats.Lines, stat)
+	}
+
+	mean := float64(total) / float64(len(stats.Lines))
+	variance := 0.0
+	for _, stat := range stats.Lines {
+		d := float64(stat.Length) - mean
+		variance += d * d
+	}
+	stats.AverageLength = round2(mean)
+	stats.StandardDeviation = round2(math.Sqrt(variance / float64(len(stats.Lines))))
+	return stats
+}
+
+func (r CountResult) Map() map[string]int {
+	return map[string]int{
+		"words":              r.Words,
+		"characters":         r.Characters,
+		"charactersNoSpaces": r.CharactersNoSpaces,
+		"lines":              r.Lines,
+		"paragraphs":         r.Paragraphs,
+		"tokens":             r.Tokens,
+	}
+}
+
+func WordCountWithOptions(text string, opts CountOptions) CountResult {
+	if !opts.KeepWhitespace {
+		text = strings.TrimSpace(text)
+	}
+
+	length := func(s string) int { return len(s) }
+	if opts.CountRunes {
+		length = utf8.RuneCountInString
+	}
+
+	paragraphs := 0
+	for _, p := range strings.Split(text, "\n\n") {
+		if strings.TrimSpace(p) != "" {
+			paragraphs++
+		}
+	}
+
+	tokenizer, ok := lookupTokenizer(opts.Model)
+	if !ok {
+		tokenizer, _ = lookupTokenizer(DefaultTokenModel)
+	}
+
+	result := CountResult{
+		Words:              opts.countWords(text),
+		Characters:         length(text),
+		CharactersNoSpaces: length(strings.ReplaceAll(strings.ReplaceAll(text, " ", ""), "\n", "")),
+		Lines:              len(strings.Split(text, "\n")),
+		Paragraphs:         paragraphs,
+		Tokens:             tokenizer.count(text),
+	}
+	if opts.PerLine {
+		result.LineStats = lineStatistics(text, opts)
+	}
+	return result
+}
@@ -0,0 +1,220 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type OutlineFormat string
+
+const (
+	// OutlineAuto reads Markdown when the text has any # heading, plain text
+	// otherwise
+	OutlineAuto     OutlineFormat = "auto"
+	OutlineMarkdown OutlineFormat = "markdown"
+	OutlineText     OutlineFormat = "text"
+)
+
+var OutlineFormats = []OutlineFormat{OutlineAuto, OutlineMarkdown, OutlineText}
+
+func (OutlineFormat) Values() []string {
+	return EnumValues(OutlineFormats)
+}
+
+func (f OutlineFormat) Valid() bool {
+	return EnumValid(OutlineFormats, f)
+}
+
+type OutlineHeading struct {
+	Level int    `json:"level"`
+	Title string `json:"title"`
+	// Number is the section number of a numbered plain-text heading
+	Number   string            `json:"number,omitempty"`
+	Anchor   string            `json:"anchor"`
+	Line     int               `json:"line"`
+	Children []*OutlineHeading `json:"children"`
+}
+
+type Outline struct {
+	Format   OutlineFormat     `json:"format"`
+	Headings []*OutlineHeading `json:"headings"`
+	// TableOfContents is a Markdown list linking to every heading's anchor
+	TableOfContents string `json:"tableOfContents"`
+}
+
+var (
+	setextUnderline = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	textUnderline   = regexp.MustCompile(`^\s*(=+|-+|~+|\^+)\s*$`)
+	numberedHeading = regexp.MustCompile(`^\s*((?:\d{1,3}\.)*\d{1,3})\.?[ \t]+(\S.*)$`)
+)
+
+const maxTextHeadingLen = 80
+
+// plain-text underline characters, in the order RST and most READMEs
+// nest them
+var underlineLevels = map[byte]int{'=': 1, '-': 2, '~': 3, '^': 4}
+
+// ExtractOutline builds the heading hierarchy of a document. Markdown
+// headings are # and underlined (setext) ones, outside fenced code; plain
+// text headings are underlined lines and numbered ones like "2.1 Scope",
+// nested by how many parts the number has. Anchors are slugs, numbered
+// -1, -2... when a title repeats, as GitHub does.
+func ExtractOutline(text string, format OutlineFormat) (Outline, error) {
+	if format == "" {
+		format = OutlineAuto
+	}
+	if !format.Valid() {
+		return Outline{}, fmt.Errorf("%w: unknown outline format %q", ErrInvalidOption, format)
+	}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var flat []*OutlineHeading
+	if format != OutlineText {
+		var atx bool
+		flat, atx = markdownHeadings(lines)
+		if format == OutlineAuto && !atx {
+			format = OutlineText
+		} else {
+			format = OutlineMarkdown
+		}
+	}
+	if format == OutlineText {
+		flat = textHeadings(lines)
+	}
+
+	outline := Outline{Format: format, Headings: []*OutlineHeading{}}
+	used := make(map[string]int)
+	var stack []*OutlineHeading
+	var toc strings.Builder
+	for _, h := range flat {
+		h.Children = []*OutlineHeading{}
+		h.Anchor = uniqueAnchor(h.Title, used)
+		for len(stack) > 0 && stack[len(stack)-1].Level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			outline.Headings = append(outline.Headings, h)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, h)
+		}
+		// the contents nest by depth in the tree, so skipped levels do not
+		// indent twice
+		toc.WriteString(strings.Repeat("  ", len(stack)) + "- [" + mdEscape(h.Title) + "](#" + h.Anchor + ")\n")
+		stack = append(stack, h)
+	}
+	outline.TableOfContents = strings.TrimSuffix(toc.String(), "\n")
+	return outline, nil
+}
+
+// markdownHeadings also reports whether any heading was a # one, which is
+// what auto detection goes by: underlines are just as common in plain text
+func markdownHeadings(lines []string) ([]*OutlineHeading, bool) {
+	var headings []*OutlineHeading
+	atx := false
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if isFence(trimmed) {
+			fence = trimmed[:3]
+			continue
+		}
+		if m := mdHeadingPattern.FindStringSubmatch(line); m != nil {
+			if title := markdownTitle(m[2]); title != "" {
+				headings = append(headings, &OutlineHeading{Level: len(m[1]), Title: title, Line: i + 1})
+				atx = true
+			}
+			continue
+		}
+		if i > 0 && setextUnderline.MatchString(line) {
+			prev := strings.TrimSpace(lines[i-1])
+			if prev == "" || mdStartsBlock(lines[i-1]) || i > 1 && strings.TrimSpace(lines[i-2]) != "" {
+				continue
+			}
+			level := 1
+			if trimmed[0] == '-' {
+				level = 2
+			}
+			headings = append(headings, &OutlineHeading{Level: level, Title: markdownTitle(prev), Line: i})
+		}
+	}
+	return headings, atx
+}
+
+func markdownTitle(s string) string {
+	h := element("h1")
+	mdParseInline(h, s)
+	return strings.Join(strings.Fields(textContent(h)), " ")
+}
+
+func textHeadings(lines []string) []*OutlineHeading {
+	var headings []*OutlineHeading
+	blank := func(i int) bool { return i < 0 || i >= len(lines) || strings.TrimSpace(lines[i]) == "" }
+	for i := 0; i < len(lines); i++ {
+		title := strings.Join(strings.Fields(lines[i]), " ")
+		if title == "" || utf8.RuneCountInString(title) > maxTextHeadingLen {
+			continue
+		}
+		// an underline at least half as long as the title, so that a lone
+		// rule under a paragraph is not taken for one
+		if i+1 < len(lines) && blank(i-1) && !textUnderline.MatchString(lines[i]) {
+			if m := textUnderline.FindStringSubmatch(lines[i+1]); m != nil && 2*len(m[1]) >= utf8.RuneCountInString(title) {
+				h := &OutlineHeading{Level: underlineLevels[m[1][0]], Title: title, Line: i + 1}
+				if n := numberedHeading.FindStringSubmatch(title); n != nil {
+					h.Number, h.Title = n[1], n[2]
+				}
+				headings = append(headings, h)
+				i++
+				continue
+			}
+		}
+		m := numberedHeading.FindStringSubmatch(lines[i])
+		if m == nil || !blank(i-1) || !looksLikeHeading(m[2]) {
+			continue
+		}
+		// consecutive numbered lines are a list, not a run of headings
+		if !blank(i+1) && numberedHeading.MatchString(lines[i+1]) {
+			for i+1 < len(lines) && !blank(i+1) {
+				i++
+			}
+			continue
+		}
+		headings = append(headings, &OutlineHeading{
+			Level:  strings.Count(m[1], ".") + 1,
+			Title:  strings.Join(strings.Fields(m[2]), " "),
+			Number: m[1],
+			Line:   i + 1,
+		})
+	}
+	return headings
+}
+
+// looksLikeHeading rules out numbered sentences: a heading starts with a
+// capital or digit and does not end like a sentence or a list lead-in
+func looksLikeHeading(title string) bool {
+	first, _ := utf8.DecodeRuneInString(title)
+	last, _ := utf8.DecodeLastRuneInString(strings.TrimSpace(title))
+	return (unicode.IsUpper(first) || unicode.IsDigit(first)) && !strings.ContainsRune(".,;:!?", last)
+}
+
+func uniqueAnchor(title string, used map[string]int) string {
+	anchor := Slugify(title)
+	if anchor == "" {
+		anchor = "section"
+	}
+	n := used[anchor]
+	used[anchor] = n + 1
+	if n == 0 {
+		return anchor
+	}
+	return anchor + "-" + strconv.Itoa(n)
+}
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultChunkSize  = 256 << 10
+	ParallelThreshold = 1 << 20
+)
+
+type ParallelOptions struct {
+	Workers   int `json:"workers"`
+	ChunkSize int `json:"chunkSize"`
+}
+
+type lineChunk struct {
+	lines []string
+	first int
+}
+
+func runChunks(text string, opts ParallelOptions, fn func(lines []string, first int) []string) string {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers == 1 || len(text) < ParallelThreshold {
+		return strings.Join(fn(strings.Split(text, "\n"), 0), "\n")
+	}
+
+	size := opts.ChunkSize
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+
+	chunks := splitChunks(text, size)
+	results := make([][]string, len(chunks))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(chunks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = fn(chunks[i].lines, chunks[i].first)
+			}
+		}()
+	}
+	for i := range chunks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var b strings.Builder
+	b.Grow(len(text))
+	wrote := false
+	for _, lines := range results {
+		for _, line := range lines {
+			if wrote {
+				b.WriteByte('\n')
+			}
+			b.WriteString(line)
+			wrote = true
+		}
+	}
+	return b.String()
+}
+
+func splitChunks(text string, size int) []lineChunk {
+	var chunks []lineChunk
+	first := 0
+	for {
+		if len(text) <= size {
+			chunks = append(chunks, lineChunk{lines: strings.Split(text, "\n"), first: first})
+			return chunks
+		}
+		cut := strings.IndexByte(text[size:], '\n')
+		if cut < 0 {
+			chunks = append(chunks, lineChunk{lines: strings.Split(text, "\n"), first: first})
+			return chunks
+		}
+		cut += size
+		lines := strings.Split(text[:cut], "\n")
+		chunks = append(chunks, lineChunk{lines: lines, first: first})
+		first += len(lines)
+		text = text[cut+1:]
+	}
+}
+
+func MapLines(text string, fn func(string) string, opts ParallelOptions) string {
+	return runChunks(text, opts, func(lines []string, _ int) []string {
+		out := make([]string, len(lines))
+		for i, line := range lines {
+			out[i] = fn(line)
+		}
+		return out
+	})
+}
+
+func FilterLines(text string, keep func(string) bool, opts ParallelOptions) string {
+	return runChunks(text, opts, func(lines []string, _ int) []string {
+		out := lines[:0:0]
+		for _, line := range lines {
+			if keep(line) {
+				out = append(out, line)
+			}
+		}
+		return out
+	})
+}
+
+func TrimLines(text string, opts ParallelOptions) string {
+	return MapLines(text, strings.TrimSpace, opts)
+}
+
+func ConvertCaseLines(text, caseType string, opts ParallelOptions) string {
+	return MapLines(text, func(line string) string {
+		return ConvertCase(line, caseType)
+	}, opts)
+}
+
+// NumberLines expands format for every line, "{n}. {line}" by default, with
+// {n} the line number and {line} the line. A format without {line} is a
+// prefix the line follows.
+func NumberLines(text string, start int, format string, opts ParallelOptions) string {
+	if format == "" {
+		format = "{n}. {line}"
+	}
+	if !strings.Contains(format, "{line}") {
+		format += "{line}"
+	}
+	return runChunks(text, opts, func(lines []string, first int) []string {
+		out := make([]string, len(lines))
+		for i, line := range lines {
+			out[i] = strings.NewReplacer("{n}", strconv.Itoa(start+first+i), "{line}", line).Replace(format)
+		}
+		return out
+	})
+}
@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type PasteSource string
+
+const (
+	PasteGeneric PasteSource = "generic"
+	PasteWord    PasteSource = "word"
+	PastePDF     PasteSource = "pdf"
+	PasteWeb     PasteSource = "web"
+)
+
+var PasteSources = []PasteSource{PasteGeneric, PasteWord, PastePDF, PasteWeb}
+
+func (PasteSource) Values() []string {
+	return EnumValues(PasteSources)
+}
+
+func (s PasteSource) Valid() bool {
+	return EnumValid(PasteSources, s)
+}
+
+// the individual fixes CleanPaste can apply
+const (
+	PasteFixLigatures   = "ligatures"
+	PasteFixNBSP        = "nonBreakingSpaces"
+	PasteFixSoftHyphens = "softHyphens"
+	PasteFixZeroWidth   = "zeroWidth"
+	PasteFixLineBreaks  = "lineBreaks"
+	PasteFixWhitespace  = "whitespace"
+	PasteFixQuotes      = "quotes"
+	// for plain-ASCII targets; no source enables it by default
+	PasteFixEmoji = "emojiShortcodes"
+)
+
+var PasteFixes = []string{PasteFixLigatures, PasteFixNBSP, PasteFixSoftHyphens, PasteFixZeroWidth, PasteFixLineBreaks, PasteFixWhitespace, PasteFixQuotes, PasteFixEmoji}
+
+// what each source usually gets wrong: PDFs hard-wrap lines, Word and web
+// pages curl quotes, and only plain pastes keep their quotes as typed
+var pasteSourceFixes = map[PasteSource][]string{
+	PasteGeneric: {PasteFixLigatures, PasteFixNBSP, PasteFixSoftHyphens, PasteFixZeroWidth, PasteFixWhitespace},
+	PasteWord:    {PasteFixLigatures, PasteFixNBSP, PasteFixSoftHyphens, PasteFixZeroWidth, PasteFixWhitespace, PasteFixQuotes},
+	PastePDF:     {PasteFixLigatures, PasteFixNBSP, PasteFixSoftHyphens, PasteFixLineBreaks, PasteFixWhitespace, PasteFixQuotes},
+	PasteWeb:     {PasteFixLigatures, PasteFixNBSP, PasteFixSoftHyphens, PasteFixZeroWidth, PasteFixWhitespace, PasteFixQuotes},
+}
+
+type PasteOptions struct {
+	Source PasteSource `json:"source"`
+	// Fixes replaces the source's default set when given
+	Fixes []string `json:"fixes"`
+	// Quotes is the style the quotes fix converts to
+	Quotes QuoteStyle `json:"quotes"`
+}
+
+var ligatures = charReplacer(ligatureTable)
+
+var pasteSpaces = strings.NewReplacer("\u00a0", " ", "\u202f", " ", "\u2007", " ")
+
+// only characters that never carry meaning in pasted prose; joiners are kept
+// because emoji sequences and some scripts depend on them
+var zeroWidth = strings.NewReplacer("\u200b", "", "\u2060", "", "\ufeff", "")
+
+var (
+	repeatedSpaces = regexp.MustCompile(`([^ \t\n])[ \t]{2,}`)
+	extraBlankRuns = regexp.MustCompile(`\n{3,}`)
+)
+
+func CleanPaste(text, source string) (string, error) {
+	return CleanPasteWithOptions(text, PasteOptions{Source: PasteSource(source)})
+}
+
+func CleanPasteWithOptions(text string, opts PasteOptions) (string, error) {
+	if opts.Source == "" {
+		opts.Source = PasteGeneric
+	}
+	if !opts.Source.Valid() {
+		return "", fmt.Errorf("%w: unknown paste source %q", ErrInvalidOption, opts.Source)
+	}
+	if opts.Quotes == "" {
+		opts.Quotes = QuoteStraight
+	}
+	fixes := opts.Fixes
+	if fixes == nil {
+		fixes = pasteSourceFixes[opts.Source]
+	}
+	enabled := make(map[string]bool, len(fixes))
+	for _, fix := range fixes {
+		if !containsString(PasteFixes, fix) {
+			return "", fmt.Errorf("%w: unknown paste fix %q", ErrInvalidOption, fix)
+		}
+		enabled[fix] = true
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if enabled[PasteFixLigatures] {
+		text = ligatures.Replace(text)
+	}
+	if enabled[PasteFixNBSP] {
+		text = pasteSpaces.Replace(text)
+	}
+	if enabled[PasteFixSoftHyphens] {
+		// a soft hyphen at a line end is where a word was broken
+		text = strings.ReplaceAll(strings.ReplaceAll(text, "\u00ad\n", ""), "\u00ad", "")
+	}
+	if enabled[PasteFixLineBreaks] {
+		text = joinBrokenLines(text)
+	}
+	if enabled[PasteFixZeroWidth] {
+		text = zeroWidth.Replace(text)
+	}
+	if enabled[PasteFixQuotes] {
+		var err error
+		if text, err = ConvertQuotes(text, opts.Quotes); err != nil {
+			return "", err
+		}
+	}
+	if enabled[PasteFixEmoji] {
+		text = EmojiToShortcode(text)
+	}
+	if enabled[PasteFixWhitespace] {
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			// indentation is kept, runs inside the line become one space
+			lines[i] = repeatedSpaces.ReplaceAllString(strings.TrimRightFunc(line, unicode.IsSpace), "$1 ")
+		}
+		text = extraBlankRuns.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	}
+	return text, nil
+}
+
+// joinBrokenLines undoes line breaks in the middle of a sentence: a line that
+// does not end one, followed by a line that carries on in lower case. Unlike
+// UnwrapText it leaves deliberate breaks between sentences alone.
+func joinBrokenLines(text string) string {
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	fenced := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+		}
+		if !fenced && len(out) > 0 && carriesOn(out[len(out)-1], trimmed) {
+			out[len(out)-1] = joinWrapped(out[len(out)-1], trimmed)
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+func carriesOn(prev, next string) bool {
+	prev = strings.TrimSpace(prev)
+	if prev == "" || next == "" || strings.HasPrefix(prev, "```") || strings.HasPrefix(prev, "~~~") || listItemPattern.MatchString(next) {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(prev)
+	first, _ := utf8.DecodeRuneInString(next)
+	return !strings.ContainsRune(".!?:;\"”’)]", last) && (unicode.IsLower(first) || unicode.IsDigit(first))
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
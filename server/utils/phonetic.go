@@ -0,0 +1,645 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+func Soundex(word string) string {
+	codes := map[rune]byte{
+		'B': '1', 'F': '1', 'P': '1', 'V': '1',
+		'C': '2', 'G': '2', 'J': '2', 'K': '2', 'Q': '2', 'S': '2', 'X': '2', 'Z': '2',
+		'D': '3', 'T': '3',
+		'L': '4',
+		'M': '5', 'N': '5',
+		'R': '6',
+	}
+
+	var out []byte
+	var last byte
+	for _, r := range strings.ToUpper(word) {
+		if r < 'A' || r > 'Z' {
+			continue
+		}
+		code := codes[r]
+		if len(out) == 0 {
+			out = append(out, byte(r))
+			last = code
+			continue
+		}
+		switch {
+		case r == 'H' || r == 'W':
+			continue
+		case code == 0:
+			last = 0
+		case code != last:
+			out = append(out, code)
+			last = code
+		}
+		if len(out) == 4 {
+			break
+		}
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	for len(out) < 4 {
+		out = append(out, '0')
+	}
+	return string(out[:4])
+}
+
+const metaphoneLength = 4
+
+type metaphone struct {
+	value              []rune
+	primary, secondary strings.Builder
+	slavoGermanic      bool
+}
+
+func DoubleMetaphone(word string) (string, string) {
+	var runes []rune
+	for _, r := range strings.ToUpper(strings.TrimSpace(word)) {
+		if unicode.IsLetter(r) || r == ' ' {
+			runes = append(runes, r)
+		}
+	}
+	if len(runes) == 0 {
+		return "", ""
+	}
+
+	m := &metaphone{value: runes}
+	upper := string(runes)
+	m.slavoGermanic = strings.ContainsAny(upper, "WK") || strings.Contains(upper, "CZ") || strings.Contains(upper, "WITZ")
+
+	index := 0
+	if m.at(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		index = 1
+	}
+
+	for index < len(m.value) && (m.primary.Len() < metaphoneLength || m.secondary.Len() < metaphoneLength) {
+		switch c := m.value[index]; c {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if index == 0 {
+				m.add("A")
+			}
+			index++
+		case 'B':
+			m.add("P")
+			index = m.skip(index, 'B')
+		case 'Ç':
+			m.add("S")
+			index++
+		case 'C':
+			index = m.handleC(index)
+		case 'D':
+			index = m.handleD(index)
+		case 'F':
+			m.add("F")
+			index = m.skip(index, 'F')
+		case 'G':
+			index = m.handleG(index)
+		case 'H':
+			index = m.handleH(index)
+		case 'J':
+			index = m.handleJ(index)
+		case 'K':
+			m.add("K")
+			index = m.skip(index, 'K')
+		case 'L':
+			index = m.handleL(index)
+		case 'M':
+			m.add("M")
+			if m.char(index+1) == 'M' || (m.at(index-1, 3, "UMB") && (index+1 == len(m.value)-1 || m.at(index+2, 2, "ER"))) {
+				index += 2
+			} else {
+				index++
+			}
+		case 'N':
+			m.add("N")
+			index = m.skip(index, 'N')
+		case 'Ñ':
+			m.add("N")
+			index++
+		case 'P':
+			if m.char(index+1) == 'H' {
+				m.add("F")
+				index += 2
+			} else {
+				m.add("P")
+				if m.at(index+1, 1, "P", "B") {
+					index += 2
+				} else {
+					index++
+				}
+			}
+		case 'Q':
+			m.add("K")
+			index = m.skip(index, 'Q')
+		case 'R':
+			if index == len(m.value)-1 && !m.slavoGermanic && m.at(index-2, 2, "IE") && !m.at(index-4, 2, "ME", "MA") {
+				m.alt("", "R")
+			} else {
+				m.add("R")
+			}
+			index = m.skip(index, 'R')
+		case 'S':
+			index = m.handleS(index)
+		case 'T':
+			index = m.handleT(index)
+		case 'V':
+			m.add("F")
+			index = m.skip(index, 'V')
+		case 'W':
+			index = m.handleW(index)
+		case 'X':
+			index = m.handleX(index)
+		case 'Z':
+			index = m.handleZ(index)
+		default:
+			index++
+		}
+	}
+
+	return truncate(m.primary.String()), truncate(m.secondary.String())
+}
+
+func truncate(code string) string {
+	if len(code) > metaphoneLength {
+		return code[:metaphoneLength]
+	}
+	return code
+}
+
+func (m *metaphone) add(code string) {
+	m.alt(code, code)
+}
+
+func (m *metaphone) alt(primary, secondary string) {
+	m.primary.WriteString(primary)
+	m.secondary.WriteString(secondary)
+}
+
+func (m *metaphone) char(i int) rune {
+	if i < 0 || i >= len(m.value) {
+		return 0
+	}
+	return m.value[i]
+}
+
+func (m *metaphone) at(start, length int, options ...string) bool {
+	if start < 0 || start+length > len(m.value) {
+		return false
+	}
+	s := string(m.value[start : start+length])
+	for _, o := range options {
+		if s == o {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *metaphone) skip(index int, double rune) int {
+	if m.char(index+1) == double {
+		return index + 2
+	}
+	return index + 1
+}
+
+func isMetaphoneVowel(r rune) bool {
+	return strings.ContainsRune("AEIOUY", r)
+}
+
+func (m *metaphone) germanic() bool {
+	return m.at(0, 4, "VAN ", "VON ") || m.at(0, 3, "SCH")
+}
+
+func (m *metaphone) handleC(index int) int {
+	switch {
+	case m.conditionC0(index):
+		m.add("K")
+		return index + 2
+	case index == 0 && m.at(index, 6, "CAESAR"):
+		m.add("S")
+		return index + 2
+	case m.at(index, 2, "CH"):
+		return m.handleCH(index)
+	case m.at(index, 2, "CZ") && !m.at(index-2, 4, "WICZ"):
+		m.alt("S", "X")
+		return index + 2
+	case m.at(index+1, 3, "CIA"):
+		m.add("X")
+		return index + 3
+	case m.at(index, 2, "CC") && !(index == 1 && m.char(0) == 'M'):
+		if m.at(index+2, 1, "I", "E", "H") && !m.at(index+2, 2, "HU") {
+			if (index == 1 && m.char(index-1) == 'A') || m.at(index-1, 5, "UCCEE", "UCCES") {
+				m.add("KS")
+			} else {
+				m.add("X")
+			}
+			return index + 3
+		}
+		m.add("K")
+		return index + 2
+	case m.at(index, 2, "CK", "CG", "CQ"):
+		m.add("K")
+		return index + 2
+	case m.at(index, 2, "CI", "CE", "CY"):
+		if m.at(index, 3, "CIO", "CIE", "CIA") {
+			m.alt("S", "X")
+		} else {
+			m.add("S")
+		}
+		return index + 2
+	}
+
+	m.add("K")
+	switch {
+	case m.at(index+1, 2, " C", " Q", " G"):
+		return index + 3
+	case m.at(index+1, 1, "C", "K", "Q") && !m.at(index+1, 2, "CE", "CI"):
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) conditionC0(index int) bool {
+	if m.at(index, 4, "CHIA") {
+		return true
+	}
+	if index <= 1 || isMetaphoneVowel(m.char(index-2)) || !m.at(index-1, 3, "ACH") {
+		return false
+	}
+	c := m.char(index + 2)
+	return (c != 'I' && c != 'E') || m.at(index-2, 6, "BACHER", "MACHER")
+}
+
+func (m *metaphone) handleCH(index int) int {
+	if index > 0 && m.at(index, 4, "CHAE") {
+		m.alt("K", "X")
+		return index + 2
+	}
+	if m.conditionCH0(index) || m.conditionCH1(index) {
+		m.add("K")
+		return index + 2
+	}
+	if index > 0 {
+		if m.at(0, 2, "MC") {
+			m.add("K")
+		} else {
+			m.alt("X", "K")
+		}
+	} else {
+		m.add("X")
+	}
+	return index + 2
+}
+
+func (m *metaphone) conditionCH0(index int) bool {
+	if index != 0 {
+		return false
+	}
+	if !m.at(index+1, 5, "HARAC", "HARIS") && !m.at(index+1, 3, "HOR", "HYM", "HIA", "HEM") {
+		return false
+	}
+	return !m.at(0, 5, "CHORE")
+}
+
+func (m *metaphone) conditionCH1(index int) bool {
+	return m.germanic() ||
+		m.at(index-2, 6, "ORCHES", "ARCHIT", "ORCHID") ||
+		m.at(index+2, 1, "T", "S") ||
+		((m.at(index-1, 1, "A", "O", "U", "E") || index == 0) &&
+			(m.at(index+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") || index+1 == len(m.value)-1))
+}
+
+func (m *metaphone) handleD(index int) int {
+	switch {
+	case m.at(index, 2, "DG"):
+		if m.at(index+2, 1, "I", "E", "Y") {
+			m.add("J")
+			return index + 3
+		}
+		m.add("TK")
+		return index + 2
+	case m.at(index, 2, "DT", "DD"):
+		m.add("T")
+		return index + 2
+	}
+	m.add("T")
+	return index + 1
+}
+
+func (m *metaphone) handleG(index int) int {
+	switch {
+	case m.char(index+1) == 'H':
+		return m.handleGH(index)
+	case m.char(index+1) == 'N':
+		switch {
+		case index == 1 && isMetaphoneVowel(m.char(0)) && !m.slavoGermanic:
+			m.alt("KN", "N")
+		case !m.at(index+2, 2, "EY") && m.char(index+1) != 'Y' && !m.slavoGermanic:
+			m.alt("N", "KN")
+		default:
+			m.add("KN")
+		}
+		return index + 2
+	case m.at(index+1, 2, "LI") && !m.slavoGermanic:
+		m.alt("KL", "L")
+		return index + 2
+	case index == 0 && (m.char(index+1) == 'Y' || m.at(index+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		m.alt("K", "J")
+		return index + 2
+	case (m.at(index+1, 2, "ER") || m.char(index+1) == 'Y') &&
+		!m.at(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!m.at(index-1, 1, "E", "I") &&
+		!m.at(index-1, 3, "RGY", "OGY"):
+		m.alt("K", "J")
+		return index + 2
+	case m.at(index+1, 1, "E", "I", "Y") || m.at(index-1, 4, "AGGI", "OGGI"):
+		switch {
+		case m.germanic() || m.at(index+1, 2, "ET"):
+			m.add("K")
+		case m.at(index+1, 3, "IER"):
+			m.add("J")
+		default:
+			m.alt("J", "K")
+		}
+		return index + 2
+	case m.char(index+1) == 'G':
+		m.add("K")
+		return index + 2
+	}
+	m.add("K")
+	return index + 1
+}
+
+func (m *metaphone) handleGH(index int) int {
+	switch {
+	case index > 0 && !isMetaphoneVowel(m.char(index-1)):
+		m.add("K")
+	case index == 0:
+		if m.char(index+2) == 'I' {
+			m.add("J")
+		} else {
+			m.add("K")
+		}
+	case (index > 1 && m.at(index-2, 1, "B", "H", "D")) ||
+		(index > 2 && m.at(index-3, 1, "B", "H", "D")) ||
+		(index > 3 && m.at(index-4, 1, "B", "H")):
+	default:
+		if index > 2 && m.char(index-1) == 'U' && m.at(index-3, 1, "C", "G", "L", "R", "T") {
+			m.add("F")
+		} else if index > 0 && m.char(index-1) != 'I' {
+			m.add("K")
+		}
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleH(index int) int {
+	if (index == 0 || isMetaphoneVowel(m.char(index-1))) && isMetaphoneVowel(m.char(index+1)) {
+		m.add("H")
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleJ(index int) int {
+	if m.at(index, 4, "JOSE") || m.at(0, 4, "SAN ") {
+		if (index == 0 && m.char(index+4) == ' ') || len(m.value) == 4 || m.at(0, 4, "SAN ") {
+			m.add("H")
+		} else {
+			m.alt("J", "H")
+		}
+		return index + 1
+	}
+
+	switch {
+	case index == 0:
+		m.alt("J", "A")
+	case isMetaphoneVowel(m.char(index-1)) && !m.slavoGermanic && (m.char(index+1) == 'A' || m.char(index+1) == 'O'):
+		m.alt("J", "H")
+	case index == len(m.value)-1:
+		m.alt("J", "")
+	case !m.at(index+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") && !m.at(index-1, 1, "S", "K", "L"):
+		m.add("J")
+	}
+	return m.skip(index, 'J')
+}
+
+func (m *metaphone) handleL(index int) int {
+	if m.char(index+1) != 'L' {
+		m.add("L")
+		return index + 1
+	}
+	n := len(m.value)
+	if (index == n-3 && m.at(index-1, 4, "ILLO", "ILLA", "ALLE")) ||
+		((m.at(n-2, 2, "AS", "OS") || m.at(n-1, 1, "A", "O")) && m.at(index-1, 4, "ALLE")) {
+		m.alt("L", "")
+	} else {
+		m.add("L")
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleS(index int) int {
+	switch {
+	case m.at(index-1, 3, "ISL", "YSL"):
+		return index + 1
+	case index == 0 && m.at(index, 5, "SUGAR"):
+		m.alt("X", "S")
+		return index + 1
+	case m.at(index, 2, "SH"):
+		if m.at(index+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			m.add("S")
+		} else {
+			m.add("X")
+		}
+		return index + 2
+	case m.at(index, 3, "SIO", "SIA") || m.at(index, 4, "SIAN"):
+		if m.slavoGermanic {
+			m.add("S")
+		} else {
+			m.alt("S", "X")
+		}
+		return index + 3
+	case (index == 0 && m.at(index+1, 1, "M", "N", "L", "W")) || m.at(index+1, 1, "Z"):
+		m.alt("S", "X")
+		if m.at(index+1, 1, "Z") {
+			return index + 2
+		}
+		return index + 1
+	case m.at(index, 2, "SC"):
+		return m.handleSC(index)
+	}
+
+	if index == len(m.value)-1 && m.at(index-2, 2, "AI", "OI") {
+		m.alt("", "S")
+	} else {
+		m.add("S")
+	}
+	if m.at(index+1, 1, "S", "Z") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleSC(index int) int {
+	switch {
+	case m.char(index+2) == 'H':
+		switch {
+		case m.at(index+3, 2, "ER", "EN"):
+			m.alt("X", "SK")
+		case m.at(index+3, 2, "OO", "UY", "ED", "EM"):
+			m.add("SK")
+		case index == 0 && !isMetaphoneVowel(m.char(3)) && m.char(3) != 'W':
+			m.alt("X", "S")
+		default:
+			m.add("X")
+		}
+	case m.at(index+2, 1, "I", "E", "Y"):
+		m.add("S")
+	default:
+		m.add("SK")
+	}
+	return index + 3
+}
+
+func (m *metaphone) handleT(index int) int {
+	switch {
+	case m.at(index, 4, "TION"), m.at(index, 3, "TIA", "TCH"):
+		m.add("X")
+		return index + 3
+	case m.at(index, 2, "TH") || m.at(index, 3, "TTH"):
+		if m.at(index+2, 2, "OM", "AM") || m.germanic() {
+			m.add("T")
+		} else {
+			m.alt("0", "T")
+		}
+		return index + 2
+	}
+	m.add("T")
+	if m.at(index+1, 1, "T", "D") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleW(index int) int {
+	if m.at(index, 2, "WR") {
+		m.add("R")
+		return index + 2
+	}
+	switch {
+	case index == 0 && (isMetaphoneVowel(m.char(index+1)) || m.at(index, 2, "WH")):
+		if isMetaphoneVowel(m.char(index + 1)) {
+			m.alt("A", "F")
+		} else {
+			m.add("A")
+		}
+	case (index == len(m.value)-1 && isMetaphoneVowel(m.char(index-1))) ||
+		m.at(index-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") ||
+		m.at(0, 3, "SCH"):
+		m.alt("", "F")
+	case m.at(index, 4, "WICZ", "WITZ"):
+		m.alt("TS", "FX")
+		return index + 4
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleX(index int) int {
+	if index == 0 {
+		m.add("S")
+		return index + 1
+	}
+	if !(index == len(m.value)-1 && (m.at(index-3, 3, "IAU", "EAU") || m.at(index-2, 2, "AU", "OU"))) {
+		m.add("KS")
+	}
+	if m.at(index+1, 1, "C", "X") {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleZ(index int) int {
+	if m.char(index+1) == 'H' {
+		m.add("J")
+		return index + 2
+	}
+	if m.at(index+1, 2, "ZO", "ZI", "ZA") || (m.slavoGermanic && index > 0 && m.char(index-1) != 'T') {
+		m.alt("S", "TS")
+	} else {
+		m.add("S")
+	}
+	return m.skip(index, 'Z')
+}
+
+func phoneticCodes(word string) []string {
+	primary, secondary := DoubleMetaphone(word)
+	if primary == "" {
+		return nil
+	}
+	if secondary == "" || secondary == primary {
+		return []string{primary}
+	}
+	return []string{primary, secondary}
+}
+
+func soundsAlike(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func MatchPhonetic(lines, query string) string {
+	var queryCodes [][]string
+	for _, word := range strings.FieldsFunc(query, notLetter) {
+		if codes := phoneticCodes(word); codes != nil {
+			queryCodes = append(queryCodes, codes)
+		}
+	}
+	if len(queryCodes) == 0 {
+		return ""
+	}
+
+	var matched []string
+	for _, line := range strings.Split(lines, "\n") {
+		var lineCodes [][]string
+		for _, word := range strings.FieldsFunc(line, notLetter) {
+			if codes := phoneticCodes(word); codes != nil {
+				lineCodes = append(lineCodes, codes)
+			}
+		}
+
+		all := true
+		for _, q := range queryCodes {
+			found := false
+			for _, w := range lineCodes {
+				if soundsAlike(q, w) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				all = false
+				break
+			}
+		}
+		if all {
+			matched = append(matched, line)
+		}
+	}
+	return strings.Join(matched, "\n")
+}
+
+func notLetter(r rune) bool {
+	return !unicode.IsLetter(r)
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// with replacement a draw can be far larger than the input
+const MaxPickedLines = 10000
+
+type PickOptions struct {
+	// Delimiter separates a line from its weight, "alice,3" with ",". Lines
+	// without one weigh 1; with no delimiter every line does.
+	Delimiter string `json:"delimiter"`
+	// Replacement lets a line be picked more than once
+	Replacement bool  `json:"replacement"`
+	Seed        int64 `json:"seed"`
+	// KeepWeights leaves the weights on the picked lines
+	KeepWeights bool `json:"keepWeights"`
+}
+
+type weightedLine struct {
+	text   string
+	weight float64
+}
+
+// PickLines draws n lines at random, in the order they were drawn, with a
+// line's chance proportional to its weight. Blank lines and lines weighing
+// 0 are never picked. Without replacement every eligible line is returned
+// when there are fewer than n.
+func PickLines(text string, n int, opts PickOptions) (string, error) {
+	if n <= 0 {
+		return "", fmt.Errorf("%w: pick count must be positive, got %d", ErrInvalidOption, n)
+	}
+	if n > MaxPickedLines {
+		return "", fmt.Errorf("%w: at most %d lines can be picked", ErrInvalidOption, MaxPickedLines)
+	}
+
+	var lines []weightedLine
+	total := 0.0
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		l, err := parseWeightedLine(line, opts)
+		if err != nil {
+			return "", fmt.Errorf("%w: line %d: %v", ErrInvalidOption, i+1, err)
+		}
+		if l.weight > 0 {
+			lines = append(lines, l)
+			total += l.weight
+		}
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	var picked []string
+	if opts.Replacement {
+		cumulative := make([]float64, len(lines))
+		sum := 0.0
+		for i, l := range lines {
+			sum += l.weight
+			cumulative[i] = sum
+		}
+		for len(picked) < n {
+			target := rng.Float64() * total
+			i := sort.Search(len(cumulative), func(i int) bool { return cumulative[i] > target })
+			picked = append(picked, lines[min(i, len(lines)-1)].text)
+		}
+		return strings.Join(picked, "\n"), nil
+	}
+
+	// Efraimidis-Spirakis: every line draws the key u^(1/w) and the highest
+	// keys win, which is a weighted draw without replacement in one pass
+	keys := make([]float64, len(lines))
+	order := make([]int, len(lines))
+	for i, l := range lines {
+		keys[i] = math.Pow(rng.Float64(), 1/l.weight)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return keys[order[a]] > keys[order[b]] })
+	for _, i := range order[:min(n, len(order))] {
+		picked = append(picked, lines[i].text)
+	}
+	return strings.Join(picked, "\n"), nil
+}
+
+func parseWeightedLine(line string, opts PickOptions) (weightedLine, error) {
+	if opts.Delimiter == "" {
+		return weightedLine{text: line, weight: 1}, nil
+	}
+	i := strings.LastIndex(line, opts.Delimiter)
+	if i < 0 {
+		return weightedLine{text: line, weight: 1}, nil
+	}
+	raw := strings.TrimSpace(line[i+len(opts.Delimiter):])
+	weight, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		// a delimiter in the text itself, not a weight
+		return weightedLine{text: line, weight: 1}, nil
+	}
+	if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+		return weightedLine{}, fmt.Errorf("weight %q is not a non-negative number", raw)
+	}
+	if opts.KeepWeights {
+		return weightedLine{text: line, weight: weight}, nil
+	}
+	return weightedLine{text: strings.TrimRight(line[:i], " \t"), weight: weight}, nil
+}
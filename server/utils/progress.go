@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ProgressOptions struct {
+	Target int          `json:"target"`
+	Min    int          `json:"min"`
+	Max    int          `json:"max"`
+	Unit   string       `json:"unit"`
+	Count  CountOptions `json:"count"`
+}
+
+func (o ProgressOptions) Validate() error {
+	if o.Target < 0 || o.Min < 0 || o.Max < 0 {
+		return fmt.Errorf("%w: target, min and max must not be negative", ErrInvalidOption)
+	}
+	if o.Target == 0 && o.Min == 0 && o.Max == 0 {
+		return fmt.Errorf("%w: a target, min or max is required", ErrInvalidOption)
+	}
+	if o.Max > 0 && o.Min > o.Max {
+		return fmt.Errorf("%w: min %d is above max %d", ErrInvalidOption, o.Min, o.Max)
+	}
+	if _, ok := (CountResult{}).Map()[o.unit()]; !ok {
+		return fmt.Errorf("%w: unknown count unit %q, want one of %s", ErrInvalidOption, o.Unit, strings.Join(countUnits(), ", "))
+	}
+	return o.Count.Validate()
+}
+
+func (o ProgressOptions) unit() string {
+	if o.Unit == "" {
+		return "words"
+	}
+	return o.Unit
+}
+
+func countUnits() []string {
+	return []string{"words", "characters", "charactersNoSpaces", "lines", "paragraphs", "tokens"}
+}
+
+type CountProgressResult struct {
+	Unit      string  `json:"unit"`
+	Count     int     `json:"count"`
+	Target    int     `json:"target"`
+	Remaining int     `json:"remaining"`
+	Over      int     `json:"over"`
+	Percent   float64 `json:"percent"`
+	Min       int     `json:"min,omitempty"`
+	Max       int     `json:"max,omitempty"`
+	InRange   bool    `json:"inRange"`
+	Status    string  `json:"status"`
+}
+
+func CountProgress(text string, target int, unit string) (CountProgressResult, error) {
+	return CountProgressWithOptions(text, ProgressOptions{Target: target, Unit: unit})
+}
+
+// without an explicit target, progress is measured against min (or max when only that is set)
+func CountProgressWithOptions(text string, opts ProgressOptions) (CountProgressResult, error) {
+	if err := opts.Validate(); err != nil {
+		return CountProgressResult{}, err
+	}
+	target := opts.Target
+	if target == 0 {
+		target = opts.Min
+	}
+	if target == 0 {
+		target = opts.Max
+	}
+
+	count := WordCountWithOptions(text, opts.Count).Map()[opts.unit()]
+	result := CountProgressResult{
+		Unit:      opts.unit(),
+		Count:     count,
+		Target:    target,
+		Remaining: max(target-count, 0),
+		Over:      max(count-target, 0),
+		Percent:   round2(float64(count) * 100 / float64(target)),
+		Min:       opts.Min,
+		Max:       opts.Max,
+	}
+
+	// a bare target is a floor: reaching it is enough
+	lower, upper := opts.Min, opts.Max
+	if lower == 0 && upper == 0 {
+		lower = target
+	}
+	switch {
+	case count < lower:
+		result.Status = "under"
+	case upper > 0 && count > upper:
+		result.Status = "over"
+	default:
+		result.Status = "within"
+		result.InRange = true
+	}
+	return result, nil
+}
@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	PseudonymEmail = "email"
+	PseudonymIPv4  = "ipv4"
+	PseudonymUUID  = "uuid"
+	PseudonymPhone = "phone"
+	PseudonymName  = "name"
+)
+
+var PseudonymKinds = []string{PseudonymEmail, PseudonymIPv4, PseudonymUUID, PseudonymPhone, PseudonymName}
+
+var pseudonymPatterns = map[string]*regexp.Regexp{
+	PseudonymEmail: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	PseudonymIPv4:  regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`),
+	PseudonymUUID:  regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`),
+	PseudonymPhone: regexp.MustCompile(`\+?\(?\d{1,4}\)?[\s.-]?\d{3}[\s.-]?\d{3,4}(?:[\s.-]?\d{2,4})?\b`),
+}
+
+type PseudonymOptions struct {
+	Kinds         []string `json:"kinds"`
+	Names         []string `json:"names"`
+	Secret        string   `json:"secret"`
+	RedactSecrets bool     `json:"redactSecrets"`
+}
+
+type Pseudonym struct {
+	Kind        string `json:"kind"`
+	Original    string `json:"original"`
+	Replacement string `json:"replacement"`
+	Count       int    `json:"count"`
+}
+
+type PseudonymResult struct {
+	Text    string          `json:"text"`
+	Mapping []Pseudonym     `json:"mapping"`
+	Secrets []SecretFinding `json:"secrets,omitempty"`
+}
+
+type pseudonymSpan struct {
+	kind       string
+	start, end int
+}
+
+func Pseudonymize(text string, opts PseudonymOptions) (PseudonymResult, error) {
+	kinds := opts.Kinds
+	if len(kinds) == 0 {
+		kinds = PseudonymKinds
+	}
+	var secrets []SecretFinding
+	if opts.RedactSecrets {
+		text, secrets = RedactSecrets(text)
+	}
+
+	var spans []pseudonymSpan
+	for _, kind := range kinds {
+		var re *regexp.Regexp
+		if kind == PseudonymName {
+			re = namePattern(opts.Names)
+			if re == nil {
+				continue
+			}
+		} else {
+			re = pseudonymPatterns[kind]
+			if re == nil {
+				return PseudonymResult{}, fmt.Errorf("%w: unknown pseudonym kind %q", ErrInvalidOption, kind)
+			}
+		}
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			spans = append(spans, pseudonymSpan{kind: kind, start: loc[0], end: loc[1]})
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+
+	p := pseudonymizer{secret: opts.Secret, index: make(map[string]int), counters: make(map[string]int), used: make(map[string]bool)}
+	var b strings.Builder
+	prev := 0
+	for _, s := range spans {
+		if s.start < prev {
+			continue
+		}
+		b.WriteString(text[prev:s.start])
+		b.WriteString(p.replace(s.kind, text[s.start:s.end]))
+		prev = s.end
+	}
+	b.WriteString(text[prev:])
+
+	return PseudonymResult{Text: b.String(), Mapping: p.mapping, Secrets: secrets}, nil
+}
+
+func namePattern(names []string) *regexp.Regexp {
+	var quoted []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			quoted = append(quoted, regexp.QuoteMeta(name))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	return regexp.MustCompile(`\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
+
+type pseudonymizer struct {
+	secret   string
+	index    map[string]int
+	counters map[string]int
+	// used holds every replacement handed out, so that distinct originals
+	// never share one even where the fake value is narrow (phones, IPs)
+	used    map[string]bool
+	mapping []Pseudonym
+}
+
+func (p *pseudonymizer) replace(kind, original string) string {
+	key := kind + "\x00" + original
+	if i, ok := p.index[key]; ok {
+		p.mapping[i].Count++
+		return p.mapping[i].Replacement
+	}
+
+	var replacement string
+	for attempt := 0; replacement == "" || p.used[replacement]; attempt++ {
+		var seed uint64
+		if p.secret != "" {
+			mac := hmac.New(sha256.New, []byte(p.secret))
+			mac.Write([]byte(key))
+			if attempt > 0 {
+				fmt.Fprintf(mac, "\x00%d", attempt)
+			}
+			seed = binary.BigEndian.Uint64(mac.Sum(nil))
+		} else {
+			p.counters[kind]++
+			seed = uint64(p.counters[kind])
+		}
+		replacement = fakeValue(kind, seed, p.secret != "")
+	}
+	p.used[replacement] = true
+
+	p.index[key] = len(p.mapping)
+	p.mapping = append(p.mapping, Pseudonym{
+		Kind:        kind,
+		Original:    original,
+		Replacement: replacement,
+		Count:       1,
+	})
+	return p.mapping[len(p.mapping)-1].Replacement
+}
+
+func fakeValue(kind string, seed uint64, hashed bool) string {
+	label := fmt.Sprint(seed)
+	if hashed {
+		label = fmt.Sprintf("%08x", uint32(seed>>32))
+	}
+
+	switch kind {
+	case PseudonymEmail:
+		return "user-" + label + "@example.com"
+	case PseudonymIPv4:
+		return fmt.Sprintf("10.%d.%d.%d", byte(seed>>16), byte(seed>>8), byte(seed))
+	case PseudonymUUID:
+		var raw [16]byte
+		sum := sha256.Sum256([]byte(kind + label))
+		copy(raw[:], sum[:])
+		raw[6] = raw[6]&0x0f | 0x40
+		raw[8] = raw[8]&0x3f | 0x80
+		h := hex.EncodeToString(raw[:])
+		return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+	case PseudonymPhone:
+		n := seed % 10_000_000
+		return fmt.Sprintf("555-%03d-%04d", n/10000, n%10000)
+	default:
+		return "Person " + label
+	}
+}
@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type QuoteStyle string
+
+const (
+	QuoteUS         QuoteStyle = "us"
+	QuoteUK         QuoteStyle = "uk"
+	QuoteGuillemets QuoteStyle = "guillemets"
+	QuoteGerman     QuoteStyle = "german"
+	QuoteStraight   QuoteStyle = "straight"
+)
+
+var QuoteStyles = []QuoteStyle{QuoteUS, QuoteUK, QuoteGuillemets, QuoteGerman, QuoteStraight}
+
+func (QuoteStyle) Values() []string {
+	return EnumValues(QuoteStyles)
+}
+
+type quotePair struct{ open, close rune }
+
+// outer and inner pairs, alternating with nesting depth
+var quoteStylePairs = map[QuoteStyle][2]quotePair{
+	QuoteUS:         {{'“', '”'}, {'‘', '’'}},
+	QuoteUK:         {{'‘', '’'}, {'“', '”'}},
+	QuoteGuillemets: {{'«', '»'}, {'‹', '›'}},
+	QuoteGerman:     {{'„', '“'}, {'‚', '‘'}},
+	QuoteStraight:   {{'"', '"'}, {'\'', '\''}},
+}
+
+type quoteKind int
+
+const (
+	quoteDouble quoteKind = iota
+	quoteSingle
+)
+
+type quoteMark struct {
+	kind quoteKind
+	// +1 opens, -1 closes, 0 depends on context
+	hint int
+}
+
+var quoteMarks = map[rune]quoteMark{
+	'"':  {quoteDouble, 0},
+	'“':  {quoteDouble, 1},
+	'”':  {quoteDouble, -1},
+	'„':  {quoteDouble, 1},
+	'«':  {quoteDouble, 1},
+	'»':  {quoteDouble, -1},
+	'\'': {quoteSingle, 0},
+	'‘':  {quoteSingle, 1},
+	'’':  {quoteSingle, -1},
+	'‚':  {quoteSingle, 1},
+	'‹':  {quoteSingle, 1},
+	'›':  {quoteSingle, -1},
+}
+
+func isQuoteSpace(r rune) bool {
+	return r == ' ' || r == '\u00a0' || r == '\u202f'
+}
+
+func ConvertQuotes(text string, style QuoteStyle) (string, error) {
+	if style == "" {
+		style = QuoteUS
+	}
+	pairs, ok := quoteStylePairs[style]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown quote style %q", ErrInvalidOption, style)
+	}
+
+	runes := []rune(text)
+	out := append([]rune(nil), runes...)
+	drop := make([]bool, len(runes))
+
+	type open struct {
+		pos  int
+		kind quoteKind
+	}
+	var stack []open
+	depthOf := func(kind quoteKind) int {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].kind == kind {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for i, r := range runes {
+		mark, ok := quoteMarks[r]
+		if !ok {
+			continue
+		}
+		var prev, next rune
+		if i > 0 {
+			prev = runes[i-1]
+		}
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		if mark.kind == quoteSingle && (unicode.IsLetter(prev) || unicode.IsDigit(prev)) && unicode.IsLetter(next) {
+			continue // apostrophe inside a word
+		}
+
+		leftOpen := prev == 0 || unicode.IsSpace(prev) || strings.ContainsRune("([{—–-/", prev) || quoteMarks[prev].hint > 0
+		rightOpen := next == 0 || unicode.IsSpace(next) || unicode.IsPunct(next)
+		if _, isQuote := quoteMarks[next]; isQuote && next != r {
+			rightOpen = true
+		}
+
+		match := depthOf(mark.kind)
+		var opens bool
+		switch {
+		case leftOpen && !rightOpen:
+			opens = true
+		case rightOpen && !leftOpen:
+			opens = false
+		case mark.hint != 0:
+			opens = mark.hint > 0
+		default:
+			opens = match < 0
+		}
+
+		if !opens {
+			if match < 0 {
+				continue // stray closer or trailing apostrophe
+			}
+			// unclosed quotes opened inside this one keep their original spacing
+			for _, o := range stack[match+1:] {
+				keepSpaceAfter(drop, o.pos)
+			}
+			o := stack[match]
+			stack = stack[:match]
+			depth := len(stack)
+			out[o.pos] = pairs[depth%2].open
+			out[i] = pairs[depth%2].close
+			if style != QuoteGuillemets && isQuoteSpace(prev) && (r == '»' || r == '›') {
+				drop[i-1] = true
+			}
+			continue
+		}
+
+		stack = append(stack, open{pos: i, kind: mark.kind})
+		if style != QuoteGuillemets && isQuoteSpace(next) && (r == '«' || r == '‹') {
+			drop[i+1] = true
+		}
+	}
+	for _, o := range stack {
+		keepSpaceAfter(drop, o.pos)
+	}
+
+	var b strings.Builder
+	for i, r := range out {
+		if !drop[i] {
+			b.WriteRune(r)
+		}
+	}
+	return b.String(), nil
+}
+
+func keepSpaceAfter(drop []bool, pos int) {
+	if pos+1 < len(drop) {
+		drop[pos+1] = false
+	}
+}
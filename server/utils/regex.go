@@ -0,0 +1,248 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxRegexInput   = 1 << 20
+	MaxRegexMatches = 1000
+	RegexTimeBudget = 250 * time.Millisecond
+)
+
+type RegexFlags struct {
+	IgnoreCase  bool    `json:"ignoreCase"`
+	Multiline   bool    `json:"multiline"`
+	DotAll      bool    `json:"dotAll"`
+	Ungreedy    bool    `json:"ungreedy"`
+	Replacement *string `json:"replacement"`
+}
+
+type RegexGroup struct {
+	Index     int    `json:"index"`
+	Name      string `json:"name,omitempty"`
+	Value     string `json:"value"`
+	Matched   bool   `json:"matched"`
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+	RuneStart int    `json:"runeStart"`
+	RuneEnd   int    `json:"runeEnd"`
+}
+
+type RegexMatch struct {
+	Value     string       `json:"value"`
+	Start     int          `json:"start"`
+	End       int          `json:"end"`
+	RuneStart int          `json:"runeStart"`
+	RuneEnd   int          `json:"runeEnd"`
+	Groups    []RegexGroup `json:"groups"`
+}
+
+type RegexResult struct {
+	Pattern    string       `json:"pattern"`
+	GroupNames []string     `json:"groupNames"`
+	Matches    []RegexMatch `json:"matches"`
+	Count      int          `json:"count"`
+	Truncated  bool         `json:"truncated"`
+	Preview    *string      `json:"preview,omitempty"`
+}
+
+type RegexError struct {
+	Code     string `json:"code"`
+	Expr     string `json:"expr"`
+	Position int    `json:"position"`
+	Message  string `json:"message"`
+}
+
+func (e *RegexError) Error() string {
+	if e.Position >= 0 {
+		return fmt.Sprintf("invalid pattern at position %d: %s", e.Position, e.Message)
+	}
+	return "invalid pattern: " + e.Message
+}
+
+func (e *RegexError) Unwrap() error {
+	return ErrInvalidPattern
+}
+
+func CompileRegex(pattern string, flags RegexFlags) (*regexp.Regexp, error) {
+	var prefix strings.Builder
+	if flags.IgnoreCase {
+		prefix.WriteString("i")
+	}
+	if flags.Multiline {
+		prefix.WriteString("m")
+	}
+	if flags.DotAll {
+		prefix.WriteString("s")
+	}
+	if flags.Ungreedy {
+		prefix.WriteString("U")
+	}
+
+	full := pattern
+	if prefix.Len() > 0 {
+		full = "(?" + prefix.String() + ")" + pattern
+	}
+
+	re, err := regexp.Compile(full)
+	if err != nil {
+		return nil, regexErrorFor(pattern, err)
+	}
+	return re, nil
+}
+
+// findAllBudgeted is re.FindAllStringSubmatchIndex giving up once
+// RegexTimeBudget is spent. Like RegexReplaceContext it searches line-local
+// patterns in segments ending at line breaks, checking the budget between
+// them; any other pattern could match across a cut, so it searches the text
+// in one piece.
+func findAllBudgeted(ctx context.Context, re *regexp.Regexp, text string, n int) ([][]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, RegexTimeBudget)
+	defer cancel()
+
+	segments := []string{text}
+	if lineLocal(re) {
+		segments = splitSegments(text, 64<<10)
+	}
+
+	var locs [][]int
+	offset := 0
+	for i, seg := range segments {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		remaining := -1
+		if n >= 0 {
+			remaining = n - len(locs)
+		}
+		for _, loc := range re.FindAllStringSubmatchIndex(seg, remaining) {
+			// the next segment finds an empty match at the cut itself
+			if loc[0] == len(seg) && i < len(segments)-1 {
+				break
+			}
+			for j := range loc {
+				if loc[j] >= 0 {
+					loc[j] += offset
+				}
+			}
+			locs = append(locs, loc)
+		}
+		if n >= 0 && len(locs) >= n {
+			break
+		}
+		offset += len(seg)
+	}
+	return locs, ctx.Err()
+}
+
+func regexErrorFor(pattern string, err error) error {
+	var serr *syntax.Error
+	if !errors.As(err, &serr) {
+		return &RegexError{Position: -1, Message: err.Error()}
+	}
+
+	position := -1
+	if serr.Expr != "" {
+		position = strings.Index(pattern, serr.Expr)
+		if position >= 0 {
+			position = utf8.RuneCountInString(pattern[:position])
+		}
+	}
+
+	return &RegexError{
+		Code:     string(serr.Code),
+		Expr:     serr.Expr,
+		Position: position,
+		Message:  serr.Error(),
+	}
+}
+
+func TestRegex(pattern, text string, flags RegexFlags) (*RegexResult, error) {
+	return TestRegexContext(context.Background(), pattern, text, flags)
+}
+
+func TestRegexContext(ctx context.Context, pattern, text string, flags RegexFlags) (*RegexResult, error) {
+	if len(text) > MaxRegexInput {
+		return nil, fmt.Errorf("%w: input exceeds %d bytes", ErrInputTooLarge, MaxRegexInput)
+	}
+
+	re, err := CompileRegex(pattern, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	locs, err := findAllBudgeted(ctx, re, text, MaxRegexMatches+1)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		slog.WarnContext(ctx, "regex evaluation exceeded time budget", "pattern", pattern, "input_bytes", len(text))
+		return nil, &LimitError{Limit: LimitTimeBudget, Max: RegexTimeBudget.Seconds(), Actual: time.Since(started).Round(time.Millisecond).Seconds()}
+	}
+
+	result := &RegexResult{
+		Pattern:    pattern,
+		GroupNames: re.SubexpNames()[1:],
+	}
+	if len(locs) > MaxRegexMatches {
+		locs = locs[:MaxRegexMatches]
+		result.Truncated = true
+	}
+
+	names := re.SubexpNames()
+	lastByte, lastRune := 0, 0
+	for _, loc := range locs {
+		lastRune += utf8.RuneCountInString(text[lastByte:loc[0]])
+		lastByte = loc[0]
+
+		match := RegexMatch{
+			Value:     text[loc[0]:loc[1]],
+			Start:     loc[0],
+			End:       loc[1],
+			RuneStart: lastRune,
+			RuneEnd:   lastRune + utf8.RuneCountInString(text[loc[0]:loc[1]]),
+		}
+
+		for g := 1; g < len(loc)/2; g++ {
+			group := RegexGroup{Index: g, Name: names[g], Start: -1, End: -1, RuneStart: -1, RuneEnd: -1}
+			start, end := loc[2*g], loc[2*g+1]
+			if start >= 0 {
+				group.Matched = true
+				group.Value = text[start:end]
+				group.Start = start
+				group.End = end
+				group.RuneStart = lastRune + utf8.RuneCountInString(text[loc[0]:start])
+				group.RuneEnd = group.RuneStart + utf8.RuneCountInString(group.Value)
+			}
+			match.Groups = append(match.Groups, group)
+		}
+
+		result.Matches = append(result.Matches, match)
+	}
+	result.Count = len(result.Matches)
+
+	if flags.Replacement != nil {
+		var preview strings.Builder
+		prev := 0
+		for _, loc := range locs {
+			preview.WriteString(text[prev:loc[0]])
+			preview.Write(re.ExpandString(nil, *flags.Replacement, text, loc))
+			prev = loc[1]
+		}
+		preview.WriteString(text[prev:])
+		out := preview.String()
+		result.Preview = &out
+	}
+
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// chunkedText spans several 64KB segments, with short lines so every cut
+// lands close to the segment size
+func chunkedText() string {
+	var b strings.Builder
+	for i := 0; b.Len() < 200<<10; i++ {
+		b.WriteString([]string{"alpha beta", "", "gamma 42 delta", "  é ü  ", "b"}[i%5])
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func TestLineLocal(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{`\w+`, true},
+		{`(?m)^b$`, true},
+		{`[^a]`, false},
+		{`\s+`, false},
+		{`b\nc`, false},
+		{`.`, true},
+		{`(?s).`, false},
+		{`^b`, false},
+		{`b\z`, false},
+	}
+	for _, tt := range tests {
+		if got := lineLocal(regexp.MustCompile(tt.pattern)); got != tt.want {
+			t.Errorf("lineLocal(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestFindAllBudgeted(t *testing.T) {
+	text := chunkedText()
+	tests := []struct {
+		pattern string
+		n       int
+	}{
+		{`\w+`, -1},
+		{`(?m)^b$`, -1},
+		{`(?m)^`, -1},
+		{`(?m)$`, -1},
+		{`x*`, -1},
+		{`\b`, -1},
+		{`(\d+) (\w+)`, -1},
+		{`é|ü`, -1},
+		{`\s+`, -1},
+		{`b\n\n?a`, -1},
+		{`\w+`, 10},
+		{`(?m)^$`, 1000},
+	}
+	for _, tt := range tests {
+		re := regexp.MustCompile(tt.pattern)
+		got, err := findAllBudgeted(context.Background(), re, text, tt.n)
+		if err != nil {
+			t.Fatalf("findAllBudgeted(%q): %v", tt.pattern, err)
+		}
+		want := re.FindAllStringSubmatchIndex(text, tt.n)
+		if tt.n > 0 && len(got) > tt.n {
+			t.Errorf("findAllBudgeted(%q, %d) returned %d matches", tt.pattern, tt.n, len(got))
+			got = got[:tt.n]
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("findAllBudgeted(%q, %d): %d matches, stdlib finds %d", tt.pattern, tt.n, len(got), len(want))
+		}
+	}
+}
+
+func TestFindAllBudgetedAcrossCut(t *testing.T) {
+	// the first cut falls after the line break following 64KB, which here
+	// sits between the b and the c
+	text := strings.Repeat("a", 64<<10) + "b\nc" + strings.Repeat("\nd", 10)
+	if segments := splitSegments(text, 64<<10); len(segments) < 2 || !strings.HasSuffix(segments[0], "b\n") {
+		t.Fatalf("cut not between b and c")
+	}
+	locs, err := findAllBudgeted(context.Background(), regexp.MustCompile(`b\nc`), text, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]int{{64 << 10, 64<<10 + 3}}; !reflect.DeepEqual(locs, want) {
+		t.Errorf("matches = %v, want %v", locs, want)
+	}
+}
+
+func TestTestRegexCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := TestRegexContext(ctx, `\w+`, chunkedText(), RegexFlags{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	var lerr *LimitError
+	if errors.As(err, &lerr) {
+		t.Fatalf("a canceled request is not a time budget error: %v", err)
+	}
+}
+
+func TestTestRegexTruncates(t *testing.T) {
+	result, err := TestRegex(`\w+`, chunkedText(), RegexFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Truncated || result.Count != MaxRegexMatches {
+		t.Errorf("count = %d truncated = %v, want %d and true", result.Count, result.Truncated, MaxRegexMatches)
+	}
+}
@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type RepeatOptions struct {
+	Window   int `json:"window"`
+	MinWords int `json:"minWords"`
+	MaxWords int `json:"maxWords"`
+	MinCount int `json:"minCount"`
+}
+
+var defaultRepeatOptions = RepeatOptions{Window: 100, MinWords: 2, MaxWords: 5, MinCount: 2}
+
+func (o RepeatOptions) withDefaults() RepeatOptions {
+	if o.Window == 0 {
+		o.Window = defaultRepeatOptions.Window
+	}
+	if o.MinWords == 0 {
+		o.MinWords = defaultRepeatOptions.MinWords
+	}
+	if o.MaxWords == 0 {
+		o.MaxWords = max(defaultRepeatOptions.MaxWords, o.MinWords)
+	}
+	if o.MinCount == 0 {
+		o.MinCount = defaultRepeatOptions.MinCount
+	}
+	return o
+}
+
+func (o RepeatOptions) Validate() error {
+	if o.Window < 2 {
+		return fmt.Errorf("%w: window must be at least 2 words, got %d", ErrInvalidOption, o.Window)
+	}
+	if o.MinWords < 2 || o.MaxWords < o.MinWords {
+		return fmt.Errorf("%w: phrase length must satisfy 2 <= minWords <= maxWords, got %d..%d", ErrInvalidOption, o.MinWords, o.MaxWords)
+	}
+	if o.MinCount < 2 {
+		return fmt.Errorf("%w: minCount must be at least 2, got %d", ErrInvalidOption, o.MinCount)
+	}
+	return nil
+}
+
+type TextPosition struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type RepeatedWord struct {
+	Word     string       `json:"word"`
+	Position TextPosition `json:"position"`
+}
+
+type RepeatedPhrase struct {
+	Phrase    string         `json:"phrase"`
+	Words     int            `json:"words"`
+	Count     int            `json:"count"`
+	Positions []TextPosition `json:"positions"`
+}
+
+type RepeatReport struct {
+	Words   []RepeatedWord   `json:"words"`
+	Phrases []RepeatedPhrase `json:"phrases"`
+}
+
+type wordSpan struct {
+	word       string
+	start, end int
+}
+
+func FindRepeatedWords(text string) RepeatReport {
+	report, _ := FindRepeatedWordsWithOptions(text, RepeatOptions{})
+	return report
+}
+
+func FindRepeatedWordsWithOptions(text string, opts RepeatOptions) (RepeatReport, error) {
+	opts = opts.withDefaults()
+	if err := opts.Validate(); err != nil {
+		return RepeatReport{}, err
+	}
+
+	var words []wordSpan
+	for _, loc := range vocabularyWordPattern.FindAllStringIndex(text, -1) {
+		words = append(words, wordSpan{strings.ToLower(text[loc[0]:loc[1]]), loc[0], loc[1]})
+	}
+	position := positionIndex(text)
+
+	report := RepeatReport{Words: []RepeatedWord{}, Phrases: []RepeatedPhrase{}}
+	for i := 1; i < len(words); i++ {
+		prev, cur := words[i-1], words[i]
+		if cur.word == prev.word && strings.TrimSpace(text[prev.end:cur.start]) == "" {
+			report.Words = append(report.Words, RepeatedWord{Word: text[cur.start:cur.end], Position: position(cur.start)})
+		}
+	}
+
+	type occurrences struct {
+		starts []int
+	}
+	// longest phrases first, so shorter phrases they contain can be suppressed
+	covered := make(map[[2]int]bool)
+	for n := opts.MaxWords; n >= opts.MinWords; n-- {
+		found := make(map[string]*occurrences)
+		var order []string
+		for i := 0; i+n <= len(words); i++ {
+			if !phraseWorthReporting(words[i : i+n]) {
+				continue
+			}
+			parts := make([]string, n)
+			for j := range parts {
+				parts[j] = words[i+j].word
+			}
+			key := strings.Join(parts, " ")
+			occ, ok := found[key]
+			if !ok {
+				occ = &occurrences{}
+				found[key] = occ
+				order = append(order, key)
+			}
+			occ.starts = append(occ.starts, i)
+		}
+
+		for _, key := range order {
+			starts := found[key].starts
+			// keep the densest run of occurrences that fits inside the window
+			best, lo := []int{}, 0
+			for hi := range starts {
+				for starts[hi]-starts[lo] >= opts.Window {
+					lo++
+				}
+				if hi-lo+1 > len(best) {
+					best = starts[lo : hi+1]
+				}
+			}
+			best = nonOverlapping(best, n)
+			if len(best) < opts.MinCount {
+				continue
+			}
+			redundant := true
+			for _, s := range best {
+				if !covered[[2]int{s, s + n}] {
+					redundant = false
+				}
+			}
+			if redundant {
+				continue
+			}
+
+			phrase := RepeatedPhrase{Phrase: key, Words: n, Count: len(best)}
+			for _, s := range best {
+				phrase.Positions = append(phrase.Positions, position(words[s].start))
+				for a := s; a < s+n; a++ {
+					for b := a + 1; b <= s+n; b++ {
+						covered[[2]int{a, b}] = true
+					}
+				}
+			}
+			report.Phrases = append(report.Phrases, phrase)
+		}
+	}
+
+	sort.SliceStable(report.Phrases, func(i, j int) bool {
+		a, b := report.Phrases[i], report.Phrases[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Words != b.Words {
+			return a.Words > b.Words
+		}
+		return a.Positions[0].Offset < b.Positions[0].Offset
+	})
+	return report, nil
+}
+
+func nonOverlapping(starts []int, n int) []int {
+	var out []int
+	for _, s := range starts {
+		if len(out) == 0 || s >= out[len(out)-1]+n {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// phrases made only of very common words ("of the", "in a") are noise
+func phraseWorthReporting(words []wordSpan) bool {
+	for _, w := range words {
+		if rank := wordRank(w.word); rank == 0 || rank > 100 {
+			return true
+		}
+	}
+	return false
+}
+
+func positionIndex(text string) func(offset int) TextPosition {
+	var lineStarts []int
+	lineStarts = append(lineStarts, 0)
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return func(offset int) TextPosition {
+		line := sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset }) - 1
+		column := utf8.RuneCountInString(text[lineStarts[line]:offset]) + 1
+		return TextPosition{Offset: offset, Line: line + 1, Column: column}
+	}
+}
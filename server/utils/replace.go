@@ -0,0 +1,401 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type ReplacePair struct {
+	Find          string `json:"find" binding:"required"`
+	Replace       string `json:"replace"`
+	CaseSensitive bool   `json:"caseSensitive"`
+	WholeWord     bool   `json:"wholeWord"`
+	PreserveCase  bool   `json:"preserveCase"`
+
+	// code mode: match whole identifiers of Language, optionally outside literals and comments
+	Identifier   bool   `json:"identifier"`
+	Language     string `json:"language"`
+	SkipStrings  bool   `json:"skipStrings"`
+	SkipComments bool   `json:"skipComments"`
+}
+
+func ReplaceText(text string, pair ReplacePair) (string, error) {
+	return ReplaceTextContext(context.Background(), text, pair)
+}
+
+func FindReplaceWholeWord(text, find, replace string, caseSensitive bool) string {
+	result, err := ReplaceText(text, ReplacePair{
+		Find:          find,
+		Replace:       replace,
+		CaseSensitive: caseSensitive,
+		WholeWord:     true,
+	})
+	if err != nil {
+		return text
+	}
+	return result
+}
+
+type replaceMatch struct {
+	pair  int
+	start int
+	end   int
+}
+
+func FindReplaceMany(text string, pairs []ReplacePair) (string, []int, error) {
+	return findReplaceMany(context.Background(), text, pairs)
+}
+
+func findReplaceMany(ctx context.Context, text string, pairs []ReplacePair) (string, []int, error) {
+	matches, err := findReplaceMatches(text, pairs)
+	if err != nil {
+		return "", nil, err
+	}
+	counts := make([]int, len(pairs))
+
+	limits := LimitsFrom(ctx)
+	var result strings.Builder
+	prev := 0
+	for i, m := range matches {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return "", nil, err
+			}
+		}
+		if err := limits.CheckExpansion(int64(len(text)), int64(result.Len())); err != nil {
+			return "", nil, err
+		}
+		result.WriteString(text[prev:m.start])
+		result.WriteString(pairs[m.pair].replacement(text[m.start:m.end]))
+		counts[m.pair]++
+		prev = m.end
+	}
+	result.WriteString(text[prev:])
+
+	return result.String(), counts, nil
+}
+
+func (p ReplacePair) codeMode() bool {
+	return p.Identifier || p.SkipStrings || p.SkipComments
+}
+
+func findReplaceMatches(text string, pairs []ReplacePair) ([]replaceMatch, error) {
+	order := make([]int, 0, len(pairs))
+	matchers := make([]*regexp.Regexp, len(pairs))
+	syntaxes := make([]codeSyntax, len(pairs))
+	spans := make(map[string][]codeSpan)
+	var alternatives []string
+	for i, p := range pairs {
+		if p.Find == "" {
+			continue
+		}
+		if p.codeMode() {
+			syntax, err := lookupCodeSyntax(p.Language)
+			if err != nil {
+				return nil, err
+			}
+			syntaxes[i] = syntax
+			if _, ok := spans[p.Language]; !ok && (p.SkipStrings || p.SkipComments) {
+				spans[p.Language] = syntax.codeSpans(text)
+			}
+		}
+		order = append(order, i)
+		quoted := regexp.QuoteMeta(p.Find)
+		if !p.CaseSensitive || p.PreserveCase {
+			quoted = "(?i:" + quoted + ")"
+		}
+		re, err := regexp.Compile("^" + quoted)
+		if err != nil {
+			return nil, regexErrorFor(p.Find, err)
+		}
+		matchers[i] = re
+		alternatives = append(alternatives, quoted)
+	}
+	if len(order) == 0 {
+		return nil, nil
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		return len(pairs[order[a]].Find) > len(pairs[order[b]].Find)
+	})
+	candidates, err := regexp.Compile(strings.Join(alternatives, "|"))
+	if err != nil {
+		return nil, regexErrorFor("", err)
+	}
+
+	var matches []replaceMatch
+	pos := 0
+	for pos < len(text) {
+		loc := candidates.FindStringIndex(text[pos:])
+		if loc == nil {
+			break
+		}
+		start := pos + loc[0]
+
+		matched := false
+		for _, i := range order {
+			m := matchers[i].FindStringIndex(text[start:])
+			if m == nil || m[1] == 0 {
+				continue
+			}
+			end := start + m[1]
+			if pairs[i].WholeWord && !isWordBoundary(text, start, end) {
+				continue
+			}
+			if pairs[i].Identifier && !isIdentifierBoundary(text, start, end, syntaxes[i]) {
+				continue
+			}
+			if pairs[i].SkipStrings || pairs[i].SkipComments {
+				if span, ok := spanAt(spans[pairs[i].Language], start); ok && (span.comment && pairs[i].SkipComments || !span.comment && pairs[i].SkipStrings) {
+					continue
+				}
+			}
+			matches = append(matches, replaceMatch{pair: i, start: start, end: end})
+			pos = end
+			matched = true
+			break
+		}
+
+		if !matched {
+			_, size := utf8.DecodeRuneInString(text[start:])
+			pos = start + size
+		}
+	}
+
+	return matches, nil
+}
+
+func (p ReplacePair) replacement(match string) string {
+	if !p.PreserveCase {
+		return p.Replace
+	}
+	return MatchCase(match, p.Replace)
+}
+
+func MatchCase(pattern, replacement string) string {
+	if replacement == "" {
+		return replacement
+	}
+	hasUpper, hasLower := false, false
+	for _, r := range pattern {
+		hasUpper = hasUpper || unicode.IsUpper(r)
+		hasLower = hasLower || unicode.IsLower(r)
+	}
+
+	switch {
+	case hasUpper && !hasLower:
+		return strings.ToUpper(replacement)
+	case hasLower && !hasUpper:
+		return strings.ToLower(replacement)
+	case !hasUpper:
+		return replacement
+	}
+
+	first, size := utf8.DecodeRuneInString(pattern)
+	if unicode.IsUpper(first) && strings.ToLower(pattern[size:]) == pattern[size:] {
+		r, n := utf8.DecodeRuneInString(replacement)
+		return string(unicode.ToUpper(r)) + replacement[n:]
+	}
+
+	from, to := []rune(pattern), []rune(replacement)
+	if len(from) != len(to) {
+		return replacement
+	}
+	for i, r := range from {
+		if unicode.IsUpper(r) {
+			to[i] = unicode.ToUpper(to[i])
+		} else if unicode.IsLower(r) {
+			to[i] = unicode.ToLower(to[i])
+		}
+	}
+	return string(to)
+}
+
+func isWordBoundary(text string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(text[:start])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		r, _ := utf8.DecodeRuneInString(text[end:])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentifierBoundary(text string, start, end int, syntax codeSyntax) bool {
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(text[:start]); syntax.isIdentRune(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		if r, _ := utf8.DecodeRuneInString(text[end:]); syntax.isIdentRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func spanAt(spans []codeSpan, offset int) (codeSpan, bool) {
+	i := sort.Search(len(spans), func(i int) bool { return spans[i].end > offset })
+	if i < len(spans) && spans[i].start <= offset {
+		return spans[i], true
+	}
+	return codeSpan{}, false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+const DefaultPreviewContext = 30
+
+type ReplacePreviewMatch struct {
+	Pair        int    `json:"pair"`
+	Line        int    `json:"line"`
+	ColumnStart int    `json:"columnStart"`
+	ColumnEnd   int    `json:"columnEnd"`
+	Match       string `json:"match"`
+	Replacement string `json:"replacement"`
+	Before      string `json:"before"`
+	After       string `json:"after"`
+}
+
+type ReplacePreview struct {
+	Count   int                   `json:"count"`
+	Counts  []int                 `json:"counts"`
+	Matches []ReplacePreviewMatch `json:"matches"`
+}
+
+func PreviewFindReplace(text, find, replace string, caseSensitive, wholeWord bool) (ReplacePreview, error) {
+	return PreviewReplaceMany(text, []ReplacePair{{
+		Find:          find,
+		Replace:       replace,
+		CaseSensitive: caseSensitive,
+		WholeWord:     wholeWord,
+	}}, DefaultPreviewContext)
+}
+
+func PreviewReplaceMany(text string, pairs []ReplacePair, contextRunes int) (ReplacePreview, error) {
+	matches, err := findReplaceMatches(text, pairs)
+	if err != nil {
+		return ReplacePreview{}, err
+	}
+	preview := ReplacePreview{
+		Counts:  make([]int, len(pairs)),
+		Matches: []ReplacePreviewMatch{},
+	}
+
+	line, lineStart, scanned := 1, 0, 0
+	for _, m := range matches {
+		for i := scanned; i < m.start; i++ {
+			if text[i] == '\n' {
+				line++
+				lineStart = i + 1
+			}
+		}
+		scanned = m.start
+
+		lineEnd := strings.IndexByte(text[m.start:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(text)
+		} else {
+			lineEnd += m.start
+		}
+		if m.end > lineEnd {
+			lineEnd = m.end
+		}
+
+		column := utf8.RuneCountInString(text[lineStart:m.start]) + 1
+		preview.Matches = append(preview.Matches, ReplacePreviewMatch{
+			Pair:        m.pair,
+			Line:        line,
+			ColumnStart: column,
+			ColumnEnd:   column + utf8.RuneCountInString(text[m.start:m.end]),
+			Match:       text[m.start:m.end],
+			Replacement: pairs[m.pair].replacement(text[m.start:m.end]),
+			Before:      lastRunes(text[lineStart:m.start], contextRunes),
+			After:       firstRunes(text[m.end:lineEnd], contextRunes),
+		})
+		preview.Counts[m.pair]++
+	}
+	preview.Count = len(preview.Matches)
+
+	return preview, nil
+}
+
+func firstRunes(s string, n int) string {
+	i := 0
+	for count := 0; i < len(s) && count < n; count++ {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return s[:i]
+}
+
+func lastRunes(s string, n int) string {
+	i := len(s)
+	for count := 0; i > 0 && count < n; count++ {
+		_, size := utf8.DecodeLastRuneInString(s[:i])
+		i -= size
+	}
+	return s[i:]
+}
+
+type NamedText struct {
+	Name string `json:"name" binding:"required"`
+	Text string `json:"text"`
+}
+
+type FileReplaceResult struct {
+	Name         string `json:"name"`
+	Replacements int    `json:"replacements"`
+	Counts       []int  `json:"counts"`
+	Text         string `json:"text"`
+}
+
+type MultiReplaceResult struct {
+	Files        []FileReplaceResult `json:"files"`
+	Replacements int                 `json:"replacements"`
+	Changed      int                 `json:"changed"`
+	Diff         string              `json:"diff"`
+}
+
+func ReplaceAcrossFiles(files []NamedText, pairs []ReplacePair) (MultiReplaceResult, error) {
+	result := MultiReplaceResult{Files: []FileReplaceResult{}}
+	seen := make(map[string]bool, len(files))
+	var diff strings.Builder
+	for _, f := range files {
+		if seen[f.Name] {
+			return MultiReplaceResult{}, fmt.Errorf("%w: duplicate file name %q", ErrInvalidOption, f.Name)
+		}
+		seen[f.Name] = true
+
+		out, counts, err := FindReplaceMany(f.Text, pairs)
+		if err != nil {
+			return MultiReplaceResult{}, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		file := FileReplaceResult{Name: f.Name, Counts: counts, Text: out}
+		for _, n := range counts {
+			file.Replacements += n
+		}
+		result.Replacements += file.Replacements
+		if out != f.Text {
+			result.Changed++
+			diff.WriteString(UnifiedDiff(f.Text, out, "a/"+f.Name, "b/"+f.Name, 3))
+		}
+		result.Files = append(result.Files, file)
+	}
+	result.Diff = diff.String()
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchCase(t *testing.T) {
+	tests := []struct {
+		pattern, replacement, want string
+	}{
+		{"color", "colour", "colour"},
+		{"COLOR", "colour", "COLOUR"},
+		{"Color", "colour", "Colour"},
+		{"Color", "", ""},
+		{"COLOR", "", ""},
+		{"CoLoR", "shade", "ShAdE"},
+		{"CoLoR", "hue", "hue"},
+		{"Élan", "éclat", "Éclat"},
+		{"42", "Answer", "Answer"},
+	}
+	for _, tt := range tests {
+		if got := MatchCase(tt.pattern, tt.replacement); got != tt.want {
+			t.Errorf("MatchCase(%q, %q) = %q, want %q", tt.pattern, tt.replacement, got, tt.want)
+		}
+	}
+}
+
+func TestReplaceTextPreserveCase(t *testing.T) {
+	tests := []struct {
+		text string
+		pair ReplacePair
+		want string
+	}{
+		{"Color color COLOR", ReplacePair{Find: "color", Replace: "colour", PreserveCase: true}, "Colour colour COLOUR"},
+		{"Color color", ReplacePair{Find: "color", Replace: "", PreserveCase: true}, " "},
+		{"Color colorful", ReplacePair{Find: "color", Replace: "hue", PreserveCase: true, WholeWord: true}, "Hue colorful"},
+		{"Color color", ReplacePair{Find: "color", Replace: "hue", CaseSensitive: true}, "Color hue"},
+	}
+	for _, tt := range tests {
+		got, err := ReplaceText(tt.text, tt.pair)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("ReplaceText(%q, %+v) = %q, want %q", tt.text, tt.pair, got, tt.want)
+		}
+	}
+}
+
+func TestFindReplaceManyPreserveCase(t *testing.T) {
+	pairs := []ReplacePair{
+		{Find: "cat", Replace: "dog", PreserveCase: true},
+		{Find: "catalog", Replace: "index", PreserveCase: true},
+		{Find: "Ünit", Replace: "ëlement", PreserveCase: true},
+	}
+	text := "Cat CATALOG catalog CAT üNIT Ünit"
+	want := "Dog INDEX index DOG ëlement Ëlement"
+	got, counts, err := FindReplaceMany(text, pairs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("FindReplaceMany = %q, want %q", got, want)
+	}
+	if wantCounts := []int{2, 2, 2}; !reflect.DeepEqual(counts, wantCounts) {
+		t.Errorf("counts = %v, want %v", counts, wantCounts)
+	}
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type ReverseUnit string
+
+const (
+	ReverseCharacters    ReverseUnit = "characters"
+	ReverseWordOrder     ReverseUnit = "words"
+	ReverseSentenceOrder ReverseUnit = "sentences"
+	ReverseLineOrder     ReverseUnit = "lines"
+)
+
+var ReverseUnits = []ReverseUnit{ReverseCharacters, ReverseWordOrder, ReverseSentenceOrder, ReverseLineOrder}
+
+func (ReverseUnit) Values() []string {
+	return EnumValues(ReverseUnits)
+}
+
+type ReverseOptions struct {
+	Unit    ReverseUnit `json:"unit"`
+	PerLine bool        `json:"perLine"`
+}
+
+var nonSpacePattern = regexp.MustCompile(`\S+`)
+
+func ReverseWords(text string) string {
+	out, _ := ReverseWithOptions(text, ReverseOptions{Unit: ReverseWordOrder})
+	return out
+}
+
+func ReverseSentences(text string) string {
+	out, _ := ReverseWithOptions(text, ReverseOptions{Unit: ReverseSentenceOrder})
+	return out
+}
+
+// PerLine applies the reversal to each line on its own; it has no effect on line order
+func ReverseWithOptions(text string, opts ReverseOptions) (string, error) {
+	var reverse func(string) string
+	switch opts.Unit {
+	case "", ReverseCharacters:
+		reverse = reverseGraphemes
+	case ReverseWordOrder:
+		reverse = func(s string) string {
+			return reverseSpans(s, nonSpacePattern.FindAllStringIndex(s, -1))
+		}
+	case ReverseSentenceOrder:
+		reverse = func(s string) string {
+			sentences, _ := SplitSentences(s, "en")
+			spans := make([][]int, len(sentences))
+			for i, sentence := range sentences {
+				spans[i] = []int{sentence.Start, sentence.End}
+			}
+			return reverseSpans(s, spans)
+		}
+	case ReverseLineOrder:
+		lines := strings.Split(text, "\n")
+		for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+			lines[i], lines[j] = lines[j], lines[i]
+		}
+		return strings.Join(lines, "\n"), nil
+	default:
+		return "", fmt.Errorf("%w: unknown reverse unit %q", ErrInvalidOption, opts.Unit)
+	}
+
+	if !opts.PerLine {
+		return reverse(text), nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = reverse(line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// keeps combining marks and emoji sequences attached to their base
+func reverseGraphemes(s string) string {
+	clusters := Graphemes(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := len(clusters) - 1; i >= 0; i-- {
+		b.WriteString(clusters[i])
+	}
+	return b.String()
+}
+
+// reverseSpans reverses the order of the spans while the text between them stays put
+func reverseSpans(s string, spans [][]int) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	prev := 0
+	for i, span := range spans {
+		other := spans[len(spans)-1-i]
+		b.WriteString(s[prev:span[0]])
+		b.WriteString(s[other[0]:other[1]])
+		prev = span[1]
+	}
+	b.WriteString(s[prev:])
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+func SampleLines(text string, n int, seed int64) (string, error) {
+	if n <= 0 {
+		return "", fmt.Errorf("%w: sample size must be positive, got %d", ErrInvalidOption, n)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	// the reservoir never holds more than one entry per line, whatever n is
+	reservoir := make([][2]int, 0, min(n, strings.Count(text, "\n")+1))
+	seen := 0
+	start := 0
+	for start <= len(text) {
+		end := strings.IndexByte(text[start:], '\n')
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += start
+		}
+		if len(reservoir) < n {
+			reservoir = append(reservoir, [2]int{start, end})
+		} else if j := rng.Int63n(int64(seen + 1)); j < int64(n) {
+			reservoir[j] = [2]int{start, end}
+		}
+		seen++
+		start = end + 1
+	}
+
+	sort.Slice(reservoir, func(i, j int) bool { return reservoir[i][0] < reservoir[j][0] })
+	lines := make([]string, len(reservoir))
+	for i, o := range reservoir {
+		lines[i] = text[o[0]:o[1]]
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	SecretAWSAccessKey = "aws-access-key"
+	SecretAWSSecretKey = "aws-secret-key"
+	SecretGitHubToken  = "github-token"
+	SecretSlackToken   = "slack-token"
+	SecretPrivateKey   = "private-key"
+	SecretJWT          = "jwt"
+	SecretHighEntropy  = "high-entropy"
+)
+
+type secretRule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// rules with a capture group report only the group, so "aws_secret = ..." keeps its key name
+var secretRules = []secretRule{
+	{SecretPrivateKey, regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----[\s\S]*?-----END (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{SecretAWSAccessKey, regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA)[0-9A-Z]{16}\b`)},
+	{SecretAWSSecretKey, regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|private)?.{0,10}?key['"]?\s*[:=]\s*['"]?([A-Za-z0-9/+]{40})\b`)},
+	{SecretGitHubToken, regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`)},
+	{SecretSlackToken, regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{SecretJWT, regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]*`)},
+}
+
+type SecretFinding struct {
+	Rule    string `json:"rule"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Preview string `json:"preview"`
+	start   int
+	end     int
+}
+
+func ScanSecrets(text string) []SecretFinding {
+	var findings []SecretFinding
+	for _, rule := range secretRules {
+		for _, m := range rule.pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[0], m[1]
+			if len(m) >= 4 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			findings = append(findings, SecretFinding{Rule: rule.name, start: start, end: end})
+		}
+	}
+
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		for _, token := range HighEntropyTokens(strings.TrimSuffix(line, "\n")) {
+			start := offset + len(string([]rune(line)[:token.Column-1]))
+			findings = append(findings, SecretFinding{Rule: SecretHighEntropy, start: start, end: start + len(token.Token)})
+		}
+		offset += len(line)
+	}
+
+	// named rules win over the entropy fallback for the same span
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].start != findings[j].start {
+			return findings[i].start < findings[j].start
+		}
+		return findings[i].end-findings[i].start > findings[j].end-findings[j].start
+	})
+	position := positionIndex(text)
+	kept := []SecretFinding{}
+	prev := 0
+	for _, f := range findings {
+		if f.start < prev {
+			continue
+		}
+		pos := position(f.start)
+		f.Line, f.Column = pos.Line, pos.Column
+		f.Preview = secretPreview(text[f.start:f.end])
+		kept = append(kept, f)
+		prev = f.end
+	}
+	return kept
+}
+
+func secretPreview(secret string) string {
+	if first, _, multiline := strings.Cut(secret, "\n"); multiline {
+		return first + "…"
+	}
+	runes := []rune(secret)
+	if len(runes) <= 8 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:4]) + strings.Repeat("*", min(len(runes)-8, 16)) + string(runes[len(runes)-4:])
+}
+
+func RedactSecrets(text string) (string, []SecretFinding) {
+	findings := ScanSecrets(text)
+	var b strings.Builder
+	prev := 0
+	for _, f := range findings {
+		b.WriteString(text[prev:f.start])
+		b.WriteString("[REDACTED:" + f.Rule + "]")
+		prev = f.end
+	}
+	b.WriteString(text[prev:])
+	return b.String(), findings
+}
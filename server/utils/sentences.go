@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"toolkit-backend/languages"
+)
+
+type Sentence struct {
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+func abbreviationsFor(lang string) map[string]bool {
+	if language, err := languages.Default.Get(lang); err == nil {
+		return language.Abbreviations
+	}
+	return nil
+}
+
+// SentenceLanguages lists the registered languages with abbreviations, the
+// ones sentence splitting knows where not to break
+func SentenceLanguages() []string {
+	return languages.Default.Codes(func(l *languages.Language) bool { return len(l.Abbreviations) > 0 })
+}
+
+func SplitSentences(text, lang string) ([]Sentence, error) {
+	if lang == "" {
+		lang = "en"
+	}
+	abbreviations := abbreviationsFor(lang)
+	if len(abbreviations) == 0 {
+		return nil, fmt.Errorf("%w: unsupported sentence language %q", ErrInvalidOption, lang)
+	}
+
+	sentences := []Sentence{}
+	emit := func(start, end int) {
+		body := strings.TrimSpace(text[start:end])
+		if body == "" {
+			return
+		}
+		start += strings.Index(text[start:end], body)
+		sentences = append(sentences, Sentence{Text: body, Start: start, End: start + len(body)})
+	}
+
+	start := 0
+	for i := 0; i < len(text); {
+		r, w := utf8.DecodeRuneInString(text[i:])
+
+		if r == '\n' {
+			j := i + w
+			for j < len(text) && (text[j] == ' ' || text[j] == '\t' || text[j] == '\r') {
+				j++
+			}
+			if j < len(text) && text[j] == '\n' {
+				emit(start, i)
+				start = j
+				i = j
+				continue
+			}
+		}
+
+		if !isTerminator(r) {
+			i += w
+			continue
+		}
+
+		end := i + w
+		for end < len(text) {
+			next, nw := utf8.DecodeRuneInString(text[end:])
+			if !isTerminator(next) && !isClosingQuote(next) {
+				break
+			}
+			end += nw
+		}
+
+		if isWideTerminator(r) || sentenceBreakAfter(text, end) && !(r == '.' && isAbbreviation(text[start:i], abbreviations)) {
+			emit(start, end)
+			start = end
+		}
+		i = end
+	}
+	emit(start, len(text))
+	return sentences, nil
+}
+
+func isTerminator(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…' || isWideTerminator(r)
+}
+
+func isWideTerminator(r rune) bool {
+	return r == '。' || r == '！' || r == '？'
+}
+
+func isClosingQuote(r rune) bool {
+	return strings.ContainsRune(`"')]}»”’`, r)
+}
+
+func sentenceBreakAfter(text string, end int) bool {
+	if end == len(text) {
+		return true
+	}
+	rest := strings.TrimLeftFunc(text[end:], unicode.IsSpace)
+	if len(rest) == len(text)-end {
+		return false
+	}
+	if rest == "" {
+		return true
+	}
+	next, _ := utf8.DecodeRuneInString(rest)
+	return unicode.IsUpper(next) || unicode.IsDigit(next) || strings.ContainsRune(`"'([«“‘¿¡`, next) || !unicode.IsLetter(next) && !unicode.IsPunct(next)
+}
+
+func isAbbreviation(before string, abbreviations map[string]bool) bool {
+	word := before[strings.LastIndexFunc(before, unicode.IsSpace)+1:]
+	word = strings.TrimLeft(word, `"'([«“‘`)
+	if word == "" {
+		return false
+	}
+	if r, size := utf8.DecodeRuneInString(word); size == len(word) && unicode.IsUpper(r) {
+		return true
+	}
+	return abbreviations[strings.ToLower(word)]
+}
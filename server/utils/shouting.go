@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var shoutWordPattern = regexp.MustCompile(`\pL+(?:['’]\pL+)*`)
+
+type ShoutingOptions struct {
+	// Acronyms keep their listed spelling and don't count as shouting; it is
+	// the same dictionary CaseOptions takes
+	Acronyms []string `json:"acronyms"`
+}
+
+type shouter struct {
+	acronyms map[string]string
+}
+
+func (o ShoutingOptions) shouter() shouter {
+	return shouter{acronyms: acronymForms(o.Acronyms)}
+}
+
+func (s shouter) acronym(word string) (string, bool) {
+	form, ok := s.acronyms[strings.ToLower(word)]
+	return form, ok
+}
+
+func isAllCaps(word string) bool {
+	cased := false
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			return false
+		}
+		cased = cased || unicode.IsUpper(r)
+	}
+	return cased
+}
+
+func (s shouter) shouting(sentence string) bool {
+	words := shoutWordPattern.FindAllString(sentence, -1)
+	loud, considered := 0, 0
+	for _, w := range words {
+		if utf8.RuneCountInString(w) < 2 {
+			continue
+		}
+		considered++
+		if _, ok := s.acronym(w); isAllCaps(w) && !ok {
+			loud++
+		}
+	}
+	return loud >= 2 && loud*4 >= considered*3
+}
+
+func ShoutingSentences(text string, opts ShoutingOptions) []Sentence {
+	return opts.shouter().sentences(text)
+}
+
+func (s shouter) sentences(text string) []Sentence {
+	sentences, _ := SplitSentences(text, "en")
+	loud := []Sentence{}
+	for _, sentence := range sentences {
+		if s.shouting(sentence.Text) {
+			loud = append(loud, sentence)
+		}
+	}
+	return loud
+}
+
+func NormalizeShouting(text string, opts ShoutingOptions) string {
+	sh := opts.shouter()
+	var b strings.Builder
+	last := 0
+	for _, s := range sh.sentences(text) {
+		b.WriteString(text[last:s.Start])
+		b.WriteString(sh.sentenceCase(s.Text))
+		last = s.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func (s shouter) sentenceCase(sentence string) string {
+	first := true
+	return shoutWordPattern.ReplaceAllStringFunc(sentence, func(word string) string {
+		defer func() { first = false }()
+		if form, ok := s.acronym(word); ok {
+			return form
+		}
+		if word == "I" || strings.HasPrefix(word, "I'") || strings.HasPrefix(word, "I’") {
+			return "I" + strings.ToLower(word[1:])
+		}
+		word = strings.ToLower(word)
+		if first {
+			word = capitalizeFirst(word)
+		}
+		return word
+	})
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type SlugOptions struct {
+	Separator string `json:"separator"`
+	MaxLength int    `json:"maxLength"`
+	KeepCase  bool   `json:"keepCase"`
+}
+
+func (o SlugOptions) Validate() error {
+	if o.MaxLength < 0 {
+		return fmt.Errorf("%w: maxLength must not be negative, got %d", ErrInvalidOption, o.MaxLength)
+	}
+	return nil
+}
+
+// letters that don't decompose into a base letter plus marks
+var slugLetters = strings.NewReplacer(
+	"ß", "ss", "ẞ", "SS", "æ", "ae", "Æ", "AE", "ø", "o", "Ø", "O", "œ", "oe", "Œ", "OE",
+	"ł", "l", "Ł", "L", "đ", "d", "Đ", "D", "þ", "th", "Þ", "TH", "ð", "d", "Ð", "D", "ı", "i",
+)
+
+func Slugify(text string) string {
+	slug, _ := SlugifyWithOptions(text, SlugOptions{})
+	return slug
+}
+
+func SlugifyWithOptions(text string, opts SlugOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+	sep := opts.Separator
+	if sep == "" {
+		sep = "-"
+	}
+
+	text, err := Transliterate(text, ScriptAuto, ScriptLatin)
+	if err != nil {
+		return "", err
+	}
+	text = stripDiacritics(slugLetters.Replace(text))
+	if !opts.KeepCase {
+		text = strings.ToLower(text)
+	}
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, word := range words {
+		// cut on a word boundary rather than mid-word
+		if opts.MaxLength > 0 && b.Len() > 0 && b.Len()+len(sep)+len(word) > opts.MaxLength {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(word)
+	}
+	slug := b.String()
+	if opts.MaxLength > 0 && len(slug) > opts.MaxLength {
+		slug = slug[:opts.MaxLength]
+	}
+	return slug, nil
+}
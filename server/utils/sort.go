@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type SortOptions struct {
+	Ascending     bool        `json:"ascending"`
+	Field         int         `json:"field" min:"0"`
+	Delimiter     string      `json:"delimiter"`
+	Compare       CompareMode `json:"compare"`
+	CaseSensitive bool        `json:"caseSensitive"`
+	Equivalence   Equivalence `json:"equivalence"`
+}
+
+// SortLinesWithOptions switches to an external merge sort above
+// ExternalSortThreshold, staying in memory if temporary files fail
+func SortLinesWithOptions(text string, opts SortOptions) string {
+	if len(text) > ExternalSortThreshold {
+		if out, err := SortLinesContext(context.Background(), text, opts); err == nil {
+			return out
+		}
+	}
+	lines := strings.Split(text, "\n")
+	SortStrings(lines, opts)
+	return strings.Join(lines, "\n")
+}
+
+func SortStrings(lines []string, opts SortOptions) {
+	keys := make([]string, len(lines))
+	for i, line := range lines {
+		keys[i] = sortKey(line, opts)
+	}
+	sortByKeys(lines, keys, opts)
+}
+
+func sortByKeys(items, keys []string, opts SortOptions) {
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	less := lessFunc(opts.Compare)
+	sort.SliceStable(idx, func(i, j int) bool {
+		if opts.Ascending {
+			return less(keys[idx[i]], keys[idx[j]])
+		}
+		return less(keys[idx[j]], keys[idx[i]])
+	})
+
+	sorted := make([]string, len(items))
+	for i, k := range idx {
+		sorted[i] = items[k]
+	}
+	copy(items, sorted)
+}
+
+var paragraphBreak = regexp.MustCompile(`\n(?:[ \t]*\n)+`)
+
+// paragraphs are ordered by their first line
+func SortParagraphs(text string, opts SortOptions) string {
+	body := strings.TrimRight(text, "\n")
+	paragraphs := paragraphBreak.Split(body, -1)
+	keys := make([]string, len(paragraphs))
+	for i, p := range paragraphs {
+		first, _, _ := strings.Cut(p, "\n")
+		keys[i] = sortKey(first, opts)
+	}
+	sortByKeys(paragraphs, keys, opts)
+	return strings.Join(paragraphs, "\n\n") + text[len(body):]
+}
+
+func SortWords(text string, opts SortOptions) string {
+	words := strings.Fields(text)
+	keys := make([]string, len(words))
+	for i, w := range words {
+		if trimmed := strings.TrimFunc(w, unicode.IsPunct); trimmed != "" {
+			w = trimmed
+		}
+		keys[i] = sortKey(w, opts)
+	}
+	sortByKeys(words, keys, opts)
+	return strings.Join(words, " ")
+}
+
+func sortKey(line string, opts SortOptions) string {
+	key := line
+	if opts.Field > 0 {
+		var fields []string
+		if opts.Delimiter == "" {
+			fields = strings.Fields(line)
+		} else {
+			fields = strings.Split(line, opts.Delimiter)
+		}
+		if opts.Field <= len(fields) {
+			key = fields[opts.Field-1]
+		} else {
+			key = ""
+		}
+	}
+	key = opts.Equivalence.Normalize(key)
+	if !opts.CaseSensitive {
+		key = strings.ToLower(key)
+	}
+	return key
+}
+
+func lessFunc(compare CompareMode) func(a, b string) bool {
+	switch compare {
+	case CompareNumeric:
+		return func(a, b string) bool {
+			return leadingNumber(a) < leadingNumber(b)
+		}
+	case CompareNatural:
+		return func(a, b string) bool {
+			return naturalCompare(a, b) < 0
+		}
+	case CompareLength:
+		return func(a, b string) bool {
+			if la, lb := utf8.RuneCountInString(a), utf8.RuneCountInString(b); la != lb {
+				return la < lb
+			}
+			return a < b
+		}
+	default:
+		return func(a, b string) bool {
+			return a < b
+		}
+	}
+}
+
+func leadingNumber(s string) float64 {
+	s = strings.TrimSpace(s)
+	end := 0
+	for end < len(s) {
+		c := s[end]
+		if (c >= '0' && c <= '9') || c == '.' || ((c == '-' || c == '+') && end == 0) {
+			end++
+			continue
+		}
+		break
+	}
+	for end > 0 {
+		if n, err := strconv.ParseFloat(s[:end], 64); err == nil {
+			return n
+		}
+		end--
+	}
+	return 0
+}
+
+func naturalCompare(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		if unicode.IsDigit(ra[i]) && unicode.IsDigit(rb[j]) {
+			si := i
+			for i < len(ra) && unicode.IsDigit(ra[i]) {
+				i++
+			}
+			sj := j
+			for j < len(rb) && unicode.IsDigit(rb[j]) {
+				j++
+			}
+			na := strings.TrimLeft(string(ra[si:i]), "0")
+			nb := strings.TrimLeft(string(rb[sj:j]), "0")
+			if len(na) != len(nb) {
+				if len(na) < len(nb) {
+					return -1
+				}
+				return 1
+			}
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+		if ra[i] != rb[j] {
+			if ra[i] < rb[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	switch {
+	case len(ra)-i < len(rb)-j:
+		return -1
+	case len(ra)-i > len(rb)-j:
+		return 1
+	}
+	return 0
+}
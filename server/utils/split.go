@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type MessagePreset string
+
+const (
+	PresetSMS     MessagePreset = "sms"
+	PresetTweet   MessagePreset = "tweet"
+	PresetDiscord MessagePreset = "discord"
+)
+
+var MessagePresets = []MessagePreset{PresetSMS, PresetTweet, PresetDiscord}
+
+var messagePresetLimits = map[MessagePreset]int{
+	PresetSMS:     160,
+	PresetTweet:   280,
+	PresetDiscord: 2000,
+}
+
+func (MessagePreset) Values() []string {
+	return EnumValues(MessagePresets)
+}
+
+type SplitLimitOptions struct {
+	Preset    MessagePreset `json:"preset"`
+	NoCounter bool          `json:"noCounter"`
+}
+
+func SplitForLimit(text string, limit int, opts SplitLimitOptions) ([]string, error) {
+	if opts.Preset != "" {
+		preset, ok := messagePresetLimits[opts.Preset]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown message preset %q", ErrInvalidOption, opts.Preset)
+		}
+		if limit <= 0 {
+			limit = preset
+		}
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: limit must be positive, got %d", ErrInvalidOption, limit)
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return []string{}, nil
+	}
+	if utf8.RuneCountInString(text) <= limit {
+		return []string{text}, nil
+	}
+	if opts.NoCounter {
+		return packForLimit(text, limit)
+	}
+
+	// the counter eats into the budget, and its width depends on how many parts there end up being
+	digits := 1
+	for {
+		reserve := len(" (/)") + 2*digits
+		if reserve >= limit {
+			return nil, fmt.Errorf("%w: limit %d leaves no room for part counters", ErrInvalidOption, limit)
+		}
+		parts, err := packForLimit(text, limit-reserve)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(strconv.Itoa(len(parts))); n > digits {
+			digits = n
+			continue
+		}
+		for i := range parts {
+			parts[i] = fmt.Sprintf("%s (%d/%d)", parts[i], i+1, len(parts))
+		}
+		return parts, nil
+	}
+}
+
+func packForLimit(text string, limit int) ([]string, error) {
+	sentences, err := SplitSentences(text, "en")
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []string
+	current := ""
+	add := func(piece, sep string) {
+		switch {
+		case current == "":
+			current = piece
+		case utf8.RuneCountInString(current)+utf8.RuneCountInString(sep)+utf8.RuneCountInString(piece) <= limit:
+			current += sep + piece
+		default:
+			parts = append(parts, current)
+			current = piece
+		}
+	}
+	prevEnd := -1
+	for _, s := range sentences {
+		sep := " "
+		if prevEnd >= 0 {
+			if gap := text[prevEnd:s.Start]; strings.Contains(gap, "\n\n") {
+				sep = "\n\n"
+			} else if strings.Contains(gap, "\n") {
+				sep = "\n"
+			}
+		}
+		prevEnd = s.End
+		if utf8.RuneCountInString(s.Text) <= limit {
+			add(s.Text, sep)
+			continue
+		}
+		for i, piece := range splitLongSentence(s.Text, limit) {
+			if i > 0 {
+				sep = " "
+			}
+			add(piece, sep)
+		}
+	}
+	if current != "" {
+		parts = append(parts, current)
+	}
+	return parts, nil
+}
+
+func splitLongSentence(sentence string, limit int) []string {
+	var parts []string
+	current := ""
+	for _, word := range strings.Fields(sentence) {
+		for utf8.RuneCountInString(word) > limit {
+			if current != "" {
+				parts = append(parts, current)
+				current = ""
+			}
+			runes := []rune(word)
+			parts = append(parts, string(runes[:limit]))
+			word = string(runes[limit:])
+		}
+		switch {
+		case current == "":
+			current = word
+		case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= limit:
+			current += " " + word
+		default:
+			parts = append(parts, current)
+			current = word
+		}
+	}
+	if current != "" {
+		parts = append(parts, current)
+	}
+	return parts
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	stegoZero   = '\u200b'
+	stegoOne    = '\u200c'
+	stegoMarker = '\u2060'
+)
+
+var ErrNoHiddenMessage = errors.New("no hidden message found")
+
+func HideMessage(cover, secret string) (string, error) {
+	if secret == "" {
+		return "", fmt.Errorf("%w: secret must not be empty", ErrInvalidOption)
+	}
+	if strings.ContainsRune(cover, stegoMarker) {
+		return "", fmt.Errorf("%w: cover text already contains a hidden message", ErrInvalidOption)
+	}
+
+	var payload strings.Builder
+	payload.WriteRune(stegoMarker)
+	for i := 0; i < len(secret); i++ {
+		for bit := 7; bit >= 0; bit-- {
+			if secret[i]>>uint(bit)&1 == 1 {
+				payload.WriteRune(stegoOne)
+			} else {
+				payload.WriteRune(stegoZero)
+			}
+		}
+	}
+	payload.WriteRune(stegoMarker)
+
+	_, size := utf8.DecodeRuneInString(cover)
+	return cover[:size] + payload.String() + cover[size:], nil
+}
+
+func RevealMessage(text string) (string, error) {
+	start := strings.IndexRune(text, stegoMarker)
+	if start < 0 {
+		return "", ErrNoHiddenMessage
+	}
+	rest := text[start+utf8.RuneLen(stegoMarker):]
+	end := strings.IndexRune(rest, stegoMarker)
+	if end < 0 {
+		return "", ErrNoHiddenMessage
+	}
+
+	var bits []byte
+	for _, r := range rest[:end] {
+		switch r {
+		case stegoZero:
+			bits = append(bits, 0)
+		case stegoOne:
+			bits = append(bits, 1)
+		}
+	}
+	if len(bits) == 0 || len(bits)%8 != 0 {
+		return "", ErrNoHiddenMessage
+	}
+
+	message := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		message[i/8] = message[i/8]<<1 | bit
+	}
+	if !utf8.Valid(message) {
+		return "", ErrNoHiddenMessage
+	}
+	return string(message), nil
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"toolkit-backend/languages"
+)
+
+// StopWordLanguages lists the registered languages with stop words
+func StopWordLanguages() []string {
+	return languages.Default.Codes(func(l *languages.Language) bool { return len(l.StopWords) > 0 })
+}
+
+// RemoveStopWords drops the language's stop words, and any extra ones, from
+// every line. A word is matched without its surrounding punctuation and
+// case; lines keep their indentation and the remaining words one space apart.
+func RemoveStopWords(text, lang string, extra []string) (string, error) {
+	if lang == "" {
+		lang = "en"
+	}
+	language, err := languages.Default.Get(lang)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidOption, err)
+	}
+	extraSet := make(map[string]bool, len(extra))
+	for _, w := range extra {
+		extraSet[strings.ToLower(w)] = true
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		indent := line[:len(line)-len(strings.TrimLeftFunc(line, unicode.IsSpace))]
+		var kept []string
+		for _, word := range strings.Fields(line) {
+			core := strings.ToLower(strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }))
+			if core != "" && (language.StopWords[core] || extraSet[core]) {
+				continue
+			}
+			kept = append(kept, word)
+		}
+		if len(kept) == 0 {
+			lines[i] = ""
+			continue
+		}
+		lines[i] = indent + strings.Join(kept, " ")
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type StyleIssueKind string
+
+const (
+	StylePassive StyleIssueKind = "passive"
+	StyleWeasel  StyleIssueKind = "weasel"
+	StyleCliche  StyleIssueKind = "cliche"
+	StyleAdverb  StyleIssueKind = "adverb"
+)
+
+type StyleSeverity string
+
+const (
+	SeverityNone   StyleSeverity = "none"
+	SeverityLow    StyleSeverity = "low"
+	SeverityMedium StyleSeverity = "medium"
+	SeverityHigh   StyleSeverity = "high"
+)
+
+var severityOrder = map[StyleSeverity]int{SeverityNone: 0, SeverityLow: 1, SeverityMedium: 2, SeverityHigh: 3}
+
+type StyleIssue struct {
+	Kind     StyleIssueKind `json:"kind"`
+	Text     string         `json:"text"`
+	Start    int            `json:"start"`
+	End      int            `json:"end"`
+	Severity StyleSeverity  `json:"severity"`
+}
+
+type SentenceStyle struct {
+	Sentence
+	Severity StyleSeverity `json:"severity"`
+	Issues   []StyleIssue  `json:"issues"`
+}
+
+type StyleReport struct {
+	Sentences   []SentenceStyle        `json:"sentences"`
+	Counts      map[StyleIssueKind]int `json:"counts"`
+	Adverbs     int                    `json:"adverbs"`
+	AdverbLimit int                    `json:"adverbLimit"`
+}
+
+const irregularParticiples = "awoken|been|beaten|become|begun|bent|bet|bitten|blown|broken|brought|built|bought|caught|chosen|come|cut|dealt|done|drawn|driven|drunk|eaten|fallen|fed|felt|fought|found|forbidden|forgotten|forgiven|frozen|given|gone|grown|hung|heard|hidden|hit|held|hurt|kept|known|laid|led|left|lent|let|lost|made|meant|met|paid|put|quit|read|ridden|rung|risen|run|said|seen|sent|set|shaken|shot|shown|shut|sung|sunk|slain|slid|sold|spent|split|spoken|spread|stolen|struck|stuck|sworn|swept|taken|taught|thrown|told|thought|torn|understood|woken|worn|won|withdrawn|written"
+
+var passivePattern = regexp.MustCompile(`(?i)\b(?:am|is|are|was|were|be|been|being)\s+(?:\w+ly\s+)?(?:\w+ed|` + irregularParticiples + `)\b`)
+
+var weaselPattern = regexp.MustCompile(`(?i)\b(?:very|really|extremely|quite|fairly|rather|somewhat|basically|literally|actually|clearly|obviously|arguably|virtually|just|simply|totally|completely|many|various|several|numerous|a number of|some people (?:say|think|believe)|it is (?:said|believed|thought|known) that|experts (?:say|agree|believe)|studies (?:show|suggest)|research (?:shows|suggests)|it could be argued)\b`)
+
+var cliches = []string{
+	"at the end of the day", "think outside the box", "low-hanging fruit", "move the needle",
+	"paradigm shift", "game changer", "best of breed", "win-win", "circle back", "touch base",
+	"in this day and age", "at this point in time", "all walks of life", "avoid like the plague",
+	"better late than never", "crystal clear", "last but not least", "needless to say",
+	"only time will tell", "read between the lines", "the bottom line", "tip of the iceberg",
+	"when all is said and done", "easier said than done", "few and far between", "in the nick of time",
+	"it goes without saying", "par for the course", "the writing on the wall", "a perfect storm",
+	"push the envelope", "at the drop of a hat", "back to square one", "every cloud has a silver lining",
+	"going forward", "leverage synergies", "on the same page", "think big picture", "hit the ground running",
+}
+
+var clichePattern = func() *regexp.Regexp {
+	quoted := make([]string, len(cliches))
+	for i, c := range cliches {
+		quoted[i] = regexp.QuoteMeta(c)
+	}
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}()
+
+var adverbPattern = regexp.MustCompile(`(?i)\b\pL{2,}ly\b`)
+
+var notAdverbs = wordSet("only family early daily weekly monthly yearly reply apply supply imply rely ally belly bully fly holy ugly silly jelly july italy lily rally tally jolly folly gully sully oily curly hilly friendly lonely lovely likely lively costly deadly elderly orderly unlikely chilly assembly anomaly monopoly melancholy butterfly dragonfly firefly")
+
+func AnalyzeStyle(text string) StyleReport {
+	sentences, _ := SplitSentences(text, "en")
+	report := StyleReport{Sentences: []SentenceStyle{}, Counts: map[StyleIssueKind]int{}}
+
+	var adverbs []*StyleIssue
+	for _, s := range sentences {
+		style := SentenceStyle{Sentence: s, Severity: SeverityNone, Issues: []StyleIssue{}}
+		add := func(kind StyleIssueKind, severity StyleSeverity, loc []int) {
+			style.Issues = append(style.Issues, StyleIssue{
+				Kind:     kind,
+				Text:     s.Text[loc[0]:loc[1]],
+				Start:    s.Start + loc[0],
+				End:      s.Start + loc[1],
+				Severity: severity,
+			})
+			report.Counts[kind]++
+		}
+
+		clicheSpans := clichePattern.FindAllStringIndex(s.Text, -1)
+		for _, loc := range clicheSpans {
+			add(StyleCliche, SeverityHigh, loc)
+		}
+		for _, loc := range passivePattern.FindAllStringIndex(s.Text, -1) {
+			add(StylePassive, SeverityMedium, loc)
+		}
+		for _, loc := range weaselPattern.FindAllStringIndex(s.Text, -1) {
+			if !insideSpan(loc, clicheSpans) {
+				add(StyleWeasel, SeverityMedium, loc)
+			}
+		}
+		for _, loc := range adverbPattern.FindAllStringIndex(s.Text, -1) {
+			word := strings.ToLower(s.Text[loc[0]:loc[1]])
+			if notAdverbs[word] || weaselPattern.MatchString(word) {
+				continue
+			}
+			add(StyleAdverb, SeverityLow, loc)
+		}
+
+		sort.SliceStable(style.Issues, func(i, j int) bool { return style.Issues[i].Start < style.Issues[j].Start })
+		report.Sentences = append(report.Sentences, style)
+	}
+
+	// Hemingway's rule of thumb: about one adverb per ten sentences
+	report.AdverbLimit = (len(sentences) + 9) / 10
+	for i := range report.Sentences {
+		for j := range report.Sentences[i].Issues {
+			if report.Sentences[i].Issues[j].Kind == StyleAdverb {
+				adverbs = append(adverbs, &report.Sentences[i].Issues[j])
+			}
+		}
+	}
+	report.Adverbs = len(adverbs)
+	if len(adverbs) > report.AdverbLimit {
+		for _, issue := range adverbs {
+			issue.Severity = SeverityMedium
+		}
+	}
+
+	for i := range report.Sentences {
+		for _, issue := range report.Sentences[i].Issues {
+			if severityOrder[issue.Severity] > severityOrder[report.Sentences[i].Severity] {
+				report.Sentences[i].Severity = issue.Severity
+			}
+		}
+	}
+	return report
+}
+
+func insideSpan(loc []int, spans [][]int) bool {
+	for _, s := range spans {
+		if loc[0] >= s[0] && loc[1] <= s[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,9 @@
 package utils
 
 import (
-	"regexp"
-	"sort"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -32,32 +31,7 @@ func ReverseText(text string) string {
 }
 
 func WordCount(text string) map[string]int {
-	text = strings.TrimSpace(text)
-
-	lines := strings.Split(text, "\n")
-	lineCount := len(lines)
-
-	paragraphs := strings.Split(text, "\n\n")
-	paragraphCount := 0
-	for _, p := range paragraphs {
-		if strings.TrimSpace(p) != "" {
-			paragraphCount++
-		}
-	}
-
-	charCount := len(text)
-	charNoSpaces := len(strings.ReplaceAll(strings.ReplaceAll(text, " ", ""), "\n", ""))
-
-	words := strings.Fields(text)
-	wordCount := len(words)
-
-	return map[string]int{
-		"words":              wordCount,
-		"characters":         charCount,
-		"charactersNoSpaces": charNoSpaces,
-		"lines":              lineCount,
-		"paragraphs":         paragraphCount,
-	}
+	return WordCountWithOptions(text, CountOptions{}).Map()
 }
 
 func TrimText(text string) string {
@@ -65,127 +39,134 @@ func TrimText(text string) string {
 }
 
 func FindReplace(text, find, replace string, caseSensitive bool) string {
-	if !caseSensitive {
-		re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(find))
-		return re.ReplaceAllString(text, replace)
+	result, err := ReplaceText(text, ReplacePair{Find: find, Replace: replace, CaseSensitive: caseSensitive})
+	if err != nil {
+		return text
 	}
-	return strings.ReplaceAll(text, find, replace)
+	return result
 }
 
 func RemoveDuplicateLines(text string) string {
-	lines := strings.Split(text, "\n")
-	seen := make(map[string]bool)
-	var result []string
-
-	for _, line := range lines {
-		if !seen[line] {
-			seen[line] = true
-			result = append(result, line)
-		}
-	}
-
-	return strings.Join(result, "\n")
+	return RemoveDuplicateLinesWithOptions(text, DedupeOptions{})
 }
 
 func SortLines(text string, ascending bool) string {
-	lines := strings.Split(text, "\n")
-
-	sort.Slice(lines, func(i, j int) bool {
-		if ascending {
-			return strings.ToLower(lines[i]) < strings.ToLower(lines[j])
-		}
-		return strings.ToLower(lines[i]) > strings.ToLower(lines[j])
-	})
-
-	return strings.Join(lines, "\n")
+	return SortLinesWithOptions(text, SortOptions{Ascending: ascending})
 }
 
 func ConvertCase(text, caseType string) string {
-	switch caseType {
-	case "camelCase":
-		return toCamelCase(text)
-	case "PascalCase":
-		return toPascalCase(text)
-	case "snake_case":
-		return toSnakeCase(text)
-	case "kebab-case":
-		return toKebabCase(text)
-	case "CONSTANT_CASE":
-		return toConstantCase(text)
-	default:
+	out, err := ConvertCaseWithOptions(text, CaseOptions{Type: CaseType(caseType)})
+	if err != nil {
 		return text
 	}
+	return out
 }
 
-func toCamelCase(s string) string {
+type wordStyle int
+
+const (
+	styleLower wordStyle = iota
+	styleUpper
+	styleTitle
+)
+
+func toCamelCase(s string, acronyms map[string]string) string {
 	words := splitWords(s)
 	if len(words) == 0 {
 		return ""
 	}
-	result := strings.ToLower(words[0])
-	for _, word := range words[1:] {
-		result += strings.Title(strings.ToLower(word))
-	}
-	return result
+	return joinWords(words, "", styleLower, styleTitle, acronyms)
 }
 
-func toPascalCase(s string) string {
-	words := splitWords(s)
-	var result string
-	for _, word := range words {
-		result += strings.Title(strings.ToLower(word))
-	}
-	return result
+func toPascalCase(s string, acronyms map[string]string) string {
+	return joinWords(splitWords(s), "", styleTitle, styleTitle, acronyms)
 }
 
 func toSnakeCase(s string) string {
-	words := splitWords(s)
-	for i, word := range words {
-		words[i] = strings.ToLower(word)
-	}
-	return strings.Join(words, "_")
+	return joinWords(splitWords(s), "_", styleLower, styleLower, nil)
 }
 
 func toKebabCase(s string) string {
-	words := splitWords(s)
-	for i, word := range words {
-		words[i] = strings.ToLower(word)
-	}
-	return strings.Join(words, "-")
+	return joinWords(splitWords(s), "-", styleLower, styleLower, nil)
 }
 
 func toConstantCase(s string) string {
-	words := splitWords(s)
+	return joinWords(splitWords(s), "_", styleUpper, styleUpper, nil)
+}
+
+// acronyms only apply to title-cased words, so camelCase still starts lowercase
+func joinWords(words []string, sep string, first, rest wordStyle, acronyms map[string]string) string {
+	size := len(sep) * len(words)
+	for _, word := range words {
+		size += len(word)
+	}
+
+	var b strings.Builder
+	b.Grow(size)
 	for i, word := range words {
-		words[i] = strings.ToUpper(word)
+		style := rest
+		if i == 0 {
+			style = first
+		} else {
+			b.WriteString(sep)
+		}
+		if style == styleTitle && len(acronyms) > 0 {
+			if form, ok := acronyms[strings.ToLower(word)]; ok {
+				b.WriteString(form)
+				continue
+			}
+		}
+		writeWord(&b, word, style)
+	}
+	return b.String()
+}
+
+func writeWord(b *strings.Builder, word string, style wordStyle) {
+	for i, r := range word {
+		switch {
+		case style == styleTitle && i == 0:
+			r = unicode.ToTitle(r)
+		case r < utf8.RuneSelf:
+			// ASCII, which identifiers nearly always are, skips the case tables
+			if c := byte(r); style == styleUpper && 'a' <= c && c <= 'z' {
+				r -= 'a' - 'A'
+			} else if style != styleUpper && 'A' <= c && c <= 'Z' {
+				r += 'a' - 'A'
+			}
+			b.WriteByte(byte(r))
+			continue
+		case style == styleUpper:
+			r = unicode.ToUpper(r)
+		default:
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
 	}
-	return strings.Join(words, "_")
 }
 
+// splitWords returns the runs of letters and digits in s, also broken where
+// an upper-case letter follows a lower-case one; the words are slices of s
 func splitWords(s string) []string {
 	var words []string
-	var currentWord strings.Builder
-
+	start := -1
+	var prev rune
 	for i, r := range s {
 		if unicode.IsLetter(r) || unicode.IsNumber(r) {
-			if i > 0 && unicode.IsUpper(r) && unicode.IsLower(rune(s[i-1])) {
-				if currentWord.Len() > 0 {
-					words = append(words, currentWord.String())
-					currentWord.Reset()
-				}
+			if start >= 0 && unicode.IsUpper(r) && unicode.IsLower(prev) {
+				words = append(words, s[start:i])
+				start = i
 			}
-			currentWord.WriteRune(r)
-		} else {
-			if currentWord.Len() > 0 {
-				words = append(words, currentWord.String())
-				currentWord.Reset()
+			if start < 0 {
+				start = i
 			}
+		} else if start >= 0 {
+			words = append(words, s[start:i])
+			start = -1
 		}
+		prev = r
 	}
-
-	if currentWord.Len() > 0 {
-		words = append(words, currentWord.String())
+	if start >= 0 {
+		words = append(words, s[start:])
 	}
-
 	return words
 }
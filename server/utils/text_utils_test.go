@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+)
+
+// benchmarkIdentifiers mixes the shapes case conversion sees: camel and
+// snake input, acronyms, digits and non-ASCII letters
+var benchmarkIdentifiers = func() []string {
+	shapes := []string{
+		"userAccountID%d", "HTTPServerConfig%d", "parse_json_value_%d", "kebab-case-name-%d",
+		"SCREAMING_CONSTANT_%d", "mixedUp_Style-name%d", "straßenNameÄnderung%d", "url path segment %d",
+	}
+	ids := make([]string, 0, 10000)
+	for i := 0; len(ids) < cap(ids); i++ {
+		ids = append(ids, fmt.Sprintf(shapes[i%len(shapes)], i))
+	}
+	return ids
+}()
+
+func benchmarkCase(b *testing.B, convert func(string) string) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, id := range benchmarkIdentifiers {
+			convert(id)
+		}
+	}
+}
+
+func BenchmarkToCamelCase(b *testing.B) {
+	benchmarkCase(b, func(s string) string { return toCamelCase(s, nil) })
+}
+
+func BenchmarkToPascalCase(b *testing.B) {
+	benchmarkCase(b, func(s string) string { return toPascalCase(s, nil) })
+}
+
+func BenchmarkToSnakeCase(b *testing.B) {
+	benchmarkCase(b, toSnakeCase)
+}
+
+func BenchmarkToKebabCase(b *testing.B) {
+	benchmarkCase(b, toKebabCase)
+}
+
+func BenchmarkToConstantCase(b *testing.B) {
+	benchmarkCase(b, toConstantCase)
+}
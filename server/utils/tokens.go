@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const DefaultTokenModel = "gpt-4"
+
+type tokenizerFamily struct {
+	name          string
+	charsPerToken float64
+	wholeWord     int
+	digitGroup    int
+}
+
+var (
+	familyO200k     = tokenizerFamily{name: "o200k", charsPerToken: 4.4, wholeWord: 8, digitGroup: 3}
+	familyCl100k    = tokenizerFamily{name: "cl100k", charsPerToken: 4, wholeWord: 7, digitGroup: 3}
+	familyP50k      = tokenizerFamily{name: "p50k", charsPerToken: 3.6, wholeWord: 6, digitGroup: 2}
+	familyClaude    = tokenizerFamily{name: "claude", charsPerToken: 3.8, wholeWord: 7, digitGroup: 3}
+	familyLlama     = tokenizerFamily{name: "llama", charsPerToken: 3.6, wholeWord: 6, digitGroup: 1}
+	tokenizerModels = []struct {
+		prefix string
+		family tokenizerFamily
+	}{
+		{"gpt-4o", familyO200k},
+		{"gpt-4.1", familyO200k},
+		{"gpt-5", familyO200k},
+		{"o1", familyO200k},
+		{"o3", familyO200k},
+		{"o4", familyO200k},
+		{"o200k", familyO200k},
+		{"gpt-4", familyCl100k},
+		{"gpt-3.5", familyCl100k},
+		{"text-embedding", familyCl100k},
+		{"cl100k", familyCl100k},
+		{"gpt-3", familyP50k},
+		{"gpt2", familyP50k},
+		{"davinci", familyP50k},
+		{"p50k", familyP50k},
+		{"claude", familyClaude},
+		{"llama", familyLlama},
+		{"mistral", familyLlama},
+		{"mixtral", familyLlama},
+		{"gemma", familyLlama},
+	}
+)
+
+var pretokenPattern = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\pL\pN]?\pL+|\pN+|[^\s\pL\pN]+|\s*[\r\n]+|\s+`)
+
+func TokenModels() []string {
+	models := make([]string, len(tokenizerModels))
+	for i, m := range tokenizerModels {
+		models[i] = m.prefix
+	}
+	return models
+}
+
+func lookupTokenizer(model string) (tokenizerFamily, bool) {
+	if model == "" {
+		model = DefaultTokenModel
+	}
+	model = strings.ToLower(model)
+	for _, m := range tokenizerModels {
+		if strings.HasPrefix(model, m.prefix) {
+			return m.family, true
+		}
+	}
+	return tokenizerFamily{}, false
+}
+
+func EstimateTokens(text, model string) (int, error) {
+	family, ok := lookupTokenizer(model)
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown tokenizer model %q", ErrInvalidOption, model)
+	}
+	return family.count(text), nil
+}
+
+func (f tokenizerFamily) count(text string) int {
+	total := 0
+	for _, piece := range pretokenPattern.FindAllString(text, -1) {
+		total += f.pieceTokens(piece)
+	}
+	return total
+}
+
+func (f tokenizerFamily) pieceTokens(piece string) int {
+	first, _ := utf8.DecodeRuneInString(piece)
+	switch {
+	case strings.TrimSpace(piece) == "":
+		if strings.ContainsAny(piece, "\r\n") {
+			return 1
+		}
+		return int(math.Ceil(float64(len(piece)) / 16))
+	case unicode.IsDigit(first):
+		return (utf8.RuneCountInString(piece) + f.digitGroup - 1) / f.digitGroup
+	case piece[0] == '\'':
+		return 1
+	}
+
+	word := strings.TrimLeftFunc(piece, func(r rune) bool { return !unicode.IsLetter(r) })
+	if word == "" {
+		return utf8.RuneCountInString(piece)/2 + 1
+	}
+	tokens := 0
+	if len(word) < len(piece) && !strings.HasPrefix(piece, " ") {
+		tokens++
+	}
+
+	ascii, wide, other := 0, 0, 0
+	for _, r := range word {
+		switch {
+		case r < utf8.RuneSelf:
+			ascii++
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			wide++
+		default:
+			other++
+		}
+	}
+	if ascii > 0 {
+		if ascii <= f.wholeWord {
+			tokens++
+		} else {
+			tokens += int(math.Ceil(float64(ascii) / f.charsPerToken))
+		}
+	}
+	tokens += wide + int(math.Ceil(float64(other)/2))
+	return tokens
+}
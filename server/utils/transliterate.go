@@ -0,0 +1,324 @@
+package utils
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	ScriptAuto          = "auto"
+	ScriptLatin         = "latin"
+	ScriptCyrillic      = "cyrillic"
+	ScriptGreek         = "greek"
+	ScriptHanzi         = "hanzi"
+	ScriptPinyin        = "pinyin"
+	ScriptPinyinNumeric = "pinyin-numeric"
+)
+
+var TransliterationScripts = []string{ScriptAuto, ScriptLatin, ScriptCyrillic, ScriptGreek, ScriptHanzi, ScriptPinyin, ScriptPinyinNumeric}
+
+var cyrillicLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+	// Ukrainian, Belarusian, Serbian and Macedonian letters
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u", 'ђ': "dj", 'ј': "j", 'љ': "lj",
+	'њ': "nj", 'ћ': "c", 'џ': "dz", 'ѓ': "gj", 'ќ': "kj", 'ѕ': "dz",
+}
+
+var greekLatin = map[rune]string{
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th",
+	'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p",
+	'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps",
+	'ω': "o",
+}
+
+var greekDigraphs = map[string]string{
+	"ου": "ou", "αυ": "av", "ευ": "ev", "ηυ": "iv", "γγ": "ng", "γκ": "gk", "γξ": "nx", "γχ": "nch",
+}
+
+//go:embed data/hanzi-pinyin.txt
+var hanziPinyinList string
+
+var hanziPinyin = func() map[rune]string {
+	table := make(map[rune]string)
+	for _, line := range strings.Split(hanziPinyinList, "\n") {
+		if hanzi, pinyin, ok := strings.Cut(strings.TrimSpace(line), " "); ok {
+			table[[]rune(hanzi)[0]] = pinyin
+		}
+	}
+	return table
+}()
+
+var pinyinToneMarks = map[rune][4]rune{
+	'a': {'ā', 'á', 'ǎ', 'à'},
+	'e': {'ē', 'é', 'ě', 'è'},
+	'i': {'ī', 'í', 'ǐ', 'ì'},
+	'o': {'ō', 'ó', 'ǒ', 'ò'},
+	'u': {'ū', 'ú', 'ǔ', 'ù'},
+	'ü': {'ǖ', 'ǘ', 'ǚ', 'ǜ'},
+}
+
+var pinyinMarkedVowels = func() map[rune][2]rune {
+	marked := make(map[rune][2]rune)
+	for vowel, tones := range pinyinToneMarks {
+		for i, r := range tones {
+			marked[r] = [2]rune{vowel, rune('1' + i)}
+			marked[unicode.ToUpper(r)] = [2]rune{unicode.ToUpper(vowel), rune('1' + i)}
+		}
+	}
+	return marked
+}()
+
+func Transliterate(text, from, to string) (string, error) {
+	if from == "" {
+		from = ScriptAuto
+	}
+	if to == "" {
+		to = ScriptLatin
+	}
+	switch {
+	case from == ScriptPinyinNumeric && to == ScriptPinyin:
+		return pinyinNumbersToMarks(text), nil
+	case from == ScriptPinyin && to == ScriptPinyinNumeric:
+		return pinyinMarksToNumbers(text), nil
+	case from == ScriptPinyin && to == ScriptLatin:
+		return stripDiacritics(text), nil
+	}
+
+	switch from {
+	case ScriptAuto, ScriptCyrillic, ScriptGreek, ScriptHanzi:
+	default:
+		return "", fmt.Errorf("%w: cannot transliterate from %q", ErrInvalidOption, from)
+	}
+	switch to {
+	case ScriptLatin, ScriptPinyin, ScriptPinyinNumeric:
+	default:
+		return "", fmt.Errorf("%w: cannot transliterate to %q", ErrInvalidOption, to)
+	}
+	if (to == ScriptPinyin || to == ScriptPinyinNumeric) && from != ScriptHanzi && from != ScriptAuto {
+		return "", fmt.Errorf("%w: %s output is only available for Chinese text", ErrInvalidOption, to)
+	}
+
+	if from == ScriptAuto || from == ScriptCyrillic {
+		text = transliterateCyrillic(text)
+	}
+	if from == ScriptAuto || from == ScriptGreek {
+		text = transliterateGreek(text)
+	}
+	if from == ScriptAuto || from == ScriptHanzi {
+		text = transliterateHanzi(text, to)
+	}
+	return text, nil
+}
+
+// Щука -> Shchuka, but ЩУКА -> SHCHUKA
+func applyLetterCase(latin string, upper, wordUpper bool) string {
+	switch {
+	case !upper || latin == "":
+		return latin
+	case wordUpper:
+		return strings.ToUpper(latin)
+	}
+	return capitalizeFirst(latin)
+}
+
+func isUpperNeighbour(runes []rune, i int) bool {
+	return i+1 < len(runes) && unicode.IsUpper(runes[i+1]) || i > 0 && unicode.IsUpper(runes[i-1]) && (i+1 >= len(runes) || !unicode.IsLower(runes[i+1]))
+}
+
+func transliterateCyrillic(text string) string {
+	runes := []rune(text)
+	var b strings.Builder
+	for i, r := range runes {
+		latin, ok := cyrillicLatin[unicode.ToLower(r)]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteString(applyLetterCase(latin, unicode.IsUpper(r), isUpperNeighbour(runes, i)))
+	}
+	return b.String()
+}
+
+func transliterateGreek(text string) string {
+	// drop tonos and dialytika so accented vowels map like plain ones
+	runes := []rune(norm.NFD.String(text))
+	var b strings.Builder
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if unicode.Is(unicode.Mn, r) && i > 0 && unicode.Is(unicode.Greek, runes[i-1]) {
+			continue
+		}
+		lower := unicode.ToLower(r)
+		if i+1 < len(runes) {
+			if latin, ok := greekDigraphs[string([]rune{lower, unicode.ToLower(runes[i+1])})]; ok {
+				b.WriteString(applyLetterCase(latin, unicode.IsUpper(r), isUpperNeighbour(runes, i)))
+				i++
+				continue
+			}
+		}
+		latin, ok := greekLatin[lower]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteString(applyLetterCase(latin, unicode.IsUpper(r), isUpperNeighbour(runes, i)))
+	}
+	return norm.NFC.String(b.String())
+}
+
+func transliterateHanzi(text, to string) string {
+	var b strings.Builder
+	// syllables are space separated, and split from neighbouring letters or digits
+	prevWord, prevSyllable := false, false
+	for _, r := range text {
+		pinyin, ok := hanziPinyin[r]
+		if !ok {
+			if prevSyllable && isWordRune(r) {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			prevWord, prevSyllable = isWordRune(r), false
+			continue
+		}
+		if prevWord {
+			b.WriteByte(' ')
+		}
+		switch to {
+		case ScriptPinyin:
+			pinyin = pinyinNumbersToMarks(pinyin)
+		case ScriptLatin:
+			pinyin = strings.TrimRight(strings.ReplaceAll(pinyin, "v", "u"), "12345")
+		}
+		b.WriteString(pinyin)
+		prevWord, prevSyllable = true, true
+	}
+	return b.String()
+}
+
+func isPinyinVowel(r rune) bool {
+	return strings.ContainsRune("aeiouüvAEIOUÜV", r)
+}
+
+// mark goes on a or e, on the o of "ou", otherwise on the last vowel
+func markSyllable(syllable string, tone int) string {
+	syllable = strings.NewReplacer("u:", "ü", "U:", "Ü", "v", "ü", "V", "Ü").Replace(syllable)
+	if tone < 1 || tone > 4 {
+		return syllable
+	}
+	runes := []rune(syllable)
+	target := -1
+	for i, r := range runes {
+		switch l := unicode.ToLower(r); {
+		case l == 'a' || l == 'e':
+			target = i
+		case l == 'o' && i+1 < len(runes) && unicode.ToLower(runes[i+1]) == 'u':
+			target = i
+		}
+		if target >= 0 {
+			break
+		}
+	}
+	if target < 0 {
+		for i := len(runes) - 1; i >= 0; i-- {
+			if isPinyinVowel(runes[i]) {
+				target = i
+				break
+			}
+		}
+	}
+	if target < 0 {
+		return syllable
+	}
+	lower := unicode.ToLower(runes[target])
+	marked := pinyinToneMarks[lower][tone-1]
+	if unicode.IsUpper(runes[target]) {
+		marked = unicode.ToUpper(marked)
+	}
+	runes[target] = marked
+	return string(runes)
+}
+
+func pinyinNumbersToMarks(text string) string {
+	var b strings.Builder
+	runes := []rune(text)
+	start := -1
+	for i := 0; i <= len(runes); i++ {
+		var r rune
+		if i < len(runes) {
+			r = runes[i]
+		}
+		if r != 0 && (unicode.IsLetter(r) || r == ':') {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			syllable := string(runes[start:i])
+			if r >= '1' && r <= '5' {
+				b.WriteString(markSyllable(syllable, int(r-'0')))
+				start = -1
+				continue
+			}
+			b.WriteString(syllable)
+			start = -1
+		}
+		if r != 0 {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func pinyinMarksToNumbers(text string) string {
+	runes := []rune(norm.NFC.String(text))
+	var b strings.Builder
+	for i := 0; i < len(runes); i++ {
+		plain, ok := pinyinMarkedVowels[runes[i]]
+		if !ok {
+			b.WriteRune(runes[i])
+			continue
+		}
+		b.WriteRune(plain[0])
+		j := i + 1
+		for j < len(runes) && isPinyinVowel(runes[j]) {
+			j++
+		}
+		// a trailing n, ng or r belongs to this syllable unless a vowel follows it
+		vowelAt := func(k int) bool {
+			if k >= len(runes) {
+				return false
+			}
+			_, marked := pinyinMarkedVowels[runes[k]]
+			return isPinyinVowel(runes[k]) || marked
+		}
+		switch {
+		case j+1 < len(runes) && unicode.ToLower(runes[j]) == 'n' && unicode.ToLower(runes[j+1]) == 'g' && !vowelAt(j+2):
+			j += 2
+		case j < len(runes) && (unicode.ToLower(runes[j]) == 'n' || unicode.ToLower(runes[j]) == 'r') && !vowelAt(j+1):
+			j++
+		}
+		b.WriteString(string(runes[i+1 : j]))
+		b.WriteRune(plain[1])
+		i = j - 1
+	}
+	return b.String()
+}
+
+func stripDiacritics(text string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(text) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(b.String())
+}
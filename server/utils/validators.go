@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Validator string
+
+const (
+	ValidateLuhn   Validator = "luhn"
+	ValidateIBAN   Validator = "iban"
+	ValidateISBN10 Validator = "isbn10"
+	ValidateISBN13 Validator = "isbn13"
+	// either ISBN form
+	ValidateISBN Validator = "isbn"
+	// EAN-8, UPC-A, EAN-13 and GTIN-14 share one check digit
+	ValidateEAN Validator = "ean"
+)
+
+var Validators = []Validator{ValidateLuhn, ValidateIBAN, ValidateISBN10, ValidateISBN13, ValidateISBN, ValidateEAN}
+
+func (Validator) Values() []string {
+	return EnumValues(Validators)
+}
+
+func (v Validator) Valid() bool {
+	return EnumValid(Validators, v)
+}
+
+type ValidationMode string
+
+const (
+	// ValidationAnnotate appends a tab and "valid" or "invalid" to each line
+	ValidationAnnotate    ValidationMode = "annotate"
+	ValidationKeepValid   ValidationMode = "keepValid"
+	ValidationKeepInvalid ValidationMode = "keepInvalid"
+)
+
+var ValidationModes = []ValidationMode{ValidationAnnotate, ValidationKeepValid, ValidationKeepInvalid}
+
+func (ValidationMode) Values() []string {
+	return EnumValues(ValidationModes)
+}
+
+func (m ValidationMode) Valid() bool {
+	return EnumValid(ValidationModes, m)
+}
+
+type ValidateOptions struct {
+	Validator Validator      `json:"validator" binding:"required"`
+	Mode      ValidationMode `json:"mode"`
+	// Field picks the value out of a delimited line, as in sorting; 0 checks
+	// the whole line
+	Field     int    `json:"field" min:"0"`
+	Delimiter string `json:"delimiter"`
+}
+
+type ValidationSummary struct {
+	Valid   int `json:"valid"`
+	Invalid int `json:"invalid"`
+}
+
+// ValidateLines checks the value on every non-blank line with the chosen
+// validator. Spaces and hyphens inside a value are ignored, so grouped card
+// numbers and printed IBANs check as written. Blank lines are kept when
+// annotating and dropped when filtering.
+func ValidateLines(text string, opts ValidateOptions) (string, ValidationSummary, error) {
+	var summary ValidationSummary
+	if !opts.Validator.Valid() {
+		return "", summary, fmt.Errorf("%w: unknown validator %q", ErrInvalidOption, opts.Validator)
+	}
+	if opts.Mode == "" {
+		opts.Mode = ValidationAnnotate
+	}
+	if !opts.Mode.Valid() {
+		return "", summary, fmt.Errorf("%w: unknown validation mode %q", ErrInvalidOption, opts.Mode)
+	}
+	if opts.Field < 0 {
+		return "", summary, fmt.Errorf("%w: field must be positive, got %d", ErrInvalidOption, opts.Field)
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if opts.Mode == ValidationAnnotate {
+				out = append(out, line)
+			}
+			continue
+		}
+		ok := CheckValue(opts.Validator, sortKey(line, SortOptions{Field: opts.Field, Delimiter: opts.Delimiter, CaseSensitive: true}))
+		if ok {
+			summary.Valid++
+		} else {
+			summary.Invalid++
+		}
+		switch {
+		case opts.Mode == ValidationAnnotate && ok:
+			out = append(out, line+"\tvalid")
+		case opts.Mode == ValidationAnnotate:
+			out = append(out, line+"\tinvalid")
+		case ok == (opts.Mode == ValidationKeepValid):
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n"), summary, nil
+}
+
+// CheckValue reports whether value passes the validator's format and check
+// digit. An unknown validator accepts nothing.
+func CheckValue(v Validator, value string) bool {
+	value = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '\t' {
+			return -1
+		}
+		return r
+	}, value)
+
+	switch v {
+	case ValidateLuhn:
+		return len(value) >= 2 && allDigits(value) && luhnValid(value)
+	case ValidateIBAN:
+		return ibanValid(strings.ToUpper(value))
+	case ValidateISBN10:
+		return isbn10Valid(value)
+	case ValidateISBN13:
+		return len(value) == 13 && (strings.HasPrefix(value, "978") || strings.HasPrefix(value, "979")) && gtinValid(value)
+	case ValidateISBN:
+		return isbn10Valid(value) || CheckValue(ValidateISBN13, value)
+	case ValidateEAN:
+		switch len(value) {
+		case 8, 12, 13, 14:
+			return gtinValid(value)
+		}
+	}
+	return false
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// gtinValid checks the GS1 check digit: weights 3 and 1 alternate leftwards
+// from the digit before the check digit
+func gtinValid(digits string) bool {
+	if !allDigits(digits) {
+		return false
+	}
+	sum := 0
+	for i := 1; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10-sum%10)%10 == int(digits[len(digits)-1]-'0')
+}
+
+func isbn10Valid(s string) bool {
+	if len(s) != 10 || !allDigits(s[:9]) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(s[i]-'0')
+	}
+	switch last := s[9]; {
+	case last == 'X' || last == 'x':
+		sum += 10
+	case last >= '0' && last <= '9':
+		sum += int(last - '0')
+	default:
+		return false
+	}
+	return sum%11 == 0
+}
+
+// ibanValid checks the ISO 13616 shape and the mod-97 check digits; the
+// country's own length and format rules are not consulted
+func ibanValid(s string) bool {
+	if len(s) < 15 || len(s) > 34 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		letter, digit := c >= 'A' && c <= 'Z', c >= '0' && c <= '9'
+		switch {
+		case i < 2 && !letter, i >= 2 && i < 4 && !digit, !letter && !digit:
+			return false
+		}
+	}
+	// the country and check digits move to the end and letters count as 10-35
+	remainder := 0
+	for _, c := range s[4:] + s[:4] {
+		if c >= 'A' {
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		} else {
+			remainder = (remainder*10 + int(c-'0')) % 97
+		}
+	}
+	return remainder == 1
+}
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	_ "embed"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const vocabularyListLimit = 10
+
+//go:embed data/english-frequency.txt
+var englishFrequencyList string
+
+var englishFrequencyRanks = func() map[string]int {
+	ranks := make(map[string]int)
+	for i, w := range strings.Fields(englishFrequencyList) {
+		if _, ok := ranks[w]; !ok {
+			ranks[w] = i + 1
+		}
+	}
+	return ranks
+}()
+
+var vocabularyWordPattern = regexp.MustCompile(`\pL+(?:['’]\pL+)*`)
+
+type FrequencyBand struct {
+	Name    string  `json:"name"`
+	MaxRank int     `json:"maxRank"`
+	Words   int     `json:"words"`
+	Percent float64 `json:"percent"`
+}
+
+type VocabularyWord struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+	Rank  int    `json:"rank"`
+}
+
+type VocabularyReport struct {
+	Words             int              `json:"words"`
+	UniqueWords       int              `json:"uniqueWords"`
+	UniqueRatio       float64          `json:"uniqueRatio"`
+	AverageWordLength float64          `json:"averageWordLength"`
+	Bands             []FrequencyBand  `json:"bands"`
+	LongestWords      []VocabularyWord `json:"longestWords"`
+	RarestWords       []VocabularyWord `json:"rarestWords"`
+}
+
+func wordRank(word string) int {
+	if rank, ok := englishFrequencyRanks[word]; ok {
+		return rank
+	}
+	word = strings.TrimSuffix(strings.TrimSuffix(word, "'s"), "’s")
+	candidates := []string{word}
+	for _, rule := range [][2]string{
+		{"ies", "y"}, {"ied", "y"}, {"es", ""}, {"s", ""},
+		{"ing", ""}, {"ing", "e"}, {"ed", ""}, {"ed", "e"}, {"d", ""},
+		{"er", ""}, {"est", ""}, {"ly", ""},
+	} {
+		if stem, ok := strings.CutSuffix(word, rule[0]); ok && len(stem) >= 2 {
+			candidates = append(candidates, stem+rule[1])
+		}
+	}
+	for _, c := range candidates {
+		if rank, ok := englishFrequencyRanks[c]; ok {
+			return rank
+		}
+	}
+	return 0
+}
+
+func VocabularyProfile(text string) VocabularyReport {
+	bands := []FrequencyBand{
+		{Name: "top100", MaxRank: 100},
+		{Name: "top500", MaxRank: 500},
+		{Name: "top1000", MaxRank: len(englishFrequencyRanks)},
+		{Name: "rare"},
+	}
+
+	counts := make(map[string]int)
+	var order []string
+	total, letters := 0, 0
+	for _, w := range vocabularyWordPattern.FindAllString(text, -1) {
+		w = strings.ToLower(w)
+		if counts[w] == 0 {
+			order = append(order, w)
+		}
+		counts[w]++
+		total++
+		letters += utf8.RuneCountInString(w)
+	}
+
+	words := make([]VocabularyWord, 0, len(order))
+	for _, w := range order {
+		rank := wordRank(w)
+		words = append(words, VocabularyWord{Word: w, Count: counts[w], Rank: rank})
+		band := len(bands) - 1
+		for i := 0; i < len(bands)-1; i++ {
+			if rank > 0 && rank <= bands[i].MaxRank {
+				band = i
+				break
+			}
+		}
+		bands[band].Words += counts[w]
+	}
+
+	report := VocabularyReport{
+		Words:        total,
+		UniqueWords:  len(words),
+		Bands:        bands,
+		LongestWords: []VocabularyWord{},
+		RarestWords:  []VocabularyWord{},
+	}
+	if total == 0 {
+		return report
+	}
+	report.UniqueRatio = round2(float64(len(words)) / float64(total))
+	report.AverageWordLength = round2(float64(letters) / float64(total))
+	for i := range report.Bands {
+		report.Bands[i].Percent = round2(100 * float64(report.Bands[i].Words) / float64(total))
+	}
+
+	longest := append([]VocabularyWord(nil), words...)
+	sort.SliceStable(longest, func(i, j int) bool {
+		return utf8.RuneCountInString(longest[i].Word) > utf8.RuneCountInString(longest[j].Word)
+	})
+	report.LongestWords = longest[:min(vocabularyListLimit, len(longest))]
+
+	rarest := append([]VocabularyWord(nil), words...)
+	sort.SliceStable(rarest, func(i, j int) bool {
+		a, b := rarest[i], rarest[j]
+		if (a.Rank == 0) != (b.Rank == 0) {
+			return a.Rank == 0
+		}
+		if a.Rank != b.Rank {
+			return a.Rank > b.Rank
+		}
+		return a.Count < b.Count
+	})
+	report.RarestWords = rarest[:min(vocabularyListLimit, len(rarest))]
+	return report
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
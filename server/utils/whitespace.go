@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type WhitespaceMarker struct {
+	Marker    string `json:"marker"`
+	Name      string `json:"name"`
+	CodePoint string `json:"codePoint"`
+	Count     int    `json:"count"`
+}
+
+type WhitespaceView struct {
+	Text   string             `json:"text"`
+	Legend []WhitespaceMarker `json:"legend"`
+}
+
+var whitespaceMarkers = map[rune]WhitespaceMarker{
+	' ':    {Marker: "·", Name: "SPACE"},
+	'\t':   {Marker: "→", Name: "CHARACTER TABULATION"},
+	'\n':   {Marker: "¶", Name: "LINE FEED"},
+	'\r':   {Marker: "␍", Name: "CARRIAGE RETURN"},
+	'\v':   {Marker: "␋", Name: "LINE TABULATION"},
+	'\f':   {Marker: "␌", Name: "FORM FEED"},
+	0x00A0: {Marker: "⍽", Name: "NO-BREAK SPACE"},
+	0x202F: {Marker: "⍽", Name: "NARROW NO-BREAK SPACE"},
+	0x2028: {Marker: "↵", Name: "LINE SEPARATOR"},
+	0x2029: {Marker: "¶", Name: "PARAGRAPH SEPARATOR"},
+}
+
+func VisualizeWhitespace(text string) WhitespaceView {
+	counts := make(map[rune]*WhitespaceMarker)
+	var b strings.Builder
+	b.Grow(len(text))
+
+	for _, r := range text {
+		marker, ok := whitespaceMarkers[r]
+		if !ok {
+			if name, invisible := invisibleName(r); invisible {
+				marker = WhitespaceMarker{Marker: fmt.Sprintf("[U+%04X]", r), Name: name}
+			} else if unicode.IsSpace(r) {
+				marker = WhitespaceMarker{Marker: "␣", Name: "OTHER SPACE"}
+			} else {
+				b.WriteRune(r)
+				continue
+			}
+		}
+
+		b.WriteString(marker.Marker)
+		if r == '\n' || r == 0x2028 || r == 0x2029 {
+			b.WriteByte('\n')
+		}
+
+		m, seen := counts[r]
+		if !seen {
+			marker.CodePoint = fmt.Sprintf("U+%04X", r)
+			m = &marker
+			counts[r] = m
+		}
+		m.Count++
+	}
+
+	runes := make([]rune, 0, len(counts))
+	for r := range counts {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	legend := make([]WhitespaceMarker, len(runes))
+	for i, r := range runes {
+		legend[i] = *counts[r]
+	}
+
+	return WhitespaceView{Text: b.String(), Legend: legend}
+}
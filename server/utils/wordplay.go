@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type LetterOptions struct {
+	CaseSensitive   bool `json:"caseSensitive"`
+	KeepPunctuation bool `json:"keepPunctuation"`
+	KeepDiacritics  bool `json:"keepDiacritics"`
+	KeepSpaces      bool `json:"keepSpaces"`
+}
+
+type AnagramGroup struct {
+	Signature string   `json:"signature"`
+	Lines     []string `json:"lines"`
+}
+
+func letters(text string, opts LetterOptions) []rune {
+	if !opts.KeepDiacritics {
+		text = norm.NFD.String(text)
+	}
+	var out []rune
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			if opts.KeepDiacritics {
+				out = append(out, r)
+			}
+			continue
+		case unicode.IsSpace(r):
+			if !opts.KeepSpaces {
+				continue
+			}
+		case !unicode.IsLetter(r) && !unicode.IsNumber(r):
+			if !opts.KeepPunctuation {
+				continue
+			}
+		}
+		if !opts.CaseSensitive {
+			r = unicode.ToLower(r)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func IsPalindrome(text string, opts LetterOptions) bool {
+	runes := letters(text, opts)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		if runes[i] != runes[j] {
+			return false
+		}
+	}
+	return true
+}
+
+func anagramSignature(text string) string {
+	runes := letters(text, LetterOptions{})
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	return string(runes)
+}
+
+func AreAnagrams(a, b string) bool {
+	sa := anagramSignature(a)
+	return sa != "" && sa == anagramSignature(b)
+}
+
+func GroupAnagrams(lines string) []AnagramGroup {
+	index := make(map[string]int)
+	groups := []AnagramGroup{}
+	for _, line := range strings.Split(lines, "\n") {
+		sig := anagramSignature(line)
+		if sig == "" {
+			continue
+		}
+		i, ok := index[sig]
+		if !ok {
+			i = len(groups)
+			index[sig] = i
+			groups = append(groups, AnagramGroup{Signature: sig})
+		}
+		groups[i].Lines = append(groups[i].Lines, line)
+	}
+	return groups
+}
+
+type InitialsUnit string
+
+const (
+	InitialsLines     InitialsUnit = "lines"
+	InitialsSentences InitialsUnit = "sentences"
+	InitialsWords     InitialsUnit = "words"
+)
+
+var InitialsUnits = []InitialsUnit{InitialsLines, InitialsSentences, InitialsWords}
+
+func (InitialsUnit) Values() []string {
+	return EnumValues(InitialsUnits)
+}
+
+func (u InitialsUnit) Valid() bool {
+	return u == "" || EnumValid(InitialsUnits, u)
+}
+
+type InitialsCase string
+
+const (
+	InitialsPreserve InitialsCase = "preserve"
+	InitialsUpper    InitialsCase = "upper"
+	InitialsLower    InitialsCase = "lower"
+)
+
+var InitialsCases = []InitialsCase{InitialsPreserve, InitialsUpper, InitialsLower}
+
+func (InitialsCase) Values() []string {
+	return EnumValues(InitialsCases)
+}
+
+func (c InitialsCase) Valid() bool {
+	return c == "" || EnumValid(InitialsCases, c)
+}
+
+type InitialsOptions struct {
+	Unit      InitialsUnit `json:"unit"`
+	FirstWord bool         `json:"firstWord"`
+	Separator string       `json:"separator"`
+	Case      InitialsCase `json:"case"`
+}
+
+func (o InitialsOptions) Validate() error {
+	if !o.Unit.Valid() {
+		return fmt.Errorf("%w: unknown initials unit %q", ErrInvalidOption, o.Unit)
+	}
+	if !o.Case.Valid() {
+		return fmt.Errorf("%w: unknown initials case %q", ErrInvalidOption, o.Case)
+	}
+	return nil
+}
+
+func ExtractInitials(text string, opts InitialsOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	var units []string
+	switch opts.Unit {
+	case "", InitialsLines:
+		units = strings.Split(text, "\n")
+	case InitialsSentences:
+		sentences, err := SplitSentences(text, "")
+		if err != nil {
+			return "", err
+		}
+		for _, s := range sentences {
+			units = append(units, s.Text)
+		}
+	case InitialsWords:
+		units = strings.Fields(text)
+	}
+
+	var parts []string
+	for _, unit := range units {
+		word := strings.TrimFunc(firstField(unit), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		if word == "" {
+			continue
+		}
+		if !opts.FirstWord {
+			word = word[:nextGrapheme(word)]
+		}
+		switch opts.Case {
+		case InitialsUpper:
+			word = strings.ToUpper(word)
+		case InitialsLower:
+			word = strings.ToLower(word)
+		}
+		parts = append(parts, word)
+	}
+	return strings.Join(parts, opts.Separator), nil
+}
+
+func firstField(s string) string {
+	for _, f := range strings.Fields(s) {
+		if strings.IndexFunc(f, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) >= 0 {
+			return f
+		}
+	}
+	return ""
+}
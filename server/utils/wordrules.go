@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	countURLPattern    = regexp.MustCompile(`^(?:[A-Za-z][A-Za-z0-9+.-]*://|www\.)\S+`)
+	countNumberPattern = regexp.MustCompile(`^\d(?:[\d.,:/-]*\d)?$`)
+	countAlnumPattern  = regexp.MustCompile(`[\pL\pN]+`)
+
+	markdownInlineCode = regexp.MustCompile("`[^`\n]*`")
+	markdownImage      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownHTMLTag    = regexp.MustCompile(`</?[A-Za-z][^>\n]*>`)
+	markdownLinePrefix = regexp.MustCompile(`(?m)^[ \t]*(?:#{1,6}[ \t]+|>[ \t>]*|[-*+][ \t]+(?:\[[ xX]\][ \t]+)?|\d+[.)][ \t]+)`)
+	markdownRule       = regexp.MustCompile(`(?m)^[ \t]*(?:[-*_][ \t]*){3,}$|^[ \t]*\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	markdownEmphasis   = regexp.MustCompile(`\*+|~~|(?:^|\s)_+|_+(?:\s|$)`)
+)
+
+func (o CountOptions) countWords(text string) int {
+	if o.ExcludeCode {
+		text = stripCode(text)
+	}
+	if o.StripMarkdown {
+		text = stripMarkdownSyntax(text)
+	}
+	return o.countFields(text)
+}
+
+func (o CountOptions) countFields(text string) int {
+	n := 0
+	for _, field := range strings.Fields(text) {
+		n += o.fieldWords(field)
+	}
+	return n
+}
+
+// with no rules set every whitespace-separated field is one word
+func (o CountOptions) fieldWords(field string) int {
+	if countURLPattern.MatchString(field) {
+		if o.SplitURLs {
+			return max(len(countAlnumPattern.FindAllString(field, -1)), 1)
+		}
+		return 1
+	}
+	core := strings.TrimFunc(field, func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSymbol(r) })
+	if o.ExcludeNumbers && countNumberPattern.MatchString(core) {
+		return 0
+	}
+	if !o.SplitHyphenated && !o.SplitContractions {
+		return 1
+	}
+
+	parts := []string{core}
+	if o.SplitHyphenated {
+		parts = strings.FieldsFunc(core, func(r rune) bool { return r == '-' || r == '\u2010' || r == '\u2011' })
+	}
+	n := 0
+	for _, part := range parts {
+		if !countAlnumPattern.MatchString(part) {
+			continue
+		}
+		n++
+		if o.SplitContractions {
+			runes := []rune(part)
+			for i := 1; i+1 < len(runes); i++ {
+				if (runes[i] == '\'' || runes[i] == '’') && unicode.IsLetter(runes[i-1]) && unicode.IsLetter(runes[i+1]) {
+					n++
+				}
+			}
+		}
+	}
+	return max(n, 1)
+}
+
+func stripCode(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	fenced := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if !fenced {
+			kept = append(kept, line)
+		}
+	}
+	return markdownInlineCode.ReplaceAllString(strings.Join(kept, "\n"), " ")
+}
+
+func stripMarkdownSyntax(text string) string {
+	text = markdownRule.ReplaceAllString(text, "")
+	text = markdownImage.ReplaceAllString(text, "$1")
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = markdownHTMLTag.ReplaceAllString(text, " ")
+	text = markdownLinePrefix.ReplaceAllString(text, "")
+	text = markdownEmphasis.ReplaceAllString(text, " ")
+	return strings.NewReplacer("|", " ", "`", "").Replace(text)
+}
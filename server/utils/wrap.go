@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var listItemPattern = regexp.MustCompile(`^\s*(?:[-*+•▪◦‣]|\(?\d{1,3}[.)]|\(?[a-zA-Z][.)])\s+`)
+
+func UnwrapText(text string) string {
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	fenced := false
+	joinable := false
+
+	for _, raw := range lines {
+		line := strings.TrimRight(raw, "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			out = append(out, line)
+			joinable = false
+			continue
+		}
+		if fenced || trimmed == "" {
+			out = append(out, line)
+			joinable = false
+			continue
+		}
+
+		prefix, body := splitQuotePrefix(line)
+		if joinable && len(out) > 0 {
+			prevPrefix, prevBody := splitQuotePrefix(out[len(out)-1])
+			if prevPrefix == prefix && !startsNewParagraph(prevBody, body) {
+				out[len(out)-1] = prevPrefix + joinWrapped(prevBody, strings.TrimLeft(body, " \t"))
+				joinable = !strings.HasSuffix(line, "  ")
+				continue
+			}
+		}
+
+		out = append(out, line)
+		joinable = !strings.HasSuffix(line, "  ")
+	}
+	return strings.Join(out, "\n")
+}
+
+func splitQuotePrefix(line string) (string, string) {
+	i := 0
+	for i < len(line) && (line[i] == '>' || line[i] == ' ' && i > 0 && line[i-1] == '>') {
+		i++
+	}
+	return line[:i], line[i:]
+}
+
+func startsNewParagraph(prev, next string) bool {
+	if listItemPattern.MatchString(next) {
+		return true
+	}
+	indent := func(s string) int { return len(s) - len(strings.TrimLeft(s, " \t")) }
+	if marker := listItemPattern.FindString(prev); marker != "" && indent(next) <= len(marker) {
+		return false
+	}
+	if indent(next) > indent(prev) && indent(next) >= 2 {
+		return true
+	}
+	if strings.HasPrefix(strings.TrimSpace(next), "|") || strings.HasPrefix(strings.TrimSpace(next), "#") {
+		return true
+	}
+	return false
+}
+
+func joinWrapped(prev, next string) string {
+	prev = strings.TrimRight(prev, " \t")
+	last, _ := utf8.DecodeLastRuneInString(prev)
+	first, _ := utf8.DecodeRuneInString(next)
+
+	if last == '-' && len(prev) > 1 && unicode.IsLower(first) {
+		before, _ := utf8.DecodeLastRuneInString(prev[:len(prev)-1])
+		if unicode.IsLetter(before) {
+			return prev[:len(prev)-1] + next
+		}
+	}
+	if last == '/' || last == '\u00ad' {
+		return strings.TrimSuffix(prev, "\u00ad") + next
+	}
+	return prev + " " + next
+}
+
+type WrapLineOptions struct {
+	Prefix    string `json:"prefix"`
+	Suffix    string `json:"suffix"`
+	Separator string `json:"separator"`
+	Header    string `json:"header"`
+	Footer    string `json:"footer"`
+	SkipBlank bool   `json:"skipBlank"`
+}
+
+func WrapLines(text, prefix, suffix string) string {
+	return WrapLinesWithOptions(text, WrapLineOptions{Prefix: prefix, Suffix: suffix, Separator: "\n"})
+}
+
+func WrapLinesWithOptions(text string, opts WrapLineOptions) string {
+	template := strings.Contains(opts.Prefix, "{line}") || strings.Contains(opts.Suffix, "{line}")
+	var out []string
+	n := 0
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if opts.SkipBlank && strings.TrimSpace(line) == "" {
+			continue
+		}
+		n++
+		expand := strings.NewReplacer("{line}", line, "{n}", strconv.Itoa(n)).Replace
+		if template {
+			out = append(out, expand(opts.Prefix)+expand(opts.Suffix))
+		} else {
+			out = append(out, expand(opts.Prefix)+line+expand(opts.Suffix))
+		}
+	}
+	return opts.Header + strings.Join(out, opts.Separator) + opts.Footer
+}
+
+type LineLengthViolation struct {
+	Line   int    `json:"line"`
+	Length int    `json:"length"`
+	Text   string `json:"text"`
+}
+
+func CheckLineLengths(text string, limit int) ([]LineLengthViolation, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: line length limit must be positive, got %d", ErrInvalidOption, limit)
+	}
+	violations := []LineLengthViolation{}
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if n := DisplayWidth(line); n > limit {
+			violations = append(violations, LineLengthViolation{Line: i + 1, Length: n, Text: line})
+		}
+	}
+	return violations, nil
+}
+
+func FixLineLengths(text string, limit int) (string, error) {
+	if limit <= 0 {
+		return "", fmt.Errorf("%w: line length limit must be positive, got %d", ErrInvalidOption, limit)
+	}
+	var out []string
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if inFence || DisplayWidth(line) <= limit {
+			out = append(out, line)
+			continue
+		}
+		out = append(out, rewrapLine(line, limit)...)
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+func rewrapLine(line string, limit int) []string {
+	quote, rest := splitQuotePrefix(line)
+	body := strings.TrimLeft(rest, " \t")
+	first := quote + rest[:len(rest)-len(body)]
+	if marker := listItemPattern.FindString(rest); marker != "" {
+		first = quote + marker
+		body = rest[len(marker):]
+	}
+	next := quote + strings.Repeat(" ", DisplayWidth(first)-DisplayWidth(quote))
+
+	var lines []string
+	current, prefix := "", first
+	for _, word := range strings.Fields(body) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if current != "" && DisplayWidth(prefix+candidate) > limit {
+			lines = append(lines, prefix+current)
+			current, prefix = word, next
+			continue
+		}
+		current = candidate
+	}
+	return append(lines, prefix+current)
+}
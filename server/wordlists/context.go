@@ -0,0 +1,41 @@
+package wordlists
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/auth"
+)
+
+var ErrUnavailable = errors.New("named wordlists require an API key")
+
+type resolverKey struct{}
+
+type resolver func(kind Kind, names []string) ([]string, error)
+
+// Middleware lets operations further down the request resolve the caller's
+// wordlists by name. It has to run after auth.Authenticate.
+func Middleware(s *Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key, ok := auth.CurrentKey(c); ok {
+			ctx := context.WithValue(c.Request.Context(), resolverKey{}, resolver(func(kind Kind, names []string) ([]string, error) {
+				return s.Words(key.ID, kind, names)
+			}))
+			c.Request = c.Request.WithContext(ctx)
+		}
+		c.Next()
+	}
+}
+
+func Resolve(ctx context.Context, kind Kind, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	r, ok := ctx.Value(resolverKey{}).(resolver)
+	if !ok {
+		return nil, ErrUnavailable
+	}
+	return r(kind, names)
+}
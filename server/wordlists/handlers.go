@@ -0,0 +1,127 @@
+package wordlists
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"toolkit-backend/auth"
+	"toolkit-backend/utils"
+)
+
+type SaveRequest struct {
+	Kind        Kind     `json:"kind" binding:"required"`
+	Description string   `json:"description"`
+	Words       []string `json:"words"`
+}
+
+type WordsRequest struct {
+	Words []string `json:"words" binding:"required"`
+}
+
+func owner(c *gin.Context) (string, bool) {
+	key, ok := auth.CurrentKey(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "wordlists require an API key"})
+		return "", false
+	}
+	return key.ID, true
+}
+
+var errorStatuses = []utils.ErrorStatus{
+	{Err: ErrNotFound, Status: http.StatusNotFound},
+	{Err: ErrTooLarge, Status: http.StatusRequestEntityTooLarge},
+}
+
+func statusFor(err error) int {
+	return utils.StatusFor(err, http.StatusBadRequest, errorStatuses...)
+}
+
+func RegisterRoutes(r gin.IRouter, s *Store) {
+	r.GET("/wordlists", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"wordlists": s.List(id), "kinds": Kind("").Values()})
+	})
+
+	r.GET("/wordlists/:name", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		list, err := s.Get(id, c.Param("name"))
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, list)
+	})
+
+	r.PUT("/wordlists/:name", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		var req SaveRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		list, err := s.Save(id, Wordlist{Name: c.Param("name"), Kind: req.Kind, Description: req.Description, Words: req.Words})
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, list)
+	})
+
+	r.POST("/wordlists/:name/words", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		var req WordsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		list, err := s.AddWords(id, c.Param("name"), req.Words)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, list)
+	})
+
+	r.DELETE("/wordlists/:name/words", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		var req WordsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		list, err := s.RemoveWords(id, c.Param("name"), req.Words)
+		if err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, list)
+	})
+
+	r.DELETE("/wordlists/:name", func(c *gin.Context) {
+		id, ok := owner(c)
+		if !ok {
+			return
+		}
+		if err := s.Delete(id, c.Param("name")); err != nil {
+			c.JSON(statusFor(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,200 @@
+package wordlists
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"toolkit-backend/utils"
+)
+
+const (
+	MaxWords = 50000
+	// MaxLists is how many wordlists one key may keep
+	MaxLists = 100
+)
+
+var (
+	ErrNotFound    = errors.New("wordlist not found")
+	ErrInvalidName = errors.New("wordlist names may contain letters, digits, '-', '_' and '.' only")
+	ErrInvalidKind = errors.New("invalid wordlist kind")
+	ErrTooLarge    = errors.New("wordlist limit exceeded")
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type Kind string
+
+const (
+	KindStopWords  Kind = "stopwords"
+	KindProfanity  Kind = "profanity"
+	KindAcronyms   Kind = "acronyms"
+	KindDictionary Kind = "dictionary"
+)
+
+var Kinds = []Kind{KindStopWords, KindProfanity, KindAcronyms, KindDictionary}
+
+func (Kind) Values() []string {
+	return utils.EnumValues(Kinds)
+}
+
+func (k Kind) Valid() bool {
+	return utils.EnumValid(Kinds, k)
+}
+
+type Wordlist struct {
+	Name        string    `json:"name"`
+	Kind        Kind      `json:"kind"`
+	Description string    `json:"description,omitempty"`
+	Words       []string  `json:"words"`
+	Count       int       `json:"count"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type Store struct {
+	mu     sync.RWMutex
+	owners map[string]map[string]*Wordlist
+}
+
+func NewStore() *Store {
+	return &Store{owners: make(map[string]map[string]*Wordlist)}
+}
+
+// normalize trims entries and drops blanks and exact duplicates, keeping first-seen order
+func normalize(words []string) []string {
+	out := make([]string, 0, len(words))
+	seen := make(map[string]bool, len(words))
+	for _, w := range words {
+		w = strings.TrimSpace(w)
+		if w == "" || seen[w] {
+			continue
+		}
+		seen[w] = true
+		out = append(out, w)
+	}
+	return out
+}
+
+func (s *Store) Save(owner string, list Wordlist) (Wordlist, error) {
+	if !validName.MatchString(list.Name) {
+		return Wordlist{}, ErrInvalidName
+	}
+	if !list.Kind.Valid() {
+		return Wordlist{}, fmt.Errorf("%w %q, want one of %s", ErrInvalidKind, list.Kind, strings.Join(list.Kind.Values(), ", "))
+	}
+	list.Words = normalize(list.Words)
+	if len(list.Words) > MaxWords {
+		return Wordlist{}, fmt.Errorf("%w: wordlists hold at most %d words", ErrTooLarge, MaxWords)
+	}
+	list.Count = len(list.Words)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lists, ok := s.owners[owner]
+	if !ok {
+		lists = make(map[string]*Wordlist)
+		s.owners[owner] = lists
+	}
+
+	now := time.Now()
+	list.UpdatedAt = now
+	if existing, ok := lists[list.Name]; ok {
+		list.CreatedAt = existing.CreatedAt
+	} else if len(lists) >= MaxLists {
+		return Wordlist{}, fmt.Errorf("%w: at most %d wordlists per key", ErrTooLarge, MaxLists)
+	} else {
+		list.CreatedAt = now
+	}
+	lists[list.Name] = &list
+	return list, nil
+}
+
+// AddWords merges words into an existing list
+func (s *Store) AddWords(owner, name string, words []string) (Wordlist, error) {
+	list, err := s.Get(owner, name)
+	if err != nil {
+		return Wordlist{}, err
+	}
+	list.Words = append(list.Words, words...)
+	return s.Save(owner, list)
+}
+
+func (s *Store) RemoveWords(owner, name string, words []string) (Wordlist, error) {
+	list, err := s.Get(owner, name)
+	if err != nil {
+		return Wordlist{}, err
+	}
+	drop := make(map[string]bool, len(words))
+	for _, w := range words {
+		drop[strings.TrimSpace(w)] = true
+	}
+	kept := make([]string, 0, len(list.Words))
+	for _, w := range list.Words {
+		if !drop[w] {
+			kept = append(kept, w)
+		}
+	}
+	list.Words = kept
+	return s.Save(owner, list)
+}
+
+func (s *Store) Get(owner, name string) (Wordlist, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list, ok := s.owners[owner][name]
+	if !ok {
+		return Wordlist{}, ErrNotFound
+	}
+	out := *list
+	out.Words = append([]string(nil), list.Words...)
+	return out, nil
+}
+
+// List leaves out the words themselves; fetch a list by name to read them
+func (s *Store) List(owner string) []Wordlist {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]Wordlist, 0, len(s.owners[owner]))
+	for _, l := range s.owners[owner] {
+		summary := *l
+		summary.Words = []string{}
+		out = append(out, summary)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (s *Store) Delete(owner, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.owners[owner][name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.owners[owner], name)
+	return nil
+}
+
+// Words returns the words of the named lists, which must all be of the given kind
+func (s *Store) Words(owner string, kind Kind, names []string) ([]string, error) {
+	var words []string
+	for _, name := range names {
+		list, err := s.Get(owner, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if list.Kind != kind {
+			return nil, fmt.Errorf("%w: %s holds %s, not %s", ErrInvalidKind, name, list.Kind, kind)
+		}
+		words = append(words, list.Words...)
+	}
+	return words, nil
+}